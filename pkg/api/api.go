@@ -562,9 +562,16 @@ func (hs *HTTPServer) registerRoutes() {
 			// POST influx line protocol.
 			liveRoute.Post("/push/:streamId", hs.LivePushGateway.Handle)
 
+			// POST OTLP/HTTP metrics and logs.
+			liveRoute.Post("/otlp/:streamId/v1/metrics", hs.LivePushGateway.HandleOTLPMetrics)
+			liveRoute.Post("/otlp/:streamId/v1/logs", hs.LivePushGateway.HandleOTLPLogs)
+
 			// List available streams and fields
 			liveRoute.Get("/list", routing.Wrap(hs.Live.HandleListHTTP))
 
+			// Recent frames of managed stream channels
+			liveRoute.Get("/history/*", routing.Wrap(hs.Live.HandleHistoryHTTP))
+
 			// Some channels may have info
 			liveRoute.Get("/info/*", routing.Wrap(hs.Live.HandleInfoHTTP))
 		}, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
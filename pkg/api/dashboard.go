@@ -430,6 +430,17 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		Overwrite: cmd.Overwrite,
 	}
 
+	if hs.Live != nil && dash.UID != "" {
+		userDTODisplay, err := user.NewUserDisplayDTOFromRequester(c.SignedInUser)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Error while parsing the user DTO model", err)
+		}
+		// Respect the edit lock of another user who is editing the dashboard over Live
+		if err := hs.Live.GrafanaScope.Dashboards.CheckEditLock(ctx, c.SignedInUser.GetOrgID(), userDTODisplay, dash.UID); err != nil {
+			return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, err)
+		}
+	}
+
 	dashboard, err := hs.DashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled()), dashItem, allowUiUpdate)
 
 	if hs.Live != nil {
@@ -157,6 +157,7 @@ type FrontendSettingsDTO struct {
 	AlertingNoDataOrNullValues string                           `json:"alertingNoDataOrNullValues"`
 	AlertingMinInterval        int64                            `json:"alertingMinInterval"`
 	LiveEnabled                bool                             `json:"liveEnabled"`
+	LiveTransports             []string                         `json:"liveTransports"`
 	AutoAssignOrg              bool                             `json:"autoAssignOrg"`
 
 	VerifyEmailEnabled  bool `json:"verifyEmailEnabled"`
@@ -101,6 +101,12 @@ var (
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
 
+	// MRenderingCacheTotal is a metric counter for image rendering requests served by the render cache
+	MRenderingCacheTotal *prometheus.CounterVec
+
+	// MRenderingCacheSize is a metric gauge for the size of the images in the render cache
+	MRenderingCacheSize prometheus.Gauge
+
 	// MAccessEvaluationCount is a metric gauge for total number of evaluation requests
 	MAccessEvaluationCount prometheus.Counter
 
@@ -417,6 +423,21 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MRenderingCacheTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "rendering_cache_requests_total",
+			Help:      "counter for image rendering requests by render cache result",
+			Namespace: ExporterName,
+		},
+		[]string{"result"},
+	)
+
+	MRenderingCacheSize = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:      "rendering_cache_size_bytes",
+		Help:      "size of the images in the render cache",
+		Namespace: ExporterName,
+	})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -696,6 +717,8 @@ func initMetricVars(reg prometheus.Registerer) {
 		MRenderingSummary,
 		MRenderingUserLookupSummary,
 		MRenderingQueue,
+		MRenderingCacheTotal,
+		MRenderingCacheSize,
 		MAccessPermissionsSummary,
 		MAccessEvaluationsSummary,
 		MAlertingActiveAlerts,
@@ -0,0 +1,176 @@
+package remotecache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	memoryCacheType = "memory"
+	// defaultMemoryMaxSizeMB is the default maximum size of the values of the memory cache.
+	defaultMemoryMaxSizeMB = 100
+)
+
+// memoryStorage caches items in the memory of the Grafana server. The items are not shared between multiple
+// instances of Grafana and are lost on restart. Once the size of the values exceeds the maximum size, the expired
+// items and then the oldest items are removed.
+type memoryStorage struct {
+	maxSize int64
+
+	mtx   sync.RWMutex
+	items map[string]*list.Element
+	// order holds the items from the oldest to the most recently set.
+	order *list.List
+	size  int64
+}
+
+type memoryItem struct {
+	key   string
+	value []byte
+	// expires is zero if the item does not expire.
+	expires time.Time
+}
+
+func (i *memoryItem) expired(now time.Time) bool {
+	return !i.expires.IsZero() && !now.Before(i.expires)
+}
+
+// parseMemoryConnStr returns the maximum size in bytes of a memory cache from its connection string,
+// e.g. `max_size_mb=100`.
+func parseMemoryConnStr(connStr string) (int64, error) {
+	maxSizeMB := int64(defaultMemoryMaxSizeMB)
+	if connStr == "" {
+		return maxSizeMB * 1024 * 1024, nil
+	}
+	for _, rawKeyValue := range strings.Split(connStr, ",") {
+		keyValueTuple := strings.SplitN(rawKeyValue, "=", 2)
+		if len(keyValueTuple) != 2 {
+			return 0, fmt.Errorf("incorrect memory cache connection string format detected for '%v', format is key=value,key=value", rawKeyValue)
+		}
+		switch keyValueTuple[0] {
+		case "max_size_mb":
+			i, err := strconv.ParseInt(keyValueTuple[1], 10, 64)
+			if err != nil || i <= 0 {
+				return 0, fmt.Errorf("value for max_size_mb in memory cache connection string must be a positive number")
+			}
+			maxSizeMB = i
+		default:
+			return 0, fmt.Errorf("unrecognized option '%v' in memory cache connection string", keyValueTuple[0])
+		}
+	}
+	return maxSizeMB * 1024 * 1024, nil
+}
+
+func newMemoryStorage(opts *setting.RemoteCacheOptions) (*memoryStorage, error) {
+	maxSize, err := parseMemoryConnStr(opts.ConnStr)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryStorage{
+		maxSize: maxSize,
+		items:   map[string]*list.Element{},
+		order:   list.New(),
+	}, nil
+}
+
+// Run removes expired items periodically.
+func (s *memoryStorage) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.internalRunGC()
+		}
+	}
+}
+
+func (s *memoryStorage) internalRunGC() {
+	now := getTime()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.removeExpired(now)
+}
+
+// removeExpired must be called with the lock held.
+func (s *memoryStorage) removeExpired(now time.Time) {
+	for _, e := range s.items {
+		if e.Value.(*memoryItem).expired(now) {
+			s.remove(e)
+		}
+	}
+}
+
+// remove must be called with the lock held.
+func (s *memoryStorage) remove(e *list.Element) {
+	item := s.order.Remove(e).(*memoryItem)
+	delete(s.items, item.key)
+	s.size -= int64(len(item.value))
+}
+
+func (s *memoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	e, ok := s.items[key]
+	if !ok || e.Value.(*memoryItem).expired(getTime()) {
+		return nil, ErrCacheItemNotFound
+	}
+	return e.Value.(*memoryItem).value, nil
+}
+
+func (s *memoryStorage) Set(ctx context.Context, key string, value []byte, expire time.Duration) error {
+	now := getTime()
+	item := &memoryItem{key: key, value: value}
+	if expire != 0 {
+		item.expires = now.Add(expire)
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.remove(e)
+	}
+	size := int64(len(value))
+	if size > s.maxSize {
+		return fmt.Errorf("value of %d bytes exceeds the maximum size of the memory cache", size)
+	}
+	if s.size+size > s.maxSize {
+		s.removeExpired(now)
+	}
+	for s.size+size > s.maxSize {
+		s.remove(s.order.Front())
+	}
+	s.items[key] = s.order.PushBack(item)
+	s.size += size
+	return nil
+}
+
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if e, ok := s.items[key]; ok {
+		s.remove(e)
+	}
+	return nil
+}
+
+func (s *memoryStorage) Count(ctx context.Context, prefix string) (int64, error) {
+	now := getTime()
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	var n int64
+	for key, e := range s.items {
+		if strings.HasPrefix(key, prefix) && !e.Value.(*memoryItem).expired(now) {
+			n++
+		}
+	}
+	return n, nil
+}
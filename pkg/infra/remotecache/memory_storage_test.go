@@ -0,0 +1,80 @@
+package remotecache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	opts := &setting.RemoteCacheOptions{Name: memoryCacheType, Prefix: "test-"}
+	client := createTestClient(t, opts, nil)
+	runTestsForClient(t, client)
+	runCountTestsForClient(t, opts, nil)
+}
+
+func TestMemoryStorageGarbageCollection(t *testing.T) {
+	s, err := newMemoryStorage(&setting.RemoteCacheOptions{})
+	require.NoError(t, err)
+	obj := []byte("foolbar")
+
+	getTime = func() time.Time { return time.Now().AddDate(0, 0, -2) }
+	require.NoError(t, s.Set(context.Background(), "key1", obj, 1000*time.Second))
+	// insert object that should never expire
+	require.NoError(t, s.Set(context.Background(), "key2", obj, 0))
+	getTime = time.Now
+	require.NoError(t, s.Set(context.Background(), "key3", obj, 1000*time.Second))
+
+	s.internalRunGC()
+
+	assert.Len(t, s.items, 2)
+	_, err = s.Get(context.Background(), "key1")
+	assert.ErrorIs(t, err, ErrCacheItemNotFound)
+	_, err = s.Get(context.Background(), "key2")
+	assert.NoError(t, err)
+	_, err = s.Get(context.Background(), "key3")
+	assert.NoError(t, err)
+}
+
+func TestMemoryStorageMaxSize(t *testing.T) {
+	_, err := newMemoryStorage(&setting.RemoteCacheOptions{ConnStr: "max_size_mb=abc"})
+	require.Error(t, err)
+
+	s, err := newMemoryStorage(&setting.RemoteCacheOptions{ConnStr: "max_size_mb=1"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1024*1024), s.maxSize)
+	ctx := context.Background()
+	value := make([]byte, 400*1024)
+
+	require.NoError(t, s.Set(ctx, "key1", value, 0))
+	getTime = func() time.Time { return time.Now().AddDate(0, 0, -2) }
+	require.NoError(t, s.Set(ctx, "key2", value, time.Hour))
+	getTime = time.Now
+	require.NoError(t, s.Set(ctx, "key3", value, 0))
+	// The expired item is removed first
+	_, err = s.Get(ctx, "key1")
+	require.NoError(t, err)
+	assert.Len(t, s.items, 2)
+
+	// Then the oldest ones
+	require.NoError(t, s.Set(ctx, "key4", value, 0))
+	_, err = s.Get(ctx, "key1")
+	assert.ErrorIs(t, err, ErrCacheItemNotFound)
+	assert.Len(t, s.items, 2)
+	assert.Equal(t, int64(2*len(value)), s.size)
+
+	require.Error(t, s.Set(ctx, "key5", make([]byte, 2*1024*1024), 0))
+	require.NoError(t, s.Delete(ctx, "key3"))
+	assert.Equal(t, int64(len(value)), s.size)
+}
+
+func TestRunUnwrapsClient(t *testing.T) {
+	client, err := createClient(&setting.RemoteCacheOptions{Name: memoryCacheType, Prefix: "test-"}, nil, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &memoryStorage{}, unwrapClient(client))
+}
@@ -110,7 +110,7 @@ func (ds *RemoteCache) Count(ctx context.Context, prefix string) (int64, error)
 // Run starts the backend processes for cache clients.
 func (ds *RemoteCache) Run(ctx context.Context) error {
 	// create new interface if more clients need GC jobs
-	backgroundjob, ok := ds.client.(registry.BackgroundService)
+	backgroundjob, ok := unwrapClient(ds.client).(registry.BackgroundService)
 	if ok {
 		return backgroundjob.Run(ctx)
 	}
@@ -127,6 +127,8 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore db.DB, secretsServi
 		cache = newMemcachedStorage(opts)
 	case databaseCacheType:
 		cache = newDatabaseCache(sqlstore)
+	case memoryCacheType:
+		cache, err = newMemoryStorage(opts)
 	default:
 		return nil, ErrInvalidCacheType
 	}
@@ -143,6 +145,20 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore db.DB, secretsServi
 	return cache, nil
 }
 
+// unwrapClient returns the storage of a client that is wrapped to encrypt or prefix the items.
+func unwrapClient(client CacheStorage) CacheStorage {
+	for {
+		switch c := client.(type) {
+		case *encryptedCacheStorage:
+			client = c.cache
+		case *prefixCacheStorage:
+			client = c.cache
+		default:
+			return client
+		}
+	}
+}
+
 type encryptedCacheStorage struct {
 	cache          CacheStorage
 	secretsService encryptionService
@@ -47,8 +47,10 @@ var gzipIgnoredPaths = []matcher{
 	prefix("/api/plugin-proxy/"),
 	prefix("/api/gnet/"), // Already gzipped by grafana.com.
 	prefix("/metrics"),
-	prefix("/api/live/ws"),   // WebSocket does not support gzip compression.
-	prefix("/api/live/push"), // WebSocket does not support gzip compression.
+	prefix("/api/live/ws"),          // WebSocket does not support gzip compression.
+	prefix("/api/live/push"),        // WebSocket does not support gzip compression.
+	prefix("/api/live/sse"),         // Streamed responses must be flushed without buffering.
+	prefix("/api/live/http_stream"), // Streamed responses must be flushed without buffering.
 	substr("/resources"),
 }
 
@@ -0,0 +1,36 @@
+package caching
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OSSCachingService) registerAPIEndpoints(routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+	routeRegister.Post("/api/datasources/uid/:uid/cache/clean", middleware.ReqSignedIn,
+		authorize(accesscontrol.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.cleanCacheHandler))
+}
+
+// swagger:route POST /datasources/uid/{uid}/cache/clean datasources cleanDataSourceCache
+//
+// Invalidate the cached query results of a data source.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *OSSCachingService) cleanCacheHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.InvalidateDataSource(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to clean the query cache of the data source", err)
+	}
+	return response.Success("Query cache of the data source cleaned")
+}
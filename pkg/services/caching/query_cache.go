@@ -0,0 +1,291 @@
+package caching
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/proxyutil"
+)
+
+const (
+	// queryCacheKeyFormat is the format of the keys of cached query results: org ID, data source UID,
+	// generation of the cache of the data source and hash of the request.
+	queryCacheKeyFormat = "query-cache:%d:%s:%s:%s"
+	// queryCacheGenerationKeyFormat is the format of the key of the generation of the cache of a data source.
+	queryCacheGenerationKeyFormat = "query-cache-generation:%d:%s"
+	queryCacheInitialGeneration   = "0"
+)
+
+// identityHeaders are the headers that forward the identity of the user to data sources, so results of requests
+// with these headers are cached per value of the headers. X-Grafana-Id is set by the ID forwarding middleware.
+var identityHeaders = []string{
+	backend.OAuthIdentityTokenHeaderName,
+	backend.OAuthIdentityIDTokenHeaderName,
+	backend.CookiesHeaderName,
+	"X-Grafana-Id",
+	proxyutil.UserHeaderName,
+}
+
+// ignoredQueryProperties are properties of queries that differ between identical queries, e.g. because the
+// frontend generates them for every request, so they are not part of the cache key.
+var ignoredQueryProperties = []string{"requestId", "key", "queryCachingTTL"}
+
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl) *OSSCachingService {
+	s := &OSSCachingService{
+		cfg:   cfg,
+		cache: cache,
+		log:   log.New("query-caching"),
+	}
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s
+}
+
+// OSSCachingService caches the query results of data sources in the remote cache. Query results are keyed by the
+// data source, the normalized queries and their time range aligned to the TTL of the data source. The zero value
+// does not cache anything.
+type OSSCachingService struct {
+	cfg   *setting.Cfg
+	cache remotecache.CacheStorage
+	log   log.Logger
+}
+
+// dataSourceCachingSettings are the query caching settings in the JSON data of a data source.
+type dataSourceCachingSettings struct {
+	// QueryCachingEnabled disables caching for the data source if false.
+	QueryCachingEnabled *bool `json:"queryCachingEnabled"`
+	// QueryCachingTTL overrides the default TTL in milliseconds if greater than zero.
+	QueryCachingTTL int64 `json:"queryCachingTTL"`
+	// OAuthPassThru forwards the identity of the user, so the results are cached per user.
+	OAuthPassThru bool `json:"oauthPassThru"`
+}
+
+func readDataSourceCachingSettings(settings *backend.DataSourceInstanceSettings) (dataSourceCachingSettings, error) {
+	dsSettings := dataSourceCachingSettings{}
+	if len(settings.JSONData) > 0 {
+		if err := json.Unmarshal(settings.JSONData, &dsSettings); err != nil {
+			return dsSettings, err
+		}
+	}
+	return dsSettings, nil
+}
+
+// ttl returns the TTL of the query results of the data source, or zero if the results are not cached.
+func (s *OSSCachingService) ttl(dsSettings dataSourceCachingSettings) time.Duration {
+	if dsSettings.QueryCachingEnabled != nil && !*dsSettings.QueryCachingEnabled {
+		return 0
+	}
+	if dsSettings.QueryCachingTTL > 0 {
+		return time.Duration(dsSettings.QueryCachingTTL) * time.Millisecond
+	}
+	return s.cfg.QueryCaching.TTL
+}
+
+func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
+	if s.cfg == nil || s.cache == nil || !s.cfg.QueryCaching.Enabled || req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return false, CachedQueryDataResponse{}
+	}
+	settings := req.PluginContext.DataSourceInstanceSettings
+	dsSettings, err := readDataSourceCachingSettings(settings)
+	if err != nil {
+		s.log.Warn("Failed to read query caching settings of data source", "uid", settings.UID, "error", err)
+		setCacheStatus(ctx, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+	ttl := s.ttl(dsSettings)
+	if ttl <= 0 {
+		setCacheStatus(ctx, StatusDisabled)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := s.queryCacheKey(ctx, req, dsSettings, ttl)
+	if err != nil {
+		s.log.Warn("Failed to create query cache key", "uid", settings.UID, "error", err)
+		setCacheStatus(ctx, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+	update := s.updateQueryCacheFn(key, ttl)
+
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		setCacheStatus(ctx, StatusBypass)
+		return false, CachedQueryDataResponse{UpdateCacheFn: update}
+	}
+
+	value, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			setCacheStatus(ctx, StatusMiss)
+		} else {
+			s.log.Warn("Failed to read cached query result", "uid", settings.UID, "error", err)
+			setCacheStatus(ctx, StatusError)
+		}
+		return false, CachedQueryDataResponse{UpdateCacheFn: update}
+	}
+
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(value, resp); err != nil {
+		s.log.Warn("Failed to decode cached query result", "uid", settings.UID, "error", err)
+		setCacheStatus(ctx, StatusError)
+		return false, CachedQueryDataResponse{UpdateCacheFn: update}
+	}
+	setCacheStatus(ctx, StatusHit)
+	return true, CachedQueryDataResponse{Response: resp}
+}
+
+// HandleResourceRequest does not cache resource requests, as their responses may depend on anything in the request.
+func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
+	return false, CachedResourceDataResponse{}
+}
+
+// updateQueryCacheFn returns a function that caches successful query results that do not exceed the maximum size.
+func (s *OSSCachingService) updateQueryCacheFn(key string, ttl time.Duration) CacheQueryResponseFn {
+	return func(ctx context.Context, resp *backend.QueryDataResponse) {
+		if resp == nil {
+			return
+		}
+		for _, r := range resp.Responses {
+			if r.Error != nil || r.Status >= backend.StatusBadRequest {
+				return
+			}
+		}
+		value, err := json.Marshal(resp)
+		if err != nil {
+			s.log.Warn("Failed to encode query result", "error", err)
+			return
+		}
+		if int64(len(value)) > s.cfg.QueryCaching.MaxValueSize {
+			s.log.Debug("Query result exceeds the maximum size of cached values", "size", len(value))
+			return
+		}
+		if err := s.cache.Set(ctx, key, value, ttl); err != nil {
+			s.log.Warn("Failed to cache query result", "error", err)
+		}
+	}
+}
+
+type queryCacheKeyQuery struct {
+	RefID         string          `json:"refId"`
+	QueryType     string          `json:"queryType"`
+	MaxDataPoints int64           `json:"maxDataPoints"`
+	IntervalMS    int64           `json:"intervalMs"`
+	From          int64           `json:"from"`
+	To            int64           `json:"to"`
+	Model         json.RawMessage `json:"model"`
+}
+
+type queryCacheKeyRequest struct {
+	// Updated changes the keys if the data source is updated.
+	Updated int64  `json:"updated"`
+	User    string `json:"user,omitempty"`
+	// Identity contains the forwarded identity headers, so results that depend on them are not shared between users.
+	Identity map[string]string    `json:"identity,omitempty"`
+	Queries  []queryCacheKeyQuery `json:"queries"`
+}
+
+// queryCacheKey returns the key of the cached result of the request. The key contains the generation of the cache
+// of the data source, so the cache of a data source is invalidated by changing the generation.
+func (s *OSSCachingService) queryCacheKey(ctx context.Context, req *backend.QueryDataRequest, dsSettings dataSourceCachingSettings,
+	ttl time.Duration) (string, error) {
+	keyReq := queryCacheKeyRequest{
+		Updated: req.PluginContext.DataSourceInstanceSettings.Updated.UnixMilli(),
+		Queries: make([]queryCacheKeyQuery, 0, len(req.Queries)),
+	}
+	if dsSettings.OAuthPassThru {
+		if req.PluginContext.User == nil {
+			return "", errors.New("the data source forwards the identity of the user, but there is no user")
+		}
+		keyReq.User = req.PluginContext.User.Login
+	}
+	for _, name := range identityHeaders {
+		if value := req.GetHTTPHeader(name); value != "" {
+			if keyReq.Identity == nil {
+				keyReq.Identity = make(map[string]string, len(identityHeaders))
+			}
+			keyReq.Identity[name] = value
+		}
+	}
+
+	for _, q := range req.Queries {
+		model, err := normalizeQuery(q.JSON)
+		if err != nil {
+			return "", err
+		}
+		keyReq.Queries = append(keyReq.Queries, queryCacheKeyQuery{
+			RefID:         q.RefID,
+			QueryType:     q.QueryType,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMS:    q.Interval.Milliseconds(),
+			From:          q.TimeRange.From.Truncate(ttl).UnixMilli(),
+			To:            q.TimeRange.To.Truncate(ttl).UnixMilli(),
+			Model:         model,
+		})
+	}
+
+	b, err := json.Marshal(keyReq)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(b)
+
+	uid := req.PluginContext.DataSourceInstanceSettings.UID
+	generation, err := s.generation(ctx, req.PluginContext.OrgID, uid)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(queryCacheKeyFormat, req.PluginContext.OrgID, uid, generation, hex.EncodeToString(hash[:])), nil
+}
+
+// normalizeQuery returns the query model with sorted keys and without properties that differ between identical queries.
+func normalizeQuery(model json.RawMessage) (json.RawMessage, error) {
+	if len(model) == 0 {
+		return model, nil
+	}
+	var m map[string]any
+	if err := json.Unmarshal(model, &m); err != nil {
+		return nil, err
+	}
+	for _, p := range ignoredQueryProperties {
+		delete(m, p)
+	}
+	return json.Marshal(m)
+}
+
+func (s *OSSCachingService) generation(ctx context.Context, orgID int64, uid string) (string, error) {
+	value, err := s.cache.Get(ctx, fmt.Sprintf(queryCacheGenerationKeyFormat, orgID, uid))
+	if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return queryCacheInitialGeneration, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// InvalidateDataSource invalidates the cached query results of a data source.
+func (s *OSSCachingService) InvalidateDataSource(ctx context.Context, orgID int64, uid string) error {
+	if s.cache == nil {
+		return nil
+	}
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+	return s.cache.Set(ctx, fmt.Sprintf(queryCacheGenerationKeyFormat, orgID, uid), []byte(generation), 0)
+}
+
+func setCacheStatus(ctx context.Context, status string) {
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
+}
@@ -0,0 +1,229 @@
+package caching
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type fakeCacheStorage struct {
+	mtx   sync.Mutex
+	items map[string][]byte
+	ttls  map[string]time.Duration
+}
+
+func newFakeCacheStorage() *fakeCacheStorage {
+	return &fakeCacheStorage{items: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeCacheStorage) Get(_ context.Context, key string) ([]byte, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	v, ok := f.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return v, nil
+}
+
+func (f *fakeCacheStorage) Set(_ context.Context, key string, value []byte, expire time.Duration) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.items[key] = value
+	f.ttls[key] = expire
+	return nil
+}
+
+func (f *fakeCacheStorage) Delete(_ context.Context, key string) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	delete(f.items, key)
+	return nil
+}
+
+func (f *fakeCacheStorage) Count(_ context.Context, _ string) (int64, error) {
+	return 0, nil
+}
+
+func newTestCachingService(t *testing.T) (*OSSCachingService, *fakeCacheStorage) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, MaxValueSize: 1024 * 1024}
+	cache := newFakeCacheStorage()
+	return &OSSCachingService{cfg: cfg, cache: cache, log: log.New("test")}, cache
+}
+
+func newRequestContext(t *testing.T, skipCache bool) (context.Context, *contextmodel.ReqContext) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/ds/query", nil)
+	reqCtx := &contextmodel.ReqContext{
+		Context:        &web.Context{Req: req, Resp: web.NewResponseWriter(req.Method, httptest.NewRecorder())},
+		SkipQueryCache: skipCache,
+	}
+	return ctxkey.Set(context.Background(), reqCtx), reqCtx
+}
+
+func newQueryRequest(jsonData string, from time.Time, model string) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{
+			OrgID: 1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				UID:      "ds",
+				JSONData: []byte(jsonData),
+			},
+		},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      []byte(model),
+			TimeRange: backend.TimeRange{From: from, To: from.Add(time.Hour)},
+			Interval:  time.Minute,
+		}},
+	}
+}
+
+func queryResponse() *backend.QueryDataResponse {
+	return &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("A", data.NewField("value", nil, []float64{1, 2}))}},
+	}}
+}
+
+func TestHandleQueryRequest(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 4, 0, 0, time.UTC)
+
+	t.Run("caches results and returns them for identical queries in the same aligned time range", func(t *testing.T) {
+		s, cache := newTestCachingService(t)
+		ctx, reqCtx := newRequestContext(t, false)
+
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start.Add(10*time.Second), `{"expr":"up","requestId":"1"}`))
+		require.False(t, hit)
+		assert.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		cr.UpdateCacheFn(ctx, queryResponse())
+		require.Len(t, cache.items, 1)
+
+		ctx, reqCtx = newRequestContext(t, false)
+		hit, cr = s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start.Add(50*time.Second), `{"requestId":"2","expr":"up"}`))
+		require.True(t, hit)
+		assert.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.Len(t, cr.Response.Responses["A"].Frames, 1)
+		v, err := cr.Response.Responses["A"].Frames[0].Fields[0].FloatAt(1)
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, v)
+
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start.Add(70*time.Second), `{"expr":"up"}`))
+		assert.False(t, hit, "time range in the next TTL")
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{"expr":"down"}`))
+		assert.False(t, hit, "different query")
+	})
+
+	t.Run("uses the TTL of the data source", func(t *testing.T) {
+		s, cache := newTestCachingService(t)
+		ctx, _ := newRequestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{"queryCachingTTL":300000}`, start, `{}`))
+		cr.UpdateCacheFn(ctx, queryResponse())
+		for _, ttl := range cache.ttls {
+			assert.Equal(t, 5*time.Minute, ttl)
+		}
+	})
+
+	t.Run("does not cache data sources that disable caching", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+		ctx, reqCtx := newRequestContext(t, false)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{"queryCachingEnabled":false}`, start, `{}`))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Equal(t, StatusDisabled, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		s, cache := newTestCachingService(t)
+		ctx, _ := newRequestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		cr.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": backend.ErrDataResponse(backend.StatusBadRequest, "bad query")}})
+		assert.Empty(t, cache.items)
+	})
+
+	t.Run("bypasses the cache if requested but updates it", func(t *testing.T) {
+		s, cache := newTestCachingService(t)
+		ctx, _ := newRequestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		cr.UpdateCacheFn(ctx, queryResponse())
+
+		ctx, reqCtx := newRequestContext(t, true)
+		hit, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		assert.False(t, hit)
+		assert.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cr.UpdateCacheFn)
+		assert.Len(t, cache.items, 1)
+	})
+
+	t.Run("caches results per user if the data source forwards the identity of the user", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+		ctx, _ := newRequestContext(t, false)
+		req := newQueryRequest(`{"oauthPassThru":true}`, start, `{}`)
+		req.PluginContext.User = &backend.User{Login: "alice"}
+		_, cr := s.HandleQueryRequest(ctx, req)
+		cr.UpdateCacheFn(ctx, queryResponse())
+
+		req.PluginContext.User = &backend.User{Login: "bob"}
+		hit, _ := s.HandleQueryRequest(ctx, req)
+		assert.False(t, hit)
+	})
+
+	t.Run("caches results per forwarded identity", func(t *testing.T) {
+		for _, header := range identityHeaders {
+			t.Run(header, func(t *testing.T) {
+				s, _ := newTestCachingService(t)
+				ctx, _ := newRequestContext(t, false)
+				req := newQueryRequest(`{}`, start, `{}`)
+				req.SetHTTPHeader(header, "alice")
+				_, cr := s.HandleQueryRequest(ctx, req)
+				cr.UpdateCacheFn(ctx, queryResponse())
+				hit, _ := s.HandleQueryRequest(ctx, req)
+				require.True(t, hit)
+
+				req.SetHTTPHeader(header, "bob")
+				hit, _ = s.HandleQueryRequest(ctx, req)
+				assert.False(t, hit)
+				hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+				assert.False(t, hit)
+			})
+		}
+	})
+
+	t.Run("invalidates the results of a data source", func(t *testing.T) {
+		s, _ := newTestCachingService(t)
+		ctx, _ := newRequestContext(t, false)
+		_, cr := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		cr.UpdateCacheFn(ctx, queryResponse())
+		hit, _ := s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		require.True(t, hit)
+
+		require.NoError(t, s.InvalidateDataSource(ctx, 1, "ds"))
+		hit, _ = s.HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		assert.False(t, hit)
+	})
+
+	t.Run("does nothing if caching is not enabled", func(t *testing.T) {
+		ctx, reqCtx := newRequestContext(t, false)
+		hit, cr := (&OSSCachingService{}).HandleQueryRequest(ctx, newQueryRequest(`{}`, start, `{}`))
+		assert.False(t, hit)
+		assert.Nil(t, cr.UpdateCacheFn)
+		assert.Empty(t, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+}
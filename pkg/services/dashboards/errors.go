@@ -41,6 +41,11 @@ var (
 		StatusCode: 412,
 		Status:     "version-mismatch",
 	}
+	ErrDashboardEditLocked = DashboardErr{
+		Reason:     "The dashboard is being edited by someone else",
+		StatusCode: 423,
+		Status:     "edit-locked",
+	}
 	ErrDashboardTitleEmpty = DashboardErr{
 		Reason:     "Dashboard title cannot be empty",
 		StatusCode: 400,
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -23,7 +24,15 @@ const (
 	ActionSaved    actionType = "saved"
 	ActionDeleted  actionType = "deleted"
 	EditingStarted actionType = "editing-started"
-	//EditingFinished actionType = "editing-finished"
+	// EditingCanceled releases the edit lock of the session.
+	EditingCanceled actionType = "editing-cancelled"
+	// EditingTakeover takes over the edit lock from another session.
+	EditingTakeover actionType = "editing-takeover"
+	// PanelEditingStarted and PanelEditingFinished tell which panel the session edits.
+	PanelEditingStarted  actionType = "panel-editing-started"
+	PanelEditingFinished actionType = "panel-editing-finished"
+	// EditingState is sent on subscribe with the current edit lock and panel editors.
+	EditingState actionType = "editing-state"
 
 	GitopsChannel = "grafana/dashboard/gitops"
 )
@@ -37,6 +46,9 @@ type dashboardEvent struct {
 	Message   string                `json:"message,omitempty"`
 	Dashboard *dashboards.Dashboard `json:"dashboard,omitempty"`
 	Error     string                `json:"error,omitempty"`
+	PanelID   int64                 `json:"panelId,omitempty"`
+	Lock      *DashboardEditLock    `json:"lock,omitempty"`
+	Panels    []PanelEditor         `json:"panels,omitempty"`
 }
 
 // DashboardHandler manages all the `grafana/dashboard/*` channels
@@ -45,6 +57,8 @@ type DashboardHandler struct {
 	ClientCount      model.ChannelClientCount
 	Store            db.DB
 	DashboardService dashboards.DashboardService
+	// EditStore keeps the edit locks and panel editors of dashboards, editing is not tracked if nil.
+	EditStore DashboardEditStore
 }
 
 // GetHandlerForPath called on init
@@ -83,10 +97,26 @@ func (h *DashboardHandler) OnSubscribe(ctx context.Context, user identity.Reques
 			return model.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
 		}
 
-		return model.SubscribeReply{
+		reply := model.SubscribeReply{
 			Presence:  true,
 			JoinLeave: true,
-		}, backend.SubscribeStreamStatusOK, nil
+		}
+		if h.EditStore != nil {
+			state, err := h.EditStore.GetEditState(ctx, user.GetOrgID(), dash.UID)
+			if err != nil {
+				return model.SubscribeReply{}, 0, err
+			}
+			reply.Data, err = json.Marshal(dashboardEvent{
+				UID:    dash.UID,
+				Action: EditingState,
+				Lock:   state.Lock,
+				Panels: state.Panels,
+			})
+			if err != nil {
+				return model.SubscribeReply{}, 0, err
+			}
+		}
+		return reply, backend.SubscribeStreamStatusOK, nil
 	}
 
 	// Unknown path
@@ -114,7 +144,13 @@ func (h *DashboardHandler) OnPublish(ctx context.Context, requester identity.Req
 		if err != nil || event.UID != parts[1] {
 			return model.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("bad request")
 		}
-		if event.Action != EditingStarted {
+		switch event.Action {
+		case EditingStarted:
+		case EditingCanceled, EditingTakeover, PanelEditingStarted, PanelEditingFinished:
+			if h.EditStore == nil || event.SessionID == "" {
+				return model.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("bad request")
+			}
+		default:
 			// just ignore the event
 			return model.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("ignore???")
 		}
@@ -147,6 +183,13 @@ func (h *DashboardHandler) OnPublish(ctx context.Context, requester identity.Req
 			return model.PublishReply{}, backend.PublishStreamStatusNotFound, err
 		}
 
+		if h.EditStore != nil && event.SessionID != "" {
+			if err := h.updateEditState(ctx, requester.GetOrgID(), &event); err != nil {
+				logger.Error("Failed to update dashboard edit state", "uid", event.UID, "error", err)
+				return model.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("internal error")
+			}
+		}
+
 		msg, err := json.Marshal(event)
 		if err != nil {
 			return model.PublishReply{}, backend.PublishStreamStatusNotFound, fmt.Errorf("internal error")
@@ -157,6 +200,53 @@ func (h *DashboardHandler) OnPublish(ctx context.Context, requester identity.Req
 	return model.PublishReply{}, backend.PublishStreamStatusNotFound, nil
 }
 
+// updateEditState updates the edit lock and panel editors for the event, and sets the resulting
+// state to the event so all subscribers know who is editing.
+func (h *DashboardHandler) updateEditState(ctx context.Context, orgID int64, event *dashboardEvent) error {
+	now := time.Now()
+	expires := now.Add(dashboardEditTTL).UnixMilli()
+	editor := PanelEditor{
+		PanelID:   event.PanelID,
+		User:      event.User,
+		SessionID: event.SessionID,
+		Expires:   expires,
+	}
+
+	var err error
+	switch event.Action {
+	case EditingStarted, EditingTakeover:
+		_, _, err = h.EditStore.AcquireEditLock(ctx, orgID, event.UID, DashboardEditLock{
+			User:      event.User,
+			SessionID: event.SessionID,
+			Since:     now.UnixMilli(),
+			Expires:   expires,
+		}, event.Action == EditingTakeover)
+		if err == nil && event.PanelID > 0 {
+			err = h.EditStore.SetPanelEditor(ctx, orgID, event.UID, editor)
+		}
+	case EditingCanceled:
+		err = h.EditStore.ReleaseEditLock(ctx, orgID, event.UID, event.SessionID)
+		if err == nil {
+			err = h.EditStore.RemovePanelEditor(ctx, orgID, event.UID, event.SessionID)
+		}
+	case PanelEditingStarted:
+		err = h.EditStore.SetPanelEditor(ctx, orgID, event.UID, editor)
+	case PanelEditingFinished:
+		err = h.EditStore.RemovePanelEditor(ctx, orgID, event.UID, event.SessionID)
+	}
+	if err != nil {
+		return err
+	}
+
+	state, err := h.EditStore.GetEditState(ctx, orgID, event.UID)
+	if err != nil {
+		return err
+	}
+	event.Lock = state.Lock
+	event.Panels = state.Panels
+	return nil
+}
+
 // DashboardSaved should broadcast to the appropriate stream
 func (h *DashboardHandler) publish(orgID int64, event dashboardEvent) error {
 	msg, err := json.Marshal(event)
@@ -206,6 +296,31 @@ func (h *DashboardHandler) DashboardDeleted(orgID int64, user *user.UserDisplayD
 	})
 }
 
+// CheckEditLock returns an error if the dashboard is locked for editing by another user. Sessions
+// of the same user don't lock each other out, and the lock is ignored if it can't be checked since
+// it is only advisory.
+func (h *DashboardHandler) CheckEditLock(ctx context.Context, orgID int64, u *user.UserDisplayDTO, uid string) error {
+	if h.EditStore == nil || uid == "" {
+		return nil
+	}
+	state, err := h.EditStore.GetEditState(ctx, orgID, uid)
+	if err != nil {
+		logger.Warn("Failed to check dashboard edit lock", "uid", uid, "error", err)
+		return nil
+	}
+	lock := state.Lock
+	if lock == nil || lock.User == nil || (lock.User.ID == u.ID && lock.User.Login == u.Login) {
+		return nil
+	}
+	name := lock.User.Name
+	if name == "" {
+		name = lock.User.Login
+	}
+	lockErr := dashboards.ErrDashboardEditLocked
+	lockErr.Reason = fmt.Sprintf("The dashboard is being edited by %s", name)
+	return lockErr
+}
+
 // HasGitOpsObserver will return true if anyone is listening to the `gitops` channel
 func (h *DashboardHandler) HasGitOpsObserver(orgID int64) bool {
 	count, err := h.ClientCount(orgID, GitopsChannel)
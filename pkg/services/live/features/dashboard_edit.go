@@ -0,0 +1,173 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// dashboardEditTTL is how long edit locks and panel editors are kept without being renewed. The
+// frontend renews them while editing, so they expire soon after a browser is closed.
+const dashboardEditTTL = time.Minute
+
+// DashboardEditLock is the advisory lock of the session which edits a dashboard. Other users
+// can't save the dashboard until they take over the lock or it expires.
+type DashboardEditLock struct {
+	User      *user.UserDisplayDTO `json:"user"`
+	SessionID string               `json:"sessionId"`
+	// Since and Expires are unix timestamps in milliseconds.
+	Since   int64 `json:"since"`
+	Expires int64 `json:"expires"`
+}
+
+// PanelEditor is a session which edits a panel of a dashboard.
+type PanelEditor struct {
+	PanelID   int64                `json:"panelId"`
+	User      *user.UserDisplayDTO `json:"user"`
+	SessionID string               `json:"sessionId"`
+	Expires   int64                `json:"expires"`
+}
+
+// DashboardEditState is who is editing a dashboard.
+type DashboardEditState struct {
+	Lock   *DashboardEditLock `json:"lock,omitempty"`
+	Panels []PanelEditor      `json:"panels,omitempty"`
+}
+
+// DashboardEditStore keeps the edit locks and panel editors of dashboards.
+type DashboardEditStore interface {
+	GetEditState(ctx context.Context, orgID int64, uid string) (DashboardEditState, error)
+	// AcquireEditLock acquires or renews the lock for the session of the lock. The lock of another
+	// session is only taken over if takeover is true. It returns the current lock and whether it is
+	// held by the session.
+	AcquireEditLock(ctx context.Context, orgID int64, uid string, lock DashboardEditLock, takeover bool) (DashboardEditLock, bool, error)
+	// ReleaseEditLock releases the lock if it is held by the session.
+	ReleaseEditLock(ctx context.Context, orgID int64, uid string, sessionID string) error
+	// SetPanelEditor sets the panel edited by the session of the editor.
+	SetPanelEditor(ctx context.Context, orgID int64, uid string, editor PanelEditor) error
+	// RemovePanelEditor removes the panel edited by the session.
+	RemovePanelEditor(ctx context.Context, orgID int64, uid string, sessionID string) error
+}
+
+func dashboardEditKey(orgID int64, uid string) string {
+	return fmt.Sprintf("%d.%s", orgID, uid)
+}
+
+func sortPanelEditors(panels []PanelEditor) {
+	sort.Slice(panels, func(i, j int) bool {
+		if panels[i].PanelID != panels[j].PanelID {
+			return panels[i].PanelID < panels[j].PanelID
+		}
+		return panels[i].SessionID < panels[j].SessionID
+	})
+}
+
+type memoryDashboardEdit struct {
+	lock   *DashboardEditLock
+	panels map[string]PanelEditor
+}
+
+// MemoryDashboardEditStore keeps edit locks in memory, used when Grafana runs without the Redis
+// live engine.
+type MemoryDashboardEditStore struct {
+	mu         sync.Mutex
+	dashboards map[string]*memoryDashboardEdit
+	now        func() time.Time
+}
+
+func NewMemoryDashboardEditStore() *MemoryDashboardEditStore {
+	return &MemoryDashboardEditStore{
+		dashboards: map[string]*memoryDashboardEdit{},
+		now:        time.Now,
+	}
+}
+
+// get returns the entry of a dashboard without expired locks and editors.
+func (s *MemoryDashboardEditStore) get(orgID int64, uid string, create bool) *memoryDashboardEdit {
+	key := dashboardEditKey(orgID, uid)
+	d, ok := s.dashboards[key]
+	if !ok {
+		if !create {
+			return nil
+		}
+		d = &memoryDashboardEdit{panels: map[string]PanelEditor{}}
+		s.dashboards[key] = d
+	}
+	now := s.now().UnixMilli()
+	if d.lock != nil && d.lock.Expires <= now {
+		d.lock = nil
+	}
+	for sessionID, editor := range d.panels {
+		if editor.Expires <= now {
+			delete(d.panels, sessionID)
+		}
+	}
+	if d.lock == nil && len(d.panels) == 0 && !create {
+		delete(s.dashboards, key)
+		return nil
+	}
+	return d
+}
+
+func (s *MemoryDashboardEditStore) GetEditState(_ context.Context, orgID int64, uid string) (DashboardEditState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.get(orgID, uid, false)
+	if d == nil {
+		return DashboardEditState{}, nil
+	}
+	state := DashboardEditState{}
+	if d.lock != nil {
+		lock := *d.lock
+		state.Lock = &lock
+	}
+	for _, editor := range d.panels {
+		state.Panels = append(state.Panels, editor)
+	}
+	sortPanelEditors(state.Panels)
+	return state, nil
+}
+
+func (s *MemoryDashboardEditStore) AcquireEditLock(_ context.Context, orgID int64, uid string, lock DashboardEditLock, takeover bool) (DashboardEditLock, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.get(orgID, uid, true)
+	if d.lock != nil && d.lock.SessionID != lock.SessionID && !takeover {
+		return *d.lock, false, nil
+	}
+	if d.lock != nil && d.lock.SessionID == lock.SessionID {
+		lock.Since = d.lock.Since
+	}
+	d.lock = &lock
+	return lock, true, nil
+}
+
+func (s *MemoryDashboardEditStore) ReleaseEditLock(_ context.Context, orgID int64, uid string, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.get(orgID, uid, false)
+	if d != nil && d.lock != nil && d.lock.SessionID == sessionID {
+		d.lock = nil
+	}
+	return nil
+}
+
+func (s *MemoryDashboardEditStore) SetPanelEditor(_ context.Context, orgID int64, uid string, editor PanelEditor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(orgID, uid, true).panels[editor.SessionID] = editor
+	return nil
+}
+
+func (s *MemoryDashboardEditStore) RemovePanelEditor(_ context.Context, orgID int64, uid string, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := s.get(orgID, uid, false); d != nil {
+		delete(d.panels, sessionID)
+	}
+	return nil
+}
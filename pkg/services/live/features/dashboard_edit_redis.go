@@ -0,0 +1,141 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// acquireEditLockScript sets the lock unless it is held by another session and takeover is not
+// requested (ARGV[3]), and returns the current lock. A renewed lock keeps the time it was acquired.
+var acquireEditLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+local value = ARGV[1]
+if current then
+	local lock = cjson.decode(current)
+	if lock.sessionId == ARGV[4] then
+		local renewed = cjson.decode(value)
+		renewed.since = lock.since
+		value = cjson.encode(renewed)
+	elseif ARGV[3] ~= '1' then
+		return current
+	end
+end
+redis.call('SET', KEYS[1], value, 'PX', ARGV[2])
+return value
+`)
+
+// releaseEditLockScript deletes the lock if it is held by the session ARGV[1].
+var releaseEditLockScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and cjson.decode(current).sessionId == ARGV[1] then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisDashboardEditStore keeps edit locks in Redis, so they are shared by all Grafana instances
+// which use the Redis live engine.
+type RedisDashboardEditStore struct {
+	redisClient *redis.Client
+	now         func() time.Time
+}
+
+func NewRedisDashboardEditStore(redisClient *redis.Client) *RedisDashboardEditStore {
+	return &RedisDashboardEditStore{
+		redisClient: redisClient,
+		now:         time.Now,
+	}
+}
+
+func getEditLockKey(orgID int64, uid string) string {
+	return "gf_live.dashboard_edit_lock." + dashboardEditKey(orgID, uid)
+}
+
+func getPanelEditorsKey(orgID int64, uid string) string {
+	return "gf_live.dashboard_panel_editors." + dashboardEditKey(orgID, uid)
+}
+
+func (s *RedisDashboardEditStore) GetEditState(ctx context.Context, orgID int64, uid string) (DashboardEditState, error) {
+	state := DashboardEditState{}
+	lockData, err := s.redisClient.Get(ctx, getEditLockKey(orgID, uid)).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return DashboardEditState{}, err
+	}
+	if err == nil {
+		var lock DashboardEditLock
+		if err := json.Unmarshal(lockData, &lock); err != nil {
+			return DashboardEditState{}, err
+		}
+		state.Lock = &lock
+	}
+
+	editors, err := s.redisClient.HGetAll(ctx, getPanelEditorsKey(orgID, uid)).Result()
+	if err != nil {
+		return DashboardEditState{}, err
+	}
+	now := s.now().UnixMilli()
+	for _, editorData := range editors {
+		var editor PanelEditor
+		if err := json.Unmarshal([]byte(editorData), &editor); err != nil {
+			return DashboardEditState{}, err
+		}
+		// Editors share the expiration of the key, so the expiration of each editor is checked here.
+		if editor.Expires > now {
+			state.Panels = append(state.Panels, editor)
+		}
+	}
+	sortPanelEditors(state.Panels)
+	return state, nil
+}
+
+func (s *RedisDashboardEditStore) AcquireEditLock(ctx context.Context, orgID int64, uid string, lock DashboardEditLock, takeover bool) (DashboardEditLock, bool, error) {
+	lockData, err := json.Marshal(lock)
+	if err != nil {
+		return DashboardEditLock{}, false, err
+	}
+	takeoverArg := "0"
+	if takeover {
+		takeoverArg = "1"
+	}
+	ttl := lock.Expires - s.now().UnixMilli()
+	if ttl < 1 {
+		ttl = 1
+	}
+	result, err := acquireEditLockScript.Run(ctx, s.redisClient, []string{getEditLockKey(orgID, uid)},
+		string(lockData), ttl, takeoverArg, lock.SessionID).Text()
+	if err != nil {
+		return DashboardEditLock{}, false, err
+	}
+	var current DashboardEditLock
+	if err := json.Unmarshal([]byte(result), &current); err != nil {
+		return DashboardEditLock{}, false, err
+	}
+	return current, current.SessionID == lock.SessionID, nil
+}
+
+func (s *RedisDashboardEditStore) ReleaseEditLock(ctx context.Context, orgID int64, uid string, sessionID string) error {
+	return releaseEditLockScript.Run(ctx, s.redisClient, []string{getEditLockKey(orgID, uid)}, sessionID).Err()
+}
+
+func (s *RedisDashboardEditStore) SetPanelEditor(ctx context.Context, orgID int64, uid string, editor PanelEditor) error {
+	editorData, err := json.Marshal(editor)
+	if err != nil {
+		return err
+	}
+	key := getPanelEditorsKey(orgID, uid)
+	pipe := s.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	pipe.HSet(ctx, key, editor.SessionID, string(editorData))
+	pipe.Expire(ctx, key, dashboardEditTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisDashboardEditStore) RemovePanelEditor(ctx context.Context, orgID int64, uid string, sessionID string) error {
+	return s.redisClient.HDel(ctx, getPanelEditorsKey(orgID, uid), sessionID).Err()
+}
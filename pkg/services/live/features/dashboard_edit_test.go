@@ -0,0 +1,160 @@
+package features
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	editor1 = &user.UserDisplayDTO{ID: 1, Login: "editor1", Name: "Editor One"}
+	editor2 = &user.UserDisplayDTO{ID: 2, Login: "editor2"}
+)
+
+func testEditLock(u *user.UserDisplayDTO, sessionID string, since time.Time) DashboardEditLock {
+	return DashboardEditLock{
+		User:      u,
+		SessionID: sessionID,
+		Since:     since.UnixMilli(),
+		Expires:   since.Add(dashboardEditTTL).UnixMilli(),
+	}
+}
+
+func testDashboardEditStore(t *testing.T, s DashboardEditStore, uid string) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+
+	state, err := s.GetEditState(ctx, 1, uid)
+	require.NoError(t, err)
+	require.Nil(t, state.Lock)
+
+	lock, ok, err := s.AcquireEditLock(ctx, 1, uid, testEditLock(editor1, "s1", now), false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "s1", lock.SessionID)
+
+	// Renewing keeps the time the lock was acquired.
+	lock, ok, err = s.AcquireEditLock(ctx, 1, uid, testEditLock(editor1, "s1", now.Add(time.Second)), false)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, now.UnixMilli(), lock.Since)
+
+	lock, ok, err = s.AcquireEditLock(ctx, 1, uid, testEditLock(editor2, "s2", now), false)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Equal(t, "s1", lock.SessionID)
+	require.Equal(t, "editor1", lock.User.Login)
+
+	// Locks are per organization.
+	_, ok, err = s.AcquireEditLock(ctx, 2, uid, testEditLock(editor2, "s2", now), false)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	lock, ok, err = s.AcquireEditLock(ctx, 1, uid, testEditLock(editor2, "s2", now), true)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "s2", lock.SessionID)
+
+	// Only the session holding the lock releases it.
+	require.NoError(t, s.ReleaseEditLock(ctx, 1, uid, "s1"))
+	state, err = s.GetEditState(ctx, 1, uid)
+	require.NoError(t, err)
+	require.Equal(t, "s2", state.Lock.SessionID)
+	require.NoError(t, s.ReleaseEditLock(ctx, 1, uid, "s2"))
+	state, err = s.GetEditState(ctx, 1, uid)
+	require.NoError(t, err)
+	require.Nil(t, state.Lock)
+
+	expires := now.Add(dashboardEditTTL).UnixMilli()
+	require.NoError(t, s.SetPanelEditor(ctx, 1, uid, PanelEditor{PanelID: 3, User: editor2, SessionID: "s2", Expires: expires}))
+	require.NoError(t, s.SetPanelEditor(ctx, 1, uid, PanelEditor{PanelID: 2, User: editor1, SessionID: "s1", Expires: expires}))
+	require.NoError(t, s.SetPanelEditor(ctx, 1, uid, PanelEditor{PanelID: 4, User: editor1, SessionID: "s3", Expires: now.Add(-time.Second).UnixMilli()}))
+	state, err = s.GetEditState(ctx, 1, uid)
+	require.NoError(t, err)
+	require.Len(t, state.Panels, 2)
+	require.Equal(t, int64(2), state.Panels[0].PanelID)
+	require.Equal(t, int64(3), state.Panels[1].PanelID)
+
+	require.NoError(t, s.RemovePanelEditor(ctx, 1, uid, "s1"))
+	require.NoError(t, s.RemovePanelEditor(ctx, 1, uid, "s2"))
+	state, err = s.GetEditState(ctx, 1, uid)
+	require.NoError(t, err)
+	require.Empty(t, state.Panels)
+}
+
+func TestMemoryDashboardEditStore(t *testing.T) {
+	testDashboardEditStore(t, NewMemoryDashboardEditStore(), "dash")
+}
+
+func TestMemoryDashboardEditStore_Expiration(t *testing.T) {
+	s := NewMemoryDashboardEditStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	_, ok, err := s.AcquireEditLock(context.Background(), 1, "dash", testEditLock(editor1, "s1", now), false)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	now = now.Add(dashboardEditTTL)
+	_, ok, err = s.AcquireEditLock(context.Background(), 1, "dash", testEditLock(editor2, "s2", now), false)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestRedisDashboardEditStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	testDashboardEditStore(t, NewRedisDashboardEditStore(redisClient), "dash")
+
+	// Locks and panel editors expire in Redis.
+	require.Greater(t, mr.TTL(getEditLockKey(2, "dash")), time.Duration(0))
+	require.Equal(t, dashboardEditTTL, mr.TTL(getPanelEditorsKey(1, "dash")))
+}
+
+func TestDashboardHandler_UpdateEditState(t *testing.T) {
+	h := &DashboardHandler{EditStore: NewMemoryDashboardEditStore()}
+	ctx := context.Background()
+
+	event := &dashboardEvent{UID: "dash", Action: EditingStarted, User: editor1, SessionID: "s1", PanelID: 2}
+	require.NoError(t, h.updateEditState(ctx, 1, event))
+	require.Equal(t, "s1", event.Lock.SessionID)
+	require.Len(t, event.Panels, 1)
+
+	event = &dashboardEvent{UID: "dash", Action: EditingStarted, User: editor2, SessionID: "s2"}
+	require.NoError(t, h.updateEditState(ctx, 1, event))
+	require.Equal(t, "s1", event.Lock.SessionID)
+
+	event = &dashboardEvent{UID: "dash", Action: PanelEditingStarted, User: editor2, SessionID: "s2", PanelID: 3}
+	require.NoError(t, h.updateEditState(ctx, 1, event))
+	require.Len(t, event.Panels, 2)
+
+	// editor2 can't save until the lock is taken over.
+	err := h.CheckEditLock(ctx, 1, editor2, "dash")
+	var dashboardErr dashboards.DashboardErr
+	require.True(t, errors.As(err, &dashboardErr))
+	require.Equal(t, 423, dashboardErr.StatusCode)
+	require.Equal(t, "edit-locked", dashboardErr.Status)
+	require.Equal(t, "The dashboard is being edited by Editor One", dashboardErr.Reason)
+	require.NoError(t, h.CheckEditLock(ctx, 1, editor1, "dash"))
+
+	event = &dashboardEvent{UID: "dash", Action: EditingTakeover, User: editor2, SessionID: "s2"}
+	require.NoError(t, h.updateEditState(ctx, 1, event))
+	require.Equal(t, "s2", event.Lock.SessionID)
+	require.NoError(t, h.CheckEditLock(ctx, 1, editor2, "dash"))
+	require.Error(t, h.CheckEditLock(ctx, 1, editor1, "dash"))
+
+	event = &dashboardEvent{UID: "dash", Action: EditingCanceled, User: editor2, SessionID: "s2"}
+	require.NoError(t, h.updateEditState(ctx, 1, event))
+	require.Nil(t, event.Lock)
+	require.Len(t, event.Panels, 1)
+	require.NoError(t, h.CheckEditLock(ctx, 1, editor1, "dash"))
+}
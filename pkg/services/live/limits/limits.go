@@ -0,0 +1,243 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/quota"
+)
+
+const (
+	QuotaTargetSrv quota.TargetSrv = "live"
+	QuotaTarget    quota.Target    = "live_channel"
+)
+
+// Limits which reject publications and subscriptions, used in errors and as metric labels.
+const (
+	LimitOrg     = "org"
+	LimitChannel = "channel"
+	LimitToken   = "token"
+	LimitSize    = "size"
+	LimitQuota   = "quota"
+)
+
+const (
+	operationPublish   = "publish"
+	operationSubscribe = "subscribe"
+)
+
+var (
+	ErrRateLimited     = errors.New("rate limit exceeded")
+	ErrMessageTooLarge = errors.New("message too large")
+	ErrQuotaReached    = errors.New("quota reached")
+)
+
+// LimitError is returned when a publication, a subscription or a new channel exceeds a limit.
+type LimitError struct {
+	err error
+	// Limit is the limit which was exceeded, one of the Limit constants.
+	Limit string
+	// RetryAfter is the time after which a rate limited request is accepted again.
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	if errors.Is(e.err, ErrRateLimited) {
+		return fmt.Sprintf("%s for %s", e.err, e.Limit)
+	}
+	return e.err.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.err
+}
+
+// RetryAfterSeconds returns the value of the Retry-After header for the error, rounded up to whole seconds.
+func (e *LimitError) RetryAfterSeconds() string {
+	return strconv.Itoa(int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// Token returns the key of the user, API key or service account for the rate limits.
+func Token(user identity.Requester) string {
+	namespaceID, identifier := user.GetNamespacedID()
+	return namespaceID + ":" + identifier
+}
+
+// Config for Limiter. Rates are in messages per second, zero means no limit.
+type Config struct {
+	PublishRatePerOrg     float64
+	PublishRatePerChannel float64
+	PublishRatePerToken   float64
+	SubscribeRatePerToken float64
+	// Burst is the number of messages accepted at once above the rate. Zero uses the rate rounded up.
+	Burst int
+	// MaxMessageSize is a maximum size of published messages in bytes, zero means no limit.
+	MaxMessageSize int
+}
+
+// idleBucketTTL is how long the rate limiter of a key is kept after it was last used.
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+type metrics struct {
+	rejected *prometheus.CounterVec
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		rejected: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "live",
+			Name:      "limited_total",
+			Help:      "Number of publications, subscriptions and new channels rejected by Live limits.",
+		}, []string{"operation", "limit"}),
+	}
+}
+
+// Limiter limits the rate and the size of publications and the rate of subscriptions to Grafana Live, and the number
+// of managed stream channels with the quota service. Rate limits are kept per Grafana instance. A nil Limiter accepts
+// everything.
+type Limiter struct {
+	cfg          Config
+	quotaService quota.Service
+	metrics      *metrics
+
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+// New creates a Limiter. The quota service is optional.
+func New(cfg Config, quotaService quota.Service, reg prometheus.Registerer) *Limiter {
+	return &Limiter{
+		cfg:          cfg,
+		quotaService: quotaService,
+		metrics:      newMetrics(reg),
+		buckets:      map[string]*bucket{},
+		lastCleanup:  time.Now(),
+		now:          time.Now,
+	}
+}
+
+// AllowPublish checks a publication of size bytes to a channel of the org by the token, a namespaced ID of
+// a user, an API key or a service account. Rate limits are only consumed if the publication is accepted.
+func (l *Limiter) AllowPublish(orgID int64, channel string, token string, size int) error {
+	if l == nil {
+		return nil
+	}
+	if l.cfg.MaxMessageSize > 0 && size > l.cfg.MaxMessageSize {
+		l.reject(operationPublish, LimitSize)
+		return &LimitError{err: ErrMessageTooLarge, Limit: LimitSize}
+	}
+	return l.allow(operationPublish, []limitKey{
+		{limit: LimitOrg, key: fmt.Sprintf("publish.org.%d", orgID), rate: l.cfg.PublishRatePerOrg},
+		{limit: LimitChannel, key: fmt.Sprintf("publish.channel.%d.%s", orgID, channel), rate: l.cfg.PublishRatePerChannel},
+		{limit: LimitToken, key: "publish.token." + token, rate: l.cfg.PublishRatePerToken},
+	})
+}
+
+// AllowSubscribe checks a subscription by the token.
+func (l *Limiter) AllowSubscribe(token string) error {
+	if l == nil {
+		return nil
+	}
+	return l.allow(operationSubscribe, []limitKey{
+		{limit: LimitToken, key: "subscribe.token." + token, rate: l.cfg.SubscribeRatePerToken},
+	})
+}
+
+// CheckChannelQuota checks if a new managed stream channel can be created in the org.
+func (l *Limiter) CheckChannelQuota(ctx context.Context, orgID int64) error {
+	if l == nil || l.quotaService == nil {
+		return nil
+	}
+	reached, err := l.quotaService.CheckQuotaReached(ctx, QuotaTargetSrv, &quota.ScopeParameters{OrgID: orgID})
+	if err != nil {
+		return err
+	}
+	if reached {
+		l.reject(operationPublish, LimitQuota)
+		return &LimitError{err: ErrQuotaReached, Limit: LimitQuota}
+	}
+	return nil
+}
+
+type limitKey struct {
+	limit string
+	key   string
+	rate  float64
+}
+
+func (l *Limiter) allow(operation string, keys []limitKey) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.cleanup(now)
+
+	reservations := make([]*rate.Reservation, 0, len(keys))
+	for _, k := range keys {
+		if k.rate <= 0 {
+			continue
+		}
+		r := l.bucket(k, now).ReserveN(now, 1)
+		delay := r.DelayFrom(now)
+		if !r.OK() || delay > 0 {
+			// Give back the tokens reserved from other limits, the publication is rejected.
+			r.CancelAt(now)
+			for _, reserved := range reservations {
+				reserved.CancelAt(now)
+			}
+			l.reject(operation, k.limit)
+			return &LimitError{err: ErrRateLimited, Limit: k.limit, RetryAfter: delay}
+		}
+		reservations = append(reservations, r)
+	}
+	return nil
+}
+
+func (l *Limiter) bucket(k limitKey, now time.Time) *rate.Limiter {
+	b, ok := l.buckets[k.key]
+	if !ok {
+		burst := l.cfg.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(k.rate))
+		}
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(k.rate), burst)}
+		l.buckets[k.key] = b
+	}
+	b.lastUsed = now
+	return b.limiter
+}
+
+// cleanup removes the rate limiters which were not used recently, so channels and tokens which are gone don't
+// keep memory.
+func (l *Limiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < idleBucketTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+func (l *Limiter) reject(operation string, limit string) {
+	l.metrics.rejected.WithLabelValues(operation, limit).Inc()
+}
@@ -0,0 +1,120 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	l := New(cfg, nil, prometheus.NewRegistry())
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func requireLimited(t *testing.T, err error, limit string) {
+	t.Helper()
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr), "expected a limit error, got %v", err)
+	require.Equal(t, limit, limitErr.Limit)
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var l *Limiter
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 1<<20))
+	require.NoError(t, l.AllowSubscribe("user:1"))
+	require.NoError(t, l.CheckChannelQuota(context.Background(), 1))
+}
+
+func TestLimiter_PublishRates(t *testing.T) {
+	l, now := newTestLimiter(Config{PublishRatePerOrg: 3, PublishRatePerChannel: 2, PublishRatePerToken: 2})
+
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	err := l.AllowPublish(1, "stream/a", "user:2", 10)
+	requireLimited(t, err, LimitChannel)
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.EqualError(t, err, "rate limit exceeded for channel")
+
+	// The rejected publication didn't consume the rate of the org, other channels can still publish.
+	require.NoError(t, l.AllowPublish(1, "stream/b", "user:2", 10))
+	requireLimited(t, l.AllowPublish(1, "stream/c", "user:3", 10), LimitOrg)
+
+	// Orgs are limited separately.
+	require.NoError(t, l.AllowPublish(2, "stream/a", "user:4", 10))
+
+	*now = now.Add(time.Second)
+	require.NoError(t, l.AllowPublish(1, "stream/c", "user:1", 10))
+	require.NoError(t, l.AllowPublish(1, "stream/d", "user:1", 10))
+	requireLimited(t, l.AllowPublish(1, "stream/e", "user:1", 10), LimitToken)
+
+	require.Equal(t, 3, testutil.CollectAndCount(l.metrics.rejected))
+}
+
+func TestLimiter_RetryAfter(t *testing.T) {
+	l, _ := newTestLimiter(Config{PublishRatePerChannel: 0.5})
+
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	err := l.AllowPublish(1, "stream/a", "user:1", 10)
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, 2*time.Second, limitErr.RetryAfter)
+	require.Equal(t, "2", limitErr.RetryAfterSeconds())
+}
+
+func TestLimiter_MaxMessageSize(t *testing.T) {
+	l, _ := newTestLimiter(Config{MaxMessageSize: 10})
+
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	err := l.AllowPublish(1, "stream/a", "user:1", 11)
+	requireLimited(t, err, LimitSize)
+	require.ErrorIs(t, err, ErrMessageTooLarge)
+}
+
+func TestLimiter_SubscribeRate(t *testing.T) {
+	l, _ := newTestLimiter(Config{SubscribeRatePerToken: 1})
+
+	require.NoError(t, l.AllowSubscribe("user:1"))
+	requireLimited(t, l.AllowSubscribe("user:1"), LimitToken)
+	require.NoError(t, l.AllowSubscribe("api-key:1"))
+	// Publications are limited separately.
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+}
+
+func TestLimiter_Cleanup(t *testing.T) {
+	l, now := newTestLimiter(Config{PublishRatePerChannel: 1})
+
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	require.NoError(t, l.AllowPublish(1, "stream/b", "user:1", 10))
+	require.Len(t, l.buckets, 2)
+
+	*now = now.Add(idleBucketTTL / 2)
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	*now = now.Add(idleBucketTTL/2 + time.Second)
+	require.NoError(t, l.AllowPublish(1, "stream/c", "user:1", 10))
+	require.Len(t, l.buckets, 2)
+	require.Contains(t, l.buckets, "publish.channel.1.stream/a")
+	require.Contains(t, l.buckets, "publish.channel.1.stream/c")
+}
+
+func TestLimiter_CheckChannelQuota(t *testing.T) {
+	l := New(Config{}, quotatest.New(false, nil), prometheus.NewRegistry())
+	require.NoError(t, l.CheckChannelQuota(context.Background(), 1))
+
+	l = New(Config{}, quotatest.New(true, nil), prometheus.NewRegistry())
+	err := l.CheckChannelQuota(context.Background(), 1)
+	requireLimited(t, err, LimitQuota)
+	require.ErrorIs(t, err, ErrQuotaReached)
+	require.Equal(t, 1.0, testutil.ToFloat64(l.metrics.rejected.WithLabelValues(operationPublish, LimitQuota)))
+
+	l = New(Config{}, quotatest.New(false, errors.New("db error")), prometheus.NewRegistry())
+	require.EqualError(t, l.CheckChannelQuota(context.Background(), 1), "db error")
+}
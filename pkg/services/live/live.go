@@ -17,8 +17,10 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/gobwas/glob"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -27,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
@@ -40,6 +43,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/liveplugin"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
@@ -53,6 +57,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -79,7 +84,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, serverLock *serverlock.ServerLockService, quotaService quota.Service,
+	reg prometheus.Registerer) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -98,7 +104,16 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		},
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
+		serverLock:        serverLock,
 	}
+	g.Limiter = limits.New(limits.Config{
+		PublishRatePerOrg:     cfg.LivePublishRatePerOrg,
+		PublishRatePerChannel: cfg.LivePublishRatePerChannel,
+		PublishRatePerToken:   cfg.LivePublishRatePerToken,
+		SubscribeRatePerToken: cfg.LiveSubscribeRatePerToken,
+		Burst:                 cfg.LiveRateLimitBurst,
+		MaxMessageSize:        cfg.LiveMaxMessageSize,
+	}, quotaService, reg)
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
 
@@ -138,7 +153,9 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	var managedStreamRunner *managedstream.Runner
 	var redisClient *redis.Client
 	if g.IsHA() && redisHealthy {
-		redisClient := redis.NewClient(&redis.Options{
+		// The client is assigned to the outer variable, so the history of managed streams is shared
+		// between the instances.
+		redisClient = redis.NewClient(&redis.Options{
 			Addr:     g.Cfg.LiveHAEngineAddress,
 			Password: g.Cfg.LiveHAEnginePassword,
 		})
@@ -149,22 +166,42 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	historyConfig := managedstream.HistoryConfig{
+		MaxFrames: g.Cfg.LiveHistoryMaxFrames,
+		MaxAge:    g.Cfg.LiveHistoryMaxAge,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCache(redisClient, historyConfig),
+			g.Limiter,
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCache(historyConfig),
+			g.Limiter,
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if quotaService != nil {
+		defaultLimits, err := readQuotaConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := quotaService.RegisterQuotaReporter(&quota.NewUsageReporter{
+			TargetSrv:     limits.QuotaTargetSrv,
+			DefaultLimits: defaultLimits,
+			Reporter:      g.Usage,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -177,6 +214,11 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		Store:            sqlStore,
 		DashboardService: dashboardService,
 	}
+	if redisClient != nil {
+		dash.EditStore = features.NewRedisDashboardEditStore(redisClient)
+	} else {
+		dash.EditStore = features.NewMemoryDashboardEditStore()
+	}
 	g.storage = database.NewStorage(g.SQLStore, g.CacheService)
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
@@ -272,30 +314,22 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	})
 
 	pushWSHandler := pushws.NewHandler(g.ManagedStreamRunner, pushws.Config{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin:     checkOrigin,
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+		MessageSizeLimit: cfg.LiveMaxMessageSize,
+		CheckOrigin:      checkOrigin,
+		Limiter:          g.Limiter,
 	})
 
 	pushPipelineWSHandler := pushws.NewPipelinePushHandler(g.Pipeline, pushws.Config{
-		ReadBufferSize:  1024,
-		WriteBufferSize: 1024,
-		CheckOrigin:     checkOrigin,
+		ReadBufferSize:   1024,
+		WriteBufferSize:  1024,
+		MessageSizeLimit: cfg.LiveMaxMessageSize,
+		CheckOrigin:      checkOrigin,
+		Limiter:          g.Limiter,
 	})
 
-	g.websocketHandler = func(ctx *contextmodel.ReqContext) {
-		user := ctx.SignedInUser
-		_, identifier := user.GetNamespacedID()
-
-		// Centrifuge expects Credentials in context with a current user ID.
-		cred := &centrifuge.Credentials{
-			UserID: identifier,
-		}
-		newCtx := centrifuge.SetCredentials(ctx.Req.Context(), cred)
-		newCtx = livecontext.SetContextSignedUser(newCtx, user)
-		r := ctx.Req.WithContext(newCtx)
-		wsHandler.ServeHTTP(ctx.Resp, r)
-	}
+	g.websocketHandler = connectHandler(wsHandler, nil)
 
 	g.pushWebsocketHandler = func(ctx *contextmodel.ReqContext) {
 		user := ctx.SignedInUser
@@ -313,8 +347,28 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		pushPipelineWSHandler.ServeHTTP(ctx.Resp, r)
 	}
 
+	// Unidirectional transports for clients which can't use WebSocket, for example behind proxies which
+	// block upgrades. Clients send commands over the emulation endpoint.
+	sseHandler := connectHandler(centrifuge.NewSSEHandler(node, centrifuge.SSEConfig{}), checkOrigin)
+	httpStreamHandler := connectHandler(centrifuge.NewHTTPStreamHandler(node, centrifuge.HTTPStreamConfig{}), checkOrigin)
+	emulationHandler := connectHandler(centrifuge.NewEmulationHandler(node, centrifuge.EmulationConfig{}), checkOrigin)
+
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
-		group.Get("/ws", g.websocketHandler)
+		for _, transport := range g.transports() {
+			switch transport {
+			case transportWebsocket:
+				group.Get("/ws", g.websocketHandler)
+			case transportSSE:
+				// SSE connections start with GET in browsers.
+				group.Get("/sse", sseHandler)
+				group.Post("/sse", sseHandler)
+			case transportHTTPStream:
+				group.Post("/http_stream", httpStreamHandler)
+			}
+		}
+		if g.unidirectionalTransportsEnabled() {
+			group.Post("/emulation", emulationHandler)
+		}
 	}, middleware.ReqSignedIn, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
 
 	g.RouteRegister.Group("/api/live", func(group routing.RouteRegister) {
@@ -327,6 +381,52 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	return g, nil
 }
 
+const (
+	transportWebsocket  = "websocket"
+	transportHTTPStream = "http_stream"
+	transportSSE        = "sse"
+)
+
+// connectHandler returns a handler for client connections of a Centrifuge transport. If checkOrigin
+// is set, requests from other origins are rejected. The WebSocket handler checks the origin itself
+// during the upgrade.
+func connectHandler(h http.Handler, checkOrigin func(r *http.Request) bool) func(ctx *contextmodel.ReqContext) {
+	return func(ctx *contextmodel.ReqContext) {
+		if checkOrigin != nil && !checkOrigin(ctx.Req) {
+			ctx.Resp.WriteHeader(http.StatusForbidden)
+			return
+		}
+		user := ctx.SignedInUser
+		_, identifier := user.GetNamespacedID()
+
+		// Centrifuge expects Credentials in context with a current user ID.
+		cred := &centrifuge.Credentials{
+			UserID: identifier,
+		}
+		newCtx := centrifuge.SetCredentials(ctx.Req.Context(), cred)
+		newCtx = livecontext.SetContextSignedUser(newCtx, user)
+		r := ctx.Req.WithContext(newCtx)
+		h.ServeHTTP(ctx.Resp, r)
+	}
+}
+
+// transports returns the configured transports, or all transports if none are configured.
+func (g *GrafanaLive) transports() []string {
+	if len(g.Cfg.LiveTransports) == 0 {
+		return []string{transportWebsocket, transportHTTPStream, transportSSE}
+	}
+	return g.Cfg.LiveTransports
+}
+
+func (g *GrafanaLive) unidirectionalTransportsEnabled() bool {
+	for _, transport := range g.transports() {
+		if transport == transportHTTPStream || transport == transportSSE {
+			return true
+		}
+	}
+	return false
+}
+
 func setupRedisLiveEngine(g *GrafanaLive, node *centrifuge.Node) error {
 	redisAddress := g.Cfg.LiveHAEngineAddress
 	redisPassword := g.Cfg.LiveHAEnginePassword
@@ -399,6 +499,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	Limiter             *limits.Limiter
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -406,6 +507,8 @@ type GrafanaLive struct {
 
 	usageStatsService usagestats.Service
 	usageStats        usageStats
+
+	serverLock *serverlock.ServerLockService
 }
 
 // DashboardActivityChannel is a service to advertise dashboard activity
@@ -419,6 +522,9 @@ type DashboardActivityChannel interface {
 	// Called when a dashboard is deleted
 	DashboardDeleted(orgID int64, user *user.UserDisplayDTO, uid string) error
 
+	// Called before a dashboard is saved, returns an error if another user holds the edit lock
+	CheckEditLock(ctx context.Context, orgID int64, user *user.UserDisplayDTO, uid string) error
+
 	// Experimental! Indicate is GitOps is active.  This really means
 	// someone is subscribed to the `grafana/dashboards/gitops` channel
 	HasGitOpsObserver(orgID int64) bool
@@ -459,6 +565,21 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if inputStorage, ok := g.pipelineStorage.(pipeline.InputStorage); ok && g.Pipeline != nil {
+		// Consume pipeline inputs in a single instance, the lock is shared over the database.
+		var locker pipeline.InputLocker
+		if g.serverLock != nil {
+			locker = g.serverLock
+		}
+		inputRunner := pipeline.NewInputRunner(inputStorage, g.SecretsService, g.Pipeline, locker)
+		eGroup.Go(func() error {
+			if err := inputRunner.Run(eCtx); err != nil {
+				logger.Error("Error running live pipeline inputs", "error", err)
+			}
+			return nil
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -620,6 +741,12 @@ func (g *GrafanaLive) handleOnSubscribe(ctx context.Context, client *centrifuge.
 		return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if err := g.Limiter.AllowSubscribe(limits.Token(user)); err != nil {
+		logger.Debug("Subscription limited", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		code, text := limitErrorToHTTPError(err)
+		return centrifuge.SubscribeReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	var reply model.SubscribeReply
 	var status backend.SubscribeStreamStatus
 	var ruleFound bool
@@ -721,6 +848,13 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 		return centrifuge.PublishReply{}, centrifuge.ErrorPermissionDenied
 	}
 
+	if err := g.Limiter.AllowPublish(orgID, channel, limits.Token(user), len(e.Data)); err != nil {
+		logger.Debug("Publication limited", "user", client.UserID(), "client", client.ID(), "channel", e.Channel, "error", err)
+		// using HTTP error codes for WS errors too.
+		code, text := limitErrorToHTTPError(err)
+		return centrifuge.PublishReply{}, &centrifuge.Error{Code: uint32(code), Message: text}
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -802,6 +936,32 @@ func (g *GrafanaLive) handleOnPublish(ctx context.Context, client *centrifuge.Cl
 	return centrifugeReply, nil
 }
 
+// limitErrorToHTTPError returns the HTTP status of an error of the Live limits.
+func limitErrorToHTTPError(err error) (int, string) {
+	switch {
+	case errors.Is(err, limits.ErrRateLimited):
+		return http.StatusTooManyRequests, err.Error()
+	case errors.Is(err, limits.ErrMessageTooLarge):
+		return http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge)
+	case errors.Is(err, limits.ErrQuotaReached):
+		return http.StatusForbidden, "Quota reached"
+	default:
+		return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+	}
+}
+
+// LimitErrorResponse returns the response to a publication rejected by the Live limits. Rate limited publishers
+// get the time to retry in the Retry-After header.
+func LimitErrorResponse(err error) response.Response {
+	code, text := limitErrorToHTTPError(err)
+	resp := response.Error(code, text, nil)
+	var limitErr *limits.LimitError
+	if errors.As(err, &limitErr) && limitErr.RetryAfter > 0 {
+		resp.SetHeader("Retry-After", limitErr.RetryAfterSeconds())
+	}
+	return resp
+}
+
 func subscribeStatusToHTTPError(status backend.SubscribeStreamStatus) (int, string) {
 	switch status {
 	case backend.SubscribeStreamStatusNotFound:
@@ -956,6 +1116,11 @@ func (g *GrafanaLive) HandleHTTPPublish(ctx *contextmodel.ReqContext) response.R
 	user := ctx.SignedInUser
 	channel := cmd.Channel
 
+	if err := g.Limiter.AllowPublish(user.GetOrgID(), channel, limits.Token(user), len(cmd.Data)); err != nil {
+		logger.Debug("Publication limited", "namespaceID", namespaceID, "userID", userID, "channel", channel, "error", err)
+		return LimitErrorResponse(err)
+	}
+
 	if g.Pipeline != nil {
 		rule, ok, err := g.Pipeline.Get(user.GetOrgID(), channel)
 		if err != nil {
@@ -1035,6 +1200,46 @@ func (g *GrafanaLive) HandleListHTTP(c *contextmodel.ReqContext) response.Respon
 	return response.JSONStreaming(http.StatusOK, info)
 }
 
+type streamChannelHistoryResponse struct {
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// HandleHistoryHTTP returns the recent frames of a managed stream channel, so they can be read without subscribing.
+func (g *GrafanaLive) HandleHistoryHTTP(ctx *contextmodel.ReqContext) response.Response {
+	channel := web.Params(ctx.Req)["*"]
+	if _, err := live.ParseChannel(channel); err != nil {
+		return response.Error(http.StatusBadRequest, "invalid channel ID", err)
+	}
+	// Reading the history of a channel requires the same permissions as subscribing to it.
+	if g.Pipeline != nil {
+		rule, ok, err := g.Pipeline.Get(ctx.SignedInUser.GetOrgID(), channel)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to get channel rule", err)
+		}
+		if ok && rule.SubscribeAuth != nil {
+			allowed, err := rule.SubscribeAuth.CanSubscribe(ctx.Req.Context(), ctx.SignedInUser)
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to check subscribe permissions", err)
+			}
+			if !allowed {
+				return response.Error(http.StatusForbidden, "Permission denied", nil)
+			}
+		}
+	}
+	frameJSON, ok, err := g.ManagedStreamRunner.GetRecentFrame(ctx.Req.Context(), ctx.SignedInUser.GetOrgID(), channel)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get channel history", err)
+	}
+	if !ok {
+		return response.Error(http.StatusNotFound, "No history for this channel", nil)
+	}
+	return response.JSON(http.StatusOK, streamChannelHistoryResponse{
+		Channel: channel,
+		Data:    frameJSON,
+	})
+}
+
 // HandleInfoHTTP special http response for
 func (g *GrafanaLive) HandleInfoHTTP(ctx *contextmodel.ReqContext) response.Response {
 	path := web.Params(ctx.Req)["*"]
@@ -1067,6 +1272,8 @@ type ConvertDryRunRequest struct {
 
 type ConvertDryRunResponse struct {
 	ChannelFrames []*pipeline.ChannelFrame `json:"channelFrames"`
+	// ProcessedFrames are the channel frames after the frame processors of their channel rules.
+	ProcessedFrames []*pipeline.ChannelFrame `json:"processedFrames"`
 }
 
 type DryRunRuleStorage struct {
@@ -1149,11 +1356,35 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Error converting data", err)
 	}
+	processedFrames := make([]*pipeline.ChannelFrame, 0, len(channelFrames))
+	for _, channelFrame := range channelFrames {
+		channel := req.Channel
+		if channelFrame.Channel != "" {
+			channel = channelFrame.Channel
+		}
+		// Process a copy, so the converted frame is returned as is.
+		frame, err := pipe.ApplyFrameProcessors(c.Req.Context(), c.SignedInUser.GetOrgID(), channel, copyFrame(channelFrame.Frame))
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "Error processing frame", err)
+		}
+		if frame != nil {
+			processedFrames = append(processedFrames, &pipeline.ChannelFrame{Channel: channelFrame.Channel, Frame: frame})
+		}
+	}
 	return response.JSON(http.StatusOK, ConvertDryRunResponse{
-		ChannelFrames: channelFrames,
+		ChannelFrames:   channelFrames,
+		ProcessedFrames: processedFrames,
 	})
 }
 
+func copyFrame(frame *data.Frame) *data.Frame {
+	frameCopy := frame.EmptyCopy()
+	for i := 0; i < frame.Rows(); i++ {
+		frameCopy.AppendRow(frame.RowCopy(i)...)
+	}
+	return frameCopy
+}
+
 // HandleChannelRulesPostHTTP ...
 func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -2,21 +2,36 @@ package live
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/centrifugal/centrifuge"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/limits"
+	"github.com/grafana/grafana/pkg/services/live/livecontext"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func Test_provideLiveService_RedisUnavailable(t *testing.T) {
@@ -31,7 +46,7 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil, nil)
 
 	// Proceeds without live HA if redis is unavaialble
 	require.NoError(t, err)
@@ -183,6 +198,41 @@ func TestCheckOrigin(t *testing.T) {
 	}
 }
 
+func TestConnectHandler_CheckOrigin(t *testing.T) {
+	appURL, err := url.Parse("https://grafana.example.com")
+	require.NoError(t, err)
+	checkOrigin := getCheckOriginFunc(appURL, nil, nil)
+
+	var served bool
+	handler := connectHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served = true
+		user, ok := livecontext.GetContextSignedUser(r.Context())
+		require.True(t, ok)
+		require.Equal(t, int64(1), user.GetOrgID())
+		cred, ok := centrifuge.GetCredentials(r.Context())
+		require.True(t, ok)
+		require.Equal(t, "2", cred.UserID)
+	}), checkOrigin)
+
+	serve := func(origin string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "https://grafana.example.com/api/live/sse", nil)
+		req.Header.Set("Origin", origin)
+		handler(&contextmodel.ReqContext{
+			Context:      &web.Context{Req: req, Resp: web.NewResponseWriter(req.Method, rec)},
+			SignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1},
+		})
+		return rec
+	}
+
+	rec := serve("https://evil.example.com")
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.False(t, served)
+
+	serve("https://grafana.example.com")
+	require.True(t, served)
+}
+
 func Test_getHistogramMetric(t *testing.T) {
 	type args struct {
 		val          int
@@ -224,3 +274,98 @@ func Test_getHistogramMetric(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitErrorResponse(t *testing.T) {
+	l := limits.New(limits.Config{PublishRatePerChannel: 1, MaxMessageSize: 10}, quotatest.New(true, nil), nil)
+
+	require.NoError(t, l.AllowPublish(1, "stream/a", "user:1", 10))
+	resp := LimitErrorResponse(l.AllowPublish(1, "stream/a", "user:1", 10)).(*response.NormalResponse)
+	require.Equal(t, http.StatusTooManyRequests, resp.Status())
+	require.Equal(t, "1", resp.Header().Get("Retry-After"))
+	require.Contains(t, string(resp.Body()), "rate limit exceeded for channel")
+
+	resp = LimitErrorResponse(l.AllowPublish(1, "stream/b", "user:1", 11)).(*response.NormalResponse)
+	require.Equal(t, http.StatusRequestEntityTooLarge, resp.Status())
+	require.Empty(t, resp.Header().Get("Retry-After"))
+
+	resp = LimitErrorResponse(l.CheckChannelQuota(context.Background(), 1)).(*response.NormalResponse)
+	require.Equal(t, http.StatusForbidden, resp.Status())
+	require.Contains(t, string(resp.Body()), "Quota reached")
+}
+
+type testChannelRuleGetter map[string]*pipeline.LiveChannelRule
+
+func (g testChannelRuleGetter) Get(_ int64, channel string) (*pipeline.LiveChannelRule, bool, error) {
+	rule, ok := g[channel]
+	return rule, ok, nil
+}
+
+func TestHandleHistoryHTTP(t *testing.T) {
+	frameCache := managedstream.NewMemoryFrameCache(managedstream.HistoryConfig{MaxFrames: 1})
+	for _, channel := range []string{"stream/test/public", "stream/test/admin"} {
+		frame, err := data.FrameToJSONCache(data.NewFrame("test", data.NewField("value", nil, []float64{1})))
+		require.NoError(t, err)
+		_, err = frameCache.Update(context.Background(), 1, channel, frame)
+		require.NoError(t, err)
+	}
+	p, err := pipeline.New(testChannelRuleGetter{
+		"stream/test/admin": {SubscribeAuth: pipeline.NewRoleCheckAuthorizer(org.RoleAdmin)},
+	})
+	require.NoError(t, err)
+	g := &GrafanaLive{
+		ManagedStreamRunner: managedstream.NewRunner(nil, nil, frameCache, nil),
+		Pipeline:            p,
+	}
+
+	history := func(channel string) response.Response {
+		req := httptest.NewRequest(http.MethodGet, "/api/live/history/"+channel, nil)
+		req = web.SetURLParams(req, map[string]string{"*": channel})
+		return g.HandleHistoryHTTP(&contextmodel.ReqContext{
+			Context:      &web.Context{Req: req},
+			SignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer},
+		})
+	}
+
+	require.Equal(t, http.StatusOK, history("stream/test/public").Status())
+	// The subscribe permissions of the channel rule apply to its history.
+	require.Equal(t, http.StatusForbidden, history("stream/test/admin").Status())
+	require.Equal(t, http.StatusNotFound, history("stream/test/other").Status())
+}
+
+func TestProvideService_RedisHistory(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	newService := func() *GrafanaLive {
+		cfg := setting.NewCfg()
+		cfg.LiveHAEngine = "redis"
+		cfg.LiveHAEngineAddress = mr.Addr()
+		cfg.LiveHistoryMaxFrames = 2
+		g, err := ProvideService(nil, cfg,
+			routing.NewRouteRegister(),
+			nil, nil, nil, nil,
+			db.InitTestDB(t),
+			nil,
+			&usagestats.UsageStatsMock{T: t},
+			nil,
+			featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil, nil)
+		require.NoError(t, err)
+		return g
+	}
+	first, second := newService(), newService()
+
+	stream, err := first.ManagedStreamRunner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+	for _, v := range []float64{1, 2} {
+		require.NoError(t, stream.Push(context.Background(), "values", data.NewFrame("test", data.NewField("value", nil, []float64{v}))))
+	}
+
+	// The history is kept in Redis, so it is shared between the instances.
+	frameJSON, ok, err := second.ManagedStreamRunner.GetRecentFrame(context.Background(), 1, "stream/test/values")
+	require.NoError(t, err)
+	require.True(t, ok)
+	var frame data.Frame
+	require.NoError(t, json.Unmarshal(frameJSON, &frame))
+	require.Equal(t, 2, frame.Rows())
+}
@@ -11,7 +11,11 @@ import (
 type FrameCache interface {
 	// GetActiveChannels returns active managed stream channels with JSON schema.
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
-	// GetFrame returns full JSON frame for a channel in org.
+	// CountChannels returns the number of cached channels of the scope in the org, or in all orgs if orgID is zero.
+	// Caches shared between Grafana instances count the channels of all instances.
+	CountChannels(ctx context.Context, orgID int64, scope string) (int64, error)
+	// GetFrame returns full JSON frame for a channel in org. If the cache keeps a history of recent frames, the
+	// frames are merged into a single frame.
 	GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
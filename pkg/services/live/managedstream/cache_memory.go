@@ -3,7 +3,9 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +14,22 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu      sync.RWMutex
+	frames  map[int64]map[string]data.FrameJSONCache
+	history map[int64]map[string][]historyEntry
+	config  HistoryConfig
+	now     func() time.Time
+	log     log.Logger
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(history HistoryConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string][]historyEntry{},
+		config:  history,
+		now:     time.Now,
+		log:     log.New("live.memoryframecache"),
 	}
 }
 
@@ -39,11 +47,35 @@ func (c *MemoryFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMe
 	return info, nil
 }
 
+func (c *MemoryFrameCache) CountChannels(_ context.Context, orgID int64, scope string) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var count int64
+	for id, frames := range c.frames {
+		if orgID != 0 && id != orgID {
+			continue
+		}
+		for ch := range frames {
+			if strings.HasPrefix(ch, scope+"/") {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
 func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	cachedFrame, ok := c.frames[orgID][channel]
 	raw := cachedFrame.Bytes(data.IncludeAll)
+	if ok && c.config.maxFrames() > 1 {
+		var err error
+		raw, err = mergeHistory(c.recentFrames(orgID, channel))
+		if err != nil {
+			return nil, false, err
+		}
+	}
 	c.log.Debug("Cache get",
 		"orgId", orgID,
 		"channel", channel,
@@ -61,6 +93,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.config.maxFrames() > 1 {
+		c.appendHistory(orgID, channel, jsonFrame, schemaUpdated)
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +103,41 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+// appendHistory appends the frame to the history of the channel and drops the frames that exceed the limits. The
+// history is reset when the schema changes, since frames with different schemas can't be merged.
+func (c *MemoryFrameCache) appendHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) {
+	if _, ok := c.history[orgID]; !ok {
+		c.history[orgID] = map[string][]historyEntry{}
+	}
+	history := c.history[orgID][channel]
+	if schemaUpdated {
+		history = nil
+	}
+	now := c.now()
+	history = append(history, historyEntry{Time: now.UnixMilli(), Frame: jsonFrame.Bytes(data.IncludeAll)})
+	start := 0
+	if len(history) > c.config.maxFrames() {
+		start = len(history) - c.config.maxFrames()
+	}
+	for start < len(history)-1 && c.config.expired(time.UnixMilli(history[start].Time), now) {
+		start++
+	}
+	// The dropped frames are released when append reallocates the slice.
+	c.history[orgID][channel] = history[start:]
+}
+
+// recentFrames returns the frames of the history of the channel which are not expired, ordered from oldest to newest.
+// The latest frame is always returned.
+func (c *MemoryFrameCache) recentFrames(orgID int64, channel string) []json.RawMessage {
+	history := c.history[orgID][channel]
+	now := c.now()
+	frames := make([]json.RawMessage, 0, len(history))
+	for i, entry := range history {
+		if i < len(history)-1 && c.config.expired(time.UnixMilli(entry.Time), now) {
+			continue
+		}
+		frames = append(frames, entry.Frame)
+	}
+	return frames
+}
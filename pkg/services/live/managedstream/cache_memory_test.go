@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -63,7 +64,118 @@ func testFrameCache(t *testing.T, c FrameCache) {
 }
 
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func testFrameCacheHistory(t *testing.T, c FrameCache, now *time.Time) {
+	push := func(channel string, frame *data.Frame) {
+		frameJsonCache, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		_, err = c.Update(context.Background(), 1, channel, frameJsonCache)
+		require.NoError(t, err)
+	}
+	get := func(channel string) *data.Frame {
+		frameJSON, ok, err := c.GetFrame(context.Background(), 1, channel)
+		require.NoError(t, err)
+		require.True(t, ok)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		return &f
+	}
+
+	for i := int64(0); i < 4; i++ {
+		push("history", data.NewFrame("hello", data.NewField("value", nil, []int64{i})))
+		*now = now.Add(time.Minute)
+	}
+	// Only the last 3 frames are kept.
+	f := get("history")
+	require.Equal(t, 3, f.Rows())
+	require.Equal(t, int64(1), f.Fields[0].At(0))
+	require.Equal(t, int64(3), f.Fields[0].At(2))
+
+	// Frames older than the max age are skipped.
+	*now = now.Add(time.Minute)
+	f = get("history")
+	require.Equal(t, 2, f.Rows())
+	require.Equal(t, int64(2), f.Fields[0].At(0))
+
+	// The latest frame is always kept.
+	*now = now.Add(time.Hour)
+	f = get("history")
+	require.Equal(t, 1, f.Rows())
+	require.Equal(t, int64(3), f.Fields[0].At(0))
+
+	// The history is reset when the schema changes.
+	push("history", data.NewFrame("hello", data.NewField("value", nil, []float64{4})))
+	f = get("history")
+	require.Equal(t, 1, f.Rows())
+	require.Equal(t, 4.0, f.Fields[0].At(0))
+}
+
+func TestMemoryFrameCacheHistory(t *testing.T) {
+	c := NewMemoryFrameCache(HistoryConfig{MaxFrames: 3, MaxAge: 3 * time.Minute})
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	testFrameCacheHistory(t, c, &now)
+}
+
+// testCountChannels pushes channels to the first cache and counts them in all caches, which must share their storage.
+// The orgs must not have channels yet.
+func testCountChannels(t *testing.T, orgIDs [2]int64, caches ...FrameCache) {
+	frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("hello"))
+	require.NoError(t, err)
+	ctx := context.Background()
+	total := make([]int64, len(caches))
+	for i, c := range caches {
+		total[i], err = c.CountChannels(ctx, 0, "stream")
+		require.NoError(t, err)
+	}
+	for _, channel := range []string{"stream/test/cpu", "stream/test/mem", "ds/uid/cpu"} {
+		_, err := caches[0].Update(ctx, orgIDs[0], channel, frameJsonCache)
+		require.NoError(t, err)
+	}
+	_, err = caches[0].Update(ctx, orgIDs[1], "stream/test/cpu", frameJsonCache)
+	require.NoError(t, err)
+
+	for i, c := range caches {
+		count, err := c.CountChannels(ctx, orgIDs[0], "stream")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), count)
+		count, err = c.CountChannels(ctx, orgIDs[0], "ds")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+		count, err = c.CountChannels(ctx, 0, "stream")
+		require.NoError(t, err)
+		require.Equal(t, total[i]+3, count)
+	}
+}
+
+func TestMemoryFrameCache_CountChannels(t *testing.T) {
+	testCountChannels(t, [2]int64{1, 2}, NewMemoryFrameCache(HistoryConfig{}))
+}
+
+func TestMergeHistory(t *testing.T) {
+	toJSON := func(frame *data.Frame) json.RawMessage {
+		frameJSON, err := data.FrameToJSON(frame, data.IncludeAll)
+		require.NoError(t, err)
+		return frameJSON
+	}
+	merged, err := mergeHistory([]json.RawMessage{
+		toJSON(data.NewFrame("cpu", data.NewField("value", nil, []string{"a"}))),
+		toJSON(data.NewFrame("cpu", data.NewField("value", nil, []float64{1, 2}))),
+		toJSON(data.NewFrame("cpu", data.NewField("value", nil, []float64{3}))),
+	})
+	require.NoError(t, err)
+	var f data.Frame
+	require.NoError(t, json.Unmarshal(merged, &f))
+	require.Equal(t, "cpu", f.Name)
+	require.Equal(t, 3, f.Rows())
+	require.Equal(t, 1.0, f.Fields[0].At(0))
+	require.Equal(t, 3.0, f.Fields[0].At(2))
+
+	merged, err = mergeHistory(nil)
+	require.NoError(t, err)
+	require.Nil(t, merged)
+}
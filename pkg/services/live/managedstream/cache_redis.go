@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,13 +19,17 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	config      HistoryConfig
+	now         func() time.Time
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, history HistoryConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		config:      history,
+		now:         time.Now,
 	}
 }
 
@@ -42,7 +47,34 @@ func (c *RedisFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMes
 	return info, nil
 }
 
+// CountChannels counts the cached frames of the channels in Redis, so channels of all Grafana instances are counted
+// until their frames expire.
+func (c *RedisFrameCache) CountChannels(ctx context.Context, orgID int64, scope string) (int64, error) {
+	pattern := getCacheKey("*")
+	if orgID != 0 {
+		pattern = getCacheKey(orgchannel.PrependOrgID(orgID, scope+"/*"))
+	}
+	var count int64
+	iter := c.redisClient.Scan(ctx, 0, pattern, 1000).Iterator()
+	for iter.Next(ctx) {
+		_, channel, err := orgchannel.StripOrgID(strings.TrimPrefix(iter.Val(), getCacheKey("")))
+		if err != nil {
+			continue
+		}
+		if strings.HasPrefix(channel, scope+"/") {
+			count++
+		}
+	}
+	return count, iter.Err()
+}
+
 func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if c.config.maxFrames() > 1 {
+		frame, err := c.getHistory(ctx, orgID, channel)
+		if err != nil || frame != nil {
+			return frame, frame != nil, err
+		}
+	}
 	key := getCacheKey(orgchannel.PrependOrgID(orgID, channel))
 	cmd := c.redisClient.HGetAll(ctx, key)
 	result, err := cmd.Result()
@@ -99,14 +131,67 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 		if err != nil {
 			return false, err
 		}
-		if len(result) == 0 {
-			return true, nil
+		schemaUpdated := len(result) == 0 || result["schema"] != stringSchema
+		if c.config.maxFrames() > 1 {
+			if err := c.appendHistory(ctx, orgID, channel, jsonFrame, schemaUpdated); err != nil {
+				return false, err
+			}
 		}
-		return result["schema"] != stringSchema, nil
+		return schemaUpdated, nil
 	}
 	return true, nil
 }
 
+// appendHistory appends the frame to the history list of the channel and trims the list to the maximum number of
+// frames. The history is reset when the schema changes, since frames with different schemas can't be merged. Expired
+// frames are skipped when the history is read.
+func (c *RedisFrameCache) appendHistory(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) error {
+	entry, err := json.Marshal(historyEntry{Time: c.now().UnixMilli(), Frame: jsonFrame.Bytes(data.IncludeAll)})
+	if err != nil {
+		return err
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+
+	pipe := c.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	if schemaUpdated {
+		pipe.Del(ctx, key)
+	}
+	pipe.RPush(ctx, key, entry)
+	pipe.LTrim(ctx, key, int64(-c.config.maxFrames()), -1)
+	pipe.Expire(ctx, key, frameCacheTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// getHistory returns the frames of the history of the channel which are not expired merged into a single frame, or
+// nil if there is no history.
+func (c *RedisFrameCache) getHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, error) {
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	now := c.now()
+	frames := make([]json.RawMessage, 0, len(result))
+	for i, item := range result {
+		var entry historyEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, err
+		}
+		if i < len(result)-1 && c.config.expired(time.UnixMilli(entry.Time), now) {
+			continue
+		}
+		frames = append(frames, entry.Frame)
+	}
+	return mergeHistory(frames)
+}
+
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
@@ -1,11 +1,15 @@
 package managedstream
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 )
 
 func TestIntegrationRedisCacheStorage(t *testing.T) {
@@ -30,7 +34,18 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 		Addr: addr,
 		DB:   db,
 	})
-	c := NewRedisFrameCache(redisClient)
+	c := NewRedisFrameCache(redisClient, HistoryConfig{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
+
+	c = NewRedisFrameCache(redisClient, HistoryConfig{MaxFrames: 3, MaxAge: 3 * time.Minute})
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	// Reset the history of previous runs.
+	require.NoError(t, redisClient.Del(context.Background(), getHistoryKey(orgchannel.PrependOrgID(1, "history"))).Err())
+	testFrameCacheHistory(t, c, &now)
+
+	// Channels pushed on other instances are counted. Use unused orgs, since keys of previous runs are kept.
+	orgID := time.Now().UnixNano()
+	testCountChannels(t, [2]int64{orgID, orgID + 1}, NewRedisFrameCache(redisClient, HistoryConfig{}), NewRedisFrameCache(redisClient, HistoryConfig{}))
 }
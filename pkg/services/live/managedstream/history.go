@@ -0,0 +1,81 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// HistoryConfig configures the recent frames kept per channel by a FrameCache.
+type HistoryConfig struct {
+	// MaxFrames is the maximum number of frames kept per channel. Values below 1 keep only the latest frame.
+	MaxFrames int
+	// MaxAge is the maximum age of the frames kept per channel. The latest frame is always kept. Zero means no limit.
+	MaxAge time.Duration
+}
+
+func (h HistoryConfig) maxFrames() int {
+	if h.MaxFrames < 1 {
+		return 1
+	}
+	return h.MaxFrames
+}
+
+// expired returns true if a frame pushed at the time is too old to be kept.
+func (h HistoryConfig) expired(pushed time.Time, now time.Time) bool {
+	return h.MaxAge > 0 && now.Sub(pushed) > h.MaxAge
+}
+
+// historyEntry is a frame pushed to a channel.
+type historyEntry struct {
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// mergeHistory merges the frames of the history, ordered from oldest to newest, into a single frame with all rows.
+// Frames with a schema other than the newest frame are skipped, so the rows of the frames before the last schema
+// change are not replayed.
+func mergeHistory(history []json.RawMessage) (json.RawMessage, error) {
+	switch len(history) {
+	case 0:
+		return nil, nil
+	case 1:
+		return history[0], nil
+	}
+
+	frames := make([]*data.Frame, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		var frame data.Frame
+		if err := json.Unmarshal(history[i], &frame); err != nil {
+			return nil, err
+		}
+		if len(frames) > 0 && !sameFields(frames[0], &frame) {
+			break
+		}
+		frames = append(frames, &frame)
+	}
+	if len(frames) == 1 {
+		return history[len(history)-1], nil
+	}
+
+	merged := frames[0].EmptyCopy()
+	for i := len(frames) - 1; i >= 0; i-- {
+		for row := 0; row < frames[i].Rows(); row++ {
+			merged.AppendRow(frames[i].RowCopy(row)...)
+		}
+	}
+	return data.FrameToJSON(merged, data.IncludeAll)
+}
+
+func sameFields(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -42,20 +42,37 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	channelQuota   ChannelQuota
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
-// NewRunner creates new Runner.
-func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
+// ChannelQuota is checked before the first frame is pushed to a channel of the stream scope.
+type ChannelQuota interface {
+	CheckChannelQuota(ctx context.Context, orgID int64) error
+}
+
+// NewRunner creates new Runner. The channel quota is optional.
+func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, channelQuota ChannelQuota) *Runner {
 	return &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
+		channelQuota:   channelQuota,
+	}
+}
+
+// CountStreamChannels returns the number of active channels of the stream scope in the org, or in all orgs if orgID
+// is zero. Channels of other Grafana instances are counted if the frame cache is shared.
+func (r *Runner) CountStreamChannels(ctx context.Context, orgID int64) (int64, error) {
+	count, err := r.frameCache.CountChannels(ctx, orgID, live.ScopeStream)
+	if err != nil {
+		return 0, fmt.Errorf("error counting managed stream channels: %w", err)
 	}
+	return count, nil
 }
 
 func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
@@ -113,6 +130,12 @@ func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
 	return channels, nil
 }
 
+// GetRecentFrame returns the recent frames of a managed stream channel merged into a single frame, as sent to new
+// subscribers of the channel.
+func (r *Runner) GetRecentFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	return r.frameCache.GetFrame(ctx, orgID, channel)
+}
+
 // GetOrCreateStream -- for now this will create new manager for each key.
 // Eventually, the stream behavior will need to be configured explicitly
 func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string) (*NamespaceStream, error) {
@@ -126,6 +149,9 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
 		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		if scope == live.ScopeStream {
+			s.channelQuota = r.channelQuota
+		}
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -139,6 +165,7 @@ type NamespaceStream struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	channelQuota   ChannelQuota
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -180,6 +207,12 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 	// The channel this will be posted into.
 	channel := live.Channel{Scope: s.scope, Namespace: s.namespace, Path: path}.String()
 
+	if s.channelQuota != nil && !s.hasPath(path) {
+		if err := s.channelQuota.CheckChannelQuota(ctx, s.orgID); err != nil {
+			return err
+		}
+	}
+
 	isUpdated, err := s.frameCache.Update(ctx, s.orgID, channel, jsonFrameCache)
 	if err != nil {
 		logger.Error("Error updating managed stream schema", "error", err)
@@ -219,6 +252,14 @@ func (s *NamespaceStream) incRate(path string, nowUnix int64) {
 	s.rateMu.Unlock()
 }
 
+// hasPath returns true if a frame was pushed to the path before.
+func (s *NamespaceStream) hasPath(path string) bool {
+	s.rateMu.RLock()
+	defer s.rateMu.RUnlock()
+	_, ok := s.rates[path]
+	return ok
+}
+
 func (s *NamespaceStream) minuteRate(path string) int64 {
 	var total int64
 	s.rateMu.RLock()
@@ -2,6 +2,7 @@ package managedstream
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -17,15 +18,21 @@ func (p *testPublisher) publish(_ int64, _ string, _ []byte) error {
 	return nil
 }
 
+type testLocalPublisher struct{}
+
+func (p *testLocalPublisher) PublishLocal(_ string, _ []byte) error {
+	return nil
+}
+
 func TestNewManagedStream(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 }
 
 func TestManagedStreamMinuteRate(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache())
+	c := NewNamespaceStream(1, "stream", "a", publisher.publish, nil, NewMemoryFrameCache(HistoryConfig{}))
 	require.NotNil(t, c)
 
 	c.incRate("test1", time.Now().Unix())
@@ -47,8 +54,8 @@ func TestManagedStreamMinuteRate(t *testing.T) {
 
 func TestGetManagedStreams(t *testing.T) {
 	publisher := &testPublisher{t: t}
-	frameCache := NewMemoryFrameCache()
-	runner := NewRunner(publisher.publish, nil, frameCache)
+	frameCache := NewMemoryFrameCache(HistoryConfig{})
+	runner := NewRunner(publisher.publish, nil, frameCache, nil)
 	s1, err := runner.GetOrCreateStream(1, "stream", "test1")
 	require.NoError(t, err)
 	s2, err := runner.GetOrCreateStream(1, "stream", "test2")
@@ -83,3 +90,56 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+type testChannelQuota struct {
+	limit  int64
+	runner *Runner
+	checks int
+}
+
+func (q *testChannelQuota) CheckChannelQuota(ctx context.Context, orgID int64) error {
+	q.checks++
+	count, err := q.runner.CountStreamChannels(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if count >= q.limit {
+		return errQuotaReached
+	}
+	return nil
+}
+
+var errQuotaReached = errors.New("quota reached")
+
+func TestRunner_ChannelQuota(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	quota := &testChannelQuota{limit: 2}
+	runner := NewRunner(publisher.publish, &testLocalPublisher{}, NewMemoryFrameCache(HistoryConfig{}), quota)
+	quota.runner = runner
+
+	s, err := runner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+	require.NoError(t, s.Push(context.Background(), "cpu1", data.NewFrame("cpu1")))
+	require.NoError(t, s.Push(context.Background(), "cpu2", data.NewFrame("cpu2")))
+	require.ErrorIs(t, s.Push(context.Background(), "cpu3", data.NewFrame("cpu3")), errQuotaReached)
+
+	// Existing channels are not checked again.
+	require.NoError(t, s.Push(context.Background(), "cpu1", data.NewFrame("cpu1")))
+	require.Equal(t, 3, quota.checks)
+
+	// Channels of plugins and data sources are not limited.
+	ds, err := runner.GetOrCreateStream(1, "ds", "uid")
+	require.NoError(t, err)
+	require.NoError(t, ds.Push(context.Background(), "cpu3", data.NewFrame("cpu3")))
+
+	other, err := runner.GetOrCreateStream(2, "stream", "test")
+	require.NoError(t, err)
+	require.NoError(t, other.Push(context.Background(), "cpu1", data.NewFrame("cpu1")))
+
+	count, err := runner.CountStreamChannels(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+	count, err = runner.CountStreamChannels(context.Background(), 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
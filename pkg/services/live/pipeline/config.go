@@ -50,11 +50,55 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type WindowFrameProcessorConfig struct {
+	// TimeField is the name of the time field, the first time field by default.
+	TimeField            string `json:"timeField,omitempty"`
+	IntervalMilliseconds int64  `json:"intervalMs"`
+	// SlideMilliseconds makes windows sliding if it is shorter than the interval.
+	SlideMilliseconds int64         `json:"slideMs,omitempty"`
+	Reducer           WindowReducer `json:"reducer"`
+	// FieldNames are the numeric fields to aggregate, all numeric fields by default.
+	FieldNames []string `json:"fieldNames,omitempty"`
+}
+
+type CalculateFrameProcessorConfig struct {
+	FieldName string `json:"fieldName"`
+	// Expression is a math expression which refers to numeric fields as $name or ${name}.
+	Expression string `json:"expression"`
+}
+
+type FieldConversion struct {
+	FieldName string `json:"fieldName"`
+	Rename    string `json:"rename,omitempty"`
+	// Multiply and Add convert the values with value * multiply + add.
+	Multiply *float64 `json:"multiply,omitempty"`
+	Add      float64  `json:"add,omitempty"`
+	// Unit is the display unit of the converted values.
+	Unit string `json:"unit,omitempty"`
+}
+
+type ConvertFieldsFrameProcessorConfig struct {
+	Fields []FieldConversion `json:"fields"`
+}
+
+type ExtractLabelsFrameProcessorConfig struct {
+	// FieldName is the name of the string field with labels, "labels" by default.
+	FieldName string `json:"fieldName,omitempty"`
+	// LabelNames are the labels to extract, all labels by default.
+	LabelNames []string `json:"labelNames,omitempty"`
+	// KeepField keeps the labels field in the frame.
+	KeepField bool `json:"keepField,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                         string                             `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig    *DropFieldsFrameProcessorConfig    `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig    *KeepFieldsFrameProcessorConfig    `json:"keepFields,omitempty"`
+	MultipleProcessorConfig      *MultipleFrameProcessorConfig      `json:"multiple,omitempty"`
+	WindowProcessorConfig        *WindowFrameProcessorConfig        `json:"window,omitempty"`
+	CalculateProcessorConfig     *CalculateFrameProcessorConfig     `json:"calculate,omitempty"`
+	ConvertFieldsProcessorConfig *ConvertFieldsFrameProcessorConfig `json:"convertFields,omitempty"`
+	ExtractLabelsProcessorConfig *ExtractLabelsFrameProcessorConfig `json:"extractLabels,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -153,3 +197,24 @@ type AutoInfluxConverterConfig struct {
 type JsonFrameConverterConfig struct{}
 
 type ManagedStreamOutputConfig struct{}
+
+type MQTTInputConfig struct {
+	// Broker is the address of the MQTT broker, for example tcp://localhost:1883, ssl://broker:8883 or ws://broker:8080/mqtt.
+	Broker string `json:"broker"`
+	// Topic to subscribe to, may contain the + and # wildcards.
+	Topic string `json:"topic"`
+	QoS   byte   `json:"qos,omitempty"`
+	// ClientID is grafana-live-<uid> by default.
+	ClientID  string     `json:"clientId,omitempty"`
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+}
+
+type KafkaInputConfig struct {
+	Brokers []string `json:"brokers"`
+	Topic   string   `json:"topic"`
+	// GroupID is the consumer group, grafana-live-<uid> by default.
+	GroupID string `json:"groupId,omitempty"`
+	// BasicAuth enables SASL/PLAIN authentication.
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+	TLS       bool       `json:"tls,omitempty"`
+}
@@ -0,0 +1,175 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// calculateFuncs are the functions which can be used in the expressions of CalculateFrameProcessor.
+var calculateFuncs = map[string]func(float64) float64{
+	"abs":   math.Abs,
+	"log":   math.Log,
+	"round": math.Round,
+	"ceil":  math.Ceil,
+	"floor": math.Floor,
+	"sqrt":  math.Sqrt,
+}
+
+// CalculateFrameProcessor adds a field with the result of a math expression for each row of a
+// frame. The expression refers to numeric fields of the row as $name or ${name}, and the result is
+// null if a referenced value is null or the field is missing.
+type CalculateFrameProcessor struct {
+	config CalculateFrameProcessorConfig
+	tree   *parse.Tree
+}
+
+func NewCalculateFrameProcessor(config CalculateFrameProcessorConfig) (*CalculateFrameProcessor, error) {
+	if config.FieldName == "" {
+		return nil, fmt.Errorf("missing field name of calculated field")
+	}
+	funcs := make(map[string]parse.Func, len(calculateFuncs))
+	for name := range calculateFuncs {
+		funcs[name] = parse.Func{
+			Args:   []parse.ReturnType{parse.TypeVariantSet},
+			Return: parse.TypeScalar,
+		}
+	}
+	tree, err := parse.Parse(config.Expression, funcs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	return &CalculateFrameProcessor{config: config, tree: tree}, nil
+}
+
+const FrameProcessorTypeCalculate = "calculate"
+
+func (p *CalculateFrameProcessor) Type() string {
+	return FrameProcessorTypeCalculate
+}
+
+func (p *CalculateFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	fields := make(map[string]*data.Field, len(frame.Fields))
+	for _, f := range frame.Fields {
+		if f.Type().Numeric() {
+			fields[f.Name] = f
+		}
+	}
+	values := make([]*float64, frame.Rows())
+	for row := range values {
+		v, err := p.eval(p.tree.Root, fields, row)
+		if err != nil {
+			return nil, err
+		}
+		values[row] = v
+	}
+
+	field := data.NewField(p.config.FieldName, nil, values)
+	for i, f := range frame.Fields {
+		if f.Name == p.config.FieldName {
+			frame.Fields[i] = field
+			return frame, nil
+		}
+	}
+	frame.Fields = append(frame.Fields, field)
+	return frame, nil
+}
+
+func (p *CalculateFrameProcessor) eval(node parse.Node, fields map[string]*data.Field, row int) (*float64, error) {
+	var v float64
+	switch node := node.(type) {
+	case *parse.ScalarNode:
+		v = node.Float64
+	case *parse.VarNode:
+		f, ok := fields[node.Name]
+		if !ok {
+			return nil, nil
+		}
+		return f.NullableFloatAt(row)
+	case *parse.UnaryNode:
+		a, err := p.eval(node.Arg, fields, row)
+		if err != nil || a == nil {
+			return nil, err
+		}
+		switch node.OpStr {
+		case "-":
+			v = -*a
+		case "!":
+			v = boolToFloat(*a == 0)
+		default:
+			return nil, fmt.Errorf("unsupported unary operator: %s", node.OpStr)
+		}
+	case *parse.BinaryNode:
+		a, err := p.eval(node.Args[0], fields, row)
+		if err != nil || a == nil {
+			return nil, err
+		}
+		b, err := p.eval(node.Args[1], fields, row)
+		if err != nil || b == nil {
+			return nil, err
+		}
+		v, err = calculateBinary(node.OpStr, *a, *b)
+		if err != nil {
+			return nil, err
+		}
+	case *parse.FuncNode:
+		fn, ok := calculateFuncs[node.Name]
+		if !ok || len(node.Args) != 1 {
+			return nil, fmt.Errorf("unsupported function: %s", node.Name)
+		}
+		a, err := p.eval(node.Args[0], fields, row)
+		if err != nil || a == nil {
+			return nil, err
+		}
+		v = fn(*a)
+	default:
+		return nil, fmt.Errorf("unsupported expression: %s", node)
+	}
+	return &v, nil
+}
+
+func calculateBinary(op string, a, b float64) (float64, error) {
+	switch op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		return a / b, nil
+	case "%":
+		return math.Mod(a, b), nil
+	case "**":
+		return math.Pow(a, b), nil
+	case "==":
+		return boolToFloat(a == b), nil
+	case "!=":
+		return boolToFloat(a != b), nil
+	case ">":
+		return boolToFloat(a > b), nil
+	case ">=":
+		return boolToFloat(a >= b), nil
+	case "<":
+		return boolToFloat(a < b), nil
+	case "<=":
+		return boolToFloat(a <= b), nil
+	case "&&":
+		return boolToFloat(a != 0 && b != 0), nil
+	case "||":
+		return boolToFloat(a != 0 || b != 0), nil
+	default:
+		return 0, fmt.Errorf("unsupported binary operator: %s", op)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFrameProcessor(t *testing.T) {
+	p, err := NewCalculateFrameProcessor(CalculateFrameProcessorConfig{
+		FieldName:  "fahrenheit",
+		Expression: "round(${temperature} * 1.8 + 32) + -$offset",
+	})
+	require.NoError(t, err)
+
+	offset := 1.0
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("temperature", nil, []int64{20, 21}),
+		data.NewField("offset", nil, []*float64{&offset, nil}),
+	))
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "fahrenheit", frame.Fields[2].Name)
+	require.Equal(t, 67.0, *frame.Fields[2].At(0).(*float64))
+	require.Nil(t, frame.Fields[2].At(1))
+}
+
+func TestCalculateFrameProcessor_InvalidExpression(t *testing.T) {
+	_, err := NewCalculateFrameProcessor(CalculateFrameProcessorConfig{FieldName: "x", Expression: "median($a)"})
+	require.Error(t, err)
+	_, err = NewCalculateFrameProcessor(CalculateFrameProcessorConfig{Expression: "$a"})
+	require.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ConvertFieldsFrameProcessor can rename fields, convert numeric values with a linear conversion
+// and set the unit of fields. Converted fields become nullable float64 fields.
+type ConvertFieldsFrameProcessor struct {
+	config ConvertFieldsFrameProcessorConfig
+}
+
+func NewConvertFieldsFrameProcessor(config ConvertFieldsFrameProcessorConfig) *ConvertFieldsFrameProcessor {
+	return &ConvertFieldsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeConvertFields = "convertFields"
+
+func (p *ConvertFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeConvertFields
+}
+
+func (p *ConvertFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, conversion := range p.config.Fields {
+		for i, field := range frame.Fields {
+			if field.Name != conversion.FieldName {
+				continue
+			}
+			if conversion.Multiply != nil || conversion.Add != 0 {
+				converted, err := convertValues(field, conversion)
+				if err != nil {
+					return nil, err
+				}
+				frame.Fields[i] = converted
+				field = converted
+			}
+			if conversion.Rename != "" {
+				field.Name = conversion.Rename
+			}
+			if conversion.Unit != "" {
+				if field.Config == nil {
+					field.Config = &data.FieldConfig{}
+				}
+				field.Config.Unit = conversion.Unit
+			}
+			break
+		}
+	}
+	return frame, nil
+}
+
+func convertValues(field *data.Field, conversion FieldConversion) (*data.Field, error) {
+	if !field.Type().Numeric() {
+		return nil, fmt.Errorf("can't convert values of non-numeric field %s", field.Name)
+	}
+	multiply := 1.0
+	if conversion.Multiply != nil {
+		multiply = *conversion.Multiply
+	}
+	values := make([]*float64, field.Len())
+	for i := range values {
+		v, err := field.NullableFloatAt(i)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			converted := *v*multiply + conversion.Add
+			values[i] = &converted
+		}
+	}
+	converted := data.NewField(field.Name, field.Labels, values)
+	converted.Config = field.Config
+	return converted, nil
+}
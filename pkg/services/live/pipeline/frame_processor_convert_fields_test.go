@@ -0,0 +1,31 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFieldsFrameProcessor(t *testing.T) {
+	multiply := 100.0
+	p := NewConvertFieldsFrameProcessor(ConvertFieldsFrameProcessorConfig{Fields: []FieldConversion{
+		{FieldName: "used", Rename: "used_percent", Multiply: &multiply, Unit: "percent"},
+		{FieldName: "host", Rename: "hostname"},
+	}})
+	frame, err := p.ProcessFrame(context.Background(), Vars{}, data.NewFrame("test",
+		data.NewField("host", nil, []string{"a"}),
+		data.NewField("used", nil, []float64{0.25}),
+	))
+	require.NoError(t, err)
+	require.Equal(t, "hostname", frame.Fields[0].Name)
+	require.Equal(t, "used_percent", frame.Fields[1].Name)
+	require.Equal(t, 25.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, "percent", frame.Fields[1].Config.Unit)
+
+	_, err = NewConvertFieldsFrameProcessor(ConvertFieldsFrameProcessorConfig{Fields: []FieldConversion{
+		{FieldName: "host", Add: 1},
+	}}).ProcessFrame(context.Background(), Vars{}, data.NewFrame("test", data.NewField("host", nil, []string{"a"})))
+	require.Error(t, err)
+}
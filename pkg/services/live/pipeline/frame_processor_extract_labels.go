@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ExtractLabelsFrameProcessor extracts labels from a string field with labels, like the labels
+// column of frames converted from Influx line protocol, into separate string fields.
+type ExtractLabelsFrameProcessor struct {
+	config ExtractLabelsFrameProcessorConfig
+}
+
+func NewExtractLabelsFrameProcessor(config ExtractLabelsFrameProcessorConfig) *ExtractLabelsFrameProcessor {
+	return &ExtractLabelsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeExtractLabels = "extractLabels"
+
+func (p *ExtractLabelsFrameProcessor) Type() string {
+	return FrameProcessorTypeExtractLabels
+}
+
+func (p *ExtractLabelsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	fieldName := p.config.FieldName
+	if fieldName == "" {
+		fieldName = "labels"
+	}
+	index := -1
+	for i, f := range frame.Fields {
+		if f.Name == fieldName {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return frame, nil
+	}
+	labelsField := frame.Fields[index]
+	if labelsField.Type() != data.FieldTypeString && labelsField.Type() != data.FieldTypeNullableString {
+		return nil, fmt.Errorf("labels field %s is not a string field", fieldName)
+	}
+
+	rows := make([]data.Labels, labelsField.Len())
+	names := p.config.LabelNames
+	collectNames := len(names) == 0
+	seen := map[string]struct{}{}
+	for row := range rows {
+		v, ok := labelsField.ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		labels, err := data.LabelsFromString(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels in field %s: %w", fieldName, err)
+		}
+		rows[row] = labels
+		if collectNames {
+			for name := range labels {
+				if _, ok := seen[name]; !ok {
+					seen[name] = struct{}{}
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	if collectNames {
+		sort.Strings(names)
+	}
+
+	labelFields := make([]*data.Field, 0, len(names))
+	for _, name := range names {
+		values := make([]*string, len(rows))
+		for row, labels := range rows {
+			if v, ok := labels[name]; ok {
+				values[row] = &v
+			}
+		}
+		labelFields = append(labelFields, data.NewField(name, nil, values))
+	}
+
+	// Labels replace the labels field, or follow it if it is kept.
+	fields := make([]*data.Field, 0, len(frame.Fields)+len(labelFields))
+	fields = append(fields, frame.Fields[:index]...)
+	if p.config.KeepField {
+		fields = append(fields, labelsField)
+	}
+	fields = append(fields, labelFields...)
+	fields = append(fields, frame.Fields[index+1:]...)
+	frame.Fields = fields
+	return frame, nil
+}
@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractLabelsFrameProcessor(t *testing.T) {
+	newFrame := func() *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("labels", nil, []string{"host=a, region=eu", "host=b"}),
+			data.NewField("value", nil, []float64{1, 2}),
+		)
+	}
+
+	frame, err := NewExtractLabelsFrameProcessor(ExtractLabelsFrameProcessorConfig{}).ProcessFrame(context.Background(), Vars{}, newFrame())
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "host", frame.Fields[0].Name)
+	require.Equal(t, "a", *frame.Fields[0].At(0).(*string))
+	require.Equal(t, "region", frame.Fields[1].Name)
+	require.Equal(t, "eu", *frame.Fields[1].At(0).(*string))
+	require.Nil(t, frame.Fields[1].At(1))
+	require.Equal(t, "value", frame.Fields[2].Name)
+
+	frame, err = NewExtractLabelsFrameProcessor(ExtractLabelsFrameProcessorConfig{LabelNames: []string{"host"}, KeepField: true}).ProcessFrame(context.Background(), Vars{}, newFrame())
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "labels", frame.Fields[0].Name)
+	require.Equal(t, "host", frame.Fields[1].Name)
+	require.Equal(t, "b", *frame.Fields[1].At(1).(*string))
+}
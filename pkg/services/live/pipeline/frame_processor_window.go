@@ -0,0 +1,290 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// WindowReducer is a function to aggregate the values of a field in a window.
+type WindowReducer string
+
+const (
+	WindowReducerAvg   WindowReducer = "avg"
+	WindowReducerMin   WindowReducer = "min"
+	WindowReducerMax   WindowReducer = "max"
+	WindowReducerLast  WindowReducer = "last"
+	WindowReducerCount WindowReducer = "count"
+)
+
+// WindowFrameProcessor aggregates the values of the numeric fields of frames in time windows, so
+// high frequency data can be sent to subscribers at a lower rate. Windows are tumbling by default,
+// and sliding when the slide is shorter than the interval. A window is output once a frame with a
+// time after the end of the window was processed, so until then the processor stops the processing
+// of frames. Rows with the same values of the string fields (for example the labels column) are
+// aggregated separately.
+type WindowFrameProcessor struct {
+	config WindowFrameProcessorConfig
+
+	mu     sync.Mutex
+	states map[string]*windowState
+}
+
+func NewWindowFrameProcessor(config WindowFrameProcessorConfig) *WindowFrameProcessor {
+	return &WindowFrameProcessor{config: config, states: map[string]*windowState{}}
+}
+
+const FrameProcessorTypeWindow = "window"
+
+func (p *WindowFrameProcessor) Type() string {
+	return FrameProcessorTypeWindow
+}
+
+func (p *WindowFrameProcessor) validate() error {
+	if p.config.IntervalMilliseconds <= 0 {
+		return fmt.Errorf("window interval must be positive")
+	}
+	if p.config.SlideMilliseconds < 0 || p.config.SlideMilliseconds > p.config.IntervalMilliseconds {
+		return fmt.Errorf("window slide must be between 0 and the interval")
+	}
+	switch p.config.Reducer {
+	case WindowReducerAvg, WindowReducerMin, WindowReducerMax, WindowReducerLast, WindowReducerCount:
+	default:
+		return fmt.Errorf("unknown window reducer: %s", p.config.Reducer)
+	}
+	return nil
+}
+
+// windowState holds the open windows of a channel.
+type windowState struct {
+	// schema is the names of the key and value fields of the frames.
+	schema string
+	// keyFields and valueFields are the names of the string and numeric fields.
+	keyFields   []string
+	valueFields []string
+	// windows are the open windows by start time in milliseconds.
+	windows map[int64]*window
+	// closedStart is the start of the latest output window, rows are not added to it or earlier windows.
+	closedStart int64
+	// maxTime is the latest time of the processed rows.
+	maxTime int64
+}
+
+type window struct {
+	// keys are the values of the key fields in the order of appearance.
+	keys   []string
+	groups map[string]*windowGroup
+}
+
+type windowGroup struct {
+	keyValues []*string
+	values    []windowAccumulator
+}
+
+type windowAccumulator struct {
+	count int
+	sum   float64
+	min   float64
+	max   float64
+	last  float64
+}
+
+func (a *windowAccumulator) add(v float64) {
+	if a.count == 0 || v < a.min {
+		a.min = v
+	}
+	if a.count == 0 || v > a.max {
+		a.max = v
+	}
+	a.count++
+	a.sum += v
+	a.last = v
+}
+
+func (a *windowAccumulator) result(reducer WindowReducer) *float64 {
+	if a.count == 0 && reducer != WindowReducerCount {
+		return nil
+	}
+	var v float64
+	switch reducer {
+	case WindowReducerAvg:
+		v = a.sum / float64(a.count)
+	case WindowReducerMin:
+		v = a.min
+	case WindowReducerMax:
+		v = a.max
+	case WindowReducerLast:
+		v = a.last
+	case WindowReducerCount:
+		v = float64(a.count)
+	}
+	return &v
+}
+
+func (p *WindowFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndex := -1
+	var keyIndexes, valueIndexes []int
+	for i, f := range frame.Fields {
+		switch {
+		case f.Type().Time() && timeIndex < 0 && (p.config.TimeField == "" || p.config.TimeField == f.Name):
+			timeIndex = i
+		case f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString:
+			keyIndexes = append(keyIndexes, i)
+		case f.Type().Numeric() && p.aggregated(f.Name):
+			valueIndexes = append(valueIndexes, i)
+		}
+	}
+	if timeIndex < 0 {
+		return nil, fmt.Errorf("no time field in frame")
+	}
+
+	var schema strings.Builder
+	for _, i := range append(append([]int{}, keyIndexes...), valueIndexes...) {
+		schema.WriteString(frame.Fields[i].Name)
+		schema.WriteByte(0)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stateKey := fmt.Sprintf("%d/%s", vars.OrgID, vars.Channel)
+	state, ok := p.states[stateKey]
+	if !ok || state.schema != schema.String() {
+		// Start over when the fields change, the open windows can't be merged with the new rows.
+		state = &windowState{schema: schema.String(), windows: map[int64]*window{}, closedStart: math.MinInt64, maxTime: math.MinInt64}
+		for _, i := range keyIndexes {
+			state.keyFields = append(state.keyFields, frame.Fields[i].Name)
+		}
+		for _, i := range valueIndexes {
+			state.valueFields = append(state.valueFields, frame.Fields[i].Name)
+		}
+		p.states[stateKey] = state
+	}
+
+	interval := p.config.IntervalMilliseconds
+	slide := p.config.SlideMilliseconds
+	if slide == 0 {
+		slide = interval
+	}
+
+	timeField := frame.Fields[timeIndex]
+	for row := 0; row < frame.Rows(); row++ {
+		t, ok := timeAt(timeField, row)
+		if !ok {
+			continue
+		}
+		if t > state.maxTime {
+			state.maxTime = t
+		}
+		keyValues := make([]*string, len(keyIndexes))
+		var key strings.Builder
+		for i, index := range keyIndexes {
+			if s, ok := frame.Fields[index].ConcreteAt(row); ok {
+				str := s.(string)
+				keyValues[i] = &str
+				key.WriteString(str)
+			}
+			key.WriteByte(0)
+		}
+		// The row belongs to all windows which start in the interval before its time.
+		for start := floorDiv(t, slide) * slide; start > t-interval && start > state.closedStart; start -= slide {
+			w, ok := state.windows[start]
+			if !ok {
+				w = &window{groups: map[string]*windowGroup{}}
+				state.windows[start] = w
+			}
+			g, ok := w.groups[key.String()]
+			if !ok {
+				g = &windowGroup{keyValues: keyValues, values: make([]windowAccumulator, len(valueIndexes))}
+				w.groups[key.String()] = g
+				w.keys = append(w.keys, key.String())
+			}
+			for i, index := range valueIndexes {
+				v, err := frame.Fields[index].NullableFloatAt(row)
+				if err != nil {
+					return nil, err
+				}
+				if v != nil && !math.IsNaN(*v) {
+					g.values[i].add(*v)
+				}
+			}
+		}
+	}
+
+	// Output the windows which end before the latest time.
+	var closed []int64
+	for start := range state.windows {
+		if start+interval <= state.maxTime {
+			closed = append(closed, start)
+		}
+	}
+	if len(closed) == 0 {
+		return nil, nil
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i] < closed[j] })
+
+	fields := make([]*data.Field, 0, 1+len(state.keyFields)+len(state.valueFields))
+	fields = append(fields, data.NewField(timeField.Name, nil, []time.Time{}))
+	for _, name := range state.keyFields {
+		fields = append(fields, data.NewField(name, nil, []*string{}))
+	}
+	for _, name := range state.valueFields {
+		fields = append(fields, data.NewField(name, nil, []*float64{}))
+	}
+	for _, start := range closed {
+		w := state.windows[start]
+		for _, key := range w.keys {
+			g := w.groups[key]
+			fields[0].Append(time.UnixMilli(start).UTC())
+			for i, v := range g.keyValues {
+				fields[1+i].Append(v)
+			}
+			for i := range g.values {
+				fields[1+len(g.keyValues)+i].Append(g.values[i].result(p.config.Reducer))
+			}
+		}
+		delete(state.windows, start)
+		state.closedStart = start
+	}
+	return data.NewFrame(frame.Name, fields...), nil
+}
+
+func (p *WindowFrameProcessor) aggregated(name string) bool {
+	if len(p.config.FieldNames) == 0 {
+		return true
+	}
+	for _, n := range p.config.FieldNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// timeAt returns the time of a time field at the row in milliseconds.
+func timeAt(f *data.Field, row int) (int64, bool) {
+	v, ok := f.ConcreteAt(row)
+	if !ok {
+		return 0, false
+	}
+	t, ok := v.(time.Time)
+	if !ok {
+		return 0, false
+	}
+	return t.UnixMilli(), true
+}
+
+// floorDiv divides rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
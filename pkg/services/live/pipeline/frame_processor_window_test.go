@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func windowTestFrame(start time.Time, offsets []time.Duration, labels []string, values []float64) *data.Frame {
+	times := make([]time.Time, len(offsets))
+	for i, offset := range offsets {
+		times[i] = start.Add(offset)
+	}
+	return data.NewFrame("test",
+		data.NewField("labels", nil, labels),
+		data.NewField("time", nil, times),
+		data.NewField("value", nil, values),
+	)
+}
+
+func TestWindowFrameProcessor_Tumbling(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 4, 0, 0, time.UTC)
+	p := NewWindowFrameProcessor(WindowFrameProcessorConfig{IntervalMilliseconds: 1000, Reducer: WindowReducerAvg})
+	require.NoError(t, p.validate())
+	vars := Vars{OrgID: 1, Channel: "stream/test/xxx"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 500 * time.Millisecond},
+		[]string{"host=a", "host=b", "host=a", "host=b"},
+		[]float64{1, 10, 3, 20}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{1100 * time.Millisecond},
+		[]string{"host=a"},
+		[]float64{5}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, "time", frame.Fields[0].Name)
+	require.Equal(t, start, frame.Fields[0].At(0))
+	require.Equal(t, "host=a", *frame.Fields[1].At(0).(*string))
+	require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, "host=b", *frame.Fields[1].At(1).(*string))
+	require.Equal(t, 15.0, *frame.Fields[2].At(1).(*float64))
+
+	// Late rows of closed windows are dropped.
+	frame, err = p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{300 * time.Millisecond, 2 * time.Second},
+		[]string{"host=a", "host=a"},
+		[]float64{100, 7}))
+	require.NoError(t, err)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 5.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestWindowFrameProcessor_Sliding(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 4, 0, 0, time.UTC)
+	p := NewWindowFrameProcessor(WindowFrameProcessorConfig{IntervalMilliseconds: 2000, SlideMilliseconds: 1000, Reducer: WindowReducerCount})
+	vars := Vars{OrgID: 1, Channel: "stream/test/xxx"}
+
+	frame, err := p.ProcessFrame(context.Background(), vars, windowTestFrame(start,
+		[]time.Duration{0, 500 * time.Millisecond, 1500 * time.Millisecond, 3 * time.Second},
+		[]string{"", "", "", ""},
+		[]float64{1, 2, 3, 4}))
+	require.NoError(t, err)
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, start.Add(-time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 2.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, start, frame.Fields[0].At(1))
+	require.Equal(t, 3.0, *frame.Fields[2].At(1).(*float64))
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(2))
+	require.Equal(t, 1.0, *frame.Fields[2].At(2).(*float64))
+}
+
+func TestWindowFrameProcessor_Validate(t *testing.T) {
+	require.Error(t, NewWindowFrameProcessor(WindowFrameProcessorConfig{Reducer: WindowReducerAvg}).validate())
+	require.Error(t, NewWindowFrameProcessor(WindowFrameProcessorConfig{IntervalMilliseconds: 1000, SlideMilliseconds: 2000, Reducer: WindowReducerAvg}).validate())
+	require.Error(t, NewWindowFrameProcessor(WindowFrameProcessorConfig{IntervalMilliseconds: 1000, Reducer: "median"}).validate())
+}
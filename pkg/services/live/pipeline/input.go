@@ -0,0 +1,22 @@
+package pipeline
+
+import (
+	"context"
+)
+
+// InputMessage is a message consumed from a message broker.
+type InputMessage struct {
+	Topic   string
+	Payload []byte
+}
+
+// InputMessageHandler is called for each consumed message.
+type InputMessageHandler func(ctx context.Context, msg InputMessage)
+
+// Input consumes messages from a message broker.
+type Input interface {
+	Type() string
+	// Consume connects to the broker and calls handle for each message until the context is done
+	// or the connection is lost. It returns nil when the context is done.
+	Consume(ctx context.Context, handle InputMessageHandler) error
+}
@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// KafkaInput consumes a topic of a Kafka cluster as a member of a consumer group, so the offsets
+// of processed messages are committed and consuming continues after them on reconnect.
+type KafkaInput struct {
+	config KafkaInputConfig
+}
+
+const InputTypeKafka = "kafka"
+
+func NewKafkaInput(uid string, config KafkaInputConfig) (*KafkaInput, error) {
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("missing kafka brokers")
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("missing kafka topic")
+	}
+	if config.GroupID == "" {
+		config.GroupID = "grafana-live-" + uid
+	}
+	return &KafkaInput{config: config}, nil
+}
+
+func (i *KafkaInput) Type() string {
+	return InputTypeKafka
+}
+
+func (i *KafkaInput) Consume(ctx context.Context, handle InputMessageHandler) error {
+	dialer := &kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+	}
+	if i.config.TLS {
+		dialer.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if i.config.BasicAuth != nil {
+		dialer.SASLMechanism = plain.Mechanism{
+			Username: i.config.BasicAuth.User,
+			Password: i.config.BasicAuth.Password,
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: i.config.Brokers,
+		Topic:   i.config.Topic,
+		GroupID: i.config.GroupID,
+		Dialer:  dialer,
+		MaxWait: time.Second,
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			logger.Debug("Kafka input error", "topic", i.config.Topic, "error", fmt.Sprintf(msg, args...))
+		}),
+	})
+	defer func() { _ = reader.Close() }()
+
+	for {
+		m, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			return fmt.Errorf("error fetching kafka message: %w", err)
+		}
+		handle(ctx, InputMessage{Topic: m.Topic, Payload: m.Value})
+		if err := reader.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("error committing kafka message: %w", err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const mqttConnectTimeout = 10 * time.Second
+
+// MQTTInput subscribes to a topic of a MQTT broker. Reconnects are left to InputRunner, so
+// the automatic reconnect of the client is disabled.
+type MQTTInput struct {
+	config MQTTInputConfig
+}
+
+const InputTypeMQTT = "mqtt"
+
+func NewMQTTInput(uid string, config MQTTInputConfig) (*MQTTInput, error) {
+	if config.Broker == "" {
+		return nil, fmt.Errorf("missing mqtt broker")
+	}
+	if config.Topic == "" {
+		return nil, fmt.Errorf("missing mqtt topic")
+	}
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("invalid mqtt qos: %d", config.QoS)
+	}
+	if config.ClientID == "" {
+		config.ClientID = "grafana-live-" + uid
+	}
+	return &MQTTInput{config: config}, nil
+}
+
+func (i *MQTTInput) Type() string {
+	return InputTypeMQTT
+}
+
+func (i *MQTTInput) Consume(ctx context.Context, handle InputMessageHandler) error {
+	connectionLost := make(chan error, 1)
+	opts := mqtt.NewClientOptions().
+		AddBroker(i.config.Broker).
+		SetClientID(i.config.ClientID).
+		SetAutoReconnect(false).
+		SetConnectTimeout(mqttConnectTimeout).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			select {
+			case connectionLost <- err:
+			default:
+			}
+		})
+	if i.config.BasicAuth != nil {
+		opts.SetUsername(i.config.BasicAuth.User)
+		opts.SetPassword(i.config.BasicAuth.Password)
+	}
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("error connecting to mqtt broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	token := client.Subscribe(i.config.Topic, i.config.QoS, func(_ mqtt.Client, m mqtt.Message) {
+		handle(ctx, InputMessage{Topic: m.Topic(), Payload: m.Payload()})
+	})
+	if err := waitMQTTToken(ctx, token); err != nil {
+		return fmt.Errorf("error subscribing to mqtt topic: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-connectionLost:
+		return fmt.Errorf("mqtt connection lost: %w", err)
+	}
+}
+
+func waitMQTTToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-token.Done():
+		return token.Error()
+	}
+}
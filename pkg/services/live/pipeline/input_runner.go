@@ -0,0 +1,229 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	// inputLockLease is how long an instance consumes an input before it renews the lock.
+	// Since locks can't be extended, a lock is released and acquired again after the lease, and
+	// a lock older than two leases is considered stale so another instance takes over.
+	inputLockLease = time.Minute
+	// inputLockRetryInterval is how often instances which don't consume an input try to acquire
+	// its lock.
+	inputLockRetryInterval = 15 * time.Second
+	// inputLockHandover is how long an instance waits after acquiring the lock of an input before
+	// it consumes the input. The lock is released between two leases and the previous holder keeps
+	// consuming until it fails to acquire the lock again, which it does right after the release, so
+	// waiting lets it stop before another instance starts.
+	inputLockHandover = 5 * time.Second
+)
+
+// InputProcessor processes the payloads of input messages in channels.
+type InputProcessor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// InputLocker makes sure that a single Grafana instance consumes an input in HA setups,
+// implemented by serverlock.ServerLockService.
+type InputLocker interface {
+	LockExecuteAndRelease(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+// InputRunner consumes the messages of the inputs and processes them in the channels of the
+// inputs. It reconnects inputs with backoff when the connection fails, and when a locker is set
+// only the instance holding the lock of an input consumes it. An instance which stops renewing the
+// lock without stopping, e.g. when blocked for longer than two leases, may still consume an input
+// taken over by another instance, so messages can be processed twice during a handover.
+type InputRunner struct {
+	storage        InputStorage
+	secretsService secrets.Service
+	processor      InputProcessor
+	locker         InputLocker
+
+	backoffConfig     backoff.Config
+	lease             time.Duration
+	lockRetryInterval time.Duration
+	lockHandover      time.Duration
+	newInput          func(ctx context.Context, config InputConfig) (Input, error)
+}
+
+// NewInputRunner creates a new InputRunner. The locker may be nil, then all inputs are consumed
+// without coordination with other instances.
+func NewInputRunner(storage InputStorage, secretsService secrets.Service, processor InputProcessor, locker InputLocker) *InputRunner {
+	r := &InputRunner{
+		storage:        storage,
+		secretsService: secretsService,
+		processor:      processor,
+		locker:         locker,
+		backoffConfig: backoff.Config{
+			MinBackoff: time.Second,
+			MaxBackoff: 30 * time.Second,
+		},
+		lease:             inputLockLease,
+		lockRetryInterval: inputLockRetryInterval,
+		lockHandover:      inputLockHandover,
+	}
+	r.newInput = r.buildInput
+	return r
+}
+
+// Run consumes the inputs until the context is done. Inputs are loaded once, so changes to
+// the inputs apply after a restart.
+func (r *InputRunner) Run(ctx context.Context) error {
+	configs, err := r.storage.ListInputConfigs(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing inputs: %w", err)
+	}
+	var wg sync.WaitGroup
+	for _, config := range configs {
+		if ok, reason := config.Valid(); !ok {
+			logger.Error("Invalid live input", "uid", config.UID, "reason", reason)
+			continue
+		}
+		input, err := r.newInput(ctx, config)
+		if err != nil {
+			logger.Error("Error creating live input", "uid", config.UID, "error", err)
+			continue
+		}
+		wg.Add(1)
+		go func(config InputConfig) {
+			defer wg.Done()
+			r.runInput(ctx, config, input)
+		}(config)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (r *InputRunner) buildInput(ctx context.Context, config InputConfig) (Input, error) {
+	missingConfiguration := fmt.Errorf("missing configuration for %s", config.Type)
+	switch config.Type {
+	case InputTypeMQTT:
+		if config.MQTTInputConfig == nil {
+			return nil, missingConfiguration
+		}
+		mqttConfig := *config.MQTTInputConfig
+		basicAuth, err := decryptBasicAuth(ctx, r.secretsService, mqttConfig.BasicAuth, config.SecureSettings)
+		if err != nil {
+			return nil, err
+		}
+		mqttConfig.BasicAuth = basicAuth
+		return NewMQTTInput(config.UID, mqttConfig)
+	case InputTypeKafka:
+		if config.KafkaInputConfig == nil {
+			return nil, missingConfiguration
+		}
+		kafkaConfig := *config.KafkaInputConfig
+		basicAuth, err := decryptBasicAuth(ctx, r.secretsService, kafkaConfig.BasicAuth, config.SecureSettings)
+		if err != nil {
+			return nil, err
+		}
+		kafkaConfig.BasicAuth = basicAuth
+		return NewKafkaInput(config.UID, kafkaConfig)
+	default:
+		return nil, fmt.Errorf("unknown input type: %s", config.Type)
+	}
+}
+
+func (r *InputRunner) runInput(ctx context.Context, config InputConfig, input Input) {
+	if r.locker == nil {
+		r.consume(ctx, config, input)
+		return
+	}
+
+	var stop context.CancelFunc
+	var done chan struct{}
+	stopConsuming := func() {
+		if stop != nil {
+			stop()
+			<-done
+			stop = nil
+		}
+	}
+	defer stopConsuming()
+
+	lockName := fmt.Sprintf("live input %d/%s", config.OrgId, config.UID)
+	for ctx.Err() == nil {
+		err := r.locker.LockExecuteAndRelease(ctx, lockName, 2*r.lease, func(context.Context) {
+			if stop == nil {
+				// Let the previous holder of the lock stop consuming first.
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(r.lockHandover):
+				}
+				logger.Info("Start consuming live input", "uid", config.UID, "type", input.Type())
+				var consumeCtx context.Context
+				consumeCtx, stop = context.WithCancel(ctx)
+				done = make(chan struct{})
+				go func() {
+					defer close(done)
+					r.consume(consumeCtx, config, input)
+				}()
+			}
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.lease):
+			}
+		})
+		if err == nil {
+			// Acquire the lock again right away, other instances only retry after an interval.
+			continue
+		}
+		if stop != nil {
+			logger.Info("Stop consuming live input, lock not acquired", "uid", config.UID, "error", err)
+			stopConsuming()
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(r.lockRetryInterval):
+		}
+	}
+}
+
+// consume consumes an input until the context is done, reconnecting with backoff.
+func (r *InputRunner) consume(ctx context.Context, config InputConfig, input Input) {
+	handle := func(ctx context.Context, msg InputMessage) {
+		r.handleMessage(ctx, config, msg)
+	}
+	b := backoff.New(ctx, r.backoffConfig)
+	for b.Ongoing() {
+		start := time.Now()
+		err := input.Consume(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > r.backoffConfig.MaxBackoff {
+			// The input was connected for a while, so reconnect fast.
+			b.Reset()
+		}
+		logger.Warn("Live input disconnected", "uid", config.UID, "type", input.Type(), "error", err, "retries", b.NumRetries())
+		b.Wait()
+	}
+}
+
+func (r *InputRunner) handleMessage(ctx context.Context, config InputConfig, msg InputMessage) {
+	channel := strings.ReplaceAll(config.Channel, "${topic}", msg.Topic)
+	if _, err := live.ParseChannel(channel); err != nil {
+		logger.Warn("Invalid channel of live input message", "uid", config.UID, "channel", channel, "error", err)
+		return
+	}
+	ok, err := r.processor.ProcessInput(ctx, config.OrgId, channel, msg.Payload)
+	if err != nil {
+		logger.Error("Error processing live input message", "uid", config.UID, "channel", channel, "error", err)
+		return
+	}
+	if !ok {
+		logger.Debug("No converter for live input message", "uid", config.UID, "channel", channel)
+	}
+}
@@ -0,0 +1,296 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/backoff"
+	"github.com/stretchr/testify/require"
+)
+
+type testInputStorage struct {
+	configs []InputConfig
+}
+
+func (s *testInputStorage) ListInputConfigs(_ context.Context) ([]InputConfig, error) {
+	return s.configs, nil
+}
+
+type testInputProcessor struct {
+	mu       sync.Mutex
+	channels []string
+	payloads []string
+}
+
+func (p *testInputProcessor) ProcessInput(_ context.Context, _ int64, channelID string, body []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.channels = append(p.channels, channelID)
+	p.payloads = append(p.payloads, string(body))
+	return true, nil
+}
+
+func (p *testInputProcessor) processed() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string{}, p.channels...)
+}
+
+// testInput fails to connect the first time, then consumes its messages until the context is done.
+type testInput struct {
+	mu       sync.Mutex
+	consumes int
+	messages []InputMessage
+}
+
+func (i *testInput) Type() string {
+	return "test"
+}
+
+func (i *testInput) Consume(ctx context.Context, handle InputMessageHandler) error {
+	i.mu.Lock()
+	i.consumes++
+	consumes := i.consumes
+	i.mu.Unlock()
+	if consumes == 1 {
+		return errors.New("connection refused")
+	}
+	for _, msg := range i.messages {
+		handle(ctx, msg)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+type testInputLocker struct {
+	mu     sync.Mutex
+	locked bool
+	calls  int
+}
+
+func (l *testInputLocker) setLocked(locked bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.locked = locked
+}
+
+func (l *testInputLocker) LockExecuteAndRelease(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	l.mu.Lock()
+	l.calls++
+	locked := l.locked
+	l.mu.Unlock()
+	if locked {
+		return errors.New("locked by another instance")
+	}
+	fn(ctx)
+	return nil
+}
+
+// testSharedInputLock is a lock shared by instances, which is handed over to another instance after each lease.
+// Failing to acquire it takes a while, so the next holder acquires it before the previous one stops.
+type testSharedInputLock struct {
+	mu     sync.Mutex
+	holder string
+	last   string
+}
+
+type testInstanceInputLocker struct {
+	lock     *testSharedInputLock
+	instance string
+}
+
+func (l *testInstanceInputLocker) LockExecuteAndRelease(ctx context.Context, _ string, _ time.Duration, fn func(ctx context.Context)) error {
+	l.lock.mu.Lock()
+	if l.lock.holder != "" || l.lock.last == l.instance {
+		l.lock.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		return errors.New("locked by another instance")
+	}
+	l.lock.holder = l.instance
+	l.lock.mu.Unlock()
+
+	fn(ctx)
+
+	l.lock.mu.Lock()
+	l.lock.holder = ""
+	l.lock.last = l.instance
+	l.lock.mu.Unlock()
+	return nil
+}
+
+// exclusiveInput counts the instances consuming it at the same time.
+type exclusiveInput struct {
+	mu        sync.Mutex
+	consumes  int
+	active    int
+	maxActive int
+}
+
+func (i *exclusiveInput) Type() string {
+	return "test"
+}
+
+func (i *exclusiveInput) Consume(ctx context.Context, _ InputMessageHandler) error {
+	i.mu.Lock()
+	i.consumes++
+	i.active++
+	if i.active > i.maxActive {
+		i.maxActive = i.active
+	}
+	i.mu.Unlock()
+	<-ctx.Done()
+	i.mu.Lock()
+	i.active--
+	i.mu.Unlock()
+	return nil
+}
+
+func newTestInputRunner(input Input, processor InputProcessor, locker InputLocker) *InputRunner {
+	r := NewInputRunner(&testInputStorage{configs: []InputConfig{{
+		OrgId:   1,
+		UID:     "devices",
+		Channel: "stream/devices/${topic}",
+		Type:    "test",
+	}}}, nil, processor, locker)
+	r.backoffConfig = backoff.Config{MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	r.lease = 10 * time.Millisecond
+	r.lockRetryInterval = time.Millisecond
+	r.lockHandover = time.Millisecond
+	r.newInput = func(_ context.Context, _ InputConfig) (Input, error) {
+		return input, nil
+	}
+	return r
+}
+
+func runInputRunner(t *testing.T, r *InputRunner) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NoError(t, r.Run(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestInputRunner_Reconnect(t *testing.T) {
+	input := &testInput{messages: []InputMessage{
+		{Topic: "sensor1", Payload: []byte(`{"value": 1}`)},
+		{Topic: "sensor2", Payload: []byte(`{"value": 2}`)},
+		{Topic: "invalid channel", Payload: []byte(`{"value": 3}`)},
+	}}
+	processor := &testInputProcessor{}
+	runInputRunner(t, newTestInputRunner(input, processor, nil))
+
+	require.Eventually(t, func() bool {
+		return len(processor.processed()) == 2
+	}, time.Second, time.Millisecond)
+	require.Equal(t, []string{"stream/devices/sensor1", "stream/devices/sensor2"}, processor.processed())
+	require.Equal(t, []string{`{"value": 1}`, `{"value": 2}`}, processor.payloads)
+}
+
+func TestInputRunner_Lock(t *testing.T) {
+	input := &testInput{messages: []InputMessage{{Topic: "sensor1", Payload: []byte(`{}`)}}}
+	processor := &testInputProcessor{}
+	locker := &testInputLocker{locked: true}
+	runInputRunner(t, newTestInputRunner(input, processor, locker))
+
+	require.Eventually(t, func() bool {
+		locker.mu.Lock()
+		defer locker.mu.Unlock()
+		return locker.calls > 3
+	}, time.Second, time.Millisecond)
+	require.Empty(t, processor.processed())
+
+	locker.setLocked(false)
+	require.Eventually(t, func() bool {
+		return len(processor.processed()) == 1
+	}, time.Second, time.Millisecond)
+
+	// The input keeps being consumed while the lock is renewed.
+	time.Sleep(50 * time.Millisecond)
+	input.mu.Lock()
+	require.Equal(t, 2, input.consumes)
+	input.mu.Unlock()
+}
+
+func TestInputRunner_LockHandover(t *testing.T) {
+	input := &exclusiveInput{}
+	lock := &testSharedInputLock{}
+	for _, instance := range []string{"a", "b"} {
+		r := newTestInputRunner(input, &testInputProcessor{}, &testInstanceInputLocker{lock: lock, instance: instance})
+		r.lockHandover = 20 * time.Millisecond
+		runInputRunner(t, r)
+	}
+
+	require.Eventually(t, func() bool {
+		input.mu.Lock()
+		defer input.mu.Unlock()
+		return input.consumes >= 4
+	}, 5*time.Second, time.Millisecond)
+	input.mu.Lock()
+	defer input.mu.Unlock()
+	require.Equal(t, 1, input.maxActive)
+}
+
+func TestInputRunner_BuildInput(t *testing.T) {
+	r := NewInputRunner(&testInputStorage{}, nil, &testInputProcessor{}, nil)
+
+	_, err := r.buildInput(context.Background(), InputConfig{UID: "a", Type: "amqp"})
+	require.EqualError(t, err, "unknown input type: amqp")
+	_, err = r.buildInput(context.Background(), InputConfig{UID: "a", Type: InputTypeMQTT})
+	require.EqualError(t, err, "missing configuration for mqtt")
+	_, err = r.buildInput(context.Background(), InputConfig{UID: "a", Type: InputTypeMQTT, MQTTInputConfig: &MQTTInputConfig{Broker: "tcp://localhost:1883"}})
+	require.EqualError(t, err, "missing mqtt topic")
+	_, err = r.buildInput(context.Background(), InputConfig{UID: "a", Type: InputTypeKafka, KafkaInputConfig: &KafkaInputConfig{Topic: "devices"}})
+	require.EqualError(t, err, "missing kafka brokers")
+
+	input, err := r.buildInput(context.Background(), InputConfig{UID: "a", Type: InputTypeMQTT, MQTTInputConfig: &MQTTInputConfig{
+		Broker:    "tcp://localhost:1883",
+		Topic:     "devices/#",
+		BasicAuth: &BasicAuth{User: "grafana", Password: "secret"},
+	}})
+	require.NoError(t, err)
+	mqttInput := input.(*MQTTInput)
+	require.Equal(t, "grafana-live-a", mqttInput.config.ClientID)
+	require.Equal(t, "secret", mqttInput.config.BasicAuth.Password)
+
+	input, err = r.buildInput(context.Background(), InputConfig{UID: "a", Type: InputTypeKafka, KafkaInputConfig: &KafkaInputConfig{
+		Brokers: []string{"localhost:9092"},
+		Topic:   "devices",
+	}})
+	require.NoError(t, err)
+	require.Equal(t, "grafana-live-a", input.(*KafkaInput).config.GroupID)
+}
+
+func TestFileStorage_ListInputConfigs(t *testing.T) {
+	dataPath := t.TempDir()
+	storage := &FileStorage{DataPath: dataPath}
+
+	configs, err := storage.ListInputConfigs(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, configs)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dataPath, "pipeline"), 0750))
+	err = os.WriteFile(filepath.Join(dataPath, "pipeline", "live-inputs.json"), []byte(`{"inputs": [
+		{"uid": "a", "channel": "stream/devices/a", "type": "mqtt", "mqtt": {"broker": "tcp://localhost:1883", "topic": "a"}},
+		{"orgId": 2, "uid": "b", "channel": "stream/devices/b", "type": "kafka", "kafka": {"brokers": ["localhost:9092"], "topic": "b"}}
+	]}`), 0600)
+	require.NoError(t, err)
+
+	configs, err = storage.ListInputConfigs(context.Background())
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.Equal(t, int64(1), configs[0].OrgId)
+	require.Equal(t, "a", configs[0].MQTTInputConfig.Topic)
+	require.Equal(t, int64(2), configs[1].OrgId)
+	require.Equal(t, []string{"localhost:9092"}, configs[1].KafkaInputConfig.Brokers)
+}
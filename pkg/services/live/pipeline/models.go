@@ -125,6 +125,34 @@ type WriteConfigs struct {
 	Configs []WriteConfig `json:"writeConfigs"`
 }
 
+// InputConfig describes an input which consumes messages from a message broker and processes
+// them in a channel, so the converter of the channel rule decodes the message payloads.
+type InputConfig struct {
+	OrgId int64  `json:"orgId,omitempty"`
+	UID   string `json:"uid"`
+	// Channel to process the messages in. ${topic} is replaced with the topic of a message, so
+	// messages of different topics can be routed into different channels.
+	Channel          string            `json:"channel"`
+	Type             string            `json:"type"`
+	MQTTInputConfig  *MQTTInputConfig  `json:"mqtt,omitempty"`
+	KafkaInputConfig *KafkaInputConfig `json:"kafka,omitempty"`
+	SecureSettings   map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+func (c InputConfig) Valid() (bool, string) {
+	if c.UID == "" {
+		return false, "uid required"
+	}
+	if c.Channel == "" {
+		return false, "channel required"
+	}
+	return true, ""
+}
+
+type InputConfigs struct {
+	Inputs []InputConfig `json:"inputs"`
+}
+
 type ChannelRules struct {
 	Rules []ChannelRule `json:"rules"`
 }
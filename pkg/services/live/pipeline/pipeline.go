@@ -235,6 +235,36 @@ func (p *Pipeline) ProcessInput(ctx context.Context, orgID int64, channelID stri
 	return ok, err
 }
 
+// ProcessFrame processes a frame which was already converted from the input of the channel, for example by a push
+// endpoint which decodes its own format. It returns false if there is no rule with frame processors or outputters
+// for the channel.
+func (p *Pipeline) ProcessFrame(ctx context.Context, orgID int64, channelID string, frame *data.Frame) (bool, error) {
+	var span trace.Span
+	if p.tracer != nil {
+		ctx, span = p.tracer.Start(ctx, "live.pipeline.process_frame_input")
+		span.SetAttributes(
+			attribute.Int64("orgId", orgID),
+			attribute.String("channel", channelID),
+		)
+		defer span.End()
+	}
+	rule, ok, err := p.ruleGetter.Get(orgID, channelID)
+	if err != nil {
+		return false, err
+	}
+	if !ok || (len(rule.FrameProcessors) == 0 && len(rule.FrameOutputters) == 0) {
+		return false, nil
+	}
+	err = p.processChannelFrames(ctx, orgID, channelID, []*ChannelFrame{{Frame: frame}}, nil)
+	if err != nil {
+		if p.tracer != nil && span != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return false, fmt.Errorf("error processing frame: %w", err)
+	}
+	return true, nil
+}
+
 func (p *Pipeline) processInput(ctx context.Context, orgID int64, channelID string, body []byte, visitedChannels map[string]struct{}) (bool, error) {
 	var span trace.Span
 	if p.tracer != nil {
@@ -405,17 +435,9 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 		Path:      ch.Path,
 	}
 
-	if len(rule.FrameProcessors) > 0 {
-		for _, proc := range rule.FrameProcessors {
-			frame, err = p.execProcessor(ctx, proc, vars, frame)
-			if err != nil {
-				logger.Error("Error processing frame", "error", err)
-				return nil, err
-			}
-			if frame == nil {
-				return nil, nil
-			}
-		}
+	frame, err = p.applyFrameProcessors(ctx, rule, vars, frame)
+	if err != nil || frame == nil {
+		return nil, err
 	}
 
 	if len(rule.FrameOutputters) > 0 {
@@ -434,6 +456,42 @@ func (p *Pipeline) processFrame(ctx context.Context, orgID int64, channelID stri
 	return nil, nil
 }
 
+// ApplyFrameProcessors applies the frame processors of the rule of the channel to the frame, without
+// outputting it. It returns nil if a processor dropped the frame.
+func (p *Pipeline) ApplyFrameProcessors(ctx context.Context, orgID int64, channelID string, frame *data.Frame) (*data.Frame, error) {
+	rule, ok, err := p.ruleGetter.Get(orgID, channelID)
+	if err != nil || !ok {
+		return frame, err
+	}
+	ch, err := live.ParseChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	vars := Vars{
+		OrgID:     orgID,
+		Channel:   channelID,
+		Scope:     ch.Scope,
+		Namespace: ch.Namespace,
+		Path:      ch.Path,
+	}
+	return p.applyFrameProcessors(ctx, rule, vars, frame)
+}
+
+func (p *Pipeline) applyFrameProcessors(ctx context.Context, rule *LiveChannelRule, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, proc := range rule.FrameProcessors {
+		var err error
+		frame, err = p.execProcessor(ctx, proc, vars, frame)
+		if err != nil {
+			logger.Error("Error processing frame", "error", err)
+			return nil, err
+		}
+		if frame == nil {
+			return nil, nil
+		}
+	}
+	return frame, nil
+}
+
 func (p *Pipeline) execProcessor(ctx context.Context, proc FrameProcessor, vars Vars, frame *data.Frame) (*data.Frame, error) {
 	var span trace.Span
 	if p.tracer != nil {
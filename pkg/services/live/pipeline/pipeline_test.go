@@ -142,3 +142,31 @@ func TestPipeline_Recursion(t *testing.T) {
 	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
 	require.ErrorIs(t, err, errChannelRecursion)
 }
+
+func TestPipeline_ProcessFrame(t *testing.T) {
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+			"stream/test/yyy": {
+				Converter: &testConverter{"", data.NewFrame("test")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ok, err := p.ProcessFrame(context.Background(), 1, "stream/test/xxx", data.NewFrame("test"))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NotNil(t, outputter.frame)
+
+	ok, err = p.ProcessFrame(context.Background(), 1, "stream/test/yyy", data.NewFrame("test"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = p.ProcessFrame(context.Background(), 1, "stream/test/zzz", data.NewFrame("test"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -79,6 +79,34 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindow,
+		Description: "aggregate numeric fields in tumbling or sliding time windows",
+		Example: WindowFrameProcessorConfig{
+			IntervalMilliseconds: 1000,
+			Reducer:              WindowReducerAvg,
+		},
+	},
+	{
+		Type:        FrameProcessorTypeCalculate,
+		Description: "add a field calculated with a math expression",
+		Example: CalculateFrameProcessorConfig{
+			FieldName:  "temperature_f",
+			Expression: "$temperature * 1.8 + 32",
+		},
+	},
+	{
+		Type:        FrameProcessorTypeConvertFields,
+		Description: "rename fields, convert their values and set their units",
+		Example: ConvertFieldsFrameProcessorConfig{
+			Fields: []FieldConversion{{FieldName: "used", Rename: "used_percent", Unit: "percent"}},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeExtractLabels,
+		Description: "extract labels from the labels column into separate fields",
+		Example:     ExtractLabelsFrameProcessorConfig{},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -104,6 +104,30 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeWindow:
+		if config.WindowProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		proc := NewWindowFrameProcessor(*config.WindowProcessorConfig)
+		if err := proc.validate(); err != nil {
+			return nil, err
+		}
+		return proc, nil
+	case FrameProcessorTypeCalculate:
+		if config.CalculateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewCalculateFrameProcessor(*config.CalculateProcessorConfig)
+	case FrameProcessorTypeConvertFields:
+		if config.ConvertFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewConvertFieldsFrameProcessor(*config.ConvertFieldsProcessorConfig), nil
+	case FrameProcessorTypeExtractLabels:
+		if config.ExtractLabelsProcessorConfig == nil {
+			config.ExtractLabelsProcessorConfig = &ExtractLabelsFrameProcessorConfig{}
+		}
+		return NewExtractLabelsFrameProcessor(*config.ExtractLabelsProcessorConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -141,25 +165,29 @@ func (f *StorageRuleBuilder) extractFrameConditionChecker(config *FrameCondition
 }
 
 func (f *StorageRuleBuilder) constructBasicAuth(writeConfig WriteConfig) (*BasicAuth, error) {
-	if writeConfig.Settings.BasicAuth == nil {
+	return decryptBasicAuth(context.Background(), f.SecretsService, writeConfig.Settings.BasicAuth, writeConfig.SecureSettings)
+}
+
+// decryptBasicAuth returns basic auth with the password decrypted from the basicAuthPassword
+// secure setting, or with the plain text password if there is no secure password.
+func decryptBasicAuth(ctx context.Context, secretsService secrets.Service, basicAuth *BasicAuth, secureSettings map[string][]byte) (*BasicAuth, error) {
+	if basicAuth == nil {
 		return nil, nil
 	}
 	var password string
-	hasSecurePassword := len(writeConfig.SecureSettings["basicAuthPassword"]) > 0
+	hasSecurePassword := len(secureSettings["basicAuthPassword"]) > 0
 	if hasSecurePassword {
-		passwordBytes, err := f.SecretsService.Decrypt(context.Background(), writeConfig.SecureSettings["basicAuthPassword"])
+		passwordBytes, err := secretsService.Decrypt(ctx, secureSettings["basicAuthPassword"])
 		if err != nil {
 			return nil, fmt.Errorf("basicAuthPassword can't be decrypted: %w", err)
 		}
 		password = string(passwordBytes)
 	} else {
 		// Use plain text password (should be removed upon database integration).
-		if writeConfig.Settings.BasicAuth != nil {
-			password = writeConfig.Settings.BasicAuth.Password
-		}
+		password = basicAuth.Password
 	}
 	return &BasicAuth{
-		User:     writeConfig.Settings.BasicAuth.User,
+		User:     basicAuth.User,
 		Password: password,
 	}, nil
 }
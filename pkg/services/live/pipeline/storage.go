@@ -14,3 +14,8 @@ type Storage interface {
 	UpdateChannelRule(_ context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error)
 	DeleteChannelRule(_ context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error
 }
+
+// InputStorage describes methods to load the inputs of all organizations.
+type InputStorage interface {
+	ListInputConfigs(_ context.Context) ([]InputConfig, error)
+}
@@ -337,3 +337,33 @@ func (f *FileStorage) saveWriteConfigs(_ int64, writeConfigs WriteConfigs) error
 	}
 	return nil
 }
+
+func (f *FileStorage) inputConfigsFilePath() string {
+	return filepath.Join(f.DataPath, "pipeline", "live-inputs.json")
+}
+
+// ListInputConfigs returns the inputs of all organizations, inputs without organization belong to
+// the main organization. There are no inputs if the file does not exist.
+func (f *FileStorage) ListInputConfigs(_ context.Context) ([]InputConfig, error) {
+	filePath := f.inputConfigsFilePath()
+	// Safe to ignore gosec warning G304.
+	// nolint:gosec
+	bytes, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("can't read %s file: %w", filePath, err)
+	}
+	var inputConfigs InputConfigs
+	err = json.Unmarshal(bytes, &inputConfigs)
+	if err != nil {
+		return nil, fmt.Errorf("can't unmarshal %s data: %w", filePath, err)
+	}
+	for i := range inputConfigs.Inputs {
+		if inputConfigs.Inputs[i].OrgId == 0 {
+			inputConfigs.Inputs[i].OrgId = 1
+		}
+	}
+	return inputConfigs.Inputs, nil
+}
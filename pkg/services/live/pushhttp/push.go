@@ -1,18 +1,26 @@
 package pushhttp
 
 import (
+	"compress/gzip"
 	"context"
 	"errors"
 	"io"
+	"mime"
 	"net/http"
 
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/live"
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/limits"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -72,6 +80,13 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 		"frameFormat", frameFormat,
 	)
 
+	channel := liveDto.Channel{Scope: liveDto.ScopeStream, Namespace: streamID}.String()
+	if err := g.GrafanaLive.Limiter.AllowPublish(ctx.SignedInUser.OrgID, channel, limits.Token(ctx.SignedInUser), len(body)); err != nil {
+		logger.Debug("Push limited", "streamId", streamID, "error", err)
+		live.LimitErrorResponse(err).WriteTo(ctx)
+		return
+	}
+
 	metricFrames, err := g.converter.Convert(body, frameFormat)
 	if err != nil {
 		logger.Error("Error converting metrics", "error", err, "frameFormat", frameFormat)
@@ -88,6 +103,10 @@ func (g *Gateway) Handle(ctx *contextmodel.ReqContext) {
 
 	for _, mf := range metricFrames {
 		err := stream.Push(ctx.Req.Context(), mf.Key(), mf.Frame())
+		if errors.Is(err, limits.ErrQuotaReached) {
+			live.LimitErrorResponse(err).WriteTo(ctx)
+			return
+		}
 		if err != nil {
 			logger.Error("Error pushing frame", "error", err, "data", string(body))
 			ctx.Resp.WriteHeader(http.StatusInternalServerError)
@@ -113,6 +132,12 @@ func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 		"bodyLength", len(body),
 	)
 
+	if err := g.GrafanaLive.Limiter.AllowPublish(ctx.OrgID, channelID, limits.Token(ctx.SignedInUser), len(body)); err != nil {
+		logger.Debug("Push limited", "channel", channelID, "error", err)
+		live.LimitErrorResponse(err).WriteTo(ctx)
+		return
+	}
+
 	ruleFound, err := g.GrafanaLive.Pipeline.ProcessInput(ctx.Req.Context(), ctx.OrgID, channelID, body)
 	if err != nil {
 		logger.Error("Pipeline input processing error", "error", err, "body", string(body))
@@ -131,3 +156,166 @@ func (g *Gateway) HandlePipelinePush(ctx *contextmodel.ReqContext) {
 
 	ctx.Resp.WriteHeader(http.StatusOK)
 }
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// readLimited reads at most maxSize bytes. The bodies of OTLP requests are limited once decompressed, as a small
+// compressed body can expand to a large one.
+func readLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxSize {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
+
+// HandleOTLPMetrics receives metrics in the OTLP/HTTP format and pushes a frame for each metric name to the
+// stream/<streamId>/<metric name> channel.
+func (g *Gateway) HandleOTLPMetrics(ctx *contextmodel.ReqContext) {
+	g.handleOTLP(ctx, "metrics", func(json bool) telemetry.Converter {
+		return otlp.NewMetricsConverter(otlp.WithJSONEncoding(json))
+	}, func(contentType string) ([]byte, error) {
+		if contentType == contentTypeJSON {
+			return pmetricotlp.NewExportResponse().MarshalJSON()
+		}
+		return pmetricotlp.NewExportResponse().MarshalProto()
+	})
+}
+
+// HandleOTLPLogs receives logs in the OTLP/HTTP format and pushes them as a frame to the stream/<streamId>/logs
+// channel.
+func (g *Gateway) HandleOTLPLogs(ctx *contextmodel.ReqContext) {
+	g.handleOTLP(ctx, "logs", func(json bool) telemetry.Converter {
+		return otlp.NewLogsConverter(otlp.WithJSONEncoding(json))
+	}, func(contentType string) ([]byte, error) {
+		if contentType == contentTypeJSON {
+			return plogotlp.NewExportResponse().MarshalJSON()
+		}
+		return plogotlp.NewExportResponse().MarshalProto()
+	})
+}
+
+func (g *Gateway) handleOTLP(ctx *contextmodel.ReqContext, signal string, newConverter func(json bool) telemetry.Converter, response func(contentType string) ([]byte, error)) {
+	streamID := web.Params(ctx.Req)[":streamId"]
+
+	contentType, _, err := mime.ParseMediaType(ctx.Req.Header.Get("Content-Type"))
+	if err != nil || (contentType != contentTypeProtobuf && contentType != contentTypeJSON) {
+		ctx.Resp.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var reader io.Reader = ctx.Req.Body
+	switch ctx.Req.Header.Get("Content-Encoding") {
+	case "", "identity":
+	case "gzip":
+		gzipReader, err := gzip.NewReader(ctx.Req.Body)
+		if err != nil {
+			logger.Debug("Error reading gzip body", "error", err)
+			ctx.Resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer func() { _ = gzipReader.Close() }()
+		reader = gzipReader
+	default:
+		ctx.Resp.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := readLimited(reader, g.Cfg.LiveOTLPMaxBodySize)
+	if errors.Is(err, errBodyTooLarge) {
+		logger.Debug("OTLP request body too large", "streamId", streamID, "maxBodySize", g.Cfg.LiveOTLPMaxBodySize)
+		ctx.Resp.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		logger.Error("Error reading body", "error", err)
+		ctx.Resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	logger.Debug("Live OTLP push request",
+		"protocol", "http",
+		"streamId", streamID,
+		"signal", signal,
+		"bodyLength", len(body),
+		"contentType", contentType,
+	)
+
+	channel := liveDto.Channel{Scope: liveDto.ScopeStream, Namespace: streamID}.String()
+	if err := g.GrafanaLive.Limiter.AllowPublish(ctx.SignedInUser.OrgID, channel, limits.Token(ctx.SignedInUser), len(body)); err != nil {
+		logger.Debug("OTLP push limited", "streamId", streamID, "error", err)
+		live.LimitErrorResponse(err).WriteTo(ctx)
+		return
+	}
+
+	frames, err := newConverter(contentType == contentTypeJSON).Convert(body)
+	if err != nil {
+		logger.Debug("Error converting OTLP request", "error", err, "signal", signal)
+		ctx.Resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if err := g.pushFrames(ctx, streamID, frames); err != nil {
+		if errors.Is(err, limits.ErrQuotaReached) {
+			live.LimitErrorResponse(err).WriteTo(ctx)
+			return
+		}
+		if errors.Is(err, liveDto.ErrInvalidChannelID) {
+			ctx.Resp.WriteHeader(http.StatusBadRequest)
+		} else {
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	res, err := response(contentType)
+	if err != nil {
+		logger.Error("Error encoding OTLP response", "error", err)
+		ctx.Resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ctx.Resp.Header().Set("Content-Type", contentType)
+	ctx.Resp.WriteHeader(http.StatusOK)
+	_, _ = ctx.Resp.Write(res)
+}
+
+// pushFrames passes the frames to the pipeline rules of their channels, or pushes them to the managed stream if
+// there is no rule which processes frames for a channel.
+func (g *Gateway) pushFrames(ctx *contextmodel.ReqContext, streamID string, frames []telemetry.FrameWrapper) error {
+	var stream *managedstream.NamespaceStream
+	for _, f := range frames {
+		if g.GrafanaLive.Pipeline != nil {
+			channelID := liveDto.Channel{Scope: liveDto.ScopeStream, Namespace: streamID, Path: f.Key()}.String()
+			ok, err := g.GrafanaLive.Pipeline.ProcessFrame(ctx.Req.Context(), ctx.SignedInUser.OrgID, channelID, f.Frame())
+			if err != nil {
+				logger.Error("Pipeline frame processing error", "error", err, "channel", channelID)
+				return err
+			}
+			if ok {
+				continue
+			}
+		}
+		if stream == nil {
+			var err error
+			stream, err = g.GrafanaLive.ManagedStreamRunner.GetOrCreateStream(ctx.SignedInUser.OrgID, liveDto.ScopeStream, streamID)
+			if err != nil {
+				logger.Error("Error getting stream", "error", err)
+				return err
+			}
+		}
+		if err := stream.Push(ctx.Req.Context(), f.Key(), f.Frame()); err != nil {
+			if errors.Is(err, limits.ErrQuotaReached) {
+				return err
+			}
+			logger.Error("Error pushing frame", "error", err, "streamId", streamID, "key", f.Key())
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package pushhttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLimited(t *testing.T) {
+	body, err := readLimited(strings.NewReader("0123456789"), 10)
+	require.NoError(t, err)
+	require.Equal(t, "0123456789", string(body))
+
+	_, err = readLimited(strings.NewReader("0123456789a"), 10)
+	require.ErrorIs(t, err, errBodyTooLarge)
+
+	// A small compressed body is limited once decompressed.
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, err = w.Write(make([]byte, 10*1024*1024))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Less(t, compressed.Len(), 1024*1024)
+	r, err := gzip.NewReader(&compressed)
+	require.NoError(t, err)
+	_, err = readLimited(r, 1024*1024)
+	require.ErrorIs(t, err, errBodyTooLarge)
+}
@@ -1,6 +1,7 @@
 package pushws
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -8,6 +9,7 @@ import (
 	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
 
 	"github.com/grafana/grafana/pkg/services/live/convert"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/live/pushurl"
@@ -70,6 +72,13 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		channel := liveDto.Channel{Scope: liveDto.ScopeStream, Namespace: streamID}.String()
+		if err := s.config.Limiter.AllowPublish(user.GetOrgID(), channel, limits.Token(user), len(body)); err != nil {
+			logger.Debug("Push limited", "streamId", streamID, "error", err)
+			closeLimited(conn, err)
+			return
+		}
+
 		stream, err := s.managedStreamRunner.GetOrCreateStream(user.GetOrgID(), liveDto.ScopeStream, streamID)
 		if err != nil {
 			logger.Error("Error getting stream", "error", err)
@@ -97,6 +106,10 @@ func (s *Handler) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 		for _, mf := range metricFrames {
 			err := stream.Push(r.Context(), mf.Key(), mf.Frame())
 			if err != nil {
+				if errors.Is(err, limits.ErrQuotaReached) {
+					closeLimited(conn, err)
+					return
+				}
 				logger.Error("Error pushing frame", "error", err, "data", string(body))
 				return
 			}
@@ -2,6 +2,7 @@ package pushws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -11,6 +12,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live/limits"
 )
 
 var (
@@ -38,6 +40,9 @@ type Config struct {
 	// PingInterval sets interval server will send ping messages to clients.
 	// By default DefaultWebsocketPingInterval will be used.
 	PingInterval time.Duration
+
+	// Limiter limits the rate of pushed messages, nil means no limits.
+	Limiter *limits.Limiter
 }
 
 func sameHostOriginCheck() func(r *http.Request) bool {
@@ -72,6 +77,19 @@ const (
 	DefaultWebsocketMessageSizeLimit = 1024 * 1024 // 1MB
 )
 
+// closeLimited closes the connection of a client which exceeded a limit, with the limit in the close reason.
+func closeLimited(conn *websocket.Conn, err error) {
+	code := websocket.ClosePolicyViolation
+	switch {
+	case errors.Is(err, limits.ErrRateLimited):
+		code = websocket.CloseTryAgainLater
+	case errors.Is(err, limits.ErrMessageTooLarge):
+		code = websocket.CloseMessageTooBig
+	}
+	msg := websocket.FormatCloseMessage(code, err.Error())
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+}
+
 func setupWSConn(ctx context.Context, conn *websocket.Conn, config Config) {
 	pingInterval := config.PingInterval
 	if pingInterval == 0 {
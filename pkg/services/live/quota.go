@@ -0,0 +1,59 @@
+package live
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/live/limits"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Usage returns the number of managed stream channels for the quota service. Channels of all Grafana instances are
+// counted if the frame cache is stored in Redis.
+func (g *GrafanaLive) Usage(ctx context.Context, scopeParams *quota.ScopeParameters) (*quota.Map, error) {
+	u := &quota.Map{}
+
+	globalTag, err := quota.NewTag(limits.QuotaTargetSrv, limits.QuotaTarget, quota.GlobalScope)
+	if err != nil {
+		return nil, err
+	}
+	count, err := g.ManagedStreamRunner.CountStreamChannels(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	u.Set(globalTag, count)
+
+	if scopeParams != nil && scopeParams.OrgID != 0 {
+		orgTag, err := quota.NewTag(limits.QuotaTargetSrv, limits.QuotaTarget, quota.OrgScope)
+		if err != nil {
+			return nil, err
+		}
+		count, err := g.ManagedStreamRunner.CountStreamChannels(ctx, scopeParams.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		u.Set(orgTag, count)
+	}
+	return u, nil
+}
+
+func readQuotaConfig(cfg *setting.Cfg) (*quota.Map, error) {
+	defaultLimits := &quota.Map{}
+
+	if cfg == nil {
+		return defaultLimits, nil
+	}
+
+	globalQuotaTag, err := quota.NewTag(limits.QuotaTargetSrv, limits.QuotaTarget, quota.GlobalScope)
+	if err != nil {
+		return defaultLimits, err
+	}
+	orgQuotaTag, err := quota.NewTag(limits.QuotaTargetSrv, limits.QuotaTarget, quota.OrgScope)
+	if err != nil {
+		return defaultLimits, err
+	}
+
+	defaultLimits.Set(globalQuotaTag, cfg.Quota.Global.LiveChannel)
+	defaultLimits.Set(orgQuotaTag, cfg.Quota.Org.LiveChannel)
+	return defaultLimits, nil
+}
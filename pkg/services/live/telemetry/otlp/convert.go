@@ -0,0 +1,336 @@
+package otlp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+// LogsFrameKey is the key of the frame of log records.
+const LogsFrameKey = "logs"
+
+var (
+	_ telemetry.Converter = (*MetricsConverter)(nil)
+	_ telemetry.Converter = (*LogsConverter)(nil)
+)
+
+// ConverterOption configures the MetricsConverter and LogsConverter, e.g. the encoding of the requests they convert.
+type ConverterOption func(*options)
+
+type options struct {
+	json bool
+	now  func() time.Time
+}
+
+// WithJSONEncoding expects OTLP requests encoded as JSON instead of protobuf.
+func WithJSONEncoding(enabled bool) ConverterOption {
+	return func(o *options) {
+		o.json = enabled
+	}
+}
+
+func newOptions(opts []ConverterOption) options {
+	o := options{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// MetricsConverter converts OTLP metrics export requests to Grafana frames.
+// It generates one frame for each metric name with a labels column, a time column and a column for each value of
+// the data points, so frames can be used like the frames of the Telegraf converter with labels column.
+type MetricsConverter struct {
+	options
+}
+
+// NewMetricsConverter creates new MetricsConverter from OTLP metrics to Grafana Data Frames.
+func NewMetricsConverter(opts ...ConverterOption) *MetricsConverter {
+	return &MetricsConverter{options: newOptions(opts)}
+}
+
+// Convert metrics.
+func (c *MetricsConverter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := pmetricotlp.NewExportRequest()
+	var err error
+	if c.json {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics: %w", err)
+	}
+
+	frames := newFrameSet()
+	resourceMetrics := req.Metrics().ResourceMetrics()
+	for i := 0; i < resourceMetrics.Len(); i++ {
+		rm := resourceMetrics.At(i)
+		scopeMetrics := rm.ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			metrics := scopeMetrics.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				c.convertMetric(frames, rm.Resource().Attributes(), metrics.At(k))
+			}
+		}
+	}
+	return frames.wrappers(), nil
+}
+
+func (c *MetricsConverter) convertMetric(frames *frameSet, resource pcommon.Map, m pmetric.Metric) {
+	frame := frames.get(FrameKey(m.Name()))
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		c.appendNumberDataPoints(frame, resource, m.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		c.appendNumberDataPoints(frame, resource, m.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			values := []fieldValue{{name: "count", value: float64(dp.Count())}}
+			if dp.HasSum() {
+				values = append(values, fieldValue{name: "sum", value: dp.Sum()})
+			}
+			frame.append(attributesToLabels(resource, dp.Attributes()), c.timestamp(dp.Timestamp()), values)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := m.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			values := []fieldValue{{name: "count", value: float64(dp.Count())}}
+			if dp.HasSum() {
+				values = append(values, fieldValue{name: "sum", value: dp.Sum()})
+			}
+			frame.append(attributesToLabels(resource, dp.Attributes()), c.timestamp(dp.Timestamp()), values)
+		}
+	case pmetric.MetricTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			frame.append(attributesToLabels(resource, dp.Attributes()), c.timestamp(dp.Timestamp()), []fieldValue{
+				{name: "count", value: float64(dp.Count())},
+				{name: "sum", value: dp.Sum()},
+			})
+		}
+	}
+}
+
+func (c *MetricsConverter) appendNumberDataPoints(frame *labelsColumnFrame, resource pcommon.Map, dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		var value float64
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			value = float64(dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			value = dp.DoubleValue()
+		default:
+			continue
+		}
+		frame.append(attributesToLabels(resource, dp.Attributes()), c.timestamp(dp.Timestamp()), []fieldValue{{name: "value", value: value}})
+	}
+}
+
+// LogsConverter converts OTLP logs export requests to Grafana frames.
+// It generates one frame with a labels column, a time column and columns for the severity and body of the records.
+type LogsConverter struct {
+	options
+}
+
+// NewLogsConverter creates new LogsConverter from OTLP logs to Grafana Data Frames.
+func NewLogsConverter(opts ...ConverterOption) *LogsConverter {
+	return &LogsConverter{options: newOptions(opts)}
+}
+
+// Convert logs.
+func (c *LogsConverter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := plogotlp.NewExportRequest()
+	var err error
+	if c.json {
+		err = req.UnmarshalJSON(body)
+	} else {
+		err = req.UnmarshalProto(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing logs: %w", err)
+	}
+
+	frames := newFrameSet()
+	resourceLogs := req.Logs().ResourceLogs()
+	for i := 0; i < resourceLogs.Len(); i++ {
+		rl := resourceLogs.At(i)
+		scopeLogs := rl.ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				c.appendLogRecord(frames.get(LogsFrameKey), rl.Resource().Attributes(), records.At(k))
+			}
+		}
+	}
+	return frames.wrappers(), nil
+}
+
+func (c *LogsConverter) appendLogRecord(frame *labelsColumnFrame, resource pcommon.Map, r plog.LogRecord) {
+	ts := r.Timestamp()
+	if ts == 0 {
+		ts = r.ObservedTimestamp()
+	}
+	severity := r.SeverityText()
+	if severity == "" && r.SeverityNumber() != plog.SeverityNumberUnspecified {
+		severity = r.SeverityNumber().String()
+	}
+	frame.append(attributesToLabels(resource, r.Attributes()), c.timestamp(ts), []fieldValue{
+		{name: "severity", value: severity},
+		{name: "body", value: r.Body().AsString()},
+	})
+}
+
+func (o options) timestamp(ts pcommon.Timestamp) time.Time {
+	if ts == 0 {
+		return o.now().UTC()
+	}
+	return ts.AsTime().UTC()
+}
+
+// FrameKey returns the key of the frame of the metric, which is used as path of the channel of the frame. Slashes and
+// characters that are not allowed in channel paths are replaced by underscores.
+func FrameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.', r == '=':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// attributesToLabels merges the attributes of the resource and of the data point into labels.
+func attributesToLabels(resource pcommon.Map, attributes pcommon.Map) data.Labels {
+	labels := data.Labels{}
+	for _, m := range []pcommon.Map{resource, attributes} {
+		m.Range(func(k string, v pcommon.Value) bool {
+			labels[k] = v.AsString()
+			return true
+		})
+	}
+	return labels
+}
+
+type fieldValue struct {
+	name string
+	// value is a float64 or a string
+	value any
+}
+
+// frameSet maintains the order of frames as they appear in input.
+type frameSet struct {
+	keys   []string
+	frames map[string]*labelsColumnFrame
+}
+
+func newFrameSet() *frameSet {
+	return &frameSet{frames: map[string]*labelsColumnFrame{}}
+}
+
+func (s *frameSet) get(key string) *labelsColumnFrame {
+	frame, ok := s.frames[key]
+	if !ok {
+		frame = &labelsColumnFrame{
+			key: key,
+			fields: []*data.Field{
+				data.NewField("labels", nil, []string{}),
+				data.NewField("time", nil, []time.Time{}),
+			},
+			fieldCache: map[string]int{},
+		}
+		s.frames[key] = frame
+		s.keys = append(s.keys, key)
+	}
+	return frame
+}
+
+func (s *frameSet) wrappers() []telemetry.FrameWrapper {
+	wrappers := make([]telemetry.FrameWrapper, 0, len(s.keys))
+	for _, key := range s.keys {
+		frame := s.frames[key]
+		if frame.fields[0].Len() == 0 {
+			continue
+		}
+		// Fill value columns with nulls in case of unequal length.
+		for i := 2; i < len(frame.fields); i++ {
+			for frame.fields[i].Len() < frame.fields[0].Len() {
+				frame.fields[i].Append(nil)
+			}
+		}
+		wrappers = append(wrappers, frame)
+	}
+	return wrappers
+}
+
+type labelsColumnFrame struct {
+	key        string
+	fields     []*data.Field
+	fieldCache map[string]int
+}
+
+// Key returns a key which describes Frame metrics.
+func (f *labelsColumnFrame) Key() string {
+	return f.key
+}
+
+// Frame transforms labelsColumnFrame to Grafana data.Frame.
+func (f *labelsColumnFrame) Frame() *data.Frame {
+	return data.NewFrame(f.key, f.fields...)
+}
+
+func (f *labelsColumnFrame) append(labels data.Labels, ts time.Time, values []fieldValue) {
+	f.fields[0].Append(labels.String())
+	f.fields[1].Append(ts)
+	row := f.fields[0].Len() - 1
+	for _, v := range values {
+		index, ok := f.fieldCache[v.name]
+		if !ok {
+			var field *data.Field
+			if _, isString := v.value.(string); isString {
+				field = data.NewField(v.name, nil, []*string{})
+			} else {
+				field = data.NewField(v.name, nil, []*float64{})
+			}
+			f.fields = append(f.fields, field)
+			index = len(f.fields) - 1
+			f.fieldCache[v.name] = index
+		}
+		field := f.fields[index]
+		// If field does not have a desired length till this moment
+		// we fill it with nulls up to the currently processed index.
+		for field.Len() < row {
+			field.Append(nil)
+		}
+		switch value := v.value.(type) {
+		case string:
+			if field.Type() == data.FieldTypeNullableString {
+				field.Append(&value)
+			} else {
+				field.Append(nil)
+			}
+		case float64:
+			if field.Type() == data.FieldTypeNullableFloat64 {
+				field.Append(&value)
+			} else {
+				s := fmt.Sprintf("%v", value)
+				field.Append(&s)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
+)
+
+var testTime = time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testMetrics() pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "api")
+	ms := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := ms.AppendEmpty()
+	gauge.SetName("cpu.usage")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.Attributes().PutStr("cpu", "0")
+	dp.SetDoubleValue(0.5)
+	dp = gauge.Gauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.Attributes().PutStr("cpu", "1")
+	dp.SetIntValue(1)
+
+	histogram := ms.AppendEmpty()
+	histogram.SetName("http server/duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	hdp.SetCount(3)
+	hdp.SetSum(1.5)
+	return metrics
+}
+
+func TestMetricsConverter_Convert(t *testing.T) {
+	body, err := pmetricotlp.NewExportRequestFromMetrics(testMetrics()).MarshalProto()
+	require.NoError(t, err)
+
+	frameWrappers, err := NewMetricsConverter().Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	require.Equal(t, "cpu.usage", frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, `cpu=0, service.name=api`, frame.Fields[0].At(0))
+	require.Equal(t, `cpu=1, service.name=api`, frame.Fields[0].At(1))
+	require.Equal(t, testTime, frame.Fields[1].At(0))
+	require.Equal(t, "value", frame.Fields[2].Name)
+	require.Equal(t, 0.5, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, 1.0, *frame.Fields[2].At(1).(*float64))
+
+	require.Equal(t, "http_server_duration", frameWrappers[1].Key())
+	frame = frameWrappers[1].Frame()
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, "count", frame.Fields[2].Name)
+	require.Equal(t, 3.0, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, "sum", frame.Fields[3].Name)
+	require.Equal(t, 1.5, *frame.Fields[3].At(0).(*float64))
+}
+
+func TestMetricsConverter_Convert_JSON(t *testing.T) {
+	body, err := pmetricotlp.NewExportRequestFromMetrics(testMetrics()).MarshalJSON()
+	require.NoError(t, err)
+
+	frameWrappers, err := NewMetricsConverter(WithJSONEncoding(true)).Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 2)
+
+	_, err = NewMetricsConverter().Convert(body)
+	require.Error(t, err)
+}
+
+func TestMetricsConverter_Convert_MissingValues(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	m := ms.AppendEmpty()
+	m.SetName("requests")
+	dps := m.SetEmptyHistogram().DataPoints()
+	dps.AppendEmpty().SetCount(1)
+	dp := dps.AppendEmpty()
+	dp.SetCount(2)
+	dp.SetSum(3)
+	dps.AppendEmpty().SetCount(4)
+	body, err := pmetricotlp.NewExportRequestFromMetrics(metrics).MarshalProto()
+	require.NoError(t, err)
+
+	converter := NewMetricsConverter()
+	converter.now = func() time.Time { return testTime }
+	frameWrappers, err := converter.Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, testTime, frame.Fields[1].At(0))
+	require.Nil(t, frame.Fields[3].At(0))
+	require.Equal(t, 3.0, *frame.Fields[3].At(1).(*float64))
+	require.Nil(t, frame.Fields[3].At(2))
+}
+
+func TestLogsConverter_Convert(t *testing.T) {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "api")
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	r := records.AppendEmpty()
+	r.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	r.SetSeverityText("warn")
+	r.Body().SetStr("slow request")
+	r = records.AppendEmpty()
+	r.SetObservedTimestamp(pcommon.NewTimestampFromTime(testTime.Add(time.Second)))
+	r.SetSeverityNumber(plog.SeverityNumberError)
+	r.Attributes().PutInt("status", 500)
+	r.Body().SetStr("failed request")
+	body, err := plogotlp.NewExportRequestFromLogs(logs).MarshalProto()
+	require.NoError(t, err)
+
+	frameWrappers, err := NewLogsConverter().Convert(body)
+	require.NoError(t, err)
+	require.Len(t, frameWrappers, 1)
+	require.Equal(t, LogsFrameKey, frameWrappers[0].Key())
+	frame := frameWrappers[0].Frame()
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, `service.name=api`, frame.Fields[0].At(0))
+	require.Equal(t, `service.name=api, status=500`, frame.Fields[0].At(1))
+	require.Equal(t, testTime.Add(time.Second), frame.Fields[1].At(1))
+	require.Equal(t, "warn", *frame.Fields[2].At(0).(*string))
+	require.Equal(t, "Error", *frame.Fields[2].At(1).(*string))
+	require.Equal(t, "failed request", *frame.Fields[3].At(1).(*string))
+	require.Equal(t, data.FieldTypeNullableString, frame.Fields[3].Type())
+}
+
+func TestFrameKey(t *testing.T) {
+	require.Equal(t, "http.server.duration", FrameKey("http.server.duration"))
+	require.Equal(t, "a_b_c", FrameKey("a b/c"))
+}
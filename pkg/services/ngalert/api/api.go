@@ -114,6 +114,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			log:                logger,
 			cfg:                &api.Cfg.UnifiedAlerting,
 			authz:              ruleAuthzService,
+			nsValidator:        notifier.NewNotificationSettingsValidationService(api.AlertingStore),
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
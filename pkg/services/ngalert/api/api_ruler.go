@@ -33,6 +33,11 @@ type ConditionValidator interface {
 	Validate(ctx eval.EvaluationContext, condition ngmodels.Condition) error
 }
 
+// NotificationSettingsValidator validates the notification settings of alert rules against the Alertmanager configuration of an organization.
+type NotificationSettingsValidator interface {
+	ValidateNotificationSettings(ctx context.Context, orgID int64, settings ...ngmodels.NotificationSettings) error
+}
+
 type RulerSrv struct {
 	xactManager        provisioning.TransactionManager
 	provenanceStore    provisioning.ProvisioningStore
@@ -42,6 +47,7 @@ type RulerSrv struct {
 	cfg                *setting.UnifiedAlertingSettings
 	conditionValidator ConditionValidator
 	authz              RuleAccessControlService
+	nsValidator        NotificationSettingsValidator
 }
 
 var (
@@ -300,6 +306,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if err := validateNotificationSettings(c.Req.Context(), srv.nsValidator, groupChanges); err != nil {
+			return err
+		}
+
 		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
 		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
@@ -382,6 +392,25 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 	return changesToResponse(finalChanges)
 }
 
+// validateNotificationSettings validates the notification settings of new and updated rules against the
+// Alertmanager configuration of the organization.
+func validateNotificationSettings(ctx context.Context, validator NotificationSettingsValidator, changes *store.GroupDelta) error {
+	var settings []ngmodels.NotificationSettings
+	for _, rule := range changes.New {
+		settings = append(settings, rule.NotificationSettings...)
+	}
+	for _, upd := range changes.Update {
+		settings = append(settings, upd.New.NotificationSettings...)
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+	if err := validator.ValidateNotificationSettings(ctx, changes.GroupKey.OrgID, settings...); err != nil {
+		return fmt.Errorf("%w: %w", ngmodels.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
+
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
@@ -429,21 +458,22 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 	}
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
-			ID:              r.ID,
-			OrgID:           r.OrgID,
-			Title:           r.Title,
-			Condition:       r.Condition,
-			Data:            ApiAlertQueriesFromAlertQueries(r.Data),
-			Updated:         r.Updated,
-			IntervalSeconds: r.IntervalSeconds,
-			Version:         r.Version,
-			UID:             r.UID,
-			NamespaceUID:    r.NamespaceUID,
-			RuleGroup:       r.RuleGroup,
-			NoDataState:     apimodels.NoDataState(r.NoDataState),
-			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
-			Provenance:      apimodels.Provenance(provenance),
-			IsPaused:        r.IsPaused,
+			ID:                   r.ID,
+			OrgID:                r.OrgID,
+			Title:                r.Title,
+			Condition:            r.Condition,
+			Data:                 ApiAlertQueriesFromAlertQueries(r.Data),
+			Updated:              r.Updated,
+			IntervalSeconds:      r.IntervalSeconds,
+			Version:              r.Version,
+			UID:                  r.UID,
+			NamespaceUID:         r.NamespaceUID,
+			RuleGroup:            r.RuleGroup,
+			NoDataState:          apimodels.NoDataState(r.NoDataState),
+			ExecErrState:         apimodels.ExecutionErrorState(r.ExecErrState),
+			Provenance:           apimodels.Provenance(provenance),
+			IsPaused:             r.IsPaused,
+			NotificationSettings: NotificationSettingsToApi(r.GetNotificationSettings()),
 		},
 	}
 	forDuration := model.Duration(r.For)
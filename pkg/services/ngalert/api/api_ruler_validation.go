@@ -102,6 +102,13 @@ func validateRuleNode(
 		return nil, err
 	}
 
+	if settings := ruleNode.GrafanaManagedAlert.NotificationSettings; settings != nil {
+		newAlertRule.NotificationSettings = NotificationSettingsFromApi(settings)
+		if err := newAlertRule.NotificationSettings[0].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		newAlertRule.Labels = ruleNode.ApiRuleNode.Labels
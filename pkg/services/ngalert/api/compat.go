@@ -15,43 +15,45 @@ import (
 // AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
 func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
 	return models.AlertRule{
-		ID:           a.ID,
-		UID:          a.UID,
-		OrgID:        a.OrgID,
-		NamespaceUID: a.FolderUID,
-		RuleGroup:    a.RuleGroup,
-		Title:        a.Title,
-		Condition:    a.Condition,
-		Data:         AlertQueriesFromApiAlertQueries(a.Data),
-		Updated:      a.Updated,
-		NoDataState:  models.NoDataState(a.NoDataState),          // TODO there must be a validation
-		ExecErrState: models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
-		For:          time.Duration(a.For),
-		Annotations:  a.Annotations,
-		Labels:       a.Labels,
-		IsPaused:     a.IsPaused,
+		ID:                   a.ID,
+		UID:                  a.UID,
+		OrgID:                a.OrgID,
+		NamespaceUID:         a.FolderUID,
+		RuleGroup:            a.RuleGroup,
+		Title:                a.Title,
+		Condition:            a.Condition,
+		Data:                 AlertQueriesFromApiAlertQueries(a.Data),
+		Updated:              a.Updated,
+		NoDataState:          models.NoDataState(a.NoDataState),          // TODO there must be a validation
+		ExecErrState:         models.ExecutionErrorState(a.ExecErrState), // TODO there must be a validation
+		For:                  time.Duration(a.For),
+		Annotations:          a.Annotations,
+		Labels:               a.Labels,
+		IsPaused:             a.IsPaused,
+		NotificationSettings: NotificationSettingsFromApi(a.NotificationSettings),
 	}, nil
 }
 
 // ProvisionedAlertRuleFromAlertRule converts models.AlertRule to definitions.ProvisionedAlertRule and sets provided provenance status
 func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.Provenance) definitions.ProvisionedAlertRule {
 	return definitions.ProvisionedAlertRule{
-		ID:           rule.ID,
-		UID:          rule.UID,
-		OrgID:        rule.OrgID,
-		FolderUID:    rule.NamespaceUID,
-		RuleGroup:    rule.RuleGroup,
-		Title:        rule.Title,
-		For:          model.Duration(rule.For),
-		Condition:    rule.Condition,
-		Data:         ApiAlertQueriesFromAlertQueries(rule.Data),
-		Updated:      rule.Updated,
-		NoDataState:  definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
-		ExecErrState: definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
-		Annotations:  rule.Annotations,
-		Labels:       rule.Labels,
-		Provenance:   definitions.Provenance(provenance), // TODO validate enum conversion?
-		IsPaused:     rule.IsPaused,
+		ID:                   rule.ID,
+		UID:                  rule.UID,
+		OrgID:                rule.OrgID,
+		FolderUID:            rule.NamespaceUID,
+		RuleGroup:            rule.RuleGroup,
+		Title:                rule.Title,
+		For:                  model.Duration(rule.For),
+		Condition:            rule.Condition,
+		Data:                 ApiAlertQueriesFromAlertQueries(rule.Data),
+		Updated:              rule.Updated,
+		NoDataState:          definitions.NoDataState(rule.NoDataState),          // TODO there may be a validation
+		ExecErrState:         definitions.ExecutionErrorState(rule.ExecErrState), // TODO there may be a validation
+		Annotations:          rule.Annotations,
+		Labels:               rule.Labels,
+		Provenance:           definitions.Provenance(provenance), // TODO validate enum conversion?
+		IsPaused:             rule.IsPaused,
+		NotificationSettings: NotificationSettingsToApi(rule.GetNotificationSettings()),
 	}
 }
 
@@ -195,9 +197,30 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if ns := rule.GetNotificationSettings(); ns != nil {
+		result.NotificationSettings = AlertRuleNotificationSettingsExportFromNotificationSettings(*ns)
+	}
 	return result, nil
 }
 
+// AlertRuleNotificationSettingsExportFromNotificationSettings creates a definitions.AlertRuleNotificationSettingsExport DTO from models.NotificationSettings.
+func AlertRuleNotificationSettingsExportFromNotificationSettings(ns models.NotificationSettings) *definitions.AlertRuleNotificationSettingsExport {
+	toStringIfNotNil := func(d *model.Duration) *string {
+		if d == nil {
+			return nil
+		}
+		return util.Pointer(d.String())
+	}
+	return &definitions.AlertRuleNotificationSettingsExport{
+		Receiver:          ns.Receiver,
+		GroupBy:           ns.GroupBy,
+		GroupWait:         toStringIfNotNil(ns.GroupWait),
+		GroupInterval:     toStringIfNotNil(ns.GroupInterval),
+		RepeatInterval:    toStringIfNotNil(ns.RepeatInterval),
+		MuteTimeIntervals: ns.MuteTimeIntervals,
+	}
+}
+
 // AlertQueryExportFromAlertQuery creates a definitions.AlertQueryExport DTO from models.AlertQuery.
 func AlertQueryExportFromAlertQuery(query models.AlertQuery) (definitions.AlertQueryExport, error) {
 	// We unmarshal the json.RawMessage model into a map in order to facilitate yaml marshalling.
@@ -373,3 +396,33 @@ func MuteTimingIntervalToMuteTimeIntervalHclExport(m definitions.MuteTimeInterva
 	err = j.Unmarshal(mdata, &result)
 	return result, err
 }
+
+// NotificationSettingsFromApi converts definitions.AlertRuleNotificationSettings to the notification settings of models.AlertRule.
+func NotificationSettingsFromApi(settings *definitions.AlertRuleNotificationSettings) []models.NotificationSettings {
+	if settings == nil {
+		return nil
+	}
+	return []models.NotificationSettings{{
+		Receiver:          settings.Receiver,
+		GroupBy:           settings.GroupBy,
+		GroupWait:         settings.GroupWait,
+		GroupInterval:     settings.GroupInterval,
+		RepeatInterval:    settings.RepeatInterval,
+		MuteTimeIntervals: settings.MuteTimeIntervals,
+	}}
+}
+
+// NotificationSettingsToApi converts models.NotificationSettings to definitions.AlertRuleNotificationSettings.
+func NotificationSettingsToApi(settings *models.NotificationSettings) *definitions.AlertRuleNotificationSettings {
+	if settings == nil {
+		return nil
+	}
+	return &definitions.AlertRuleNotificationSettings{
+		Receiver:          settings.Receiver,
+		GroupBy:           settings.GroupBy,
+		GroupWait:         settings.GroupWait,
+		GroupInterval:     settings.GroupInterval,
+		RepeatInterval:    settings.RepeatInterval,
+		MuteTimeIntervals: settings.MuteTimeIntervals,
+	}
+}
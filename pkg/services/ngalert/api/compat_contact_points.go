@@ -49,6 +49,13 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 	var integration []*notify.GrafanaIntegrationConfig
 
 	var errs []error
+	for _, i := range cp.AMQP {
+		el, err := marshallIntegration(j, "amqp", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	for _, i := range cp.Alertmanager {
 		el, err := marshallIntegration(j, "prometheus-alertmanager", i, i.DisableResolveMessage)
 		integration = append(integration, el)
@@ -98,6 +105,20 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 			errs = append(errs, err)
 		}
 	}
+	for _, i := range cp.MQTT {
+		el, err := marshallIntegration(j, "mqtt", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, i := range cp.NATS {
+		el, err := marshallIntegration(j, "nats", i, i.DisableResolveMessage)
+		integration = append(integration, el)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
 	for _, i := range cp.Opsgenie {
 		el, err := marshallIntegration(j, "opsgenie", i, i.DisableResolveMessage)
 		integration = append(integration, el)
@@ -229,6 +250,11 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Alertmanager = append(result.Alertmanager, integration)
 		}
+	case "amqp":
+		integration := definitions.AMQPIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.AMQP = append(result.AMQP, integration)
+		}
 	case "dingding":
 		integration := definitions.DingdingIntegration{DisableResolveMessage: disable}
 		if err = json.Unmarshal(data, &integration); err == nil {
@@ -259,6 +285,16 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Line = append(result.Line, integration)
 		}
+	case "mqtt":
+		integration := definitions.MQTTIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.MQTT = append(result.MQTT, integration)
+		}
+	case "nats":
+		integration := definitions.NATSIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.NATS = append(result.NATS, integration)
+		}
 	case "opsgenie":
 		integration := definitions.OpsgenieIntegration{DisableResolveMessage: disable}
 		if err = json.Unmarshal(data, &integration); err == nil {
@@ -363,6 +399,21 @@ func (c contactPointsExtension) UpdateStructDescriptor(structDescriptor *jsonite
 		desc.Decoder = codec
 		desc.Encoder = codec
 	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.AMQPIntegration{}) ||
+		structDescriptor.Type == reflect2.TypeOf(definitions.NATSIntegration{}) {
+		codec := &numberAsStringCodec{}
+		desc := structDescriptor.GetField("MaxAlerts")
+		desc.Decoder = codec
+		desc.Encoder = codec
+	}
+	if structDescriptor.Type == reflect2.TypeOf(definitions.MQTTIntegration{}) {
+		codec := &numberAsStringCodec{}
+		for _, field := range []string{"MaxAlerts", "QoS"} {
+			desc := structDescriptor.GetField(field)
+			desc.Decoder = codec
+			desc.Encoder = codec
+		}
+	}
 	if structDescriptor.Type == reflect2.TypeOf(definitions.OnCallIntegration{}) {
 		codec := &numberAsStringCodec{ignoreError: true}
 		desc := structDescriptor.GetField("MaxAlerts")
@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/messagebus"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 )
 
@@ -89,6 +90,52 @@ func TestContactPointFromContactPointExports(t *testing.T) {
 		})
 	}
 
+	// integrations that publish to message brokers are not implemented in the alerting module
+	for integrationType, cfg := range messagebus.AllKnownConfigsForTesting {
+		t.Run(integrationType, func(t *testing.T) {
+			recCfg := &notify.APIReceiver{
+				ConfigReceiver: notify.ConfigReceiver{Name: "test-receiver"},
+				GrafanaIntegrations: notify.GrafanaIntegrations{
+					Integrations: []*notify.GrafanaIntegrationConfig{
+						cfg.GetRawNotifierConfig("test"),
+					},
+				},
+			}
+			parse := func(i *notify.GrafanaIntegrationConfig) any {
+				secrets := make(map[string][]byte, len(i.SecureSettings))
+				for key, value := range i.SecureSettings {
+					d, err := base64.StdEncoding.DecodeString(value)
+					require.NoError(t, err)
+					secrets[key] = d
+				}
+				decryptFn := receiversTesting.DecryptForTesting(secrets)
+				var (
+					result any
+					err    error
+				)
+				switch i.Type {
+				case messagebus.AMQPType:
+					result, err = messagebus.NewAMQPConfig(i.Settings, decryptFn)
+				case messagebus.NATSType:
+					result, err = messagebus.NewNATSConfig(i.Settings, decryptFn)
+				case messagebus.MQTTType:
+					result, err = messagebus.NewMQTTConfig(i.Settings, decryptFn)
+				}
+				require.NoError(t, err)
+				return result
+			}
+
+			result, err := ContactPointFromContactPointExport(getContactPointExport(t, recCfg))
+			require.NoError(t, err)
+
+			back, err := ContactPointToContactPointExport(result)
+			require.NoError(t, err)
+			require.Len(t, back.Integrations, 1)
+
+			require.Equal(t, parse(recCfg.Integrations[0]), parse(back.Integrations[0]))
+		})
+	}
+
 	t.Run("pushover optional numbers as string", func(t *testing.T) {
 		export := definitions.ContactPointExport{
 			Name: "test",
@@ -151,6 +151,14 @@ import (
 //     Responses:
 //       200: receiversResponse
 
+// swagger:route GET /api/alertmanager/grafana/config/api/v1/receivers/health alertmanager RouteGetGrafanaReceiversHealth
+//
+// Get the health of the integrations of all Grafana managed receivers
+//
+//     Responses:
+//       200: receiversHealthResponse
+//       404: NotFound
+
 // swagger:route POST /api/alertmanager/grafana/config/api/v1/receivers/test alertmanager RoutePostTestGrafanaReceivers
 //
 // Test Grafana managed receivers without saving them.
@@ -479,6 +487,52 @@ type ReceiversResponse struct {
 // swagger:model integration
 type Integration = amv2.Integration
 
+// swagger:response receiversHealthResponse
+type ReceiversHealthResponse struct {
+	// in:body
+	Body []ReceiverHealth
+}
+
+// ReceiverHealth is the health of the integrations of a receiver.
+// swagger:model
+type ReceiverHealth struct {
+	Name         string              `json:"name"`
+	Integrations []IntegrationHealth `json:"integrations"`
+}
+
+// IntegrationHealthStatus is the status of an integration.
+type IntegrationHealthStatus string
+
+const (
+	// IntegrationHealthUnknown is the status of integrations that have not sent notifications.
+	IntegrationHealthUnknown IntegrationHealthStatus = "unknown"
+	// IntegrationHealthHealthy is the status of integrations whose last notification succeeded.
+	IntegrationHealthHealthy IntegrationHealthStatus = "healthy"
+	// IntegrationHealthFailing is the status of integrations whose last notification failed.
+	IntegrationHealthFailing IntegrationHealthStatus = "failing"
+)
+
+// IntegrationHealth is the health of an integration since Grafana was started.
+// swagger:model
+type IntegrationHealth struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	// Index is the index of the integration among the integrations of the same type in the receiver.
+	Index int `json:"index"`
+	// FallbackAfterFailures is greater than zero if the integration is a fallback integration.
+	FallbackAfterFailures int                     `json:"fallbackAfterFailures,omitempty"`
+	Status                IntegrationHealthStatus `json:"status"`
+	// ConsecutiveFailures is the number of failed notifications since the last successful notification.
+	ConsecutiveFailures int   `json:"consecutiveFailures"`
+	TotalSuccesses      int64 `json:"totalSuccesses"`
+	TotalFailures       int64 `json:"totalFailures"`
+	// FailedOver is true if notifications are currently sent to the fallback integrations of the receiver.
+	FailedOver  bool       `json:"failedOver"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
 // swagger:parameters RouteGetAMAlerts RouteGetAMAlertGroups RouteGetGrafanaAMAlerts RouteGetGrafanaAMAlertGroups
 type AlertsParams struct {
 
@@ -1108,6 +1162,7 @@ type GettableGrafanaReceiver struct {
 	Settings              RawMessage      `json:"settings,omitempty"`
 	SecureFields          map[string]bool `json:"secureFields"`
 	Provenance            Provenance      `json:"provenance,omitempty"`
+	FallbackAfterFailures int             `json:"fallbackAfterFailures,omitempty"`
 }
 
 type PostableGrafanaReceiver struct {
@@ -1117,6 +1172,10 @@ type PostableGrafanaReceiver struct {
 	DisableResolveMessage bool              `json:"disableResolveMessage"`
 	Settings              RawMessage        `json:"settings,omitempty"`
 	SecureSettings        map[string]string `json:"secureSettings"`
+	// FallbackAfterFailures makes the integration a fallback for the other integrations in the
+	// same receiver. A fallback integration is not sent notifications unless another integration
+	// in the receiver has failed to send this many notifications in a row.
+	FallbackAfterFailures int `json:"fallbackAfterFailures,omitempty"`
 }
 
 type ReceiverType int
@@ -1245,6 +1304,9 @@ func (r *PostableApiReceiver) UnmarshalJSON(b []byte) error {
 		if len(r.VictorOpsConfigs) > 0 {
 			return fmt.Errorf("cannot have both Alertmanager VictorOpsConfigs & Grafana receivers together")
 		}
+		if err := r.ValidateFallbacks(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -151,3 +151,24 @@ func (mt *MuteTimeInterval) Validate() error {
 	}
 	return nil
 }
+
+// ValidateFallbacks ensures that fallback integrations have a valid number of failures
+// and that a receiver with fallback integrations has at least one integration that is
+// not a fallback.
+func (r *PostableApiReceiver) ValidateFallbacks() error {
+	var hasFallback, hasPrimary bool
+	for _, gr := range r.GrafanaManagedReceivers {
+		if gr.FallbackAfterFailures < 0 {
+			return fmt.Errorf("integration %q in receiver %q has a negative fallbackAfterFailures", gr.Name, r.Name)
+		}
+		if gr.FallbackAfterFailures > 0 {
+			hasFallback = true
+		} else {
+			hasPrimary = true
+		}
+	}
+	if hasFallback && !hasPrimary {
+		return fmt.Errorf("receiver %q has fallback integrations but no integrations to fall back from", r.Name)
+	}
+	return nil
+}
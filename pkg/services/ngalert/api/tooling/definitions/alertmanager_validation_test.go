@@ -1,6 +1,7 @@
 package definitions
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -479,3 +480,66 @@ func TestValidateNotificationTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateFallbacks(t *testing.T) {
+	tc := []struct {
+		name      string
+		receivers []*PostableGrafanaReceiver
+		expError  string
+	}{
+		{
+			name: "no fallback integrations",
+			receivers: []*PostableGrafanaReceiver{
+				{Name: "a", Type: "email"},
+				{Name: "b", Type: "webhook"},
+			},
+		},
+		{
+			name: "fallback integration",
+			receivers: []*PostableGrafanaReceiver{
+				{Name: "a", Type: "email"},
+				{Name: "b", Type: "webhook", FallbackAfterFailures: 3},
+			},
+		},
+		{
+			name: "negative number of failures",
+			receivers: []*PostableGrafanaReceiver{
+				{Name: "a", Type: "email"},
+				{Name: "b", Type: "webhook", FallbackAfterFailures: -1},
+			},
+			expError: "integration \"b\" in receiver \"test\" has a negative fallbackAfterFailures",
+		},
+		{
+			name: "only fallback integrations",
+			receivers: []*PostableGrafanaReceiver{
+				{Name: "a", Type: "email", FallbackAfterFailures: 1},
+			},
+			expError: "receiver \"test\" has fallback integrations but no integrations to fall back from",
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			r := PostableApiReceiver{
+				Receiver:                 config.Receiver{Name: "test"},
+				PostableGrafanaReceivers: PostableGrafanaReceivers{GrafanaManagedReceivers: tt.receivers},
+			}
+			err := r.ValidateFallbacks()
+			if tt.expError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expError)
+		})
+	}
+}
+
+func TestPostableApiReceiverUnmarshalValidatesFallbacks(t *testing.T) {
+	var r PostableApiReceiver
+	err := json.Unmarshal([]byte(`{"name": "test", "grafana_managed_receiver_configs": [{"name": "a", "type": "email", "fallbackAfterFailures": 2}]}`), &r)
+	require.EqualError(t, err, "receiver \"test\" has fallback integrations but no integrations to fall back from")
+
+	r = PostableApiReceiver{}
+	err = json.Unmarshal([]byte(`{"name": "test", "grafana_managed_receiver_configs": [{"name": "a", "type": "email"}, {"name": "b", "type": "webhook", "fallbackAfterFailures": 2}]}`), &r)
+	require.NoError(t, err)
+}
@@ -2,7 +2,8 @@ package definitions
 
 // This file contains API models of integrations that are supported by Grafana Managed Alerts.
 // The models below match the Config models described in the module github.com/grafana/alerting, package 'receivers/**'
-// as well as models described in Grafana Terraform Provider.
+// as well as models described in Grafana Terraform Provider. The models of integrations that publish to message brokers (AMQP, MQTT and NATS)
+// match the Config models in the package 'pkg/services/ngalert/notifier/messagebus'.
 // Currently, they are used only for export to HCL but in the future we expand their scope.
 // The consistency between  models in the alerting module and this file is enforced by unit-tests.
 
@@ -16,6 +17,25 @@ package definitions
 // A string that contain sensitive information.
 type Secret string // TODO implement masking fields when models are used
 
+type AMQPIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL        string `json:"url" yaml:"url" hcl:"url"`
+	RoutingKey string `json:"routingKey" yaml:"routingKey" hcl:"routing_key"`
+
+	Exchange  *string `json:"exchange,omitempty" yaml:"exchange,omitempty" hcl:"exchange"`
+	Username  *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password  *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	MaxAlerts *int64  `json:"maxAlerts,omitempty" yaml:"maxAlerts,omitempty" hcl:"max_alerts"`
+	Title     *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message   *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+
+	TLSSkipVerify        *bool   `json:"tlsSkipVerify,omitempty" yaml:"tlsSkipVerify,omitempty" hcl:"tls_skip_verify"`
+	TLSCACertificate     *string `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty" hcl:"tls_client_key"`
+}
+
 type AlertmanagerIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"` // TODO change when https://github.com/hashicorp/hcl/issues/290 is fixed
 
@@ -85,6 +105,47 @@ type LineIntegration struct {
 	Description *string `json:"description,omitempty" yaml:"description,omitempty" hcl:"description"`
 }
 
+type MQTTIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	BrokerURL string `json:"brokerUrl" yaml:"brokerUrl" hcl:"broker_url"`
+	Topic     string `json:"topic" yaml:"topic" hcl:"topic"`
+
+	ClientID  *string `json:"clientId,omitempty" yaml:"clientId,omitempty" hcl:"client_id"`
+	Username  *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password  *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	QoS       *int64  `json:"qos,omitempty" yaml:"qos,omitempty" hcl:"qos"`
+	Retain    *bool   `json:"retain,omitempty" yaml:"retain,omitempty" hcl:"retain"`
+	MaxAlerts *int64  `json:"maxAlerts,omitempty" yaml:"maxAlerts,omitempty" hcl:"max_alerts"`
+	Title     *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message   *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+
+	TLSSkipVerify        *bool   `json:"tlsSkipVerify,omitempty" yaml:"tlsSkipVerify,omitempty" hcl:"tls_skip_verify"`
+	TLSCACertificate     *string `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty" hcl:"tls_client_key"`
+}
+
+type NATSIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL     string `json:"url" yaml:"url" hcl:"url"`
+	Subject string `json:"subject" yaml:"subject" hcl:"subject"`
+
+	JetStream *bool   `json:"jetStream,omitempty" yaml:"jetStream,omitempty" hcl:"jet_stream"`
+	Username  *string `json:"username,omitempty" yaml:"username,omitempty" hcl:"username"`
+	Password  *Secret `json:"password,omitempty" yaml:"password,omitempty" hcl:"password"`
+	Token     *Secret `json:"token,omitempty" yaml:"token,omitempty" hcl:"token"`
+	MaxAlerts *int64  `json:"maxAlerts,omitempty" yaml:"maxAlerts,omitempty" hcl:"max_alerts"`
+	Title     *string `json:"title,omitempty" yaml:"title,omitempty" hcl:"title"`
+	Message   *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
+
+	TLSSkipVerify        *bool   `json:"tlsSkipVerify,omitempty" yaml:"tlsSkipVerify,omitempty" hcl:"tls_skip_verify"`
+	TLSCACertificate     *string `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate *string `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey         *Secret `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty" hcl:"tls_client_key"`
+}
+
 type OnCallIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -269,6 +330,7 @@ type WecomIntegration struct {
 
 type ContactPoint struct {
 	Name         string                    `json:"name" yaml:"name" hcl:"name"`
+	AMQP         []AMQPIntegration         `json:"amqp" yaml:"amqp" hcl:"amqp,block"`
 	Alertmanager []AlertmanagerIntegration `json:"alertmanager" yaml:"alertmanager" hcl:"alertmanager,block"`
 	Dingding     []DingdingIntegration     `json:"dingding" yaml:"dingding" hcl:"dingding,block"`
 	Discord      []DiscordIntegration      `json:"discord" yaml:"discord" hcl:"discord,block"`
@@ -276,6 +338,8 @@ type ContactPoint struct {
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
+	MQTT         []MQTTIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
+	NATS         []NATSIntegration         `json:"nats" yaml:"nats" hcl:"nats,block"`
 	Opsgenie     []OpsgenieIntegration     `json:"opsgenie" yaml:"opsgenie" hcl:"opsgenie,block"`
 	Pagerduty    []PagerdutyIntegration    `json:"pagerduty" yaml:"pagerduty" hcl:"pagerduty,block"`
 	OnCall       []OnCallIntegration       `json:"oncall" yaml:"oncall" hcl:"oncall,block"`
@@ -412,25 +412,60 @@ type PostableGrafanaRule struct {
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
 	IsPaused     *bool               `json:"is_paused" yaml:"is_paused"`
+	// NotificationSettings are optional settings to send notifications for the alerts of the rule directly to
+	// a contact point instead of routing them through the notification policy tree.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []AlertQuery        `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
-	Provenance      Provenance          `json:"provenance,omitempty" yaml:"provenance,omitempty"`
-	IsPaused        bool                `json:"is_paused" yaml:"is_paused"`
+	ID                   int64                          `json:"id" yaml:"id"`
+	OrgID                int64                          `json:"orgId" yaml:"orgId"`
+	Title                string                         `json:"title" yaml:"title"`
+	Condition            string                         `json:"condition" yaml:"condition"`
+	Data                 []AlertQuery                   `json:"data" yaml:"data"`
+	Updated              time.Time                      `json:"updated" yaml:"updated"`
+	IntervalSeconds      int64                          `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version              int64                          `json:"version" yaml:"version"`
+	UID                  string                         `json:"uid" yaml:"uid"`
+	NamespaceUID         string                         `json:"namespace_uid" yaml:"namespace_uid"`
+	RuleGroup            string                         `json:"rule_group" yaml:"rule_group"`
+	NoDataState          NoDataState                    `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState         ExecutionErrorState            `json:"exec_err_state" yaml:"exec_err_state"`
+	Provenance           Provenance                     `json:"provenance,omitempty" yaml:"provenance,omitempty"`
+	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
+}
+
+// AlertRuleNotificationSettings are the settings to send notifications for the alerts of a rule directly to a
+// contact point. Alerts are always grouped by folder and alert rule name, and timings that are not specified are
+// inherited from the default notification policy.
+// swagger:model
+type AlertRuleNotificationSettings struct {
+	// Name of the receiver to send notifications to.
+	// required: true
+	// example: grafana-default-email
+	Receiver string `json:"receiver" yaml:"receiver"`
+
+	// Additional labels to group alerts by. Use the special label '...' to group alerts by all labels.
+	// example: ["namespace", "pod"]
+	GroupBy []string `json:"group_by,omitempty" yaml:"group_by,omitempty"`
+
+	// How long to initially wait to send a notification for a group of alerts.
+	// example: 30s
+	GroupWait *model.Duration `json:"group_wait,omitempty" yaml:"group_wait,omitempty"`
+
+	// How long to wait before sending a notification about new alerts that are added to a group of alerts.
+	// example: 5m
+	GroupInterval *model.Duration `json:"group_interval,omitempty" yaml:"group_interval,omitempty"`
+
+	// How long to wait before sending a notification again if it has already been sent successfully.
+	// example: 4h
+	RepeatInterval *model.Duration `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty"`
+
+	// Names of the mute time intervals to apply.
+	// example: ["maintenance"]
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
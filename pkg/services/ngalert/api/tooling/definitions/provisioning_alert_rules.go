@@ -156,6 +156,9 @@ type ProvisionedAlertRule struct {
 	Provenance Provenance `json:"provenance,omitempty"`
 	// example: false
 	IsPaused bool `json:"isPaused"`
+	// NotificationSettings are optional settings to send notifications for the alerts of the rule directly to
+	// a contact point instead of routing them through the notification policy tree.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
 }
 
 // swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -250,6 +253,18 @@ type AlertRuleExport struct {
 	Annotations *map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
 	Labels      *map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused    bool               `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
+
+	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
+}
+
+// AlertRuleNotificationSettingsExport is the provisioned export of models.NotificationSettings.
+type AlertRuleNotificationSettingsExport struct {
+	Receiver          string   `json:"receiver" yaml:"receiver" hcl:"contact_point"`
+	GroupBy           []string `json:"group_by,omitempty" yaml:"group_by,omitempty" hcl:"group_by"`
+	GroupWait         *string  `json:"group_wait,omitempty" yaml:"group_wait,omitempty" hcl:"group_wait"`
+	GroupInterval     *string  `json:"group_interval,omitempty" yaml:"group_interval,omitempty" hcl:"group_interval"`
+	RepeatInterval    *string  `json:"repeat_interval,omitempty" yaml:"repeat_interval,omitempty" hcl:"repeat_interval"`
+	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty" yaml:"mute_time_intervals,omitempty" hcl:"mute_timings"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -105,6 +105,12 @@ type EmbeddedContactPoint struct {
 	Settings *simplejson.Json `json:"settings" binding:"required"`
 	// example: false
 	DisableResolveMessage bool `json:"disableResolveMessage"`
+	// FallbackAfterFailures makes the contact point a fallback for the other contact
+	// points with the same name. It is sent notifications only after another contact point
+	// with the same name has failed to send this many notifications in a row.
+	// minimum: 0
+	// example: 3
+	FallbackAfterFailures int `json:"fallbackAfterFailures,omitempty"`
 	// readonly: true
 	Provenance string `json:"provenance,omitempty"`
 }
@@ -599,6 +599,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 		evalResults = append(evalResults, Result{
 			State:              NoData,
 			Instance:           labels,
+			Results:            execResults.Results,
 			EvaluatedAt:        ts,
 			EvaluationDuration: time.Since(ts),
 		})
@@ -667,6 +668,7 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 
 		r := Result{
 			Instance:           f.Fields[0].Labels,
+			Results:            execResults.Results,
 			EvaluatedAt:        ts,
 			EvaluationDuration: time.Since(ts),
 			EvaluationString:   extractEvalString(f),
@@ -916,6 +916,11 @@ func TestEvaluate(t *testing.T) {
 				for i := range results {
 					tc.expected[i].EvaluatedAt = results[i].EvaluatedAt
 					tc.expected[i].EvaluationDuration = results[i].EvaluationDuration
+					// The frames of the queries and expressions are kept in each result.
+					for refID, res := range tc.resp.Responses {
+						assert.Equal(t, res.Frames, results[i].Results[refID])
+					}
+					tc.expected[i].Results = results[i].Results
 					assert.Equal(t, tc.expected[i], results[i])
 				}
 			}
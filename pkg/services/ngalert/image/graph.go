@@ -0,0 +1,416 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	defaultGraphWidth  = 1000
+	defaultGraphHeight = 500
+
+	// graphMaxSeries is the maximum number of series drawn in a graph. Alert rules
+	// with many dimensions would otherwise produce an unreadable image.
+	graphMaxSeries = 20
+)
+
+var (
+	// ErrNoGraphData is returned when the results of the alert rule do not contain
+	// time series that can be drawn.
+	ErrNoGraphData = errors.New("no time series data to draw")
+
+	graphBackground = color.RGBA{R: 0x18, G: 0x1b, B: 0x1f, A: 0xff}
+	graphGrid       = color.RGBA{R: 0x2c, G: 0x32, B: 0x35, A: 0xff}
+	graphText       = color.RGBA{R: 0xcc, G: 0xcc, B: 0xdc, A: 0xff}
+	graphThreshold  = color.RGBA{R: 0xf2, G: 0x49, B: 0x5c, A: 0xff}
+
+	// graphPalette is the classic palette of series colors in Grafana.
+	graphPalette = []color.RGBA{
+		{R: 0x73, G: 0xbf, B: 0x69, A: 0xff},
+		{R: 0xf2, G: 0xcc, B: 0x0c, A: 0xff},
+		{R: 0x8a, G: 0xb8, B: 0xff, A: 0xff},
+		{R: 0xff, G: 0x78, B: 0x0a, A: 0xff},
+		{R: 0xf2, G: 0x49, B: 0x5c, A: 0xff},
+		{R: 0x57, G: 0x94, B: 0xf2, A: 0xff},
+		{R: 0xb8, G: 0x77, B: 0xd9, A: 0xff},
+		{R: 0x70, G: 0x5d, B: 0xa0, A: 0xff},
+		{R: 0x37, G: 0x87, B: 0x2d, A: 0xff},
+		{R: 0xfa, G: 0xde, B: 0x2a, A: 0xff},
+	}
+)
+
+// GraphSeries is a single line in a graph.
+type GraphSeries struct {
+	Name   string
+	Times  []time.Time
+	Values []float64
+}
+
+// Graph contains everything needed to draw a graph of the results of an alert rule.
+type Graph struct {
+	Title      string
+	Series     []GraphSeries
+	Thresholds []float64
+}
+
+// GraphRenderer draws graphs of the query results of alert rules without the
+// image renderer. It can be used when the image renderer is not installed,
+// or when the alert rule is not associated with a dashboard panel.
+type GraphRenderer struct {
+	imagesDir string
+	width     int
+	height    int
+}
+
+// NewGraphRenderer returns a new GraphRenderer that saves images in imagesDir.
+func NewGraphRenderer(imagesDir string) *GraphRenderer {
+	return &GraphRenderer{
+		imagesDir: imagesDir,
+		width:     defaultGraphWidth,
+		height:    defaultGraphHeight,
+	}
+}
+
+// Render draws a graph of the results and saves it as a PNG in the images directory.
+// It returns ErrNoGraphData if the results do not contain any time series.
+func (g *GraphRenderer) Render(_ context.Context, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error) {
+	graph := NewGraph(r, results)
+	if len(graph.Series) == 0 {
+		return nil, ErrNoGraphData
+	}
+
+	if err := os.MkdirAll(g.imagesDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create images directory %q: %w", g.imagesDir, err)
+	}
+
+	rand, err := util.GetRandomString(20)
+	if err != nil {
+		return nil, err
+	}
+	path, err := filepath.Abs(filepath.Join(g.imagesDir, rand+".png"))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if err := png.Encode(f, graph.Draw(g.width, g.height)); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return &models.Image{Path: path}, nil
+}
+
+// NewGraph returns a graph of the time series returned from the queries of the alert
+// rule, with the thresholds of its threshold expressions and classic conditions.
+// Results of expressions are not drawn, as reduce, math and threshold expressions
+// return numbers rather than time series.
+func NewGraph(r *models.AlertRule, results map[string]data.Frames) Graph {
+	graph := Graph{Title: r.Title}
+
+	refIDs := make([]string, 0, len(r.Data))
+	for _, q := range r.Data {
+		if expr.NodeTypeFromDatasourceUID(q.DatasourceUID) == expr.TypeDatasourceNode {
+			refIDs = append(refIDs, q.RefID)
+		} else {
+			graph.Thresholds = append(graph.Thresholds, thresholdsFromModel(q.Model)...)
+		}
+	}
+	sort.Strings(refIDs)
+
+	for _, refID := range refIDs {
+		for _, frame := range results[refID] {
+			for _, s := range seriesFromFrame(refID, frame) {
+				if len(graph.Series) >= graphMaxSeries {
+					return graph
+				}
+				graph.Series = append(graph.Series, s)
+			}
+		}
+	}
+
+	return graph
+}
+
+// thresholdsFromModel returns the thresholds of threshold expressions and classic
+// conditions. It returns nil for all other queries and expressions.
+func thresholdsFromModel(model json.RawMessage) []float64 {
+	var m struct {
+		Type       string `json:"type"`
+		Conditions []struct {
+			Evaluator expr.ConditionEvalJSON `json:"evaluator"`
+		} `json:"conditions"`
+	}
+	if err := json.Unmarshal(model, &m); err != nil {
+		return nil
+	}
+
+	if t, err := expr.ParseCommandType(m.Type); err != nil || (t != expr.TypeThreshold && t != expr.TypeClassicConditions) {
+		return nil
+	}
+
+	var thresholds []float64
+	for _, c := range m.Conditions {
+		thresholds = append(thresholds, c.Evaluator.Params...)
+	}
+	return thresholds
+}
+
+// seriesFromFrame returns a series for each numeric field in a frame with a time field.
+// It returns nil if the frame does not have a time field.
+func seriesFromFrame(refID string, frame *data.Frame) []GraphSeries {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) == 0 {
+		return nil
+	}
+	timeField := frame.Fields[timeIndices[0]]
+
+	var series []GraphSeries
+	for _, field := range frame.Fields {
+		if !field.Type().Numeric() {
+			continue
+		}
+
+		s := GraphSeries{
+			Name:   seriesName(refID, field),
+			Times:  make([]time.Time, 0, field.Len()),
+			Values: make([]float64, 0, field.Len()),
+		}
+		for i := 0; i < field.Len() && i < timeField.Len(); i++ {
+			t, ok := timeField.ConcreteAt(i)
+			if !ok {
+				continue
+			}
+			v, err := field.NullableFloatAt(i)
+			if err != nil {
+				continue
+			}
+			value := math.NaN()
+			if v != nil {
+				value = *v
+			}
+			s.Times = append(s.Times, t.(time.Time))
+			s.Values = append(s.Values, value)
+		}
+		if len(s.Times) > 0 {
+			series = append(series, s)
+		}
+	}
+
+	return series
+}
+
+func seriesName(refID string, field *data.Field) string {
+	if field.Config != nil && field.Config.DisplayNameFromDS != "" {
+		return field.Config.DisplayNameFromDS
+	}
+	if len(field.Labels) > 0 {
+		return field.Labels.String()
+	}
+	if field.Name != "" {
+		return field.Name
+	}
+	return refID
+}
+
+// Draw draws the graph into a new image of the given width and height.
+func (g Graph) Draw(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: graphBackground}, image.Point{}, draw.Src)
+
+	const (
+		marginLeft   = 70
+		marginRight  = 20
+		marginTop    = 30
+		marginBottom = 30
+		legendHeight = 16
+		gridLines    = 5
+	)
+
+	legendRows := len(g.Series)
+	if maxRows := (height - marginTop - marginBottom) / (2 * legendHeight); legendRows > maxRows {
+		legendRows = maxRows
+	}
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom-legendRows*legendHeight)
+
+	drawText(img, g.Title, 10, 18, graphText)
+
+	minT, maxT, minV, maxV := g.bounds()
+
+	x := func(t time.Time) int {
+		if !maxT.After(minT) {
+			return plot.Min.X + plot.Dx()/2
+		}
+		return plot.Min.X + int(float64(plot.Dx())*float64(t.Sub(minT))/float64(maxT.Sub(minT)))
+	}
+	y := func(v float64) int {
+		return plot.Max.Y - int(float64(plot.Dy())*(v-minV)/(maxV-minV))
+	}
+
+	// Draw the horizontal grid with the value of each line
+	for i := 0; i <= gridLines; i++ {
+		v := minV + (maxV-minV)*float64(i)/gridLines
+		lineY := y(v)
+		drawLine(img, plot.Min.X, lineY, plot.Max.X, lineY, graphGrid, 0)
+		drawText(img, formatGraphValue(v), 5, lineY+4, graphText)
+	}
+
+	// Draw the time of the first and last data point underneath the x-axis
+	drawLine(img, plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y, graphGrid, 0)
+	drawText(img, minT.UTC().Format("2006-01-02 15:04:05"), plot.Min.X, plot.Max.Y+18, graphText)
+	last := maxT.UTC().Format("2006-01-02 15:04:05 MST")
+	drawText(img, last, plot.Max.X-textWidth(last), plot.Max.Y+18, graphText)
+
+	for i, s := range g.Series {
+		c := graphPalette[i%len(graphPalette)]
+		hasPrev, prevX, prevY := false, 0, 0
+		for j, t := range s.Times {
+			if math.IsNaN(s.Values[j]) || math.IsInf(s.Values[j], 0) {
+				hasPrev = false
+				continue
+			}
+			nextX, nextY := x(t), y(s.Values[j])
+			if hasPrev {
+				drawLine(img, prevX, prevY, nextX, nextY, c, 0)
+			} else {
+				img.Set(nextX, nextY, c)
+			}
+			hasPrev, prevX, prevY = true, nextX, nextY
+		}
+	}
+
+	for _, threshold := range g.Thresholds {
+		lineY := y(threshold)
+		drawLine(img, plot.Min.X, lineY, plot.Max.X, lineY, graphThreshold, 6)
+	}
+
+	for i := 0; i < legendRows; i++ {
+		rowY := plot.Max.Y + marginBottom + i*legendHeight
+		c := graphPalette[i%len(graphPalette)]
+		draw.Draw(img, image.Rect(plot.Min.X, rowY-8, plot.Min.X+12, rowY-2), &image.Uniform{C: c}, image.Point{}, draw.Src)
+		drawText(img, g.Series[i].Name, plot.Min.X+18, rowY, graphText)
+	}
+
+	return img
+}
+
+// bounds returns the minimum and maximum time and value of the graph. The value range
+// includes all thresholds and is padded so lines are not drawn on the edge of the graph.
+func (g Graph) bounds() (time.Time, time.Time, float64, float64) {
+	var (
+		minT, maxT time.Time
+		minV       = math.Inf(1)
+		maxV       = math.Inf(-1)
+	)
+	for _, s := range g.Series {
+		for i, t := range s.Times {
+			if minT.IsZero() || t.Before(minT) {
+				minT = t
+			}
+			if maxT.IsZero() || t.After(maxT) {
+				maxT = t
+			}
+			if v := s.Values[i]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+				minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+			}
+		}
+	}
+	for _, v := range g.Thresholds {
+		minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+	}
+
+	if math.IsInf(minV, 0) || math.IsInf(maxV, 0) {
+		minV, maxV = 0, 1
+	}
+	padding := (maxV - minV) * 0.1
+	if padding == 0 {
+		padding = math.Max(math.Abs(maxV)*0.1, 1)
+	}
+	return minT, maxT, minV - padding, maxV + padding
+}
+
+// drawLine draws a line from (x0, y0) to (x1, y1) using Bresenham's algorithm.
+// If dash is greater than 0 the line is drawn with dashes of that length.
+func drawLine(img draw.Image, x0, y0, x1, y1 int, c color.Color, dash int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for n := 0; ; n++ {
+		if dash <= 0 || (n/dash)%2 == 0 {
+			img.Set(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func drawText(img draw.Image, s string, x, y int, c color.Color) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+func textWidth(s string) int {
+	return font.MeasureString(basicfont.Face7x13, s).Round()
+}
+
+func formatGraphValue(v float64) string {
+	if math.Abs(v) >= 1e6 || (v != 0 && math.Abs(v) < 1e-3) {
+		return strconv.FormatFloat(v, 'e', 2, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
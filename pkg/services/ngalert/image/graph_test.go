@@ -0,0 +1,138 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"image/png"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestNewGraph(t *testing.T) {
+	now := time.Now()
+
+	rule := models.AlertRule{
+		Title: "test",
+		Data: []models.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "datasource",
+			Model:         json.RawMessage(`{}`),
+		}, {
+			RefID:         "B",
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"type":"reduce","expression":"A","reducer":"last"}`),
+		}, {
+			RefID:         "C",
+			DatasourceUID: expr.DatasourceUID,
+			Model:         json.RawMessage(`{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"within_range","params":[5,10]}}]}`),
+		}},
+	}
+
+	results := map[string]data.Frames{
+		"A": {
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+				data.NewField("value", data.Labels{"instance": "foo"}, []*float64{util.Pointer(1.0), nil}),
+			),
+			// frames without a time field cannot be drawn
+			data.NewFrame("",
+				data.NewField("value", nil, []float64{1}),
+			),
+		},
+		"B": {
+			data.NewFrame("",
+				data.NewField("", data.Labels{"instance": "foo"}, []*float64{util.Pointer(1.0)}),
+			),
+		},
+	}
+
+	g := NewGraph(&rule, results)
+	assert.Equal(t, "test", g.Title)
+	assert.Equal(t, []float64{5, 10}, g.Thresholds)
+	require.Len(t, g.Series, 1)
+	assert.Equal(t, "instance=foo", g.Series[0].Name)
+	assert.Equal(t, []time.Time{now.Add(-time.Minute), now}, g.Series[0].Times)
+	require.Len(t, g.Series[0].Values, 2)
+	assert.Equal(t, 1.0, g.Series[0].Values[0])
+	assert.True(t, math.IsNaN(g.Series[0].Values[1]))
+}
+
+func TestThresholdsFromModel(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    string
+		expected []float64
+	}{{
+		name:     "threshold expression",
+		model:    `{"type":"threshold","expression":"B","conditions":[{"evaluator":{"type":"gt","params":[10]}}]}`,
+		expected: []float64{10},
+	}, {
+		name:     "classic condition",
+		model:    `{"type":"classic_conditions","conditions":[{"evaluator":{"type":"gt","params":[10]}},{"evaluator":{"type":"lt","params":[1]}}]}`,
+		expected: []float64{10, 1},
+	}, {
+		name:  "math expression",
+		model: `{"type":"math","expression":"$A > 10"}`,
+	}, {
+		name:  "invalid model",
+		model: `{`,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, thresholdsFromModel(json.RawMessage(test.model)))
+		})
+	}
+}
+
+func TestGraphRenderer(t *testing.T) {
+	r := NewGraphRenderer(t.TempDir())
+
+	rule := models.AlertRule{
+		Title: "test",
+		Data: []models.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "datasource",
+			Model:         json.RawMessage(`{}`),
+		}},
+	}
+
+	t.Run("image is drawn and saved to disk", func(t *testing.T) {
+		now := time.Now()
+		results := map[string]data.Frames{
+			"A": {data.NewFrame("",
+				data.NewField("time", nil, []time.Time{now.Add(-2 * time.Minute), now.Add(-time.Minute), now}),
+				data.NewField("value", nil, []float64{1, 5, 3}),
+			)},
+		}
+
+		image, err := r.Render(context.Background(), &rule, results)
+		require.NoError(t, err)
+		require.NotNil(t, image)
+
+		f, err := os.Open(image.Path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+
+		cfg, err := png.DecodeConfig(f)
+		require.NoError(t, err)
+		assert.Equal(t, defaultGraphWidth, cfg.Width)
+		assert.Equal(t, defaultGraphHeight, cfg.Height)
+	})
+
+	t.Run("ErrNoGraphData is returned when there are no time series", func(t *testing.T) {
+		image, err := r.Render(context.Background(), &rule, nil)
+		assert.ErrorIs(t, err, ErrNoGraphData)
+		assert.Nil(t, image)
+	})
+}
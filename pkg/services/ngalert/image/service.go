@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/singleflight"
 
@@ -38,16 +39,20 @@ func ProvideDeleteExpiredService(store *store.DBstore) *DeleteExpiredService {
 }
 
 type ImageService interface {
-	// NewImage returns a new image for the alert instance.
-	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
+	// NewImage returns a new image for the alert instance. The results are the
+	// results of the queries and expressions from the evaluation of the alert rule.
+	NewImage(ctx context.Context, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error)
 }
 
 // ScreenshotImageService takes screenshots of the alert rule and saves the
 // image in the store. The image contains a unique token that can be passed
 // as an annotation or label to the Alertmanager. This service cannot take
 // screenshots of alert rules that are not associated with a dashboard panel.
+// If graphs are enabled, a graph of the query results is drawn instead when
+// a screenshot cannot be taken.
 type ScreenshotImageService struct {
 	cache             CacheService
+	graphs            *GraphRenderer
 	limiter           screenshot.RateLimiter
 	logger            log.Logger
 	screenshots       screenshot.ScreenshotService
@@ -65,9 +70,11 @@ func NewScreenshotImageService(
 	screenshots screenshot.ScreenshotService,
 	screenshotTimeout time.Duration,
 	store store.ImageStore,
-	uploads *UploadingService) ImageService {
+	uploads *UploadingService,
+	graphs *GraphRenderer) ImageService {
 	return &ScreenshotImageService{
 		cache:             cache,
+		graphs:            graphs,
 		limiter:           limiter,
 		logger:            logger,
 		screenshots:       screenshots,
@@ -87,6 +94,7 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 		screenshots       screenshot.ScreenshotService = &screenshot.ScreenshotUnavailableService{}
 		screenshotTimeout time.Duration                = 0
 		uploads           *UploadingService            = nil
+		graphs            *GraphRenderer               = nil
 	)
 
 	// If screenshots are enabled
@@ -95,19 +103,25 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 		limiter = screenshot.NewTokenRateLimiter(cfg.UnifiedAlerting.Screenshots.MaxConcurrentScreenshots)
 		screenshots = screenshot.NewHeadlessScreenshotService(ds, rs, r)
 		screenshotTimeout = cfg.UnifiedAlerting.Screenshots.CaptureTimeout
+	}
 
-		// Image uploading is an optional feature
-		if cfg.UnifiedAlerting.Screenshots.UploadExternalImageStorage {
-			m, err := imguploader.NewImageUploader()
-			if err != nil {
-				return nil, fmt.Errorf("failed to initialize uploading screenshot service: %w", err)
-			}
-			uploads = NewUploadingService(m, r)
+	// If graphs are enabled
+	if cfg.UnifiedAlerting.Screenshots.CaptureGraphs {
+		graphs = NewGraphRenderer(cfg.ImagesDir)
+	}
+
+	// Image uploading is an optional feature
+	if (cfg.UnifiedAlerting.Screenshots.Capture || cfg.UnifiedAlerting.Screenshots.CaptureGraphs) &&
+		cfg.UnifiedAlerting.Screenshots.UploadExternalImageStorage {
+		m, err := imguploader.NewImageUploader()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize uploading screenshot service: %w", err)
 		}
+		uploads = NewUploadingService(m, r)
 	}
 
 	return NewScreenshotImageService(cache, limiter, log.New("ngalert.image"),
-		screenshots, screenshotTimeout, db, uploads), nil
+		screenshots, screenshotTimeout, db, uploads, graphs), nil
 }
 
 // NewImage returns a screenshot of the alert rule or an error.
@@ -117,7 +131,69 @@ func NewScreenshotImageServiceFromCfg(cfg *setting.Cfg, db *store.DBstore, ds da
 // or the dashboard does not exist, a models.ErrNoDashboard error is returned. If the
 // alert rule has a Dashboard UID and the dashboard exists, but does not have a
 // Panel ID in its annotations then a models.ErrNoPanel error is returned.
-func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error) {
+//
+// If graphs are enabled and a screenshot cannot be taken, either because the alert
+// rule is not associated with a dashboard panel or because screenshots are unavailable,
+// a graph of the results is returned instead. If the results do not contain time
+// series then the original error is returned.
+func (s *ScreenshotImageService) NewImage(ctx context.Context, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error) {
+	image, err := s.newScreenshot(ctx, r)
+	if err == nil || s.graphs == nil || !canDrawGraph(err) {
+		return image, err
+	}
+
+	logger := s.logger.FromContext(ctx)
+	logger.Debug("Cannot take screenshot, drawing graph instead", "reason", err)
+
+	image, graphErr := s.newGraph(ctx, r, results)
+	if graphErr != nil {
+		if errors.Is(graphErr, ErrNoGraphData) {
+			return nil, err
+		}
+		return nil, graphErr
+	}
+	return image, nil
+}
+
+// canDrawGraph returns true if a graph should be drawn when a screenshot
+// failed with the error.
+func canDrawGraph(err error) bool {
+	return errors.Is(err, models.ErrNoDashboard) ||
+		errors.Is(err, models.ErrNoPanel) ||
+		errors.Is(err, screenshot.ErrScreenshotsUnavailable) ||
+		errors.Is(err, rendering.ErrRenderUnavailable)
+}
+
+// newGraph draws a graph of the results, uploads it if uploading is enabled, and
+// saves it in the store. Graphs are not cached as the results are different for
+// each evaluation.
+func (s *ScreenshotImageService) newGraph(ctx context.Context, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error) {
+	logger := s.logger.FromContext(ctx)
+
+	image, err := s.graphs.Render(ctx, r, results)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Drew graph", "path", image.Path)
+
+	// Uploading images is optional
+	if s.uploads != nil {
+		if *image, err = s.uploads.Upload(ctx, *image); err != nil {
+			logger.Warn("Failed to upload image", "error", err)
+		} else {
+			logger.Debug("Uploaded image", "url", image.URL)
+		}
+	}
+
+	if err := s.store.SaveImage(ctx, image); err != nil {
+		return nil, fmt.Errorf("failed to save image: %w", err)
+	}
+	logger.Debug("Saved image", "token", image.Token)
+
+	return image, nil
+}
+
+func (s *ScreenshotImageService) newScreenshot(ctx context.Context, r *models.AlertRule) (*models.Image, error) {
 	logger := s.logger.FromContext(ctx)
 
 	dashboardUID := r.GetDashboardUID()
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,7 +33,7 @@ func TestScreenshotImageService(t *testing.T) {
 	)
 
 	s := NewScreenshotImageService(cache, &limiter, log.NewNopLogger(), screenshots, 5*time.Second, images,
-		NewUploadingService(uploads, prometheus.NewRegistry()))
+		NewUploadingService(uploads, prometheus.NewRegistry()), nil)
 
 	ctx := context.Background()
 
@@ -69,7 +70,7 @@ func TestScreenshotImageService(t *testing.T) {
 			OrgID:        1,
 			UID:          "foo",
 			DashboardUID: util.Pointer("foo"),
-			PanelID:      util.Pointer(int64(1))})
+			PanelID:      util.Pointer(int64(1))}, nil)
 		require.NoError(t, err)
 		assert.Equal(t, expected, *image)
 	})
@@ -106,7 +107,7 @@ func TestScreenshotImageService(t *testing.T) {
 			OrgID:        1,
 			UID:          "bar",
 			DashboardUID: util.Pointer("bar"),
-			PanelID:      util.Pointer(int64(1))})
+			PanelID:      util.Pointer(int64(1))}, nil)
 		require.NoError(t, err)
 		assert.Equal(t, expected, *image)
 	})
@@ -121,7 +122,7 @@ func TestScreenshotImageService(t *testing.T) {
 			OrgID:        1,
 			UID:          "baz",
 			DashboardUID: util.Pointer("baz"),
-			PanelID:      util.Pointer(int64(1))})
+			PanelID:      util.Pointer(int64(1))}, nil)
 		require.NoError(t, err)
 		assert.Equal(t, expected, *image)
 	})
@@ -142,8 +143,68 @@ func TestScreenshotImageService(t *testing.T) {
 			OrgID:        1,
 			UID:          "qux",
 			DashboardUID: util.Pointer("qux"),
-			PanelID:      util.Pointer(int64(1))})
+			PanelID:      util.Pointer(int64(1))}, nil)
 		assert.EqualError(t, err, "context deadline exceeded")
 		assert.Nil(t, image)
 	})
 }
+
+func TestScreenshotImageServiceWithGraphs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var (
+		cache       = NewMockCacheService(ctrl)
+		images      = store.NewFakeImageStore(t)
+		limiter     = screenshot.NoOpRateLimiter{}
+		screenshots = screenshot.NewMockScreenshotService(ctrl)
+	)
+
+	s := NewScreenshotImageService(cache, &limiter, log.NewNopLogger(), screenshots, 5*time.Second, images,
+		nil, NewGraphRenderer(t.TempDir()))
+
+	ctx := context.Background()
+	now := time.Now()
+	rule := models.AlertRule{
+		OrgID: 1,
+		UID:   "foo",
+		Data: []models.AlertQuery{{
+			RefID:         "A",
+			DatasourceUID: "datasource",
+		}},
+	}
+	results := map[string]data.Frames{
+		"A": {data.NewFrame("",
+			data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+			data.NewField("value", nil, []float64{1, 2}),
+		)},
+	}
+
+	t.Run("graph is drawn when the alert rule is not associated with a dashboard", func(t *testing.T) {
+		image, err := s.NewImage(ctx, &rule, results)
+		require.NoError(t, err)
+		require.NotNil(t, image)
+		assert.NotEmpty(t, image.Token)
+		assert.FileExists(t, image.Path)
+	})
+
+	t.Run("graph is drawn when screenshots are unavailable", func(t *testing.T) {
+		r := rule
+		r.DashboardUID = util.Pointer("foo")
+		r.PanelID = util.Pointer(int64(1))
+
+		cache.EXPECT().Get(gomock.Any(), gomock.Any()).Return(models.Image{}, false)
+		screenshots.EXPECT().Take(gomock.Any(), gomock.Any()).Return(nil, screenshot.ErrScreenshotsUnavailable)
+
+		image, err := s.NewImage(ctx, &r, results)
+		require.NoError(t, err)
+		require.NotNil(t, image)
+		assert.FileExists(t, image.Path)
+	})
+
+	t.Run("original error is returned when there is no data to draw", func(t *testing.T) {
+		image, err := s.NewImage(ctx, &rule, nil)
+		assert.ErrorIs(t, err, models.ErrNoDashboard)
+		assert.Nil(t, image)
+	})
+}
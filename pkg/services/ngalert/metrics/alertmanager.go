@@ -11,6 +11,7 @@ type Alertmanager struct {
 	Registerer prometheus.Registerer
 	*metrics.Alerts
 	*AlertmanagerConfigMetrics
+	*IntegrationHealthMetrics
 }
 
 // NewAlertmanagerMetrics creates a set of metrics for the Alertmanager of each organization.
@@ -20,6 +21,7 @@ func NewAlertmanagerMetrics(r prometheus.Registerer) *Alertmanager {
 		Registerer:                r,
 		Alerts:                    metrics.NewAlerts("grafana", other),
 		AlertmanagerConfigMetrics: NewAlertmanagerConfigMetrics(r),
+		IntegrationHealthMetrics:  NewIntegrationHealthMetrics(r),
 	}
 }
 
@@ -54,3 +56,44 @@ func NewAlertmanagerConfigMetrics(r prometheus.Registerer) *AlertmanagerConfigMe
 	}
 	return m
 }
+
+// IntegrationHealthMetrics are metrics about the health of each integration in a receiver.
+type IntegrationHealthMetrics struct {
+	ConsecutiveFailures *prometheus.GaugeVec
+	FailedOver          *prometheus.GaugeVec
+	Failovers           *prometheus.CounterVec
+	Recoveries          *prometheus.CounterVec
+}
+
+func NewIntegrationHealthMetrics(r prometheus.Registerer) *IntegrationHealthMetrics {
+	m := &IntegrationHealthMetrics{
+		ConsecutiveFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "integration_consecutive_failures",
+			Help:      "The number of failed notifications since the last successful notification.",
+		}, []string{"receiver", "integration"}),
+		FailedOver: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "integration_failed_over",
+			Help:      "1 if notifications are sent to the fallback integrations of the receiver, 0 otherwise.",
+		}, []string{"receiver", "integration"}),
+		Failovers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "integration_failovers_total",
+			Help:      "The total number of notifications sent to fallback integrations.",
+		}, []string{"receiver", "integration"}),
+		Recoveries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: Subsystem,
+			Name:      "integration_recoveries_total",
+			Help:      "The total number of times an integration succeeded after having failed over.",
+		}, []string{"receiver", "integration"}),
+	}
+	if r != nil {
+		r.MustRegister(m.ConsecutiveFailures, m.FailedOver, m.Failovers, m.Recoveries)
+	}
+	return m
+}
@@ -117,6 +117,12 @@ type AlertmanagerAggregatedMetrics struct {
 	matchRE        *prometheus.Desc
 	match          *prometheus.Desc
 	objectMatchers *prometheus.Desc
+
+	// exported metrics, gathered from the health of integrations
+	integrationConsecutiveFailures *prometheus.Desc
+	integrationFailedOver          *prometheus.Desc
+	integrationFailovers           *prometheus.Desc
+	integrationRecoveries          *prometheus.Desc
 }
 
 func NewAlertmanagerAggregatedMetrics(registries *metrics.TenantRegistries) *AlertmanagerAggregatedMetrics {
@@ -253,6 +259,23 @@ func NewAlertmanagerAggregatedMetrics(registries *metrics.TenantRegistries) *Ale
 			fmt.Sprintf("%s_%s_alertmanager_config_object_matchers", Namespace, Subsystem),
 			"The total number of object_matchers",
 			nil, nil),
+
+		integrationConsecutiveFailures: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_consecutive_failures", Namespace, Subsystem),
+			"The number of failed notifications since the last successful notification.",
+			[]string{"org", "receiver", "integration"}, nil),
+		integrationFailedOver: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_failed_over", Namespace, Subsystem),
+			"1 if notifications are sent to the fallback integrations of the receiver, 0 otherwise.",
+			[]string{"org", "receiver", "integration"}, nil),
+		integrationFailovers: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_failovers_total", Namespace, Subsystem),
+			"The total number of notifications sent to fallback integrations.",
+			[]string{"org", "receiver", "integration"}, nil),
+		integrationRecoveries: prometheus.NewDesc(
+			fmt.Sprintf("%s_%s_integration_recoveries_total", Namespace, Subsystem),
+			"The total number of times an integration succeeded after having failed over.",
+			[]string{"org", "receiver", "integration"}, nil),
 	}
 
 	return aggregatedMetrics
@@ -296,6 +319,11 @@ func (a *AlertmanagerAggregatedMetrics) Describe(out chan<- *prometheus.Desc) {
 	out <- a.matchRE
 	out <- a.match
 	out <- a.objectMatchers
+
+	out <- a.integrationConsecutiveFailures
+	out <- a.integrationFailedOver
+	out <- a.integrationFailovers
+	out <- a.integrationRecoveries
 }
 
 func (a *AlertmanagerAggregatedMetrics) Collect(out chan<- prometheus.Metric) {
@@ -338,4 +366,9 @@ func (a *AlertmanagerAggregatedMetrics) Collect(out chan<- prometheus.Metric) {
 	data.SendSumOfGauges(out, a.matchRE, "alertmanager_config_match_re")
 	data.SendSumOfGauges(out, a.match, "alertmanager_config_match")
 	data.SendSumOfGauges(out, a.objectMatchers, "alertmanager_config_object_matchers")
+
+	data.SendSumOfGaugesPerTenantWithLabels(out, a.integrationConsecutiveFailures, "grafana_alerting_integration_consecutive_failures", "receiver", "integration")
+	data.SendSumOfGaugesPerTenantWithLabels(out, a.integrationFailedOver, "grafana_alerting_integration_failed_over", "receiver", "integration")
+	data.SendSumOfCountersPerTenant(out, a.integrationFailovers, "grafana_alerting_integration_failovers_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
+	data.SendSumOfCountersPerTenant(out, a.integrationRecoveries, "grafana_alerting_integration_recoveries_total", metrics.WithLabels("receiver", "integration"), metrics.WithSkipZeroValueMetrics)
 }
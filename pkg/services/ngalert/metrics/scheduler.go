@@ -27,6 +27,7 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	EvaluationsPerTick                  prometheus.Histogram
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -143,5 +144,14 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		EvaluationsPerTick: promauto.With(r).NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_tick_rule_evaluations",
+				Help:      "The number of rule evaluations scheduled on a tick.",
+				Buckets:   prometheus.ExponentialBuckets(1, 2, 14),
+			},
+		),
 	}
 }
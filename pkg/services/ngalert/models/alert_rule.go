@@ -130,8 +130,11 @@ const (
 var (
 	// InternalLabelNameSet are labels that grafana automatically include as part of the labelset.
 	InternalLabelNameSet = map[string]struct{}{
-		alertingModels.RuleUIDLabel:      {},
-		alertingModels.NamespaceUIDLabel: {},
+		alertingModels.RuleUIDLabel:         {},
+		alertingModels.NamespaceUIDLabel:    {},
+		AutogeneratedRouteLabel:             {},
+		AutogeneratedRouteReceiverNameLabel: {},
+		AutogeneratedRouteSettingsHashLabel: {},
 	}
 	InternalAnnotationNameSet = map[string]struct{}{
 		DashboardUIDAnnotation:              {},
@@ -217,6 +220,9 @@ type AlertRule struct {
 	Annotations map[string]string
 	Labels      map[string]string
 	IsPaused    bool
+	// NotificationSettings are the settings to send notifications for the alerts of the rule directly to a receiver.
+	// It is stored as a list in the database but contains at most one element.
+	NotificationSettings []NotificationSettings `xorm:"notification_settings"`
 }
 
 // AlertRuleWithOptionals This is to avoid having to pass in additional arguments deep in the call stack. Alert rule
@@ -468,9 +474,27 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 	if alertRule.For < 0 {
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
+
+	if len(alertRule.NotificationSettings) > 0 {
+		if len(alertRule.NotificationSettings) != 1 {
+			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
+		}
+		if err := alertRule.NotificationSettings[0].Validate(); err != nil {
+			return errors.Join(ErrAlertRuleFailedValidation, err)
+		}
+	}
 	return nil
 }
 
+// GetNotificationSettings returns the notification settings of the rule, or nil if the alerts of the rule are
+// routed by the notification policy tree.
+func (alertRule *AlertRule) GetNotificationSettings() *NotificationSettings {
+	if len(alertRule.NotificationSettings) == 0 {
+		return nil
+	}
+	return &alertRule.NotificationSettings[0]
+}
+
 func (alertRule *AlertRule) ResourceType() string {
 	return "alertRule"
 }
@@ -504,10 +528,11 @@ type AlertRuleVersion struct {
 	ExecErrState    ExecutionErrorState
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
-	For         time.Duration
-	Annotations map[string]string
-	Labels      map[string]string
-	IsPaused    bool
+	For                  time.Duration
+	Annotations          map[string]string
+	Labels               map[string]string
+	IsPaused             bool
+	NotificationSettings []NotificationSettings `xorm:"notification_settings"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -568,6 +593,13 @@ type ListOrgRuleGroupsQuery struct {
 	PanelID      int64
 }
 
+// ListNotificationSettingsQuery is the query for listing the notification settings of the alert rules of an organization.
+type ListNotificationSettingsQuery struct {
+	OrgID int64
+	// ReceiverName filters the result to notification settings that use the receiver.
+	ReceiverName string
+}
+
 type UpdateRule struct {
 	Existing *AlertRule
 	New      AlertRule
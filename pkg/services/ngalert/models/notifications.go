@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// AutogeneratedRouteLabel is the label that is added to alerts of rules with notification settings. It is used
+	// to route the alerts to the autogenerated notification policy. The only expected value is "true".
+	AutogeneratedRouteLabel = "__grafana_autogenerated__"
+	// AutogeneratedRouteReceiverNameLabel is the label that contains the name of the receiver that is used to send
+	// notifications for alerts of rules with notification settings.
+	AutogeneratedRouteReceiverNameLabel = "__grafana_receiver__"
+	// AutogeneratedRouteSettingsHashLabel is the label that contains the fingerprint of the notification settings
+	// of a rule. It identifies the autogenerated notification policy with the settings, so that alerts of rules with
+	// the same settings are grouped together.
+	AutogeneratedRouteSettingsHashLabel = "__grafana_route_settings_hash__"
+
+	// GroupByAll is a special value for group_by that groups alerts by all labels.
+	GroupByAll = "..."
+)
+
+var (
+	// DefaultNotificationSettingsGroupBy are the labels that alerts of rules with notification settings are
+	// grouped by if no labels are specified. Alerts are always grouped by these labels unless they are grouped by all labels.
+	DefaultNotificationSettingsGroupBy = []string{FolderTitleLabel, model.AlertNameLabel}
+
+	ErrNotificationSettingsInvalid = errors.New("invalid notification settings")
+)
+
+// NotificationSettings are the settings to send notifications for the alerts of a rule directly to a receiver
+// instead of routing them through the notification policy tree. The alerts of rules with notification settings
+// are routed by an autogenerated notification policy that takes precedence over the policies defined by users.
+// Alerts are always grouped by folder and rule name, and timings that are not specified are inherited from
+// the default notification policy.
+type NotificationSettings struct {
+	Receiver string `json:"receiver"`
+
+	GroupBy           []string        `json:"group_by,omitempty"`
+	GroupWait         *model.Duration `json:"group_wait,omitempty"`
+	GroupInterval     *model.Duration `json:"group_interval,omitempty"`
+	RepeatInterval    *model.Duration `json:"repeat_interval,omitempty"`
+	MuteTimeIntervals []string        `json:"mute_time_intervals,omitempty"`
+}
+
+// NewDefaultNotificationSettings creates notification settings that send notifications to the receiver and
+// inherit all other settings from the default notification policy.
+func NewDefaultNotificationSettings(receiver string) NotificationSettings {
+	return NotificationSettings{
+		Receiver: receiver,
+	}
+}
+
+// Validate checks that the settings are valid. It does not check that the receiver and mute time intervals exist.
+func (s *NotificationSettings) Validate() error {
+	if s.Receiver == "" {
+		return fmt.Errorf("%w: receiver must be specified", ErrNotificationSettingsInvalid)
+	}
+	if len(s.GroupBy) > 0 {
+		groupBy := make(map[string]struct{}, len(s.GroupBy))
+		for _, lbl := range s.GroupBy {
+			if lbl == GroupByAll {
+				if len(s.GroupBy) > 1 {
+					return fmt.Errorf("%w: group_by must not contain other labels if it contains '%s'", ErrNotificationSettingsInvalid, GroupByAll)
+				}
+				continue
+			}
+			if !model.LabelName(lbl).IsValid() {
+				return fmt.Errorf("%w: invalid label name '%s' in group_by", ErrNotificationSettingsInvalid, lbl)
+			}
+			if _, ok := groupBy[lbl]; ok {
+				return fmt.Errorf("%w: duplicate label '%s' in group_by", ErrNotificationSettingsInvalid, lbl)
+			}
+			groupBy[lbl] = struct{}{}
+		}
+	}
+	if s.GroupWait != nil && *s.GroupWait < 0 {
+		return fmt.Errorf("%w: group_wait must not be negative", ErrNotificationSettingsInvalid)
+	}
+	if s.GroupInterval != nil && *s.GroupInterval <= 0 {
+		return fmt.Errorf("%w: group_interval must be positive", ErrNotificationSettingsInvalid)
+	}
+	if s.RepeatInterval != nil && *s.RepeatInterval <= 0 {
+		return fmt.Errorf("%w: repeat_interval must be positive", ErrNotificationSettingsInvalid)
+	}
+	if s.GroupInterval != nil && s.RepeatInterval != nil && *s.RepeatInterval < *s.GroupInterval {
+		return fmt.Errorf("%w: repeat_interval must not be less than group_interval", ErrNotificationSettingsInvalid)
+	}
+	return nil
+}
+
+// IsAllDefault returns true if only the receiver is specified and all other settings are inherited.
+func (s *NotificationSettings) IsAllDefault() bool {
+	return len(s.GroupBy) == 0 && s.GroupWait == nil && s.GroupInterval == nil && s.RepeatInterval == nil && len(s.MuteTimeIntervals) == 0
+}
+
+// NormalizedGroupBy returns the labels that alerts are grouped by. It is DefaultNotificationSettingsGroupBy
+// followed by the other labels in group_by, or GroupByAll if alerts are grouped by all labels.
+func (s *NotificationSettings) NormalizedGroupBy() []string {
+	if len(s.GroupBy) == 0 {
+		return slices.Clone(DefaultNotificationSettingsGroupBy)
+	}
+	if slices.Contains(s.GroupBy, GroupByAll) {
+		return []string{GroupByAll}
+	}
+	result := slices.Clone(DefaultNotificationSettingsGroupBy)
+	for _, lbl := range s.GroupBy {
+		if !slices.Contains(result, lbl) {
+			result = append(result, lbl)
+		}
+	}
+	return result
+}
+
+// ToLabels returns the labels that are added to alerts of the rule to route them to the autogenerated
+// notification policy.
+func (s *NotificationSettings) ToLabels() data.Labels {
+	result := make(data.Labels, 3)
+	result[AutogeneratedRouteLabel] = "true"
+	result[AutogeneratedRouteReceiverNameLabel] = s.Receiver
+	if !s.IsAllDefault() {
+		result[AutogeneratedRouteSettingsHashLabel] = s.Fingerprint().String()
+	}
+	return result
+}
+
+// Fingerprint calculates a hash of the settings. Settings that group and send notifications in the same way have
+// the same fingerprint.
+func (s *NotificationSettings) Fingerprint() data.Fingerprint {
+	h := fnv.New64()
+	tmp := make([]byte, 8)
+
+	writeString := func(s string) {
+		// ignore errors returned by Write method because fnv never returns them.
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{255}) // use an invalid utf-8 sequence as separator
+	}
+	writeDuration := func(d *model.Duration) {
+		if d == nil {
+			_, _ = h.Write([]byte{255})
+		} else {
+			binary.LittleEndian.PutUint64(tmp, uint64(*d))
+			_, _ = h.Write(tmp)
+			_, _ = h.Write([]byte{255})
+		}
+	}
+
+	writeString(s.Receiver)
+	for _, lbl := range s.NormalizedGroupBy() {
+		writeString(lbl)
+	}
+	writeDuration(s.GroupWait)
+	writeDuration(s.GroupInterval)
+	writeDuration(s.RepeatInterval)
+	intervals := slices.Clone(s.MuteTimeIntervals)
+	slices.Sort(intervals)
+	for _, interval := range intervals {
+		writeString(interval)
+	}
+	return data.Fingerprint(h.Sum64())
+}
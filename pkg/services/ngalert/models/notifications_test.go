@@ -0,0 +1,156 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func durationPtr(d time.Duration) *model.Duration {
+	md := model.Duration(d)
+	return &md
+}
+
+func TestNotificationSettingsValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		settings NotificationSettings
+		expErr   string
+	}{
+		{
+			name:     "valid default settings",
+			settings: NewDefaultNotificationSettings("receiver"),
+		},
+		{
+			name: "valid settings",
+			settings: NotificationSettings{
+				Receiver:          "receiver",
+				GroupBy:           []string{"namespace", "pod"},
+				GroupWait:         durationPtr(0),
+				GroupInterval:     durationPtr(time.Minute),
+				RepeatInterval:    durationPtr(time.Hour),
+				MuteTimeIntervals: []string{"weekends"},
+			},
+		},
+		{
+			name:     "valid settings grouped by all labels",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{GroupByAll}},
+		},
+		{
+			name:     "missing receiver",
+			settings: NotificationSettings{},
+			expErr:   "receiver must be specified",
+		},
+		{
+			name:     "group by all labels combined with other labels",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{GroupByAll, "pod"}},
+			expErr:   "group_by must not contain other labels",
+		},
+		{
+			name:     "invalid label in group by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"invalid-label"}},
+			expErr:   "invalid label name 'invalid-label'",
+		},
+		{
+			name:     "duplicate label in group by",
+			settings: NotificationSettings{Receiver: "receiver", GroupBy: []string{"pod", "pod"}},
+			expErr:   "duplicate label 'pod'",
+		},
+		{
+			name:     "negative group wait",
+			settings: NotificationSettings{Receiver: "receiver", GroupWait: durationPtr(-time.Second)},
+			expErr:   "group_wait must not be negative",
+		},
+		{
+			name:     "zero group interval",
+			settings: NotificationSettings{Receiver: "receiver", GroupInterval: durationPtr(0)},
+			expErr:   "group_interval must be positive",
+		},
+		{
+			name:     "zero repeat interval",
+			settings: NotificationSettings{Receiver: "receiver", RepeatInterval: durationPtr(0)},
+			expErr:   "repeat_interval must be positive",
+		},
+		{
+			name:     "repeat interval less than group interval",
+			settings: NotificationSettings{Receiver: "receiver", GroupInterval: durationPtr(time.Hour), RepeatInterval: durationPtr(time.Minute)},
+			expErr:   "repeat_interval must not be less than group_interval",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.settings.Validate()
+			if tc.expErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrNotificationSettingsInvalid)
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
+
+func TestNotificationSettingsNormalizedGroupBy(t *testing.T) {
+	s := NewDefaultNotificationSettings("receiver")
+	assert.Equal(t, DefaultNotificationSettingsGroupBy, s.NormalizedGroupBy())
+
+	s.GroupBy = []string{"pod", model.AlertNameLabel}
+	assert.Equal(t, append(DefaultNotificationSettingsGroupBy, "pod"), s.NormalizedGroupBy())
+
+	s.GroupBy = []string{GroupByAll}
+	assert.Equal(t, []string{GroupByAll}, s.NormalizedGroupBy())
+}
+
+func TestNotificationSettingsFingerprint(t *testing.T) {
+	s := NotificationSettings{
+		Receiver:          "receiver",
+		GroupBy:           []string{"pod"},
+		GroupWait:         durationPtr(time.Second),
+		MuteTimeIntervals: []string{"a", "b"},
+	}
+
+	t.Run("is the same for equivalent settings", func(t *testing.T) {
+		other := s
+		other.GroupBy = []string{FolderTitleLabel, "pod"}
+		other.MuteTimeIntervals = []string{"b", "a"}
+		assert.Equal(t, s.Fingerprint(), other.Fingerprint())
+	})
+
+	t.Run("changes if any setting changes", func(t *testing.T) {
+		mutations := map[string]func(*NotificationSettings){
+			"receiver":            func(s *NotificationSettings) { s.Receiver = "other" },
+			"group_by":            func(s *NotificationSettings) { s.GroupBy = []string{"namespace"} },
+			"group_wait":          func(s *NotificationSettings) { s.GroupWait = nil },
+			"group_interval":      func(s *NotificationSettings) { s.GroupInterval = durationPtr(time.Second) },
+			"repeat_interval":     func(s *NotificationSettings) { s.RepeatInterval = durationPtr(time.Second) },
+			"mute_time_intervals": func(s *NotificationSettings) { s.MuteTimeIntervals = []string{"a"} },
+		}
+		for name, mutate := range mutations {
+			other := s
+			mutate(&other)
+			assert.NotEqualf(t, s.Fingerprint(), other.Fingerprint(), "fingerprint did not change when %s changed", name)
+		}
+	})
+
+	t.Run("does not confuse group interval and repeat interval", func(t *testing.T) {
+		a := NotificationSettings{Receiver: "receiver", GroupInterval: durationPtr(time.Minute)}
+		b := NotificationSettings{Receiver: "receiver", RepeatInterval: durationPtr(time.Minute)}
+		assert.NotEqual(t, a.Fingerprint(), b.Fingerprint())
+	})
+}
+
+func TestNotificationSettingsToLabels(t *testing.T) {
+	s := NewDefaultNotificationSettings("receiver")
+	labels := s.ToLabels()
+	assert.Equal(t, "true", labels[AutogeneratedRouteLabel])
+	assert.Equal(t, "receiver", labels[AutogeneratedRouteReceiverNameLabel])
+	assert.NotContains(t, labels, AutogeneratedRouteSettingsHashLabel)
+
+	s.GroupBy = []string{"pod"}
+	labels = s.ToLabels()
+	assert.Equal(t, s.Fingerprint().String(), labels[AutogeneratedRouteSettingsHashLabel])
+}
@@ -277,6 +277,12 @@ func WithGroupKey(groupKey AlertRuleGroupKey) AlertRuleMutator {
 	}
 }
 
+func WithNotificationSettings(settings NotificationSettings) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.NotificationSettings = []NotificationSettings{settings}
+	}
+}
+
 func GenerateAlertLabels(count int, prefix string) data.Labels {
 	labels := make(data.Labels, count)
 	for i := 0; i < count; i++ {
@@ -406,6 +412,13 @@ func CopyRule(r *AlertRule) *AlertRule {
 		}
 	}
 
+	for _, s := range r.NotificationSettings {
+		ns := s
+		ns.GroupBy = slices.Clone(s.GroupBy)
+		ns.MuteTimeIntervals = slices.Clone(s.MuteTimeIntervals)
+		result.NotificationSettings = append(result.NotificationSettings, ns)
+	}
+
 	return &result
 }
 
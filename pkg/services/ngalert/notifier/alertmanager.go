@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strconv"
 	"time"
@@ -21,6 +22,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/messagebus"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -42,6 +44,7 @@ var silenceMaintenanceInterval = 15 * time.Minute
 type AlertingStore interface {
 	store.AlertingStore
 	store.ImageStore
+	autogenRuleStore
 }
 
 type alertmanager struct {
@@ -54,6 +57,9 @@ type alertmanager struct {
 	fileStore           *FileStore
 	NotificationService notifications.Service
 
+	// health tracks the health of integrations and sends notifications to fallback integrations.
+	health *integrationHealthTracker
+
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
 }
@@ -143,6 +149,7 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 		fileStore:           fileStore,
 		logger:              l,
+		health:              newIntegrationHealthTracker(m.IntegrationHealthMetrics, l.New("component", "integration-health")),
 	}
 
 	return am, nil
@@ -179,7 +186,7 @@ func (am *alertmanager) SaveAndApplyDefaultConfig(ctx context.Context) error {
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, []byte(am.Settings.UnifiedAlerting.DefaultConfiguration))
+			_, err := am.applyConfig(ctx, cfg, true)
 			return err
 		})
 		if err != nil {
@@ -209,7 +216,9 @@ func (am *alertmanager) SaveAndApplyConfig(ctx context.Context, cfg *apimodels.P
 		}
 
 		err = am.Store.SaveAlertmanagerConfigurationWithCallback(ctx, cmd, func() error {
-			_, err := am.applyConfig(cfg, rawConfig)
+			// The configuration is not saved if alert rules have notification settings that refer to
+			// receivers or mute time intervals that do not exist in it.
+			_, err := am.applyConfig(ctx, cfg, false)
 			return err
 		})
 		if err != nil {
@@ -231,7 +240,7 @@ func (am *alertmanager) ApplyConfig(ctx context.Context, dbCfg *ngmodels.AlertCo
 
 	var outerErr error
 	am.Base.WithLock(func() {
-		if err := am.applyAndMarkConfig(ctx, dbCfg.ConfigurationHash, cfg, nil); err != nil {
+		if err := am.applyAndMarkConfig(ctx, dbCfg.ConfigurationHash, cfg); err != nil {
 			outerErr = fmt.Errorf("unable to apply configuration: %w", err)
 			return
 		}
@@ -279,18 +288,26 @@ func (am *alertmanager) aggregateInhibitMatchers(rules []config.InhibitRule, amu
 }
 
 // applyConfig applies a new configuration by re-initializing all components using the configuration provided.
+// The autogenerated notification policy for the notification settings of alert rules is added to a copy of the
+// configuration, and invalid notification settings are skipped if skipInvalid is true.
+// The policy is part of the configuration hash, so it is regenerated from the current rules every time the
+// configuration is applied. Changes to the notification settings of rules therefore take effect on the next
+// periodic sync of the configuration (see alertmanager_config_poll_interval) or the next save of the configuration.
 // It returns a boolean indicating whether the user config was changed and an error.
 // It is not safe to call concurrently.
-func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig []byte) (bool, error) {
+func (am *alertmanager) applyConfig(ctx context.Context, cfg *apimodels.PostableUserConfig, skipInvalid bool) (bool, error) {
+	cfg = copyConfigForApply(cfg)
+	err := AddAutogenConfig(ctx, am.logger, am.Store, am.orgID, &cfg.AlertmanagerConfig, skipInvalid)
+	if err != nil {
+		return false, err
+	}
+
 	// First, let's make sure this config is not already loaded
 	var amConfigChanged bool
-	if rawConfig == nil {
-		enc, err := json.Marshal(cfg.AlertmanagerConfig)
-		if err != nil {
-			// In theory, this should never happen.
-			return false, err
-		}
-		rawConfig = enc
+	rawConfig, err := json.Marshal(cfg)
+	if err != nil {
+		// In theory, this should never happen.
+		return false, err
 	}
 
 	if am.Base.ConfigHash() != md5.Sum(rawConfig) {
@@ -316,6 +333,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	}
 
 	am.updateConfigMetrics(cfg)
+	am.health.applyConfig(cfg)
 
 	err = am.Base.ApplyConfig(AlertingConfiguration{
 		rawAlertmanagerConfig:    rawConfig,
@@ -330,9 +348,23 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	return true, nil
 }
 
+// copyConfigForApply returns a copy of the configuration that applyConfig can modify without changing the
+// configuration of the caller. Only the root route and the template files are copied, as they are the only parts
+// that are modified when the configuration is applied. The configuration is not copied via JSON because secrets
+// of upstream integrations are masked when marshaled.
+func copyConfigForApply(cfg *apimodels.PostableUserConfig) *apimodels.PostableUserConfig {
+	result := *cfg
+	if cfg.AlertmanagerConfig.Route != nil {
+		route := *cfg.AlertmanagerConfig.Route
+		result.AlertmanagerConfig.Route = &route
+	}
+	result.TemplateFiles = maps.Clone(cfg.TemplateFiles)
+	return &result
+}
+
 // applyAndMarkConfig applies a configuration and marks it as applied if no errors occur.
-func (am *alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig, rawConfig []byte) error {
-	configChanged, err := am.applyConfig(cfg, rawConfig)
+func (am *alertmanager) applyAndMarkConfig(ctx context.Context, hash string, cfg *apimodels.PostableUserConfig) error {
+	configChanged, err := am.applyConfig(ctx, cfg, true)
 	if err != nil {
 		return err
 	}
@@ -354,7 +386,9 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
-	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
+	// Integrations that publish to message brokers are not implemented in the alerting module
+	alertingReceiver, messageBusIntegrations := messagebus.Split(receiver)
+	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), alertingReceiver, am.decryptFn)
 	if err != nil {
 		return nil, err
 	}
@@ -377,7 +411,19 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	if len(messageBusIntegrations) > 0 {
+		other, err := messagebus.BuildIntegrations(context.Background(), messageBusIntegrations, am.decryptFn, tmpl, img, LoggerFactory, am.orgID)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, other...)
+	}
+	// Integrations built to send test notifications do not have a receiver name. Their
+	// health is not tracked, and fallback integrations are tested like any other integration.
+	if receiver.Name == "" {
+		return integrations, nil
+	}
+	return am.health.wrap(receiver, integrations), nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
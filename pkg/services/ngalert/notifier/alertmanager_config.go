@@ -135,6 +135,7 @@ func (moa *MultiOrgAlertmanager) gettableUserConfigFromAMConfigString(ctx contex
 				DisableResolveMessage: pr.DisableResolveMessage,
 				Settings:              pr.Settings,
 				SecureFields:          secureFields,
+				FallbackAfterFailures: pr.FallbackAfterFailures,
 			}
 			receivers = append(receivers, &gr)
 		}
@@ -373,6 +373,60 @@ func (_c *AlertmanagerMock_GetReceivers_Call) RunAndReturn(run func(context.Cont
 	return _c
 }
 
+// GetReceiversHealth provides a mock function with given fields: ctx
+func (_m *AlertmanagerMock) GetReceiversHealth(ctx context.Context) ([]definitions.ReceiverHealth, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []definitions.ReceiverHealth
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]definitions.ReceiverHealth, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []definitions.ReceiverHealth); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]definitions.ReceiverHealth)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AlertmanagerMock_GetReceiversHealth_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetReceiversHealth'
+type AlertmanagerMock_GetReceiversHealth_Call struct {
+	*mock.Call
+}
+
+// GetReceiversHealth is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *AlertmanagerMock_Expecter) GetReceiversHealth(ctx interface{}) *AlertmanagerMock_GetReceiversHealth_Call {
+	return &AlertmanagerMock_GetReceiversHealth_Call{Call: _e.mock.On("GetReceiversHealth", ctx)}
+}
+
+func (_c *AlertmanagerMock_GetReceiversHealth_Call) Run(run func(ctx context.Context)) *AlertmanagerMock_GetReceiversHealth_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *AlertmanagerMock_GetReceiversHealth_Call) Return(_a0 []definitions.ReceiverHealth, _a1 error) *AlertmanagerMock_GetReceiversHealth_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AlertmanagerMock_GetReceiversHealth_Call) RunAndReturn(run func(context.Context) ([]definitions.ReceiverHealth, error)) *AlertmanagerMock_GetReceiversHealth_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetSilence provides a mock function with given fields: _a0, _a1
 func (_m *AlertmanagerMock) GetSilence(_a0 context.Context, _a1 string) (v2models.GettableSilence, error) {
 	ret := _m.Called(_a0, _a1)
@@ -2,6 +2,8 @@ package notifier
 
 import (
 	"context"
+	"crypto/md5"
+	"fmt"
 	"testing"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
@@ -50,3 +53,64 @@ func TestAlertmanager_newAlertmanager(t *testing.T) {
 	am := setupAMTest(t)
 	require.False(t, am.Ready())
 }
+
+func TestAlertmanager_ApplyConfigAutogen(t *testing.T) {
+	rawConfig := []byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "default"},
+			"receivers": [{"name": "default"}, {"name": "receiver1"}]
+		}
+	}`)
+	dbConfig := &ngmodels.AlertConfiguration{
+		AlertmanagerConfiguration: string(rawConfig),
+		ConfigurationHash:         fmt.Sprintf("%x", md5.Sum(rawConfig)),
+		OrgID:                     1,
+	}
+	configStore := NewFakeConfigStore(t, map[int64]*ngmodels.AlertConfiguration{1: dbConfig})
+	configStore.notificationSettings = map[int64]map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings{}
+
+	m := metrics.NewAlertmanagerMetrics(prometheus.NewRegistry())
+	cfg := &setting.Cfg{DataPath: t.TempDir()}
+	am, err := NewAlertmanager(context.Background(), 1, cfg, configStore, fakes.NewFakeKVStore(t), &NilPeer{}, nil, nil, m)
+	require.NoError(t, err)
+
+	require.NoError(t, am.ApplyConfig(context.Background(), dbConfig))
+	initialHash := am.Base.ConfigHash()
+
+	t.Run("configuration of the caller is not modified", func(t *testing.T) {
+		configStore.notificationSettings[1] = map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {ngmodels.NewDefaultNotificationSettings("receiver1")},
+		}
+		t.Cleanup(func() { configStore.notificationSettings[1] = nil })
+
+		userConfig, err := Load(rawConfig)
+		require.NoError(t, err)
+		am.Base.WithLock(func() {
+			_, err = am.applyConfig(context.Background(), userConfig, false)
+		})
+		require.NoError(t, err)
+
+		expected, err := Load(rawConfig)
+		require.NoError(t, err)
+		require.Equal(t, expected, userConfig)
+	})
+
+	t.Run("changes to notification settings are applied on the next sync", func(t *testing.T) {
+		require.NoError(t, am.ApplyConfig(context.Background(), dbConfig))
+		require.Equal(t, initialHash, am.Base.ConfigHash())
+
+		configStore.notificationSettings[1] = map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {ngmodels.NewDefaultNotificationSettings("receiver1")},
+		}
+		require.NoError(t, am.ApplyConfig(context.Background(), dbConfig))
+		withSettingsHash := am.Base.ConfigHash()
+		require.NotEqual(t, initialHash, withSettingsHash)
+
+		require.NoError(t, am.ApplyConfig(context.Background(), dbConfig))
+		require.Equal(t, withSettingsHash, am.Base.ConfigHash())
+
+		configStore.notificationSettings[1] = nil
+		require.NoError(t, am.ApplyConfig(context.Background(), dbConfig))
+		require.Equal(t, initialHash, am.Base.ConfigHash())
+	})
+}
@@ -0,0 +1,161 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// autogenRuleStore is the store that is used to get the notification settings of alert rules.
+type autogenRuleStore interface {
+	ListNotificationSettings(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error)
+}
+
+// AddAutogenConfig creates the autogenerated notification policy for the notification settings of the alert
+// rules of the organization and adds it to the configuration as the first child of the root route. The policy does
+// not continue to the sibling routes, so the alerts of rules with notification settings are not routed by the
+// notification policies defined by users. Alerts of other rules never match it.
+//
+// Notification settings that refer to receivers or mute time intervals that do not exist in the configuration are
+// skipped if skipInvalid is true, in which case their alerts are sent to the receiver of the root route. Otherwise,
+// an error is returned.
+func AddAutogenConfig(ctx context.Context, logger log.Logger, store autogenRuleStore, orgID int64, cfg *definitions.PostableApiAlertingConfig, skipInvalid bool) error {
+	if cfg.Route == nil {
+		return nil
+	}
+	RemoveAutogenConfigIfExists(cfg.Route)
+
+	settings, err := store.ListNotificationSettings(ctx, models.ListNotificationSettingsQuery{OrgID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to list notification settings of alert rules: %w", err)
+	}
+
+	route, err := newAutogeneratedRoute(logger, settings, cfg, skipInvalid)
+	if err != nil {
+		return err
+	}
+	if route == nil {
+		return nil
+	}
+
+	root := *cfg.Route
+	root.Routes = append([]*definitions.Route{route}, cfg.Route.Routes...)
+	cfg.Route = &root
+	return nil
+}
+
+// RemoveAutogenConfigIfExists removes the autogenerated notification policy from the root route.
+func RemoveAutogenConfigIfExists(route *definitions.Route) {
+	if route == nil || len(route.Routes) == 0 || !isAutogeneratedRoute(route.Routes[0]) {
+		return
+	}
+	route.Routes = route.Routes[1:]
+}
+
+func isAutogeneratedRoute(route *definitions.Route) bool {
+	return len(route.ObjectMatchers) == 1 &&
+		route.ObjectMatchers[0].Name == models.AutogeneratedRouteLabel &&
+		route.ObjectMatchers[0].Type == labels.MatchEqual &&
+		route.ObjectMatchers[0].Value == "true"
+}
+
+// newAutogeneratedRoute creates the autogenerated notification policy. The policy has a child route for each
+// receiver, and each receiver route has a child route for each combination of settings that are not all default.
+// It returns nil if there are no valid notification settings.
+func newAutogeneratedRoute(logger log.Logger, settings map[models.AlertRuleKey][]models.NotificationSettings, cfg *definitions.PostableApiAlertingConfig, skipInvalid bool) (*definitions.Route, error) {
+	validator := NewNotificationSettingsValidator(cfg)
+
+	receiverRoutes := make(map[string]*definitions.Route)
+	settingsRoutes := make(map[string]map[string]*definitions.Route)
+	var errs []error
+	for ruleKey, ruleSettings := range settings {
+		for _, s := range ruleSettings {
+			if err := validator.Validate(s); err != nil {
+				if skipInvalid {
+					logger.Warn("Skipping invalid notification settings of alert rule", append(ruleKey.LogContext(), "error", err)...)
+					continue
+				}
+				errs = append(errs, fmt.Errorf("invalid notification settings of alert rule %s: %w", ruleKey.UID, err))
+				continue
+			}
+
+			if _, ok := receiverRoutes[s.Receiver]; !ok {
+				receiverRoutes[s.Receiver] = newRoute(models.AutogeneratedRouteReceiverNameLabel, s.Receiver, models.NewDefaultNotificationSettings(s.Receiver))
+				settingsRoutes[s.Receiver] = make(map[string]*definitions.Route)
+			}
+			if s.IsAllDefault() {
+				continue
+			}
+			fingerprint := s.Fingerprint().String()
+			if _, ok := settingsRoutes[s.Receiver][fingerprint]; !ok {
+				settingsRoutes[s.Receiver][fingerprint] = newRoute(models.AutogeneratedRouteSettingsHashLabel, fingerprint, s)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if len(receiverRoutes) == 0 {
+		return nil, nil
+	}
+
+	// The autogenerated policy inherits all settings of the root route, so alerts of rules with invalid settings
+	// are handled in the same way as alerts that do not match any notification policy.
+	matcher, _ := labels.NewMatcher(labels.MatchEqual, models.AutogeneratedRouteLabel, "true")
+	autogenRoute := &definitions.Route{
+		Receiver:       cfg.Route.Receiver,
+		ObjectMatchers: definitions.ObjectMatchers{matcher},
+	}
+	// Routes are sorted so that the configuration, and therefore its hash, does not change if the settings are the same.
+	for _, receiver := range sortedKeys(receiverRoutes) {
+		receiverRoute := receiverRoutes[receiver]
+		for _, fingerprint := range sortedKeys(settingsRoutes[receiver]) {
+			receiverRoute.Routes = append(receiverRoute.Routes, settingsRoutes[receiver][fingerprint])
+		}
+		autogenRoute.Routes = append(autogenRoute.Routes, receiverRoute)
+	}
+	return autogenRoute, nil
+}
+
+// newRoute creates a route that matches alerts with the label and sends notifications with the settings.
+func newRoute(label, value string, s models.NotificationSettings) *definitions.Route {
+	// The label names and values are valid, so the error can be ignored.
+	matcher, _ := labels.NewMatcher(labels.MatchEqual, label, value)
+
+	groupBy := s.NormalizedGroupBy()
+	route := &definitions.Route{
+		Receiver:          s.Receiver,
+		ObjectMatchers:    definitions.ObjectMatchers{matcher},
+		GroupByStr:        groupBy,
+		MuteTimeIntervals: s.MuteTimeIntervals,
+		GroupWait:         s.GroupWait,
+		GroupInterval:     s.GroupInterval,
+		RepeatInterval:    s.RepeatInterval,
+	}
+	if slices.Contains(groupBy, models.GroupByAll) {
+		route.GroupByAll = true
+	} else {
+		route.GroupBy = make([]model.LabelName, 0, len(groupBy))
+		for _, lbl := range groupBy {
+			route.GroupBy = append(route.GroupBy, model.LabelName(lbl))
+		}
+	}
+	return route
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestAddAutogenConfig(t *testing.T) {
+	rootRoute := func() *definitions.Route {
+		return &definitions.Route{
+			Receiver: "default",
+			Routes: []*definitions.Route{
+				{Receiver: "user-defined"},
+			},
+		}
+	}
+	newConfig := func() *definitions.PostableApiAlertingConfig {
+		return &definitions.PostableApiAlertingConfig{
+			Config: definitions.Config{
+				Route: rootRoute(),
+				MuteTimeIntervals: []config.MuteTimeInterval{
+					{Name: "weekends"},
+				},
+			},
+			Receivers: []*definitions.PostableApiReceiver{
+				{Receiver: config.Receiver{Name: "default"}},
+				{Receiver: config.Receiver{Name: "user-defined"}},
+				{Receiver: config.Receiver{Name: "receiver1"}},
+				{Receiver: config.Receiver{Name: "receiver2"}},
+			},
+		}
+	}
+	groupWait := model.Duration(time.Minute)
+	customSettings := models.NotificationSettings{
+		Receiver:          "receiver1",
+		GroupBy:           []string{"pod"},
+		GroupWait:         &groupWait,
+		MuteTimeIntervals: []string{"weekends"},
+	}
+	store := func(settings map[models.AlertRuleKey][]models.NotificationSettings) *fakeConfigStore {
+		return &fakeConfigStore{
+			notificationSettings: map[int64]map[models.AlertRuleKey][]models.NotificationSettings{1: settings},
+		}
+	}
+
+	t.Run("does not add a route if there are no notification settings", func(t *testing.T) {
+		cfg := newConfig()
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), store(nil), 1, cfg, false))
+		assert.Equal(t, rootRoute(), cfg.Route)
+	})
+
+	t.Run("adds a route for the notification settings before the user-defined routes", func(t *testing.T) {
+		cfg := newConfig()
+		s := store(map[models.AlertRuleKey][]models.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {models.NewDefaultNotificationSettings("receiver2")},
+			{OrgID: 1, UID: "rule2"}: {customSettings},
+			{OrgID: 1, UID: "rule3"}: {customSettings},
+			{OrgID: 1, UID: "rule4"}: {models.NewDefaultNotificationSettings("receiver1")},
+		})
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, false))
+
+		require.Len(t, cfg.Route.Routes, 2)
+		assert.Equal(t, "user-defined", cfg.Route.Routes[1].Receiver)
+
+		autogen := cfg.Route.Routes[0]
+		require.True(t, isAutogeneratedRoute(autogen))
+		assert.Equal(t, "default", autogen.Receiver)
+		assert.Nil(t, autogen.GroupBy)
+		assert.Nil(t, autogen.GroupWait)
+		assert.False(t, autogen.Continue)
+
+		require.Len(t, autogen.Routes, 2)
+		receiver1, receiver2 := autogen.Routes[0], autogen.Routes[1]
+		assert.Equal(t, "receiver1", receiver1.Receiver)
+		assert.Equal(t, models.AutogeneratedRouteReceiverNameLabel, receiver1.ObjectMatchers[0].Name)
+		assert.Equal(t, "receiver1", receiver1.ObjectMatchers[0].Value)
+		assert.Equal(t, []model.LabelName{models.FolderTitleLabel, model.AlertNameLabel}, receiver1.GroupBy)
+		assert.Equal(t, "receiver2", receiver2.Receiver)
+		assert.Empty(t, receiver2.Routes)
+
+		require.Len(t, receiver1.Routes, 1)
+		settingsRoute := receiver1.Routes[0]
+		assert.Equal(t, models.AutogeneratedRouteSettingsHashLabel, settingsRoute.ObjectMatchers[0].Name)
+		assert.Equal(t, customSettings.Fingerprint().String(), settingsRoute.ObjectMatchers[0].Value)
+		assert.Equal(t, []model.LabelName{models.FolderTitleLabel, model.AlertNameLabel, "pod"}, settingsRoute.GroupBy)
+		assert.Equal(t, []string{models.FolderTitleLabel, model.AlertNameLabel, "pod"}, settingsRoute.GroupByStr)
+		assert.Equal(t, &groupWait, settingsRoute.GroupWait)
+		assert.Nil(t, settingsRoute.GroupInterval)
+		assert.Equal(t, []string{"weekends"}, settingsRoute.MuteTimeIntervals)
+	})
+
+	t.Run("replaces the existing autogenerated route", func(t *testing.T) {
+		cfg := newConfig()
+		s := store(map[models.AlertRuleKey][]models.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {models.NewDefaultNotificationSettings("receiver1")},
+		})
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, false))
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, false))
+		require.Len(t, cfg.Route.Routes, 2)
+
+		RemoveAutogenConfigIfExists(cfg.Route)
+		assert.Equal(t, rootRoute(), cfg.Route)
+	})
+
+	t.Run("groups by all labels", func(t *testing.T) {
+		cfg := newConfig()
+		s := store(map[models.AlertRuleKey][]models.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {{Receiver: "receiver1", GroupBy: []string{models.GroupByAll}}},
+		})
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, false))
+		settingsRoute := cfg.Route.Routes[0].Routes[0].Routes[0]
+		assert.True(t, settingsRoute.GroupByAll)
+		assert.Nil(t, settingsRoute.GroupBy)
+	})
+
+	t.Run("fails if the settings refer to a receiver that does not exist", func(t *testing.T) {
+		cfg := newConfig()
+		s := store(map[models.AlertRuleKey][]models.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {models.NewDefaultNotificationSettings("missing")},
+			{OrgID: 1, UID: "rule2"}: {models.NewDefaultNotificationSettings("receiver1")},
+		})
+		err := AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, false)
+		require.ErrorIs(t, err, models.ErrNotificationSettingsInvalid)
+		require.ErrorContains(t, err, "receiver 'missing' does not exist")
+	})
+
+	t.Run("skips invalid settings if skipInvalid is true", func(t *testing.T) {
+		cfg := newConfig()
+		s := store(map[models.AlertRuleKey][]models.NotificationSettings{
+			{OrgID: 1, UID: "rule1"}: {{Receiver: "receiver1", MuteTimeIntervals: []string{"missing"}}},
+			{OrgID: 1, UID: "rule2"}: {models.NewDefaultNotificationSettings("receiver2")},
+		})
+		require.NoError(t, AddAutogenConfig(context.Background(), log.NewNopLogger(), s, 1, cfg, true))
+		autogen := cfg.Route.Routes[0]
+		require.Len(t, autogen.Routes, 1)
+		assert.Equal(t, "receiver2", autogen.Routes[0].Receiver)
+	})
+}
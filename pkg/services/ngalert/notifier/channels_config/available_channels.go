@@ -110,6 +110,58 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 		},
 	}
 
+	// messageBusOptions are the options that are common to all contact points that publish notifications to
+	// message brokers.
+	messageBusOptions := []NotifierOption{
+		{
+			Label:        "Max Alerts",
+			Description:  "Max alerts to include in a notification. Remaining alerts in the same batch will be ignored above this number. 0 means no limit.",
+			Element:      ElementTypeInput,
+			InputType:    InputTypeText,
+			PropertyName: "maxAlerts",
+		},
+		{
+			Label:        "Title",
+			Description:  "Templated title of the message.",
+			Element:      ElementTypeInput,
+			InputType:    InputTypeText,
+			PropertyName: "title",
+			Placeholder:  alertingTemplates.DefaultMessageTitleEmbed,
+		},
+		{
+			Label:        "Message",
+			Description:  "Custom message. You can use template variables.",
+			Element:      ElementTypeTextArea,
+			PropertyName: "message",
+			Placeholder:  alertingTemplates.DefaultMessageEmbed,
+		},
+		{
+			Label:        "Skip TLS verification",
+			Description:  "Do not verify the certificate of the broker.",
+			Element:      ElementTypeCheckbox,
+			PropertyName: "tlsSkipVerify",
+		},
+		{
+			Label:        "TLS CA certificate",
+			Description:  "PEM encoded certificate of a CA that is trusted in addition to the system CAs.",
+			Element:      ElementTypeTextArea,
+			PropertyName: "tlsCACertificate",
+		},
+		{
+			Label:        "TLS client certificate",
+			Description:  "PEM encoded certificate used to authenticate with the broker.",
+			Element:      ElementTypeTextArea,
+			PropertyName: "tlsClientCertificate",
+		},
+		{
+			Label:        "TLS client key",
+			Description:  "PEM encoded key of the client certificate.",
+			Element:      ElementTypeTextArea,
+			PropertyName: "tlsClientKey",
+			Secure:       true,
+		},
+	}
+
 	return []*NotifierPlugin{
 		{
 			Type:        "dingding",
@@ -946,6 +998,179 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "amqp",
+			Name:        "AMQP",
+			Description: "Publishes notifications to an AMQP 0.9.1 broker, such as RabbitMQ",
+			Heading:     "AMQP settings",
+			Info:        "Notifications have the same payload as webhook notifications.",
+			Options: append([]NotifierOption{
+				{
+					Label:        "URL",
+					Description:  "URL of the broker. Use the amqps scheme to connect with TLS.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "amqp://localhost:5672/",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Exchange",
+					Description:  "Exchange to publish notifications to. The default exchange is used if empty.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "exchange",
+				},
+				{
+					Label:        "Routing key",
+					Description:  "Templated routing key of the messages. With the default exchange, this is the name of the queue.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "alerts",
+					PropertyName: "routingKey",
+					Required:     true,
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+			}, messageBusOptions...),
+		},
+		{
+			Type:        "nats",
+			Name:        "NATS",
+			Description: "Publishes notifications to a NATS subject",
+			Heading:     "NATS settings",
+			Info:        "Notifications have the same payload as webhook notifications.",
+			Options: append([]NotifierOption{
+				{
+					Label:        "URL",
+					Description:  "Comma separated list of server URLs. Use the tls scheme to connect with TLS.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "nats://localhost:4222",
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Subject",
+					Description:  "Templated subject of the messages.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "alerts",
+					PropertyName: "subject",
+					Required:     true,
+				},
+				{
+					Label:        "JetStream",
+					Description:  "Publish to a JetStream stream and wait for the server to acknowledge that the message has been stored.",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "jetStream",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+				{
+					Label:        "Token",
+					Description:  "Token used to authenticate instead of a username and password.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "token",
+					Secure:       true,
+				},
+			}, messageBusOptions...),
+		},
+		{
+			Type:        "mqtt",
+			Name:        "MQTT",
+			Description: "Publishes notifications to an MQTT broker",
+			Heading:     "MQTT settings",
+			Info:        "Notifications have the same payload as webhook notifications.",
+			Options: append([]NotifierOption{
+				{
+					Label:        "Broker URL",
+					Description:  "URL of the broker. Use the ssl scheme to connect with TLS, and the ws or wss schemes to connect with WebSockets.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "tcp://localhost:1883",
+					PropertyName: "brokerUrl",
+					Required:     true,
+				},
+				{
+					Label:        "Topic",
+					Description:  "Templated topic of the messages.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					Placeholder:  "grafana/alerts",
+					PropertyName: "topic",
+					Required:     true,
+				},
+				{
+					Label:   "QoS",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: "0",
+							Label: "At most once (0)",
+						},
+						{
+							Value: "1",
+							Label: "At least once (1)",
+						},
+						{
+							Value: "2",
+							Label: "Exactly once (2)",
+						},
+					},
+					PropertyName: "qos",
+				},
+				{
+					Label:        "Retain",
+					Description:  "The broker keeps the last notification and sends it to new subscribers.",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "retain",
+				},
+				{
+					Label:        "Client ID",
+					Description:  "A random client ID is used if empty.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "clientId",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+				},
+			}, messageBusOptions...),
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
@@ -0,0 +1,393 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+// integrationHealth is the health of an integration. It is safe to use concurrently.
+type integrationHealth struct {
+	mtx sync.Mutex
+
+	uid                   string
+	receiver              string
+	name                  string
+	index                 int
+	fallbackAfterFailures int
+
+	consecutiveFailures int
+	totalSuccesses      int64
+	totalFailures       int64
+	failedOver          bool
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	lastError           error
+	// lastFailedFlush is the flush of the last failed notification, so that retries of
+	// the same notification are counted as a single failure.
+	lastFailedFlush string
+}
+
+func (h *integrationHealth) String() string {
+	return fmt.Sprintf("%s[%d]", h.name, h.index)
+}
+
+// report records the result of a notification and returns the number of consecutive failures.
+// Failed retries of the notification of a flush are counted once.
+func (h *integrationHealth) report(now time.Time, flush string, err error) int {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if err != nil {
+		if flush == "" || flush != h.lastFailedFlush {
+			h.consecutiveFailures++
+			h.totalFailures++
+		}
+		h.lastFailedFlush = flush
+		h.lastFailure = now
+		h.lastError = err
+	} else {
+		h.consecutiveFailures = 0
+		h.totalSuccesses++
+		h.lastSuccess = now
+		h.lastFailedFlush = ""
+	}
+	return h.consecutiveFailures
+}
+
+// flushKey returns the key of the flush of the aggregation group the notification is sent for.
+// The Alertmanager retries a failed notification with the same context until it succeeds or
+// the next flush, so all the retries share the same key. It returns an empty string if the
+// context does not have a group key.
+func flushKey(ctx context.Context) string {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ""
+	}
+	now, _ := notify.Now(ctx)
+	return fmt.Sprintf("%s@%d", groupKey, now.UnixNano())
+}
+
+// setFailedOver sets whether notifications are sent to fallback integrations, and returns
+// the previous value.
+func (h *integrationHealth) setFailedOver(failedOver bool) bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	previous := h.failedOver
+	h.failedOver = failedOver
+	return previous
+}
+
+func (h *integrationHealth) toAPIModel() apimodels.IntegrationHealth {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	result := apimodels.IntegrationHealth{
+		UID:                   h.uid,
+		Name:                  h.name,
+		Index:                 h.index,
+		FallbackAfterFailures: h.fallbackAfterFailures,
+		Status:                apimodels.IntegrationHealthUnknown,
+		ConsecutiveFailures:   h.consecutiveFailures,
+		TotalSuccesses:        h.totalSuccesses,
+		TotalFailures:         h.totalFailures,
+		FailedOver:            h.failedOver,
+	}
+	if !h.lastSuccess.IsZero() {
+		t := h.lastSuccess
+		result.LastSuccess = &t
+		result.Status = apimodels.IntegrationHealthHealthy
+	}
+	if !h.lastFailure.IsZero() {
+		t := h.lastFailure
+		result.LastFailure = &t
+		result.LastError = h.lastError.Error()
+		if h.consecutiveFailures > 0 {
+			result.Status = apimodels.IntegrationHealthFailing
+		}
+	}
+	return result
+}
+
+// integrationHealthTracker tracks the health of the integrations of all receivers in
+// an Alertmanager. The health of an integration is kept when the configuration is
+// applied as long as an integration with the same UID still exists.
+type integrationHealthTracker struct {
+	mtx          sync.Mutex
+	integrations map[string]*integrationHealth
+	// fallbacks contains the number of failures after which each fallback integration
+	// is used, keyed by integrationKey.
+	fallbacks map[string]int
+	metrics   *metrics.IntegrationHealthMetrics
+	logger    log.Logger
+	now       func() time.Time
+}
+
+func newIntegrationHealthTracker(m *metrics.IntegrationHealthMetrics, l log.Logger) *integrationHealthTracker {
+	return &integrationHealthTracker{
+		integrations: make(map[string]*integrationHealth),
+		fallbacks:    make(map[string]int),
+		metrics:      m,
+		logger:       l,
+		now:          time.Now,
+	}
+}
+
+// integrationKey returns the key of an integration. Integrations are keyed on their UID
+// so their health is kept when they are moved or renamed. The receiver, type and index
+// are used for integrations without a UID.
+func integrationKey(receiver, uid, typ string, index int) string {
+	if uid != "" {
+		return uid
+	}
+	return fmt.Sprintf("%s/%s[%d]", receiver, typ, index)
+}
+
+// getOrCreate returns the health of the integration, creating it if it does not exist, and
+// the number of failures after which the integration is used as a fallback, 0 if it is not
+// a fallback integration.
+func (t *integrationHealthTracker) getOrCreate(receiver string, cfg *alertingNotify.GrafanaIntegrationConfig, index int) (*integrationHealth, int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	key := integrationKey(receiver, cfg.UID, cfg.Type, index)
+	fallbackAfterFailures := t.fallbacks[key]
+
+	h, ok := t.integrations[key]
+	if !ok || h.receiver != receiver || h.name != cfg.Type || h.index != index {
+		if ok {
+			t.deleteMetrics(h)
+		}
+		h = &integrationHealth{uid: cfg.UID, receiver: receiver, name: cfg.Type, index: index}
+		t.integrations[key] = h
+	}
+	h.mtx.Lock()
+	h.fallbackAfterFailures = fallbackAfterFailures
+	h.mtx.Unlock()
+	return h, fallbackAfterFailures
+}
+
+// applyConfig must be called before the integrations of the configuration are built.
+// It updates the fallback integrations and removes the health of all integrations that
+// are no longer in the configuration.
+func (t *integrationHealthTracker) applyConfig(cfg *apimodels.PostableUserConfig) {
+	fallbacks := make(map[string]int)
+	keys := make(map[string]struct{})
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		indices := make(map[string]int)
+		for _, gr := range r.GrafanaManagedReceivers {
+			key := integrationKey(r.Name, gr.UID, gr.Type, indices[gr.Type])
+			indices[gr.Type]++
+			keys[key] = struct{}{}
+			if gr.FallbackAfterFailures > 0 {
+				fallbacks[key] = gr.FallbackAfterFailures
+			}
+		}
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.fallbacks = fallbacks
+	for key, h := range t.integrations {
+		if _, ok := keys[key]; !ok {
+			t.deleteMetrics(h)
+			delete(t.integrations, key)
+		}
+	}
+}
+
+func (t *integrationHealthTracker) deleteMetrics(h *integrationHealth) {
+	t.metrics.ConsecutiveFailures.DeleteLabelValues(h.receiver, h.String())
+	t.metrics.FailedOver.DeleteLabelValues(h.receiver, h.String())
+	t.metrics.Failovers.DeleteLabelValues(h.receiver, h.String())
+	t.metrics.Recoveries.DeleteLabelValues(h.receiver, h.String())
+}
+
+// receiversHealth returns the health of all integrations grouped by receiver.
+func (t *integrationHealthTracker) receiversHealth() []apimodels.ReceiverHealth {
+	t.mtx.Lock()
+	byReceiver := make(map[string][]apimodels.IntegrationHealth)
+	for _, h := range t.integrations {
+		byReceiver[h.receiver] = append(byReceiver[h.receiver], h.toAPIModel())
+	}
+	t.mtx.Unlock()
+
+	result := make([]apimodels.ReceiverHealth, 0, len(byReceiver))
+	for name, integrations := range byReceiver {
+		sort.Slice(integrations, func(i, j int) bool {
+			if integrations[i].Name != integrations[j].Name {
+				return integrations[i].Name < integrations[j].Name
+			}
+			return integrations[i].Index < integrations[j].Index
+		})
+		result = append(result, apimodels.ReceiverHealth{Name: name, Integrations: integrations})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// wrap returns the integrations of the receiver that should be used by the Alertmanager.
+// The integrations are wrapped so the result of each notification is reported to the
+// tracker. Fallback integrations are not returned, and are instead sent notifications
+// from the integrations that have failed to send FallbackAfterFailures notifications
+// in a row.
+func (t *integrationHealthTracker) wrap(receiver *alertingNotify.APIReceiver, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	configs := integrationConfigsByIndex(receiver)
+
+	var (
+		primaries []*healthTrackingNotifier
+		fallbacks []*fallbackNotifier
+		result    = make([]*alertingNotify.Integration, 0, len(integrations))
+	)
+	for _, i := range integrations {
+		cfg, ok := configs[i.String()]
+		if !ok {
+			// This should never happen, but if it does the integration is used as is
+			t.logger.Warn("Failed to find configuration for integration", "receiver", receiver.Name, "integration", i.String())
+			result = append(result, i)
+			continue
+		}
+		h, fallbackAfterFailures := t.getOrCreate(receiver.Name, cfg, i.Index())
+		if fallbackAfterFailures > 0 {
+			fallbacks = append(fallbacks, &fallbackNotifier{integration: i, health: h, afterFailures: fallbackAfterFailures})
+			continue
+		}
+		p := &healthTrackingNotifier{integration: i, health: h, tracker: t}
+		primaries = append(primaries, p)
+		result = append(result, alertingNotify.NewIntegration(p, i, i.Name(), i.Index(), cfg.Name))
+	}
+	for _, p := range primaries {
+		p.fallbacks = fallbacks
+	}
+	return result
+}
+
+// integrationConfigsByIndex returns the configuration of each integration in the receiver
+// keyed by its type and index, in the same format as the String() method of integrations.
+func integrationConfigsByIndex(receiver *alertingNotify.APIReceiver) map[string]*alertingNotify.GrafanaIntegrationConfig {
+	result := make(map[string]*alertingNotify.GrafanaIntegrationConfig, len(receiver.Integrations))
+	indices := make(map[string]int)
+	for _, cfg := range receiver.Integrations {
+		result[fmt.Sprintf("%s[%d]", cfg.Type, indices[cfg.Type])] = cfg
+		indices[cfg.Type]++
+	}
+	return result
+}
+
+// fallbackNotifier is an integration that is used when another integration is failing.
+// The fallback integration is shared by all the integrations of the receiver, and sends
+// the notification of a flush once even if several of them are failing.
+type fallbackNotifier struct {
+	integration   *alertingNotify.Integration
+	health        *integrationHealth
+	afterFailures int
+
+	mtx       sync.Mutex
+	sentFlush string
+}
+
+// notify sends the alerts to the fallback integration unless they have already been sent
+// for the flush, in which case it returns true.
+func (f *fallbackNotifier) notify(ctx context.Context, t *integrationHealthTracker, flush string, alerts []*types.Alert) (bool, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if flush != "" && f.sentFlush == flush {
+		return true, nil
+	}
+
+	_, err := f.integration.Notify(ctx, alerts...)
+	failures := f.health.report(t.now(), flush, err)
+	t.metrics.ConsecutiveFailures.WithLabelValues(f.health.receiver, f.health.String()).Set(float64(failures))
+	if err == nil {
+		f.sentFlush = flush
+	}
+	return false, err
+}
+
+// healthTrackingNotifier reports the result of each notification to the tracker, and sends
+// notifications to fallback integrations once the integration has failed more than the
+// number of times configured for each fallback integration.
+type healthTrackingNotifier struct {
+	integration *alertingNotify.Integration
+	health      *integrationHealth
+	fallbacks   []*fallbackNotifier
+	tracker     *integrationHealthTracker
+}
+
+func (n *healthTrackingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	flush := flushKey(ctx)
+	retry, err := n.integration.Notify(ctx, alerts...)
+	failures := n.health.report(n.tracker.now(), flush, err)
+	n.tracker.metrics.ConsecutiveFailures.WithLabelValues(n.health.receiver, n.health.String()).Set(float64(failures))
+
+	if err == nil {
+		if n.health.setFailedOver(false) {
+			n.tracker.logger.Info("Integration has recovered, sending notifications to it again", "receiver", n.health.receiver, "integration", n.health.String())
+			n.tracker.metrics.FailedOver.WithLabelValues(n.health.receiver, n.health.String()).Set(0)
+			n.tracker.metrics.Recoveries.WithLabelValues(n.health.receiver, n.health.String()).Inc()
+		}
+		return retry, nil
+	}
+
+	var (
+		attempted bool
+		errs      = []error{err}
+	)
+	for _, f := range n.fallbacks {
+		if failures < f.afterFailures {
+			continue
+		}
+		sent := filterResolved(f.integration, alerts)
+		if len(sent) == 0 {
+			continue
+		}
+		if !attempted {
+			attempted = true
+			if !n.health.setFailedOver(true) {
+				n.tracker.logger.Warn("Integration is failing, sending notifications to fallback integrations", "receiver", n.health.receiver, "integration", n.health.String(), "failures", failures)
+				n.tracker.metrics.FailedOver.WithLabelValues(n.health.receiver, n.health.String()).Set(1)
+			}
+		}
+		alreadySent, fallbackErr := f.notify(ctx, n.tracker, flush, sent)
+		if fallbackErr != nil {
+			errs = append(errs, fmt.Errorf("fallback %s: %w", f.health.String(), fallbackErr))
+			continue
+		}
+		if !alreadySent {
+			n.tracker.metrics.Failovers.WithLabelValues(n.health.receiver, n.health.String()).Inc()
+		}
+		// The notification was sent by at least one fallback integration
+		err = nil
+	}
+
+	if err != nil {
+		return retry, errors.Join(errs...)
+	}
+	return false, nil
+}
+
+// filterResolved removes resolved alerts if the integration does not send resolved notifications.
+func filterResolved(i *alertingNotify.Integration, alerts []*types.Alert) []*types.Alert {
+	if i.SendResolved() {
+		return alerts
+	}
+	result := make([]*types.Alert, 0, len(alerts))
+	for _, a := range alerts {
+		if !a.Resolved() {
+			result = append(result, a)
+		}
+	}
+	return result
+}
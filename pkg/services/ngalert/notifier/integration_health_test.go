@@ -0,0 +1,270 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type fakeIntegrationNotifier struct {
+	err          error
+	sendResolved bool
+	alerts       []*types.Alert
+	calls        int
+}
+
+func (n *fakeIntegrationNotifier) Notify(_ context.Context, alerts ...*types.Alert) (bool, error) {
+	n.calls++
+	n.alerts = alerts
+	return n.err != nil, n.err
+}
+
+func (n *fakeIntegrationNotifier) SendResolved() bool {
+	return n.sendResolved
+}
+
+func newTestIntegrationHealthTracker(t *testing.T) *integrationHealthTracker {
+	t.Helper()
+	tracker := newIntegrationHealthTracker(metrics.NewIntegrationHealthMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+	tracker.now = func() time.Time { return time.Unix(1000, 0) }
+	return tracker
+}
+
+func newTestHealthConfig(receiver string, integrations ...*apimodels.PostableGrafanaReceiver) *apimodels.PostableUserConfig {
+	return &apimodels.PostableUserConfig{
+		AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+			Receivers: []*apimodels.PostableApiReceiver{{
+				Receiver: config.Receiver{Name: receiver},
+				PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+					GrafanaManagedReceivers: integrations,
+				},
+			}},
+		},
+	}
+}
+
+// buildTestIntegrations returns the receiver and its integrations in the same way as
+// they are built by the Alertmanager.
+func buildTestIntegrations(cfg *apimodels.PostableUserConfig, notifiers ...*fakeIntegrationNotifier) (*alertingNotify.APIReceiver, []*alertingNotify.Integration) {
+	r := cfg.AlertmanagerConfig.Receivers[0]
+	receiver := &alertingNotify.APIReceiver{ConfigReceiver: r.Receiver}
+	integrations := make([]*alertingNotify.Integration, 0, len(r.GrafanaManagedReceivers))
+	indices := make(map[string]int)
+	for i, gr := range r.GrafanaManagedReceivers {
+		receiver.Integrations = append(receiver.Integrations, &alertingNotify.GrafanaIntegrationConfig{
+			UID:  gr.UID,
+			Name: gr.Name,
+			Type: gr.Type,
+		})
+		integrations = append(integrations, alertingNotify.NewIntegration(notifiers[i], notifiers[i], gr.Type, indices[gr.Type], gr.Name))
+		indices[gr.Type]++
+	}
+	return receiver, integrations
+}
+
+func TestIntegrationHealthTrackerConcurrentWrap(t *testing.T) {
+	cfg := newTestHealthConfig("test",
+		&apimodels.PostableGrafanaReceiver{UID: "primary", Name: "primary", Type: "webhook"},
+		&apimodels.PostableGrafanaReceiver{UID: "fallback", Name: "fallback", Type: "email", FallbackAfterFailures: 2},
+	)
+	tracker := newTestIntegrationHealthTracker(t)
+	tracker.applyConfig(cfg)
+
+	// The integrations of a receiver are wrapped again when the configuration is applied,
+	// while the Alertmanager may still use the previous ones.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			receiver, integrations := buildTestIntegrations(cfg, &fakeIntegrationNotifier{}, &fakeIntegrationNotifier{})
+			wrapped := tracker.wrap(receiver, integrations)
+			assert.Len(t, wrapped, 1)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestIntegrationHealthTracker(t *testing.T) {
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "firing"}, EndsAt: time.Now().Add(time.Hour)}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "resolved"}, EndsAt: time.Now().Add(-time.Hour)}}
+
+	cfg := newTestHealthConfig("test",
+		&apimodels.PostableGrafanaReceiver{UID: "primary", Name: "primary", Type: "webhook"},
+		&apimodels.PostableGrafanaReceiver{UID: "fallback", Name: "fallback", Type: "email", FallbackAfterFailures: 2},
+	)
+
+	t.Run("fallback integrations are not used until the integration fails", func(t *testing.T) {
+		tracker := newTestIntegrationHealthTracker(t)
+		tracker.applyConfig(cfg)
+
+		primary, fallback := &fakeIntegrationNotifier{}, &fakeIntegrationNotifier{}
+		receiver, integrations := buildTestIntegrations(cfg, primary, fallback)
+		wrapped := tracker.wrap(receiver, integrations)
+		require.Len(t, wrapped, 1)
+		assert.Equal(t, "webhook[0]", wrapped[0].String())
+
+		_, err := wrapped[0].Notify(context.Background(), firing)
+		require.NoError(t, err)
+		assert.Equal(t, 1, primary.calls)
+		assert.Equal(t, 0, fallback.calls)
+
+		primary.err = errors.New("failed")
+		_, err = wrapped[0].Notify(context.Background(), firing)
+		require.Error(t, err)
+		assert.Equal(t, 0, fallback.calls)
+
+		// The fallback integration is used on the second failure in a row
+		retry, err := wrapped[0].Notify(context.Background(), firing, resolved)
+		require.NoError(t, err)
+		assert.False(t, retry)
+		assert.Equal(t, 1, fallback.calls)
+		assert.Equal(t, []*types.Alert{firing}, fallback.alerts, "resolved alerts should not be sent to integrations that do not send resolved notifications")
+		assert.Equal(t, 1.0, testutil.ToFloat64(tracker.metrics.FailedOver.WithLabelValues("test", "webhook[0]")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(tracker.metrics.Failovers.WithLabelValues("test", "webhook[0]")))
+
+		health := tracker.receiversHealth()
+		require.Len(t, health, 1)
+		require.Len(t, health[0].Integrations, 2)
+		// Integrations are sorted by name
+		fallbackHealth, primaryHealth := health[0].Integrations[0], health[0].Integrations[1]
+		assert.Equal(t, apimodels.IntegrationHealthFailing, primaryHealth.Status)
+		assert.Equal(t, 2, primaryHealth.ConsecutiveFailures)
+		assert.Equal(t, int64(1), primaryHealth.TotalSuccesses)
+		assert.Equal(t, int64(2), primaryHealth.TotalFailures)
+		assert.Equal(t, "failed", primaryHealth.LastError)
+		assert.True(t, primaryHealth.FailedOver)
+		assert.Equal(t, apimodels.IntegrationHealthHealthy, fallbackHealth.Status)
+		assert.Equal(t, 2, fallbackHealth.FallbackAfterFailures)
+
+		// The integration recovers
+		primary.err = nil
+		_, err = wrapped[0].Notify(context.Background(), firing)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fallback.calls)
+		assert.Equal(t, 0.0, testutil.ToFloat64(tracker.metrics.FailedOver.WithLabelValues("test", "webhook[0]")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(tracker.metrics.Recoveries.WithLabelValues("test", "webhook[0]")))
+
+		health = tracker.receiversHealth()
+		assert.Equal(t, apimodels.IntegrationHealthHealthy, health[0].Integrations[1].Status)
+		assert.False(t, health[0].Integrations[1].FailedOver)
+	})
+
+	t.Run("errors are returned when fallback integrations fail", func(t *testing.T) {
+		tracker := newTestIntegrationHealthTracker(t)
+		tracker.applyConfig(cfg)
+
+		primary := &fakeIntegrationNotifier{err: errors.New("primary failed")}
+		fallback := &fakeIntegrationNotifier{err: errors.New("fallback failed")}
+		receiver, integrations := buildTestIntegrations(cfg, primary, fallback)
+		wrapped := tracker.wrap(receiver, integrations)
+
+		_, _ = wrapped[0].Notify(context.Background(), firing)
+		retry, err := wrapped[0].Notify(context.Background(), firing)
+		require.ErrorContains(t, err, "primary failed")
+		require.ErrorContains(t, err, "fallback failed")
+		assert.True(t, retry)
+		assert.Equal(t, 1, fallback.calls)
+	})
+
+	t.Run("health is kept when the configuration is applied", func(t *testing.T) {
+		tracker := newTestIntegrationHealthTracker(t)
+		tracker.applyConfig(cfg)
+
+		primary := &fakeIntegrationNotifier{err: errors.New("failed")}
+		receiver, integrations := buildTestIntegrations(cfg, primary, &fakeIntegrationNotifier{})
+		_, _ = tracker.wrap(receiver, integrations)[0].Notify(context.Background(), firing)
+
+		tracker.applyConfig(cfg)
+		receiver, integrations = buildTestIntegrations(cfg, primary, &fakeIntegrationNotifier{})
+		_ = tracker.wrap(receiver, integrations)
+
+		health := tracker.receiversHealth()
+		require.Len(t, health, 1)
+		assert.Equal(t, 1, health[0].Integrations[1].ConsecutiveFailures)
+
+		// The health of integrations that have been removed is deleted
+		tracker.applyConfig(newTestHealthConfig("test",
+			&apimodels.PostableGrafanaReceiver{UID: "fallback", Name: "fallback", Type: "email"},
+		))
+		health = tracker.receiversHealth()
+		require.Len(t, health, 1)
+		require.Len(t, health[0].Integrations, 1)
+		assert.Equal(t, "fallback", health[0].Integrations[0].UID)
+	})
+
+	t.Run("retries of a notification are counted as a single failure", func(t *testing.T) {
+		tracker := newTestIntegrationHealthTracker(t)
+		tracker.applyConfig(cfg)
+
+		primary := &fakeIntegrationNotifier{err: errors.New("failed")}
+		fallback := &fakeIntegrationNotifier{}
+		receiver, integrations := buildTestIntegrations(cfg, primary, fallback)
+		wrapped := tracker.wrap(receiver, integrations)
+
+		ctx := notify.WithNow(notify.WithGroupKey(context.Background(), "group"), time.Unix(1000, 0))
+		for i := 0; i < 3; i++ {
+			_, err := wrapped[0].Notify(ctx, firing)
+			require.Error(t, err)
+		}
+		assert.Equal(t, 3, primary.calls)
+		assert.Equal(t, 0, fallback.calls)
+		assert.Equal(t, 1, tracker.receiversHealth()[0].Integrations[1].ConsecutiveFailures)
+
+		// The next flush of the group is a new failure
+		ctx = notify.WithNow(ctx, time.Unix(1060, 0))
+		_, err := wrapped[0].Notify(ctx, firing)
+		require.NoError(t, err)
+		assert.Equal(t, 1, fallback.calls)
+		assert.Equal(t, 2, tracker.receiversHealth()[0].Integrations[1].ConsecutiveFailures)
+	})
+
+	t.Run("fallback integrations are sent a notification once per flush", func(t *testing.T) {
+		cfg := newTestHealthConfig("test",
+			&apimodels.PostableGrafanaReceiver{UID: "webhook", Name: "webhook", Type: "webhook"},
+			&apimodels.PostableGrafanaReceiver{UID: "slack", Name: "slack", Type: "slack"},
+			&apimodels.PostableGrafanaReceiver{UID: "fallback", Name: "fallback", Type: "email", FallbackAfterFailures: 1},
+		)
+		tracker := newTestIntegrationHealthTracker(t)
+		tracker.applyConfig(cfg)
+
+		webhook := &fakeIntegrationNotifier{err: errors.New("webhook failed")}
+		slack := &fakeIntegrationNotifier{err: errors.New("slack failed")}
+		fallback := &fakeIntegrationNotifier{}
+		receiver, integrations := buildTestIntegrations(cfg, webhook, slack, fallback)
+		wrapped := tracker.wrap(receiver, integrations)
+		require.Len(t, wrapped, 2)
+
+		ctx := notify.WithNow(notify.WithGroupKey(context.Background(), "group"), time.Unix(1000, 0))
+		for _, i := range wrapped {
+			_, err := i.Notify(ctx, firing)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, fallback.calls)
+		assert.Equal(t, 1.0, testutil.ToFloat64(tracker.metrics.Failovers.WithLabelValues("test", "webhook[0]")))
+		assert.Equal(t, 0.0, testutil.ToFloat64(tracker.metrics.Failovers.WithLabelValues("test", "slack[0]")))
+
+		ctx = notify.WithNow(ctx, time.Unix(1060, 0))
+		for _, i := range wrapped {
+			_, err := i.Notify(ctx, firing)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, fallback.calls)
+	})
+}
@@ -0,0 +1,130 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/alerting/receivers"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AMQPConfig contains the settings of integrations that publish notifications to AMQP 0.9.1 brokers,
+// such as RabbitMQ.
+type AMQPConfig struct {
+	Config
+	// URL is the URL of the broker, such as amqp://localhost:5672/vhost. The scheme amqps is used for TLS.
+	URL      string
+	Exchange string
+	Username string
+	Password string
+}
+
+func NewAMQPConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (AMQPConfig, error) {
+	rawSettings := struct {
+		rawConfig
+		URL        string `json:"url,omitempty" yaml:"url,omitempty"`
+		Exchange   string `json:"exchange,omitempty" yaml:"exchange,omitempty"`
+		RoutingKey string `json:"routingKey,omitempty" yaml:"routingKey,omitempty"`
+		Username   string `json:"username,omitempty" yaml:"username,omitempty"`
+		Password   string `json:"password,omitempty" yaml:"password,omitempty"`
+	}{}
+	if err := json.Unmarshal(jsonData, &rawSettings); err != nil {
+		return AMQPConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.URL == "" {
+		return AMQPConfig{}, errors.New("required field 'url' is not specified")
+	}
+	if _, err := amqp.ParseURI(rawSettings.URL); err != nil {
+		return AMQPConfig{}, fmt.Errorf("invalid url: %w", err)
+	}
+	// The routing key is the name of the queue when using the default exchange, so it is required.
+	if rawSettings.RoutingKey == "" {
+		return AMQPConfig{}, errors.New("required field 'routingKey' is not specified")
+	}
+
+	common, err := newConfig(rawSettings.rawConfig, rawSettings.RoutingKey, decryptFn)
+	if err != nil {
+		return AMQPConfig{}, err
+	}
+	return AMQPConfig{
+		Config:   common,
+		URL:      rawSettings.URL,
+		Exchange: rawSettings.Exchange,
+		Username: rawSettings.Username,
+		Password: decryptFn("password", rawSettings.Password),
+	}, nil
+}
+
+// AMQPPublisher publishes messages to an exchange of an AMQP 0.9.1 broker. A connection is opened
+// for each message because notifications are infrequent.
+type AMQPPublisher struct {
+	cfg AMQPConfig
+}
+
+func NewAMQPPublisher(cfg AMQPConfig) *AMQPPublisher {
+	return &AMQPPublisher{cfg: cfg}
+}
+
+// Publish publishes the message with the routing key, and waits until the broker has confirmed
+// that the message has been routed to at least one queue.
+func (p *AMQPPublisher) Publish(ctx context.Context, routingKey string, body []byte) error {
+	tlsConfig, err := p.cfg.TLS.Build()
+	if err != nil {
+		return err
+	}
+	amqpCfg := amqp.Config{
+		TLSClientConfig: tlsConfig,
+		Dial:            amqp.DefaultDial(connectTimeout),
+		Properties:      amqp.NewConnectionProperties(),
+	}
+	amqpCfg.Properties.SetClientConnectionName("grafana-alerting")
+	if p.cfg.Username != "" {
+		amqpCfg.SASL = []amqp.Authentication{&amqp.PlainAuth{Username: p.cfg.Username, Password: p.cfg.Password}}
+	}
+
+	conn, err := amqp.DialConfig(p.cfg.URL, amqpCfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer func() { _ = ch.Close() }()
+
+	if err := ch.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	// Messages are published as mandatory, so the broker returns messages that cannot be routed
+	// to a queue before it confirms them.
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, p.cfg.Exchange, routingKey, true, false, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		AppId:        "grafana",
+		Body:         body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the broker to confirm the message: %w", err)
+	}
+	if !acked {
+		return errors.New("the broker did not accept the message")
+	}
+	select {
+	case r := <-returns:
+		return fmt.Errorf("the message could not be routed to a queue: %d %s", r.ReplyCode, r.ReplyText)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,174 @@
+// Package messagebus implements contact points that publish notifications to message brokers.
+//
+// The integrations in the github.com/grafana/alerting module send notifications to chat, paging and
+// webhook services. The integrations in this package publish the same payload as the webhook integration
+// to AMQP 0.9.1, NATS and MQTT brokers, so that consumers of webhook notifications can consume them from
+// a message bus instead.
+package messagebus
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+)
+
+const (
+	AMQPType = "amqp"
+	NATSType = "nats"
+	MQTTType = "mqtt"
+)
+
+var (
+	// connectTimeout is the maximum time to connect to a message broker.
+	connectTimeout = 10 * time.Second
+	// publishTimeout is the maximum time to wait for a message broker to receive a message when
+	// the context does not have a deadline.
+	publishTimeout = 30 * time.Second
+)
+
+// IsMessageBusType returns true if integrations of the type are implemented in this package.
+func IsMessageBusType(typ string) bool {
+	switch strings.ToLower(typ) {
+	case AMQPType, NATSType, MQTTType:
+		return true
+	default:
+		return false
+	}
+}
+
+// Split returns a copy of the receiver without the integrations that are implemented in this package,
+// and the integrations that are implemented in this package. The receiver is returned as is if it does
+// not have any such integrations.
+func Split(receiver *alertingNotify.APIReceiver) (*alertingNotify.APIReceiver, []*alertingNotify.GrafanaIntegrationConfig) {
+	var messageBus []*alertingNotify.GrafanaIntegrationConfig
+	for _, i := range receiver.Integrations {
+		if IsMessageBusType(i.Type) {
+			messageBus = append(messageBus, i)
+		}
+	}
+	if len(messageBus) == 0 {
+		return receiver, nil
+	}
+
+	other := make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(receiver.Integrations)-len(messageBus))
+	for _, i := range receiver.Integrations {
+		if !IsMessageBusType(i.Type) {
+			other = append(other, i)
+		}
+	}
+	return &alertingNotify.APIReceiver{
+		ConfigReceiver:      receiver.ConfigReceiver,
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: other},
+	}, messageBus
+}
+
+// ValidateIntegration parses, decrypts and validates the configuration of the integration.
+func ValidateIntegration(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) error {
+	_, err := newPublisher(ctx, cfg, decrypt)
+	if err != nil {
+		return alertingNotify.IntegrationValidationError{Integration: cfg, Err: err}
+	}
+	return nil
+}
+
+// BuildIntegrations builds the integrations for the configurations. The configurations must be of types
+// for which IsMessageBusType returns true. The index of each integration is its index among the
+// integrations of the same type, in the same way as in the github.com/grafana/alerting module.
+func BuildIntegrations(
+	ctx context.Context,
+	cfgs []*alertingNotify.GrafanaIntegrationConfig,
+	decrypt alertingNotify.GetDecryptedValueFn,
+	tmpl *alertingTemplates.Template,
+	img images.Provider,
+	logger logging.LoggerFactory,
+	orgID int64,
+) ([]*alertingNotify.Integration, error) {
+	var (
+		integrations = make([]*alertingNotify.Integration, 0, len(cfgs))
+		indices      = make(map[string]int)
+		errs         []error
+	)
+	for _, cfg := range cfgs {
+		p, err := newPublisher(ctx, cfg, decrypt)
+		if err != nil {
+			errs = append(errs, alertingNotify.IntegrationValidationError{Integration: cfg, Err: err})
+			continue
+		}
+		meta := receivers.Metadata{
+			UID:                   cfg.UID,
+			Name:                  cfg.Name,
+			Type:                  cfg.Type,
+			DisableResolveMessage: cfg.DisableResolveMessage,
+		}
+		n := New(p.settings, meta, p.publisher, tmpl, img, logger("ngalert.notifier."+cfg.Type, "notifierUID", cfg.UID), orgID)
+		typ := strings.ToLower(cfg.Type)
+		integrations = append(integrations, alertingNotify.NewIntegration(n, n, typ, indices[typ], cfg.Name))
+		indices[typ]++
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return integrations, nil
+}
+
+// configuredPublisher is a publisher and the settings that are common to all integrations in this package.
+type configuredPublisher struct {
+	settings  Config
+	publisher Publisher
+}
+
+// newPublisher parses, decrypts and validates the configuration of the integration and returns its publisher.
+func newPublisher(ctx context.Context, cfg *alertingNotify.GrafanaIntegrationConfig, decrypt alertingNotify.GetDecryptedValueFn) (configuredPublisher, error) {
+	secureSettings, err := decodeSecrets(cfg.SecureSettings)
+	if err != nil {
+		return configuredPublisher{}, err
+	}
+	decryptFn := func(key string, fallback string) string {
+		return decrypt(ctx, secureSettings, key, fallback)
+	}
+
+	switch strings.ToLower(cfg.Type) {
+	case AMQPType:
+		c, err := NewAMQPConfig(cfg.Settings, decryptFn)
+		if err != nil {
+			return configuredPublisher{}, err
+		}
+		return configuredPublisher{settings: c.Config, publisher: NewAMQPPublisher(c)}, nil
+	case NATSType:
+		c, err := NewNATSConfig(cfg.Settings, decryptFn)
+		if err != nil {
+			return configuredPublisher{}, err
+		}
+		return configuredPublisher{settings: c.Config, publisher: NewNATSPublisher(c)}, nil
+	case MQTTType:
+		c, err := NewMQTTConfig(cfg.Settings, decryptFn)
+		if err != nil {
+			return configuredPublisher{}, err
+		}
+		return configuredPublisher{settings: c.Config, publisher: NewMQTTPublisher(c)}, nil
+	default:
+		return configuredPublisher{}, fmt.Errorf("notifier %s is not supported", cfg.Type)
+	}
+}
+
+// decodeSecrets decodes the base64 encoded secure settings of an integration.
+func decodeSecrets(secrets map[string]string) (map[string][]byte, error) {
+	result := make(map[string][]byte, len(secrets))
+	for key, value := range secrets {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secure setting with key %q: %w", key, err)
+		}
+		result[key] = decoded
+	}
+	return result, nil
+}
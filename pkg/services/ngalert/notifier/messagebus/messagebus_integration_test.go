@@ -0,0 +1,111 @@
+package messagebus
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/nats-io/nats.go"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The integration tests publish messages to local brokers, which can be started with
+// make devenv sources=rabbitmq,nats,mosquitto
+
+func TestIntegrationAMQPPublisher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	u, ok := os.LookupEnv("AMQP_URL")
+	if !ok || u == "" {
+		t.Skip("No AMQP URL supplied")
+	}
+
+	conn, err := amqp.Dial(u)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	ch, err := conn.Channel()
+	require.NoError(t, err)
+	q, err := ch.QueueDeclare("grafana-test-"+util.GenerateShortUID(), false, true, true, false, nil)
+	require.NoError(t, err)
+	deliveries, err := ch.Consume(q.Name, "", true, true, false, false, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	p := NewAMQPPublisher(AMQPConfig{URL: u})
+
+	require.NoError(t, p.Publish(ctx, q.Name, []byte(`{"test":true}`)))
+	select {
+	case d := <-deliveries:
+		require.Equal(t, "application/json", d.ContentType)
+		require.Equal(t, `{"test":true}`, string(d.Body))
+	case <-ctx.Done():
+		t.Fatal("message was not delivered")
+	}
+
+	err = p.Publish(ctx, "grafana-test-missing-"+util.GenerateShortUID(), []byte(`{}`))
+	require.ErrorContains(t, err, "the message could not be routed to a queue")
+}
+
+func TestIntegrationNATSPublisher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	u, ok := os.LookupEnv("NATS_URL")
+	if !ok || u == "" {
+		t.Skip("No NATS URL supplied")
+	}
+
+	nc, err := nats.Connect(u)
+	require.NoError(t, err)
+	t.Cleanup(nc.Close)
+	subject := "grafana.test." + util.GenerateShortUID()
+	sub, err := nc.SubscribeSync(subject)
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, NewNATSPublisher(NATSConfig{URL: u}).Publish(ctx, subject, []byte(`{"test":true}`)))
+
+	msg, err := sub.NextMsgWithContext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", msg.Header.Get("Content-Type"))
+	require.Equal(t, `{"test":true}`, string(msg.Data))
+}
+
+func TestIntegrationMQTTPublisher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	u, ok := os.LookupEnv("MQTT_URL")
+	if !ok || u == "" {
+		t.Skip("No MQTT URL supplied")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	received := make(chan []byte, 1)
+	topic := "grafana/test/" + util.GenerateShortUID()
+	client := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(u).SetClientID("grafana-test-" + util.GenerateShortUID()))
+	require.NoError(t, waitForToken(ctx, client.Connect()))
+	t.Cleanup(func() { client.Disconnect(250) })
+	require.NoError(t, waitForToken(ctx, client.Subscribe(topic, 1, func(_ mqtt.Client, m mqtt.Message) {
+		received <- m.Payload()
+	})))
+
+	require.NoError(t, NewMQTTPublisher(MQTTConfig{BrokerURL: u, QoS: 1}).Publish(ctx, topic, []byte(`{"test":true}`)))
+	select {
+	case body := <-received:
+		require.Equal(t, `{"test":true}`, string(body))
+	case <-ctx.Done():
+		t.Fatal("message was not delivered")
+	}
+}
@@ -0,0 +1,571 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	receiversTesting "github.com/grafana/alerting/receivers/testing"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAMQPConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    AMQPConfig
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          `{}`,
+			expectedInitError: "required field 'url' is not specified",
+		},
+		{
+			name:              "Error if url is invalid",
+			settings:          `{"url": "http://localhost", "routingKey": "alerts"}`,
+			expectedInitError: "invalid url: AMQP scheme must be either 'amqp://' or 'amqps://'",
+		},
+		{
+			name:              "Error if routing key is missing",
+			settings:          `{"url": "amqp://localhost"}`,
+			expectedInitError: "required field 'routingKey' is not specified",
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{"url": "amqp://localhost", "routingKey": "alerts"}`,
+			expectedConfig: AMQPConfig{
+				Config: Config{
+					Topic:   "alerts",
+					Title:   templates.DefaultMessageTitleEmbed,
+					Message: templates.DefaultMessageEmbed,
+				},
+				URL: "amqp://localhost",
+			},
+		},
+		{
+			name:     "All empty fields = minimal valid configuration",
+			settings: `{"url": "amqp://localhost", "routingKey": "alerts", "exchange": "", "username": "", "password": "", "maxAlerts": "", "title": "", "message": ""}`,
+			expectedConfig: AMQPConfig{
+				Config: Config{
+					Topic:   "alerts",
+					Title:   templates.DefaultMessageTitleEmbed,
+					Message: templates.DefaultMessageEmbed,
+				},
+				URL: "amqp://localhost",
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: withTestCertificates(t, FullValidAMQPConfigForTesting),
+			expectedConfig: AMQPConfig{
+				Config: Config{
+					Topic:     "test-routing-key",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				URL:      "amqp://localhost:5672/",
+				Exchange: "test-exchange",
+				Username: "test-user",
+				Password: "test-pass",
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       withTestCertificates(t, FullValidAMQPConfigForTesting),
+			secureSettings: secretsForTesting(t),
+			expectedConfig: AMQPConfig{
+				Config: Config{
+					Topic:     "test-routing-key",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				URL:      "amqp://localhost:5672/",
+				Exchange: "test-exchange",
+				Username: "test-user",
+				Password: "test-secret-pass",
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewAMQPConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestNewNATSConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    NATSConfig
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          `{}`,
+			expectedInitError: "required field 'url' is not specified",
+		},
+		{
+			name:              "Error if subject is missing",
+			settings:          `{"url": "nats://localhost"}`,
+			expectedInitError: "required field 'subject' is not specified",
+		},
+		{
+			name:              "Error if both username and token are set",
+			settings:          `{"url": "nats://localhost", "subject": "alerts", "username": "user", "token": "token"}`,
+			expectedInitError: "both username and token are set, only 1 is permitted",
+		},
+		{
+			name:              "Error if maxAlerts is invalid",
+			settings:          `{"url": "nats://localhost", "subject": "alerts", "maxAlerts": "-1"}`,
+			expectedInitError: `invalid maxAlerts "-1": must be a positive number`,
+		},
+		{
+			name:              "Error if the client certificate is set without a key",
+			settings:          `{"url": "nats://localhost", "subject": "alerts", "tlsClientCertificate": "cert"}`,
+			expectedInitError: "both the client certificate and the client key must be set",
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{"url": "nats://localhost", "subject": "alerts"}`,
+			expectedConfig: NATSConfig{
+				Config: Config{
+					Topic:   "alerts",
+					Title:   templates.DefaultMessageTitleEmbed,
+					Message: templates.DefaultMessageEmbed,
+				},
+				URL: "nats://localhost",
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: withTestCertificates(t, FullValidNATSConfigForTesting),
+			expectedConfig: NATSConfig{
+				Config: Config{
+					Topic:     "test-subject",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				URL:       "nats://localhost:4222",
+				Password:  "test-pass",
+				Token:     "test-token",
+				JetStream: true,
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       withTestCertificates(t, FullValidNATSConfigForTesting),
+			secureSettings: secretsForTesting(t),
+			expectedConfig: NATSConfig{
+				Config: Config{
+					Topic:     "test-subject",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				URL:       "nats://localhost:4222",
+				Password:  "test-secret-pass",
+				Token:     "test-secret-token",
+				JetStream: true,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewNATSConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestNewMQTTConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		settings          string
+		secureSettings    map[string][]byte
+		expectedConfig    MQTTConfig
+		expectedInitError string
+	}{
+		{
+			name:              "Error if empty",
+			settings:          `{}`,
+			expectedInitError: "required field 'brokerUrl' is not specified",
+		},
+		{
+			name:              "Error if scheme is not supported",
+			settings:          `{"brokerUrl": "http://localhost:1883", "topic": "alerts"}`,
+			expectedInitError: "invalid brokerUrl: scheme must be one of tcp, mqtt, ssl, tls, mqtts, ws, wss",
+		},
+		{
+			name:              "Error if topic is missing",
+			settings:          `{"brokerUrl": "tcp://localhost:1883"}`,
+			expectedInitError: "required field 'topic' is not specified",
+		},
+		{
+			name:              "Error if qos is invalid",
+			settings:          `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": 3}`,
+			expectedInitError: `invalid qos "3": must be 0, 1 or 2`,
+		},
+		{
+			name:     "Minimal valid configuration",
+			settings: `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts"}`,
+			expectedConfig: MQTTConfig{
+				Config: Config{
+					Topic:   "alerts",
+					Title:   templates.DefaultMessageTitleEmbed,
+					Message: templates.DefaultMessageEmbed,
+				},
+				BrokerURL: "tcp://localhost:1883",
+			},
+		},
+		{
+			name:     "Should parse qos as number",
+			settings: `{"brokerUrl": "tcp://localhost:1883", "topic": "alerts", "qos": 2}`,
+			expectedConfig: MQTTConfig{
+				Config: Config{
+					Topic:   "alerts",
+					Title:   templates.DefaultMessageTitleEmbed,
+					Message: templates.DefaultMessageEmbed,
+				},
+				BrokerURL: "tcp://localhost:1883",
+				QoS:       2,
+			},
+		},
+		{
+			name:     "Extracts all fields",
+			settings: withTestCertificates(t, FullValidMQTTConfigForTesting),
+			expectedConfig: MQTTConfig{
+				Config: Config{
+					Topic:     "test/topic",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				BrokerURL: "tcp://localhost:1883",
+				ClientID:  "test-client",
+				Username:  "test-user",
+				Password:  "test-pass",
+				QoS:       1,
+				Retain:    true,
+			},
+		},
+		{
+			name:           "Extracts all fields + override from secrets",
+			settings:       withTestCertificates(t, FullValidMQTTConfigForTesting),
+			secureSettings: secretsForTesting(t),
+			expectedConfig: MQTTConfig{
+				Config: Config{
+					Topic:     "test/topic",
+					MaxAlerts: 2,
+					Title:     "test-title",
+					Message:   "test-message",
+					TLS: TLSConfig{
+						InsecureSkipVerify: true,
+						CACertificate:      testCertificate,
+						ClientCertificate:  testCertificate,
+						ClientKey:          testKey,
+					},
+				},
+				BrokerURL: "tcp://localhost:1883",
+				ClientID:  "test-client",
+				Username:  "test-user",
+				Password:  "test-secret-pass",
+				QoS:       1,
+				Retain:    true,
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			actual, err := NewMQTTConfig(json.RawMessage(c.settings), receiversTesting.DecryptForTesting(c.secureSettings))
+			if c.expectedInitError != "" {
+				require.ErrorContains(t, err, c.expectedInitError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expectedConfig, actual)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	webhook := &alertingNotify.GrafanaIntegrationConfig{UID: "webhook", Type: "webhook"}
+	amqp := &alertingNotify.GrafanaIntegrationConfig{UID: "amqp", Type: "amqp"}
+	mqtt := &alertingNotify.GrafanaIntegrationConfig{UID: "mqtt", Type: "MQTT"}
+
+	t.Run("should return receiver as is if it has no message bus integrations", func(t *testing.T) {
+		receiver := &alertingNotify.APIReceiver{
+			ConfigReceiver:      alertingNotify.ConfigReceiver{Name: "test"},
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{webhook}},
+		}
+		other, messageBus := Split(receiver)
+		require.Same(t, receiver, other)
+		require.Empty(t, messageBus)
+	})
+
+	t.Run("should split message bus integrations", func(t *testing.T) {
+		receiver := &alertingNotify.APIReceiver{
+			ConfigReceiver:      alertingNotify.ConfigReceiver{Name: "test"},
+			GrafanaIntegrations: alertingNotify.GrafanaIntegrations{Integrations: []*alertingNotify.GrafanaIntegrationConfig{amqp, webhook, mqtt}},
+		}
+		other, messageBus := Split(receiver)
+		require.Equal(t, "test", other.Name)
+		require.Equal(t, []*alertingNotify.GrafanaIntegrationConfig{webhook}, other.Integrations)
+		require.Equal(t, []*alertingNotify.GrafanaIntegrationConfig{amqp, mqtt}, messageBus)
+		// The original receiver is not modified
+		require.Len(t, receiver.Integrations, 3)
+	})
+}
+
+func TestBuildIntegrations(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	loggerFactory := func(_ string, _ ...any) logging.Logger { return &logging.FakeLogger{} }
+	decrypt := func(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+		return receiversTesting.DecryptForTesting(sjd)(key, fallback)
+	}
+
+	cfgs := []*alertingNotify.GrafanaIntegrationConfig{
+		{UID: "1", Name: "amqp-1", Type: AMQPType, Settings: json.RawMessage(`{"url": "amqp://localhost", "routingKey": "alerts"}`)},
+		{UID: "2", Name: "nats-1", Type: NATSType, Settings: json.RawMessage(`{"url": "nats://localhost", "subject": "alerts"}`)},
+		{UID: "3", Name: "amqp-2", Type: AMQPType, Settings: json.RawMessage(`{"url": "amqp://localhost", "routingKey": "alerts"}`), DisableResolveMessage: true},
+		{
+			UID: "4", Name: "mqtt-1", Type: MQTTType, Settings: json.RawMessage(`{"brokerUrl": "tcp://localhost:1883", "topic": "alerts"}`),
+			SecureSettings: map[string]string{"password": base64.StdEncoding.EncodeToString([]byte("secret"))},
+		},
+	}
+
+	t.Run("should build integrations with indices per type", func(t *testing.T) {
+		integrations, err := BuildIntegrations(context.Background(), cfgs, decrypt, tmpl, &images.UnavailableProvider{}, loggerFactory, 1)
+		require.NoError(t, err)
+		require.Len(t, integrations, 4)
+
+		expected := []string{"amqp[0]", "nats[0]", "amqp[1]", "mqtt[0]"}
+		for i, integration := range integrations {
+			assert.Equal(t, expected[i], integration.String())
+		}
+		assert.True(t, integrations[0].SendResolved())
+		assert.False(t, integrations[2].SendResolved())
+	})
+
+	t.Run("should return validation errors for all invalid integrations", func(t *testing.T) {
+		invalid := []*alertingNotify.GrafanaIntegrationConfig{
+			cfgs[0],
+			{UID: "5", Name: "nats-2", Type: NATSType, Settings: json.RawMessage(`{"url": "nats://localhost"}`)},
+			{UID: "6", Name: "mqtt-2", Type: MQTTType, Settings: json.RawMessage(`{"topic": "alerts"}`)},
+		}
+		_, err := BuildIntegrations(context.Background(), invalid, decrypt, tmpl, &images.UnavailableProvider{}, loggerFactory, 1)
+		require.ErrorContains(t, err, "required field 'subject' is not specified")
+		require.ErrorContains(t, err, "required field 'brokerUrl' is not specified")
+
+		var validationErr alertingNotify.IntegrationValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, "5", validationErr.Integration.UID)
+	})
+
+	t.Run("should fail validation if secure settings cannot be decoded", func(t *testing.T) {
+		cfg := &alertingNotify.GrafanaIntegrationConfig{
+			UID: "7", Type: NATSType, Settings: json.RawMessage(`{"url": "nats://localhost", "subject": "alerts"}`),
+			SecureSettings: map[string]string{"token": "not base64"},
+		}
+		err := ValidateIntegration(context.Background(), cfg, decrypt)
+		require.ErrorContains(t, err, `failed to decode secure setting with key "token"`)
+	})
+}
+
+type fakePublisher struct {
+	topic string
+	body  []byte
+	err   error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, body []byte) error {
+	p.topic = topic
+	p.body = body
+	return p.err
+}
+
+func TestNotify(t *testing.T) {
+	tmpl := templates.ForTests(t)
+	externalURL, err := url.Parse("http://localhost")
+	require.NoError(t, err)
+	tmpl.ExternalURL = externalURL
+
+	alerts := []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val1"},
+				Annotations: model.LabelSet{"ann1": "annv1"},
+			},
+		},
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "alert1", "lbl1": "val2"},
+				Annotations: model.LabelSet{"ann1": "annv2"},
+			},
+		},
+	}
+
+	newNotifier := func(cfg Config, p Publisher) *Notifier {
+		return New(cfg, receivers.Metadata{Name: "test", Type: AMQPType}, p, tmpl, &images.UnavailableProvider{}, &logging.FakeLogger{}, 1)
+	}
+	newContext := func() context.Context {
+		ctx := notify.WithGroupKey(context.Background(), "alertname")
+		ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": ""})
+		return notify.WithReceiverName(ctx, "my_receiver")
+	}
+
+	t.Run("should publish the webhook payload to the templated topic", func(t *testing.T) {
+		p := &fakePublisher{}
+		n := newNotifier(Config{
+			Topic:     `alerts.{{ .CommonLabels.alertname }}`,
+			MaxAlerts: 1,
+			Title:     templates.DefaultMessageTitleEmbed,
+			Message:   "Custom message",
+		}, p)
+
+		ok, err := n.Notify(newContext(), alerts...)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "alerts.alert1", p.topic)
+
+		var msg message
+		require.NoError(t, json.Unmarshal(p.body, &msg))
+		assert.Equal(t, "1", msg.Version)
+		assert.Equal(t, "alertname", msg.GroupKey)
+		assert.Equal(t, int64(1), msg.OrgID)
+		assert.Equal(t, "alerting", msg.State)
+		assert.Equal(t, "Custom message", msg.Message)
+		assert.Equal(t, "[FIRING:1]  (val1)", msg.Title)
+		assert.Equal(t, "my_receiver", msg.Receiver)
+		assert.Equal(t, 1, msg.TruncatedAlerts)
+		require.Len(t, msg.Alerts, 1)
+		assert.Equal(t, "val1", msg.Alerts[0].Labels["lbl1"])
+	})
+
+	t.Run("should return error if the topic cannot be templated", func(t *testing.T) {
+		p := &fakePublisher{}
+		n := newNotifier(Config{Topic: `{{ len Alerts }}`}, p)
+
+		ok, err := n.Notify(newContext(), alerts...)
+		require.ErrorContains(t, err, "failed to template topic")
+		require.False(t, ok)
+		require.Nil(t, p.body)
+	})
+
+	t.Run("should return error if the topic is empty", func(t *testing.T) {
+		p := &fakePublisher{}
+		n := newNotifier(Config{Topic: `{{ .CommonLabels.missing }}`}, p)
+
+		ok, err := n.Notify(newContext(), alerts...)
+		require.EqualError(t, err, "topic is empty")
+		require.False(t, ok)
+	})
+
+	t.Run("should retry if publishing fails", func(t *testing.T) {
+		p := &fakePublisher{err: errors.New("connection refused")}
+		n := newNotifier(Config{Topic: "alerts"}, p)
+
+		ok, err := n.Notify(newContext(), alerts...)
+		require.EqualError(t, err, "connection refused")
+		require.True(t, ok)
+	})
+}
+
+func TestPublishInvalidTopic(t *testing.T) {
+	t.Run("NATS subjects must not contain wildcards", func(t *testing.T) {
+		err := NewNATSPublisher(NATSConfig{URL: "nats://localhost:1"}).Publish(context.Background(), "alerts.>", nil)
+		require.ErrorContains(t, err, "subjects must not contain whitespace or wildcards")
+	})
+
+	t.Run("MQTT topics must not contain wildcards", func(t *testing.T) {
+		err := NewMQTTPublisher(MQTTConfig{BrokerURL: "tcp://localhost:1"}).Publish(context.Background(), "alerts/#", nil)
+		require.ErrorContains(t, err, "topics must not contain wildcards")
+	})
+}
+
+// withTestCertificates adds the test certificate and key to the TLS settings of a configuration.
+func withTestCertificates(t *testing.T, settings string) string {
+	t.Helper()
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(settings), &m))
+	m["tlsCACertificate"] = testCertificate
+	m["tlsClientCertificate"] = testCertificate
+	m["tlsClientKey"] = testKey
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func secretsForTesting(t *testing.T) map[string][]byte {
+	t.Helper()
+	var secrets map[string]string
+	require.NoError(t, json.Unmarshal([]byte(FullValidSecretsForTesting), &secrets))
+	result := make(map[string][]byte, len(secrets))
+	for key, value := range secrets {
+		result[key] = []byte(value)
+	}
+	result["tlsClientKey"] = []byte(testKey)
+	return result
+}
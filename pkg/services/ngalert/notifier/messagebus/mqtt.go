@@ -0,0 +1,146 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/grafana/alerting/receivers"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// mqttSchemes are the schemes of broker URLs that are supported by the MQTT client.
+var mqttSchemes = []string{"tcp", "mqtt", "ssl", "tls", "mqtts", "ws", "wss"}
+
+// MQTTConfig contains the settings of integrations that publish notifications to MQTT brokers.
+type MQTTConfig struct {
+	Config
+	// BrokerURL is the URL of the broker, such as tcp://localhost:1883. The schemes ssl, tls and mqtts
+	// are used for TLS, and the schemes ws and wss for WebSockets.
+	BrokerURL string
+	// ClientID is the client identifier. A random client identifier is used if it is empty. Brokers
+	// disconnect clients with the same identifier, so it should only be set if the broker requires it.
+	ClientID string
+	Username string
+	Password string
+	QoS      byte
+	Retain   bool
+}
+
+func NewMQTTConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (MQTTConfig, error) {
+	rawSettings := struct {
+		rawConfig
+		BrokerURL string                   `json:"brokerUrl,omitempty" yaml:"brokerUrl,omitempty"`
+		Topic     string                   `json:"topic,omitempty" yaml:"topic,omitempty"`
+		ClientID  string                   `json:"clientId,omitempty" yaml:"clientId,omitempty"`
+		Username  string                   `json:"username,omitempty" yaml:"username,omitempty"`
+		Password  string                   `json:"password,omitempty" yaml:"password,omitempty"`
+		QoS       receivers.OptionalNumber `json:"qos,omitempty" yaml:"qos,omitempty"`
+		Retain    bool                     `json:"retain,omitempty" yaml:"retain,omitempty"`
+	}{}
+	if err := json.Unmarshal(jsonData, &rawSettings); err != nil {
+		return MQTTConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.BrokerURL == "" {
+		return MQTTConfig{}, errors.New("required field 'brokerUrl' is not specified")
+	}
+	u, err := url.Parse(rawSettings.BrokerURL)
+	if err != nil {
+		return MQTTConfig{}, fmt.Errorf("invalid brokerUrl: %w", err)
+	}
+	if !slices.Contains(mqttSchemes, strings.ToLower(u.Scheme)) {
+		return MQTTConfig{}, fmt.Errorf("invalid brokerUrl: scheme must be one of %s", strings.Join(mqttSchemes, ", "))
+	}
+	if rawSettings.Topic == "" {
+		return MQTTConfig{}, errors.New("required field 'topic' is not specified")
+	}
+
+	var qos int
+	if rawSettings.QoS != "" {
+		qos, err = strconv.Atoi(rawSettings.QoS.String())
+		if err != nil || qos < 0 || qos > 2 {
+			return MQTTConfig{}, fmt.Errorf("invalid qos %q: must be 0, 1 or 2", rawSettings.QoS.String())
+		}
+	}
+
+	common, err := newConfig(rawSettings.rawConfig, rawSettings.Topic, decryptFn)
+	if err != nil {
+		return MQTTConfig{}, err
+	}
+	return MQTTConfig{
+		Config:    common,
+		BrokerURL: rawSettings.BrokerURL,
+		ClientID:  rawSettings.ClientID,
+		Username:  rawSettings.Username,
+		Password:  decryptFn("password", rawSettings.Password),
+		QoS:       byte(qos),
+		Retain:    rawSettings.Retain,
+	}, nil
+}
+
+// MQTTPublisher publishes messages to topics of an MQTT broker. A connection is opened for each
+// message because notifications are infrequent.
+type MQTTPublisher struct {
+	cfg MQTTConfig
+}
+
+func NewMQTTPublisher(cfg MQTTConfig) *MQTTPublisher {
+	return &MQTTPublisher{cfg: cfg}
+}
+
+// Publish publishes the message to the topic and waits until it has been delivered with the
+// configured quality of service.
+func (p *MQTTPublisher) Publish(ctx context.Context, topic string, body []byte) error {
+	if strings.ContainsAny(topic, "+#") {
+		return fmt.Errorf("invalid topic %q: topics must not contain wildcards", topic)
+	}
+
+	clientID := p.cfg.ClientID
+	if clientID == "" {
+		clientID = "grafana-alerting-" + util.GenerateShortUID()
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(p.cfg.BrokerURL).
+		SetClientID(clientID).
+		SetCleanSession(true).
+		SetAutoReconnect(false).
+		SetConnectTimeout(connectTimeout)
+	if p.cfg.Username != "" {
+		opts.SetUsername(p.cfg.Username)
+		opts.SetPassword(p.cfg.Password)
+	}
+	tlsConfig, err := p.cfg.TLS.Build()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(opts)
+	if err := waitForToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	if err := waitForToken(ctx, client.Publish(topic, p.cfg.QoS, p.cfg.Retain, body)); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	return nil
+}
+
+func waitForToken(ctx context.Context, t mqtt.Token) error {
+	select {
+	case <-t.Done():
+		return t.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/alerting/receivers"
+	"github.com/nats-io/nats.go"
+)
+
+// NATSConfig contains the settings of integrations that publish notifications to NATS.
+type NATSConfig struct {
+	Config
+	// URL is a comma separated list of server URLs, such as nats://localhost:4222. The scheme tls is
+	// used for TLS.
+	URL      string
+	Username string
+	Password string
+	Token    string
+	// JetStream is true if messages are published to a JetStream stream, in which case the server
+	// acknowledges that the message has been stored.
+	JetStream bool
+}
+
+func NewNATSConfig(jsonData json.RawMessage, decryptFn receivers.DecryptFunc) (NATSConfig, error) {
+	rawSettings := struct {
+		rawConfig
+		URL       string `json:"url,omitempty" yaml:"url,omitempty"`
+		Subject   string `json:"subject,omitempty" yaml:"subject,omitempty"`
+		Username  string `json:"username,omitempty" yaml:"username,omitempty"`
+		Password  string `json:"password,omitempty" yaml:"password,omitempty"`
+		Token     string `json:"token,omitempty" yaml:"token,omitempty"`
+		JetStream bool   `json:"jetStream,omitempty" yaml:"jetStream,omitempty"`
+	}{}
+	if err := json.Unmarshal(jsonData, &rawSettings); err != nil {
+		return NATSConfig{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+	if rawSettings.URL == "" {
+		return NATSConfig{}, errors.New("required field 'url' is not specified")
+	}
+	if rawSettings.Subject == "" {
+		return NATSConfig{}, errors.New("required field 'subject' is not specified")
+	}
+
+	common, err := newConfig(rawSettings.rawConfig, rawSettings.Subject, decryptFn)
+	if err != nil {
+		return NATSConfig{}, err
+	}
+	settings := NATSConfig{
+		Config:    common,
+		URL:       rawSettings.URL,
+		Username:  rawSettings.Username,
+		Password:  decryptFn("password", rawSettings.Password),
+		Token:     decryptFn("token", rawSettings.Token),
+		JetStream: rawSettings.JetStream,
+	}
+	if settings.Token != "" && settings.Username != "" {
+		return NATSConfig{}, errors.New("both username and token are set, only 1 is permitted")
+	}
+	return settings, nil
+}
+
+// NATSPublisher publishes messages to NATS subjects. A connection is opened for each message
+// because notifications are infrequent.
+type NATSPublisher struct {
+	cfg NATSConfig
+}
+
+func NewNATSPublisher(cfg NATSConfig) *NATSPublisher {
+	return &NATSPublisher{cfg: cfg}
+}
+
+// Publish publishes the message to the subject and waits until the server has received it.
+func (p *NATSPublisher) Publish(ctx context.Context, subject string, body []byte) error {
+	if strings.ContainsAny(subject, " \t\r\n*>") {
+		return fmt.Errorf("invalid subject %q: subjects must not contain whitespace or wildcards", subject)
+	}
+
+	opts := []nats.Option{
+		nats.Name("grafana-alerting"),
+		nats.Timeout(connectTimeout),
+		nats.NoReconnect(),
+	}
+	if p.cfg.Username != "" {
+		opts = append(opts, nats.UserInfo(p.cfg.Username, p.cfg.Password))
+	}
+	if p.cfg.Token != "" {
+		opts = append(opts, nats.Token(p.cfg.Token))
+	}
+	tlsConfig, err := p.cfg.TLS.Build()
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		opts = append(opts, nats.Secure(tlsConfig))
+	}
+
+	// The NATS client requires a deadline to wait for the server
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, publishTimeout)
+		defer cancel()
+	}
+
+	nc, err := nats.Connect(p.cfg.URL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer nc.Close()
+
+	msg := nats.NewMsg(subject)
+	msg.Header.Set("Content-Type", "application/json")
+	msg.Data = body
+
+	if p.cfg.JetStream {
+		js, err := nc.JetStream()
+		if err != nil {
+			return fmt.Errorf("failed to create JetStream context: %w", err)
+		}
+		if _, err := js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+			return fmt.Errorf("failed to publish: %w", err)
+		}
+		return nil
+	}
+
+	if err := nc.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+	if err := nc.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush: %w", err)
+	}
+	return nil
+}
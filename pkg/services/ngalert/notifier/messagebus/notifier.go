@@ -0,0 +1,189 @@
+package messagebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/alerting/images"
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+)
+
+// Publisher publishes messages to a message broker.
+type Publisher interface {
+	// Publish publishes the body to the topic. The topic is the routing key for AMQP, the subject
+	// for NATS and the topic for MQTT.
+	Publish(ctx context.Context, topic string, body []byte) error
+}
+
+// Config contains the settings that are common to all integrations in this package.
+type Config struct {
+	// Topic is the templated routing key, subject or topic that notifications are published to.
+	Topic     string
+	MaxAlerts int
+	Title     string
+	Message   string
+	TLS       TLSConfig
+}
+
+// rawConfig contains the settings that are common to all integrations in this package as they are stored.
+type rawConfig struct {
+	MaxAlerts            receivers.OptionalNumber `json:"maxAlerts,omitempty" yaml:"maxAlerts,omitempty"`
+	Title                string                   `json:"title,omitempty" yaml:"title,omitempty"`
+	Message              string                   `json:"message,omitempty" yaml:"message,omitempty"`
+	TLSSkipVerify        bool                     `json:"tlsSkipVerify,omitempty" yaml:"tlsSkipVerify,omitempty"`
+	TLSCACertificate     string                   `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty"`
+	TLSClientCertificate string                   `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty"`
+	TLSClientKey         string                   `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty"`
+}
+
+func newConfig(raw rawConfig, topic string, decryptFn receivers.DecryptFunc) (Config, error) {
+	settings := Config{
+		Topic:   topic,
+		Title:   raw.Title,
+		Message: raw.Message,
+		TLS: TLSConfig{
+			InsecureSkipVerify: raw.TLSSkipVerify,
+			CACertificate:      raw.TLSCACertificate,
+			ClientCertificate:  raw.TLSClientCertificate,
+			ClientKey:          decryptFn("tlsClientKey", raw.TLSClientKey),
+		},
+	}
+	if raw.MaxAlerts != "" {
+		maxAlerts, err := strconv.Atoi(raw.MaxAlerts.String())
+		if err != nil || maxAlerts < 0 {
+			return Config{}, fmt.Errorf("invalid maxAlerts %q: must be a positive number", raw.MaxAlerts.String())
+		}
+		settings.MaxAlerts = maxAlerts
+	}
+	if settings.Title == "" {
+		settings.Title = templates.DefaultMessageTitleEmbed
+	}
+	if settings.Message == "" {
+		settings.Message = templates.DefaultMessageEmbed
+	}
+	// Make sure the certificates can be parsed when the configuration is saved
+	if _, err := settings.TLS.Build(); err != nil {
+		return Config{}, err
+	}
+	return settings, nil
+}
+
+// Notifier publishes notifications to a message broker. The notifications have the same payload
+// as notifications sent by the webhook integration.
+type Notifier struct {
+	*receivers.Base
+	log       logging.Logger
+	publisher Publisher
+	images    images.Provider
+	tmpl      *templates.Template
+	orgID     int64
+	settings  Config
+}
+
+// New is the constructor for notifiers that publish notifications to message brokers.
+func New(cfg Config, meta receivers.Metadata, publisher Publisher, template *templates.Template, images images.Provider, logger logging.Logger, orgID int64) *Notifier {
+	return &Notifier{
+		Base:      receivers.NewBase(meta),
+		log:       logger,
+		publisher: publisher,
+		images:    images,
+		tmpl:      template,
+		orgID:     orgID,
+		settings:  cfg,
+	}
+}
+
+// message is the payload of notifications. It matches the payload of the webhook integration.
+type message struct {
+	*templates.ExtendedData
+
+	// The protocol version.
+	Version         string `json:"version"`
+	GroupKey        string `json:"groupKey"`
+	TruncatedAlerts int    `json:"truncatedAlerts"`
+	OrgID           int64  `json:"orgId"`
+	Title           string `json:"title"`
+	State           string `json:"state"`
+	Message         string `json:"message"`
+}
+
+// Notify implements the Notifier interface.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, err := notify.ExtractGroupKey(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	as, numTruncated := truncateAlerts(n.settings.MaxAlerts, as)
+	var tmplErr error
+	tmpl, data := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	// Augment our Alert data with ImageURLs if available.
+	_ = images.WithStoredImages(ctx, n.log, n.images,
+		func(index int, image images.Image) error {
+			if len(image.URL) != 0 {
+				data.Alerts[index].ImageURL = image.URL
+			}
+			return nil
+		},
+		as...)
+
+	msg := &message{
+		Version:         "1",
+		ExtendedData:    data,
+		GroupKey:        groupKey.String(),
+		TruncatedAlerts: numTruncated,
+		OrgID:           n.orgID,
+		Title:           tmpl(n.settings.Title),
+		Message:         tmpl(n.settings.Message),
+	}
+	if types.Alerts(as...).Status() == model.AlertFiring {
+		msg.State = string(receivers.AlertStateAlerting)
+	} else {
+		msg.State = string(receivers.AlertStateOK)
+	}
+
+	if tmplErr != nil {
+		n.log.Warn("Failed to template message", "error", tmplErr.Error())
+		tmplErr = nil
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return false, err
+	}
+
+	topic := tmpl(n.settings.Topic)
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template topic: %w", tmplErr)
+	}
+	if topic == "" {
+		return false, errors.New("topic is empty")
+	}
+
+	if err := n.publisher.Publish(ctx, topic, body); err != nil {
+		// Most errors are caused by the broker being unavailable, so the notification should be retried.
+		return true, err
+	}
+	return true, nil
+}
+
+func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
+	if maxAlerts > 0 && len(alerts) > maxAlerts {
+		return alerts[:maxAlerts], len(alerts) - maxAlerts
+	}
+
+	return alerts, 0
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
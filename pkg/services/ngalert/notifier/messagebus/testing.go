@@ -0,0 +1,73 @@
+package messagebus
+
+import (
+	alertingNotify "github.com/grafana/alerting/notify"
+)
+
+// FullValidAMQPConfigForTesting is a string representation of a JSON object that contains all fields supported by AMQPConfig
+// but the TLS certificates. It can be used without secrets.
+const FullValidAMQPConfigForTesting = `{
+	"url": "amqp://localhost:5672/",
+	"exchange": "test-exchange",
+	"routingKey": "test-routing-key",
+	"username": "test-user",
+	"password": "test-pass",
+	"maxAlerts": "2",
+	"title": "test-title",
+	"message": "test-message",
+	"tlsSkipVerify": true
+}`
+
+// FullValidNATSConfigForTesting is a string representation of a JSON object that contains all fields supported by NATSConfig
+// but the TLS certificates. It can be used without secrets.
+const FullValidNATSConfigForTesting = `{
+	"url": "nats://localhost:4222",
+	"subject": "test-subject",
+	"jetStream": true,
+	"password": "test-pass",
+	"token": "test-token",
+	"maxAlerts": "2",
+	"title": "test-title",
+	"message": "test-message",
+	"tlsSkipVerify": true
+}`
+
+// FullValidMQTTConfigForTesting is a string representation of a JSON object that contains all fields supported by MQTTConfig
+// but the TLS certificates. It can be used without secrets.
+const FullValidMQTTConfigForTesting = `{
+	"brokerUrl": "tcp://localhost:1883",
+	"topic": "test/topic",
+	"clientId": "test-client",
+	"username": "test-user",
+	"password": "test-pass",
+	"qos": "1",
+	"retain": true,
+	"maxAlerts": "2",
+	"title": "test-title",
+	"message": "test-message",
+	"tlsSkipVerify": true
+}`
+
+// FullValidSecretsForTesting is a string representation of JSON object that contains all fields that can be overridden from secrets
+// but the TLS client key.
+const FullValidSecretsForTesting = `{
+	"password": "test-secret-pass",
+	"token": "test-secret-token"
+}`
+
+// AllKnownConfigsForTesting contains the configurations of all integrations in this package, in the same format
+// as notify.AllKnownConfigsForTesting in the alerting module.
+var AllKnownConfigsForTesting = map[string]alertingNotify.NotifierConfigTest{
+	AMQPType: {NotifierType: AMQPType,
+		Config:  FullValidAMQPConfigForTesting,
+		Secrets: FullValidSecretsForTesting,
+	},
+	NATSType: {NotifierType: NATSType,
+		Config:  FullValidNATSConfigForTesting,
+		Secrets: FullValidSecretsForTesting,
+	},
+	MQTTType: {NotifierType: MQTTType,
+		Config:  FullValidMQTTConfigForTesting,
+		Secrets: FullValidSecretsForTesting,
+	},
+}
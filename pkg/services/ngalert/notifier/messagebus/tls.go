@@ -0,0 +1,58 @@
+package messagebus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// TLSConfig contains the TLS settings that are used to connect to a message broker.
+type TLSConfig struct {
+	InsecureSkipVerify bool
+	// CACertificate is a PEM encoded certificate of a CA that is trusted in addition to the system CAs.
+	CACertificate string
+	// ClientCertificate and ClientKey are a PEM encoded certificate and key that are used to
+	// authenticate with the broker.
+	ClientCertificate string
+	ClientKey         string
+}
+
+// IsSet returns true if any TLS setting is set.
+func (c TLSConfig) IsSet() bool {
+	return c.InsecureSkipVerify || c.CACertificate != "" || c.ClientCertificate != "" || c.ClientKey != ""
+}
+
+// Build returns the tls.Config for the settings, or nil if no TLS setting is set.
+func (c TLSConfig) Build() (*tls.Config, error) {
+	if !c.IsSet() {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// nolint:gosec
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CACertificate != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(c.CACertificate)) {
+			return nil, errors.New("failed to parse the CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+	if c.ClientCertificate != "" || c.ClientKey != "" {
+		if c.ClientCertificate == "" || c.ClientKey == "" {
+			return nil, errors.New("both the client certificate and the client key must be set")
+		}
+		cert, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the client certificate and key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
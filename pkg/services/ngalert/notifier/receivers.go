@@ -125,3 +125,9 @@ func (am *alertmanager) GetReceivers(_ context.Context) ([]apimodels.Receiver, e
 
 	return apiReceivers, nil
 }
+
+// GetReceiversHealth returns the health of the integrations of all receivers since the
+// Alertmanager was started.
+func (am *alertmanager) GetReceiversHealth(_ context.Context) ([]apimodels.ReceiverHealth, error) {
+	return am.health.receiversHealth(), nil
+}
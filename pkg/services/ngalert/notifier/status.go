@@ -17,6 +17,8 @@ func (am *alertmanager) GetStatus() apimodels.GettableStatus {
 	if err := json.Unmarshal(status, config); err != nil {
 		am.logger.Error("Unable to unmarshall alertmanager config", "Err", err)
 	}
+	// The autogenerated notification policy is an implementation detail of the notification settings of rules.
+	RemoveAutogenConfigIfExists(config.AlertmanagerConfig.Route)
 
 	return *apimodels.NewGettableStatus(&config.AlertmanagerConfig)
 }
@@ -19,6 +19,9 @@ type fakeConfigStore struct {
 
 	// historicConfigs stores configs by orgID.
 	historicConfigs map[int64][]*models.HistoricAlertConfiguration
+
+	// notificationSettings stores the notification settings of alert rules by orgID.
+	notificationSettings map[int64]map[models.AlertRuleKey][]models.NotificationSettings
 }
 
 // Saves the image or returns an error.
@@ -57,6 +60,19 @@ func NewFakeConfigStore(t *testing.T, configs map[int64]*models.AlertConfigurati
 	}
 }
 
+func (f *fakeConfigStore) ListNotificationSettings(_ context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error) {
+	result := make(map[models.AlertRuleKey][]models.NotificationSettings)
+	for key, settings := range f.notificationSettings[q.OrgID] {
+		for _, s := range settings {
+			if q.ReceiverName != "" && s.Receiver != q.ReceiverName {
+				continue
+			}
+			result[key] = append(result[key], s)
+		}
+	}
+	return result, nil
+}
+
 func (f *fakeConfigStore) GetAllLatestAlertmanagerConfiguration(context.Context) ([]*models.AlertConfiguration, error) {
 	result := make([]*models.AlertConfiguration, 0, len(f.configs))
 	for _, configuration := range f.configs {
@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// NotificationSettingsValidator validates the notification settings of alert rules against an Alertmanager configuration.
+type NotificationSettingsValidator interface {
+	Validate(settings models.NotificationSettings) error
+}
+
+// staticValidator validates notification settings against the receivers and mute time intervals of a configuration.
+type staticValidator struct {
+	availableReceivers     map[string]struct{}
+	availableTimeIntervals map[string]struct{}
+}
+
+// NewNotificationSettingsValidator creates a NotificationSettingsValidator for the configuration.
+func NewNotificationSettingsValidator(cfg *definitions.PostableApiAlertingConfig) NotificationSettingsValidator {
+	availableReceivers := make(map[string]struct{}, len(cfg.Receivers))
+	for _, receiver := range cfg.Receivers {
+		availableReceivers[receiver.Name] = struct{}{}
+	}
+	availableTimeIntervals := make(map[string]struct{}, len(cfg.MuteTimeIntervals))
+	for _, interval := range cfg.MuteTimeIntervals {
+		availableTimeIntervals[interval.Name] = struct{}{}
+	}
+	return staticValidator{
+		availableReceivers:     availableReceivers,
+		availableTimeIntervals: availableTimeIntervals,
+	}
+}
+
+// Validate checks that the settings are valid, and that the receiver and mute time intervals exist.
+func (v staticValidator) Validate(settings models.NotificationSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	var errs []error
+	if _, ok := v.availableReceivers[settings.Receiver]; !ok {
+		errs = append(errs, fmt.Errorf("receiver '%s' does not exist", settings.Receiver))
+	}
+	for _, interval := range settings.MuteTimeIntervals {
+		if _, ok := v.availableTimeIntervals[interval]; !ok {
+			errs = append(errs, fmt.Errorf("mute time interval '%s' does not exist", interval))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", models.ErrNotificationSettingsInvalid, errors.Join(errs...))
+	}
+	return nil
+}
+
+// NotificationSettingsValidationService validates notification settings against the latest Alertmanager
+// configuration of an organization.
+type NotificationSettingsValidationService struct {
+	store configurationStore
+}
+
+func NewNotificationSettingsValidationService(store configurationStore) *NotificationSettingsValidationService {
+	return &NotificationSettingsValidationService{store: store}
+}
+
+// ValidateNotificationSettings validates the notification settings against the latest Alertmanager configuration
+// of the organization. It returns an error that wraps models.ErrNotificationSettingsInvalid if any of them are invalid.
+func (s *NotificationSettingsValidationService) ValidateNotificationSettings(ctx context.Context, orgID int64, settings ...models.NotificationSettings) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	cfg, err := s.store.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to get the Alertmanager configuration: %w", err)
+	}
+	amConfig, err := Load([]byte(cfg.AlertmanagerConfiguration))
+	if err != nil {
+		return fmt.Errorf("failed to parse the Alertmanager configuration: %w", err)
+	}
+	validator := NewNotificationSettingsValidator(&amConfig.AlertmanagerConfig)
+	for _, s := range settings {
+		if err := validator.Validate(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -22,6 +22,7 @@ type AlertRuleService struct {
 	dashboardService       dashboards.DashboardService
 	quotas                 QuotaChecker
 	xact                   TransactionManager
+	nsValidator            NotificationSettingsValidator
 	log                    log.Logger
 }
 
@@ -30,6 +31,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 	dashboardService dashboards.DashboardService,
 	quotas QuotaChecker,
 	xact TransactionManager,
+	nsValidator NotificationSettingsValidator,
 	defaultIntervalSeconds int64,
 	baseIntervalSeconds int64,
 	log log.Logger) *AlertRuleService {
@@ -41,6 +43,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		dashboardService:       dashboardService,
 		quotas:                 quotas,
 		xact:                   xact,
+		nsValidator:            nsValidator,
 		log:                    log,
 	}
 }
@@ -133,6 +136,9 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.validateNotificationSettings(ctx, rule.OrgID, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	rule.Updated = time.Now()
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		ids, err := service.ruleStore.InsertAlertRules(ctx, []models.AlertRule{
@@ -273,6 +279,14 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, orgID int
 		return nil
 	}
 
+	changed := withoutNilAlertRules(delta.New)
+	for _, update := range delta.Update {
+		changed = append(changed, *update.New)
+	}
+	if err := service.validateNotificationSettings(ctx, orgID, changed...); err != nil {
+		return err
+	}
+
 	return service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		// Delete first as this could prevent future unique constraint violations.
 		if len(delta.Delete) > 0 {
@@ -353,6 +367,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	if err != nil {
 		return models.AlertRule{}, err
 	}
+	if err := service.validateNotificationSettings(ctx, rule.OrgID, rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	err = service.xact.InTransaction(ctx, func(ctx context.Context) error {
 		err := service.ruleStore.UpdateAlertRules(ctx, []models.UpdateRule{
 			{
@@ -371,6 +388,22 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, rule model
 	return rule, err
 }
 
+// validateNotificationSettings checks that the receivers and mute time intervals in the notification settings
+// of the rules exist in the Alertmanager configuration of the organization.
+func (service *AlertRuleService) validateNotificationSettings(ctx context.Context, orgID int64, rules ...models.AlertRule) error {
+	var settings []models.NotificationSettings
+	for _, rule := range rules {
+		settings = append(settings, rule.NotificationSettings...)
+	}
+	if len(settings) == 0 {
+		return nil
+	}
+	if err := service.nsValidator.ValidateNotificationSettings(ctx, orgID, settings...); err != nil {
+		return errors.Join(models.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
+
 func (service *AlertRuleService) DeleteAlertRule(ctx context.Context, orgID int64, ruleUID string, provenance models.Provenance) error {
 	rule := &models.AlertRule{
 		OrgID: orgID,
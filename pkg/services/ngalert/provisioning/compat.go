@@ -33,6 +33,7 @@ func PostableGrafanaReceiverToEmbeddedContactPoint(contactPoint *definitions.Pos
 		Type:                  contactPoint.Type,
 		Name:                  contactPoint.Name,
 		DisableResolveMessage: contactPoint.DisableResolveMessage,
+		FallbackAfterFailures: contactPoint.FallbackAfterFailures,
 		Settings:              simpleJson,
 		Provenance:            string(provenance),
 	}
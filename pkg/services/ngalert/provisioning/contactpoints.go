@@ -17,6 +17,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/messagebus"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -172,6 +173,7 @@ func (ecp *ContactPointService) CreateContactPoint(ctx context.Context, orgID in
 		DisableResolveMessage: contactPoint.DisableResolveMessage,
 		Settings:              jsonData,
 		SecureSettings:        extractedSecrets,
+		FallbackAfterFailures: contactPoint.FallbackAfterFailures,
 	}
 
 	receiverFound := false
@@ -201,6 +203,9 @@ func (ecp *ContactPointService) CreateContactPoint(ctx context.Context, orgID in
 			},
 		})
 	}
+	if err := validateFallbacks(revision.cfg); err != nil {
+		return apimodels.EmbeddedContactPoint{}, err
+	}
 
 	err = ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
 		if err := ecp.configStore.Save(ctx, revision, orgID); err != nil {
@@ -274,6 +279,7 @@ func (ecp *ContactPointService) UpdateContactPoint(ctx context.Context, orgID in
 		DisableResolveMessage: contactPoint.DisableResolveMessage,
 		Settings:              jsonData,
 		SecureSettings:        extractedSecrets,
+		FallbackAfterFailures: contactPoint.FallbackAfterFailures,
 	}
 	// save to store
 	revision, err := ecp.configStore.Get(ctx, orgID)
@@ -285,6 +291,9 @@ func (ecp *ContactPointService) UpdateContactPoint(ctx context.Context, orgID in
 	if !configModified {
 		return fmt.Errorf("contact point with uid '%s' not found", mergedReceiver.UID)
 	}
+	if err := validateFallbacks(revision.cfg); err != nil {
+		return err
+	}
 
 	err = ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
 		if err := ecp.configStore.Save(ctx, revision, orgID); err != nil {
@@ -327,6 +336,9 @@ func (ecp *ContactPointService) DeleteContactPoint(ctx context.Context, orgID in
 	if fullRemoval && isContactPointInUse(name, []*apimodels.Route{revision.cfg.AlertmanagerConfig.Route}) {
 		return fmt.Errorf("contact point '%s' is currently used by a notification policy", name)
 	}
+	if err := validateFallbacks(revision.cfg); err != nil {
+		return err
+	}
 
 	return ecp.xact.InTransaction(ctx, func(ctx context.Context) error {
 		if err := ecp.configStore.Save(ctx, revision, orgID); err != nil {
@@ -339,6 +351,17 @@ func (ecp *ContactPointService) DeleteContactPoint(ctx context.Context, orgID in
 	})
 }
 
+// validateFallbacks returns an ErrValidation error if a receiver has invalid fallback integrations,
+// for example if all of its integrations are fallback integrations.
+func validateFallbacks(cfg *apimodels.PostableUserConfig) error {
+	for _, receiver := range cfg.AlertmanagerConfig.Receivers {
+		if err := receiver.ValidateFallbacks(); err != nil {
+			return fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+	}
+	return nil
+}
+
 func isContactPointInUse(name string, routes []*apimodels.Route) bool {
 	if len(routes) == 0 {
 		return false
@@ -482,6 +505,9 @@ func ValidateContactPoint(ctx context.Context, e apimodels.EmbeddedContactPoint,
 	if err != nil {
 		return err
 	}
+	if messagebus.IsMessageBusType(integration.Type) {
+		return messagebus.ValidateIntegration(ctx, &integration, decryptFunc)
+	}
 	_, err = alertingNotify.BuildReceiverConfiguration(ctx, &alertingNotify.APIReceiver{
 		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
 			Integrations: []*alertingNotify.GrafanaIntegrationConfig{&integration},
@@ -109,6 +109,31 @@ func TestContactPointService(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("create rejects contact points with only fallback integrations", func(t *testing.T) {
+		sut := createContactPointServiceSut(t, secretsService)
+		newCp := createTestContactPoint()
+		newCp.FallbackAfterFailures = 3
+
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrValidation)
+
+		primary, err := sut.CreateContactPoint(context.Background(), 1, createTestContactPoint(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		fallback := createTestContactPoint()
+		fallback.FallbackAfterFailures = 3
+		_, err = sut.CreateContactPoint(context.Background(), 1, fallback, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		// The last integration that isn't a fallback can't become one or be deleted.
+		update := createTestContactPoint()
+		update.UID = primary.UID
+		update.FallbackAfterFailures = 1
+		err = sut.UpdateContactPoint(context.Background(), 1, update, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrValidation)
+		err = sut.DeleteContactPoint(context.Background(), 1, primary.UID)
+		require.ErrorIs(t, err, ErrValidation)
+	})
+
 	t.Run("create rejects contact points that fail validation", func(t *testing.T) {
 		sut := createContactPointServiceSut(t, secretsService)
 		newCp := createTestContactPoint()
@@ -44,6 +44,12 @@ type RuleStore interface {
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error)
 }
 
+// NotificationSettingsValidator represents the ability to validate the notification settings of alert rules
+// against the Alertmanager configuration of an organization.
+type NotificationSettingsValidator interface {
+	ValidateNotificationSettings(ctx context.Context, orgID int64, settings ...models.NotificationSettings) error
+}
+
 // QuotaChecker represents the ability to evaluate whether quotas are met.
 //
 //go:generate mockery --name QuotaChecker --structname MockQuotaChecker --inpackage --filename quota_checker_mock.go --with-expecter
@@ -339,6 +339,11 @@ func (am *Alertmanager) GetReceivers(ctx context.Context) ([]apimodels.Receiver,
 	return rcvs, nil
 }
 
+// GetReceiversHealth returns an empty list, the remote Alertmanager does not track the health of integrations.
+func (am *Alertmanager) GetReceiversHealth(_ context.Context) ([]apimodels.ReceiverHealth, error) {
+	return []apimodels.ReceiverHealth{}, nil
+}
+
 func (am *Alertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error) {
 	return &notifier.TestReceiversResult{}, nil
 }
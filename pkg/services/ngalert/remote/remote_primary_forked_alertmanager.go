@@ -68,6 +68,10 @@ func (fam *RemotePrimaryForkedAlertmanager) GetReceivers(ctx context.Context) ([
 	return fam.remote.GetReceivers(ctx)
 }
 
+func (fam *RemotePrimaryForkedAlertmanager) GetReceiversHealth(ctx context.Context) ([]apimodels.ReceiverHealth, error) {
+	return fam.remote.GetReceiversHealth(ctx)
+}
+
 func (fam *RemotePrimaryForkedAlertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error) {
 	return fam.remote.TestReceivers(ctx, c)
 }
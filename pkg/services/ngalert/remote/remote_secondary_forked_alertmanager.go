@@ -157,6 +157,10 @@ func (fam *RemoteSecondaryForkedAlertmanager) GetReceivers(ctx context.Context)
 	return fam.internal.GetReceivers(ctx)
 }
 
+func (fam *RemoteSecondaryForkedAlertmanager) GetReceiversHealth(ctx context.Context) ([]apimodels.ReceiverHealth, error) {
+	return fam.internal.GetReceiversHealth(ctx)
+}
+
 func (fam *RemoteSecondaryForkedAlertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*notifier.TestReceiversResult, error) {
 	return fam.internal.TestReceivers(ctx, c)
 }
@@ -0,0 +1,73 @@
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// JitterStrategy represents a modifier to alert rule timing that affects how evaluations are distributed.
+type JitterStrategy int
+
+const (
+	// JitterNever evaluates all rules with the same interval on the same tick.
+	JitterNever JitterStrategy = iota
+	// JitterByGroup spreads the evaluations of rule groups across their interval. All rules of a group are evaluated on the same tick.
+	JitterByGroup
+	// JitterByRule spreads the evaluations of rules across their interval. Rules of a group can be evaluated on different ticks.
+	JitterByRule
+)
+
+func (s JitterStrategy) String() string {
+	switch s {
+	case JitterByGroup:
+		return setting.EvaluationJitterByGroup
+	case JitterByRule:
+		return setting.EvaluationJitterByRule
+	default:
+		return setting.EvaluationJitterDisabled
+	}
+}
+
+// JitterStrategyFrom returns the jitter strategy configured in the settings.
+func JitterStrategyFrom(cfg setting.UnifiedAlertingSettings) JitterStrategy {
+	switch cfg.EvaluationJitter {
+	case setting.EvaluationJitterByGroup:
+		return JitterByGroup
+	case setting.EvaluationJitterByRule:
+		return JitterByRule
+	default:
+		return JitterNever
+	}
+}
+
+// jitterOffsetInTicks gives the jitter offset for a rule, in terms of a number of ticks relative to its interval and a base interval.
+// The resulting number of ticks is non-negative and less than the number of ticks in the interval of the rule.
+// The offset is deterministic, so a rule is always evaluated on the same ticks, regardless of the instance it runs on.
+func jitterOffsetInTicks(r *ngmodels.AlertRule, baseInterval time.Duration, strategy JitterStrategy) int64 {
+	if strategy == JitterNever {
+		return 0
+	}
+	itemFrequency := r.IntervalSeconds / int64(baseInterval.Seconds())
+	if itemFrequency <= 1 {
+		return 0
+	}
+	return int64(jitterHash(r, strategy) % uint64(itemFrequency))
+}
+
+// jitterHash calculates a hash of the key of the rule group or the rule, depending on the strategy.
+func jitterHash(r *ngmodels.AlertRule, strategy JitterStrategy) uint64 {
+	ls := data.Labels{
+		"orgId":     fmt.Sprint(r.OrgID),
+		"namespace": r.NamespaceUID,
+		"group":     r.RuleGroup,
+	}
+	if strategy == JitterByRule {
+		ls["uid"] = r.UID
+	}
+	return uint64(ls.Fingerprint())
+}
@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestJitterStrategyFrom(t *testing.T) {
+	assert.Equal(t, JitterNever, JitterStrategyFrom(setting.UnifiedAlertingSettings{}))
+	assert.Equal(t, JitterNever, JitterStrategyFrom(setting.UnifiedAlertingSettings{EvaluationJitter: setting.EvaluationJitterDisabled}))
+	assert.Equal(t, JitterByGroup, JitterStrategyFrom(setting.UnifiedAlertingSettings{EvaluationJitter: setting.EvaluationJitterByGroup}))
+	assert.Equal(t, JitterByRule, JitterStrategyFrom(setting.UnifiedAlertingSettings{EvaluationJitter: setting.EvaluationJitterByRule}))
+}
+
+func TestJitterOffsetInTicks(t *testing.T) {
+	baseInterval := 10 * time.Second
+	interval := 10 * baseInterval
+	itemFrequency := int64(interval / baseInterval)
+
+	t.Run("offset is always zero if jitter is disabled", func(t *testing.T) {
+		for _, r := range models.GenerateAlertRules(100, models.AlertRuleGen(models.WithInterval(interval))) {
+			assert.Zero(t, jitterOffsetInTicks(r, baseInterval, JitterNever))
+		}
+	})
+
+	t.Run("offset is always zero if the interval of the rule equals the base interval", func(t *testing.T) {
+		for _, r := range models.GenerateAlertRules(100, models.AlertRuleGen(models.WithInterval(baseInterval))) {
+			assert.Zero(t, jitterOffsetInTicks(r, baseInterval, JitterByRule))
+		}
+	})
+
+	for _, strategy := range []JitterStrategy{JitterByGroup, JitterByRule} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			rules := models.GenerateAlertRules(1000, models.AlertRuleGen(models.WithInterval(interval)))
+			offsets := make(map[int64]int)
+			for _, r := range rules {
+				offset := jitterOffsetInTicks(r, baseInterval, strategy)
+				require.GreaterOrEqual(t, offset, int64(0))
+				require.Less(t, offset, itemFrequency)
+				require.Equal(t, offset, jitterOffsetInTicks(models.CopyRule(r), baseInterval, strategy), "offset must be deterministic")
+				offsets[offset]++
+			}
+			// The offsets of 1000 distinct rules should be spread across all ticks of the interval.
+			assert.Len(t, offsets, int(itemFrequency))
+			for offset, count := range offsets {
+				assert.Lessf(t, count, len(rules)/int(itemFrequency)*2, "too many rules have offset %d", offset)
+			}
+		})
+	}
+
+	t.Run("rules in the same group have the same offset if jittered by group", func(t *testing.T) {
+		rules := models.GenerateAlertRules(100, models.AlertRuleGen(models.WithInterval(interval), models.WithGroupKey(models.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "ns", RuleGroup: "group"})))
+		expected := jitterOffsetInTicks(rules[0], baseInterval, JitterByGroup)
+		byRule := make(map[int64]struct{})
+		for _, r := range rules {
+			assert.Equal(t, expected, jitterOffsetInTicks(r, baseInterval, JitterByGroup))
+			byRule[jitterOffsetInTicks(r, baseInterval, JitterByRule)] = struct{}{}
+		}
+		assert.Greater(t, len(byRule), 1, "rules in the same group should be spread if jittered by rule")
+	})
+}
+
+func TestProcessTicksWithJitter(t *testing.T) {
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	sch.jitterEvaluations = JitterByRule
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	interval := 10 * sch.baseInterval
+	rules := models.GenerateAlertRules(50, models.AlertRuleGen(models.WithInterval(interval), models.WithOrgID(1)))
+	ruleStore.PutRule(ctx, rules...)
+
+	evaluated := make(map[models.AlertRuleKey]int)
+	maxPerTick := 0
+	tick := time.Time{}
+	for i := 0; i < 10; i++ {
+		tick = tick.Add(sch.baseInterval)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		for _, item := range scheduled {
+			evaluated[item.rule.GetKey()]++
+		}
+		if len(scheduled) > maxPerTick {
+			maxPerTick = len(scheduled)
+		}
+	}
+
+	require.Len(t, evaluated, len(rules))
+	for key, count := range evaluated {
+		assert.Equalf(t, 1, count, "rule %s should be evaluated once per interval", key.UID)
+	}
+	assert.Less(t, maxPerTick, len(rules), "evaluations should be spread across the interval")
+}
@@ -336,6 +336,11 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		writeInt(0)
 	}
 
+	// notification settings determine the labels of the alerts
+	for _, setting := range rule.NotificationSettings {
+		writeInt(int64(setting.Fingerprint()))
+	}
+
 	// fields that do not affect the state.
 	// TODO consider removing fields below from the fingerprint
 	writeInt(rule.ID)
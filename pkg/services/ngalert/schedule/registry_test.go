@@ -414,7 +414,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			Labels: map[string]string{
 				"key-label": "value-label",
 			},
-			IsPaused: false,
+			IsPaused:             false,
+			NotificationSettings: []models.NotificationSettings{models.NewDefaultNotificationSettings("receiver")},
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -449,7 +450,8 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			Labels: map[string]string{
 				"key-label": "value-label23",
 			},
-			IsPaused: true,
+			IsPaused:             true,
+			NotificationSettings: []models.NotificationSettings{models.NewDefaultNotificationSettings("receiver-2")},
 		}
 
 		excludedFields := map[string]struct{}{
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	data "github.com/grafana/grafana-plugin-sdk-go/data"
 
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
 )
@@ -37,16 +38,16 @@ func (m *MockImageCapturer) EXPECT() *MockImageCapturerMockRecorder {
 }
 
 // NewImage mocks base method.
-func (m *MockImageCapturer) NewImage(arg0 context.Context, arg1 *models.AlertRule) (*models.Image, error) {
+func (m *MockImageCapturer) NewImage(arg0 context.Context, arg1 *models.AlertRule, arg2 map[string]data.Frames) (*models.Image, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NewImage", arg0, arg1)
+	ret := m.ctrl.Call(m, "NewImage", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*models.Image)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NewImage indicates an expected call of NewImage.
-func (mr *MockImageCapturerMockRecorder) NewImage(arg0, arg1 any) *gomock.Call {
+func (mr *MockImageCapturerMockRecorder) NewImage(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewImage", reflect.TypeOf((*MockImageCapturer)(nil).NewImage), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewImage", reflect.TypeOf((*MockImageCapturer)(nil).NewImage), arg0, arg1, arg2)
 }
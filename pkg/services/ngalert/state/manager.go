@@ -402,7 +402,7 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
 
 	if shouldTakeImage(currentState.State, oldState, currentState.Image, currentState.Resolved) {
-		image, err := takeImage(ctx, st.images, alertRule)
+		image, err := takeImage(ctx, st.images, alertRule, result.Results)
 		if err != nil {
 			logger.Warn("Failed to take an image",
 				"dashboard", alertRule.GetDashboardUID(),
@@ -545,7 +545,7 @@ func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Lo
 
 		if oldState == eval.Alerting {
 			s.Resolved = true
-			image, err := takeImage(ctx, st.images, alertRule)
+			image, err := takeImage(ctx, st.images, alertRule, nil)
 			if err != nil {
 				logger.Warn("Failed to take an image",
 					"dashboard", alertRule.GetDashboardUID(),
@@ -33,7 +33,7 @@ type CountingImageService struct {
 	Called int
 }
 
-func (c *CountingImageService) NewImage(_ context.Context, _ *ngmodels.AlertRule) (*ngmodels.Image, error) {
+func (c *CountingImageService) NewImage(_ context.Context, _ *ngmodels.AlertRule, _ map[string]data.Frames) (*ngmodels.Image, error) {
 	c.Called += 1
 	return &ngmodels.Image{
 		Token: fmt.Sprint(rand.Int()),
@@ -29,11 +29,14 @@ import (
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/services/screenshot"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -1411,6 +1414,62 @@ func printAllAnnotations(annos map[int64]annotations.Item) string {
 	return b.String()
 }
 
+// graphRecorder records the results passed to the image service.
+type graphRecorder struct {
+	image.ImageService
+	results []map[string]data.Frames
+}
+
+func (r *graphRecorder) NewImage(ctx context.Context, rule *models.AlertRule, results map[string]data.Frames) (*models.Image, error) {
+	r.results = append(r.results, results)
+	return r.ImageService.NewImage(ctx, rule, results)
+}
+
+func TestProcessEvalResultsDrawsGraphs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	images := &graphRecorder{ImageService: image.NewScreenshotImageService(&image.NoOpCacheService{}, &screenshot.NoOpRateLimiter{},
+		log.NewNopLogger(), &screenshot.ScreenshotUnavailableService{}, 0, store.NewFakeImageStore(t), nil, image.NewGraphRenderer(t.TempDir()))}
+	st := state.NewManager(state.ManagerCfg{
+		Metrics:                 metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore:           &state.FakeInstanceStore{},
+		Images:                  images,
+		Clock:                   clock.NewMock(),
+		Historian:               &state.FakeHistorian{},
+		MaxStateSaveConcurrency: 1,
+		Tracer:                  tracing.InitializeTracerForTest(),
+		Log:                     log.New("ngalert.state.manager"),
+	})
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "rule",
+		Title:           "rule",
+		NamespaceUID:    "namespace",
+		Data:            []models.AlertQuery{{RefID: "A", DatasourceUID: "datasource"}},
+		IntervalSeconds: 10,
+		ExecErrState:    models.ErrorErrState,
+		NoDataState:     models.NoData,
+	}
+	frames := data.Frames{data.NewFrame("",
+		data.NewField("time", nil, []time.Time{now.Add(-time.Minute), now}),
+		data.NewField("value", nil, []float64{1, 2}),
+	)}
+	results := eval.Results{{
+		State:       eval.Alerting,
+		Results:     map[string]data.Frames{"A": frames},
+		EvaluatedAt: now,
+	}}
+
+	transitions := st.ProcessEvalResults(ctx, now, rule, results, nil)
+	require.Len(t, transitions, 1)
+	require.Len(t, images.results, 1)
+	require.Equal(t, frames, images.results[0]["A"])
+	require.NotNil(t, transitions[0].Image)
+	require.FileExists(t, transitions[0].Image.Path)
+}
+
 func TestStaleResultsHandler(t *testing.T) {
 	evaluationTime := time.Now()
 	interval := time.Minute
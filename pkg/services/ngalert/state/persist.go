@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
 )
@@ -33,5 +35,5 @@ type Historian interface {
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
 type ImageCapturer interface {
-	NewImage(ctx context.Context, r *models.AlertRule) (*models.Image, error)
+	NewImage(ctx context.Context, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error)
 }
@@ -460,9 +460,10 @@ func shouldTakeImage(state, previousState eval.State, previousImage *models.Imag
 }
 
 // takeImage takes an image for the alert rule. It returns nil if screenshots are disabled or
-// the rule is not associated with a dashboard panel.
-func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule) (*models.Image, error) {
-	img, err := s.NewImage(ctx, r)
+// the rule is not associated with a dashboard panel. The results of the evaluation are used to
+// draw a graph when a screenshot cannot be taken, and can be nil.
+func takeImage(ctx context.Context, s ImageCapturer, r *models.AlertRule, results map[string]data.Frames) (*models.Image, error) {
+	img, err := s.NewImage(ctx, r, results)
 	if err != nil {
 		if errors.Is(err, screenshot.ErrScreenshotsUnavailable) ||
 			errors.Is(err, models.ErrNoDashboard) ||
@@ -493,5 +494,12 @@ func GetRuleExtraLabels(rule *models.AlertRule, folderTitle string, includeFolde
 	if includeFolder {
 		extraLabels[models.FolderTitleLabel] = folderTitle
 	}
+
+	// Alerts of rules with notification settings are routed by the autogenerated notification policy.
+	if ns := rule.GetNotificationSettings(); ns != nil {
+		for k, v := range ns.ToLabels() {
+			extraLabels[k] = v
+		}
+	}
 	return extraLabels
 }
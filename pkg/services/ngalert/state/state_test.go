@@ -603,8 +603,8 @@ func TestTakeImage(t *testing.T) {
 		r := ngmodels.AlertRule{}
 		s := NewMockImageCapturer(ctrl)
 
-		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoDashboard)
-		image, err := takeImage(ctx, s, &r)
+		s.EXPECT().NewImage(ctx, &r, nil).Return(nil, ngmodels.ErrNoDashboard)
+		image, err := takeImage(ctx, s, &r, nil)
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -617,8 +617,8 @@ func TestTakeImage(t *testing.T) {
 		r := ngmodels.AlertRule{DashboardUID: util.Pointer("foo")}
 		s := NewMockImageCapturer(ctrl)
 
-		s.EXPECT().NewImage(ctx, &r).Return(nil, ngmodels.ErrNoPanel)
-		image, err := takeImage(ctx, s, &r)
+		s.EXPECT().NewImage(ctx, &r, nil).Return(nil, ngmodels.ErrNoPanel)
+		image, err := takeImage(ctx, s, &r, nil)
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -631,8 +631,8 @@ func TestTakeImage(t *testing.T) {
 		r := ngmodels.AlertRule{DashboardUID: util.Pointer("foo"), PanelID: util.Pointer(int64(1))}
 		s := NewMockImageCapturer(ctrl)
 
-		s.EXPECT().NewImage(ctx, &r).Return(nil, screenshot.ErrScreenshotsUnavailable)
-		image, err := takeImage(ctx, s, &r)
+		s.EXPECT().NewImage(ctx, &r, nil).Return(nil, screenshot.ErrScreenshotsUnavailable)
+		image, err := takeImage(ctx, s, &r, nil)
 		assert.NoError(t, err)
 		assert.Nil(t, image)
 	})
@@ -645,8 +645,8 @@ func TestTakeImage(t *testing.T) {
 		r := ngmodels.AlertRule{DashboardUID: util.Pointer("foo"), PanelID: util.Pointer(int64(1))}
 		s := NewMockImageCapturer(ctrl)
 
-		s.EXPECT().NewImage(ctx, &r).Return(nil, errors.New("unknown error"))
-		image, err := takeImage(ctx, s, &r)
+		s.EXPECT().NewImage(ctx, &r, nil).Return(nil, errors.New("unknown error"))
+		image, err := takeImage(ctx, s, &r, nil)
 		assert.EqualError(t, err, "unknown error")
 		assert.Nil(t, image)
 	})
@@ -659,8 +659,8 @@ func TestTakeImage(t *testing.T) {
 		r := ngmodels.AlertRule{DashboardUID: util.Pointer("foo"), PanelID: util.Pointer(int64(1))}
 		s := NewMockImageCapturer(ctrl)
 
-		s.EXPECT().NewImage(ctx, &r).Return(&ngmodels.Image{Path: "foo.png"}, nil)
-		image, err := takeImage(ctx, s, &r)
+		s.EXPECT().NewImage(ctx, &r, nil).Return(&ngmodels.Image{Path: "foo.png"}, nil)
+		image, err := takeImage(ctx, s, &r, nil)
 		assert.NoError(t, err)
 		require.NotNil(t, image)
 		assert.Equal(t, ngmodels.Image{Path: "foo.png"}, *image)
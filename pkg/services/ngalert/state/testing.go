@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
 	"github.com/grafana/grafana/pkg/services/screenshot"
@@ -73,13 +75,13 @@ func (f *FakeHistorian) Record(ctx context.Context, rule history_model.RuleMeta,
 // NotAvailableImageService is a service that returns ErrScreenshotsUnavailable.
 type NotAvailableImageService struct{}
 
-func (s *NotAvailableImageService) NewImage(_ context.Context, _ *models.AlertRule) (*models.Image, error) {
+func (s *NotAvailableImageService) NewImage(_ context.Context, _ *models.AlertRule, _ map[string]data.Frames) (*models.Image, error) {
 	return nil, screenshot.ErrScreenshotsUnavailable
 }
 
 // NoopImageService is a no-op image service.
 type NoopImageService struct{}
 
-func (s *NoopImageService) NewImage(_ context.Context, _ *models.AlertRule) (*models.Image, error) {
+func (s *NoopImageService) NewImage(_ context.Context, _ *models.AlertRule, _ map[string]data.Frames) (*models.Image, error) {
 	return &models.Image{}, nil
 }
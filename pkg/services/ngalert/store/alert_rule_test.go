@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/user"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/exp/rand"
 
@@ -572,6 +573,59 @@ func TestIntegrationInsertAlertRules(t *testing.T) {
 	}
 }
 
+func TestIntegrationListNotificationSettings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+
+	receiver1 := models.NewDefaultNotificationSettings("receiver1")
+	receiver2 := models.NotificationSettings{Receiver: "receiver2", GroupBy: []string{"pod"}}
+	withReceiver1 := models.GenerateAlertRules(2, models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval), models.WithNotificationSettings(receiver1)))
+	withReceiver2 := models.GenerateAlertRules(1, models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval), models.WithNotificationSettings(receiver2)))
+	withoutSettings := models.GenerateAlertRules(2, models.AlertRuleGen(models.WithOrgID(1), withIntervalMatching(store.Cfg.BaseInterval)))
+	otherOrg := models.GenerateAlertRules(1, models.AlertRuleGen(models.WithOrgID(2), withIntervalMatching(store.Cfg.BaseInterval), models.WithNotificationSettings(receiver1)))
+
+	var deref []models.AlertRule
+	for _, rules := range [][]*models.AlertRule{withReceiver1, withReceiver2, withoutSettings, otherOrg} {
+		for _, rule := range rules {
+			deref = append(deref, *rule)
+		}
+	}
+	_, err := store.InsertAlertRules(context.Background(), deref)
+	require.NoError(t, err)
+
+	t.Run("returns the settings of all rules in the organization", func(t *testing.T) {
+		result, err := store.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, len(withReceiver1)+len(withReceiver2))
+		for _, rule := range withReceiver1 {
+			assert.Equal(t, []models.NotificationSettings{receiver1}, result[rule.GetKey()])
+		}
+		for _, rule := range withReceiver2 {
+			assert.Equal(t, []models.NotificationSettings{receiver2}, result[rule.GetKey()])
+		}
+	})
+
+	t.Run("filters the settings by receiver", func(t *testing.T) {
+		result, err := store.ListNotificationSettings(context.Background(), models.ListNotificationSettingsQuery{OrgID: 1, ReceiverName: "receiver2"})
+		require.NoError(t, err)
+		require.Len(t, result, len(withReceiver2))
+		for _, rule := range withReceiver2 {
+			assert.Equal(t, []models.NotificationSettings{receiver2}, result[rule.GetKey()])
+		}
+	})
+}
+
 func createRule(t *testing.T, store *DBstore, generate func() *models.AlertRule) *models.AlertRule {
 	t.Helper()
 	if generate == nil {
@@ -0,0 +1,160 @@
+package panelexport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// truncatedHeader is set on the responses of exports which were truncated to the row limit of the data proxy.
+const truncatedHeader = "X-Grafana-Export-Truncated"
+
+// variablePrefix is the prefix of the query parameters with the values of the template variables, like in the URLs
+// of dashboards.
+const variablePrefix = "var-"
+
+func (s *PanelExportService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.AccessControl)
+	uidScope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+
+	s.RouteRegister.Get("/api/dashboards/uid/:uid/panels/:panelId/export",
+		middleware.ReqSignedIn,
+		authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(s.exportHandler))
+}
+
+func errorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrPanelNotFound), errors.Is(err, ErrPanelQueriesNotFound), errors.Is(err, ErrFrameNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, ErrInvalidFormat), errors.Is(err, ErrInvalidTimeRange), errors.Is(err, ErrQueryFailed):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, datasources.ErrDataSourceAccessDenied):
+		return response.Error(http.StatusForbidden, "Access denied to the data source", err)
+	case errors.Is(err, datasources.ErrDataSourceNotFound):
+		return response.Error(http.StatusNotFound, "Data source not found", err)
+	case errors.Is(err, model.ErrLibraryElementNotFound):
+		return response.Error(http.StatusNotFound, "Library panel not found", err)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "Failed to export the panel", err)
+}
+
+// swagger:route GET /dashboards/uid/{uid}/panels/{panelId}/export dashboards exportPanel
+//
+// Export the data of a panel.
+//
+// Runs the queries and expressions of the panel and returns their results as a CSV, XLSX or Arrow file. The results
+// are truncated to the row limit of the data proxy, in which case the X-Grafana-Export-Truncated header is set.
+//
+// Produces:
+// - text/csv
+// - application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// - application/vnd.apache.arrow.file
+//
+// Responses:
+// 200: exportPanelResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *PanelExportService) exportHandler(c *contextmodel.ReqContext) response.Response {
+	panelID, err := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "panelId is invalid", err)
+	}
+
+	q := ExportPanelQuery{
+		DashboardUID: web.Params(c.Req)[":uid"],
+		PanelID:      panelID,
+		OrgID:        c.SignedInUser.GetOrgID(),
+		User:         c.SignedInUser,
+		Format:       Format(c.Query("format")),
+		From:         c.Query("from"),
+		To:           c.Query("to"),
+		Variables:    make(map[string][]string),
+	}
+	if v := c.Query("maxDataPoints"); v != "" {
+		if q.MaxDataPoints, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return response.Error(http.StatusBadRequest, "maxDataPoints is invalid", err)
+		}
+	}
+	if v := c.Query("frameIndex"); v != "" {
+		if q.FrameIndex, err = strconv.Atoi(v); err != nil {
+			return response.Error(http.StatusBadRequest, "frameIndex is invalid", err)
+		}
+	}
+	for key, values := range c.Req.URL.Query() {
+		if name, ok := strings.CutPrefix(key, variablePrefix); ok && name != "" {
+			q.Variables[name] = values
+		}
+	}
+
+	export, err := s.ExportPanel(c.Req.Context(), q)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	resp := response.Respond(http.StatusOK, export.Body).
+		SetHeader("Content-Type", export.ContentType).
+		SetHeader("Content-Disposition", fmt.Sprintf(`attachment;filename="%s"`, export.Filename))
+	if export.Truncated {
+		resp.SetHeader(truncatedHeader, "true")
+	}
+	return resp
+}
+
+// swagger:parameters exportPanel
+type ExportPanelParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:path
+	// required:true
+	PanelID int64 `json:"panelId"`
+	// The format of the file, csv, xlsx or arrow.
+	// in:query
+	// required:false
+	// default:csv
+	Format string `json:"format"`
+	// The start of the time range, relative like now-6h or in epoch milliseconds. Defaults to the time range of the
+	// dashboard.
+	// in:query
+	// required:false
+	From string `json:"from"`
+	// The end of the time range, relative like now or in epoch milliseconds. Defaults to the time range of the
+	// dashboard.
+	// in:query
+	// required:false
+	To string `json:"to"`
+	// The resolution of the queries.
+	// in:query
+	// required:false
+	// default:1000
+	MaxDataPoints int64 `json:"maxDataPoints"`
+	// The frame to export to CSV and Arrow. XLSX files have a sheet per frame.
+	// in:query
+	// required:false
+	// default:0
+	FrameIndex int `json:"frameIndex"`
+}
+
+// swagger:response exportPanelResponse
+type ExportPanelResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
@@ -0,0 +1,106 @@
+package panelexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// timeFormat is the ISO 8601 format of the times of the exports.
+const timeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// encodeCSV encodes a frame to CSV, with the display names of the fields as header.
+func encodeCSV(frame *data.Frame) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := make([]string, len(frame.Fields))
+	for i, field := range frame.Fields {
+		header[i] = fieldDisplayName(frame, field)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	record := make([]string, len(frame.Fields))
+	for row := 0; row < frame.Rows(); row++ {
+		for i, field := range frame.Fields {
+			record[i] = formatCell(cellValue(field, row))
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// fieldDisplayName returns the name of a field as the frontend displays it by default, with its labels.
+func fieldDisplayName(frame *data.Frame, field *data.Field) string {
+	if field.Config != nil {
+		if field.Config.DisplayNameFromDS != "" {
+			return field.Config.DisplayNameFromDS
+		}
+		if field.Config.DisplayName != "" {
+			return field.Config.DisplayName
+		}
+	}
+
+	name := field.Name
+	if name == "" {
+		name = frame.Name
+	}
+	if name == "" {
+		name = "Value"
+	}
+	if len(field.Labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(field.Labels))
+	for k := range field.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := make([]string, len(keys))
+	for i, k := range keys {
+		labels[i] = fmt.Sprintf("%s=%q", k, field.Labels[k])
+	}
+	return name + " {" + strings.Join(labels, ", ") + "}"
+}
+
+// cellValue returns the value of a field at a row, or nil if it is null.
+func cellValue(field *data.Field, row int) any {
+	v, ok := field.ConcreteAt(row)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(timeFormat)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case json.RawMessage:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,46 @@
+package panelexport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrame() *data.Frame {
+	value := func(f float64) *float64 { return &f }
+	return data.NewFrame("cpu",
+		data.NewField("time", nil, []time.Time{
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 0, 2, 0, 0, time.UTC),
+		}),
+		data.NewField("value", data.Labels{"host": "a", "env": "prod"}, []*float64{value(1.5), nil, value(3)}),
+		data.NewField("status", nil, []string{"ok", "with, comma", `with "quotes"`}),
+	)
+}
+
+func TestEncodeCSV(t *testing.T) {
+	frame := testFrame()
+	frame.Fields[2].Config = &data.FieldConfig{DisplayName: "State"}
+
+	csv, err := encodeCSV(frame)
+	require.NoError(t, err)
+	require.Equal(t, `time,"value {env=""prod"", host=""a""}",State
+2024-01-01T00:00:00.000Z,1.5,ok
+2024-01-01T00:01:00.000Z,,"with, comma"
+2024-01-01T00:02:00.000Z,3,"with ""quotes"""
+`, string(csv))
+}
+
+func TestFieldDisplayName(t *testing.T) {
+	frame := data.NewFrame("requests", data.NewField("", nil, []int64{}))
+	require.Equal(t, "requests", fieldDisplayName(frame, frame.Fields[0]))
+
+	frame.Name = ""
+	require.Equal(t, "Value", fieldDisplayName(frame, frame.Fields[0]))
+
+	frame.Fields[0].Config = &data.FieldConfig{DisplayNameFromDS: "From DS", DisplayName: "Override"}
+	require.Equal(t, "From DS", fieldDisplayName(frame, frame.Fields[0]))
+}
@@ -0,0 +1,68 @@
+package panelexport
+
+import (
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+var (
+	ErrPanelNotFound        = errors.New("panel not found")
+	ErrPanelQueriesNotFound = errors.New("panel has no queries")
+	ErrInvalidFormat        = errors.New("invalid export format, expected csv, xlsx or arrow")
+	ErrInvalidTimeRange     = errors.New("invalid time range")
+	ErrFrameNotFound        = errors.New("frame not found")
+	ErrQueryFailed          = errors.New("query failed")
+)
+
+// Format is the file format of an export.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatXLSX  Format = "xlsx"
+	FormatArrow Format = "arrow"
+)
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatArrow:
+		return "application/vnd.apache.arrow.file"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+func (f Format) valid() bool {
+	return f == FormatCSV || f == FormatXLSX || f == FormatArrow
+}
+
+// ExportPanelQuery is the panel to export, with the time range and the values of the template variables to query it
+// with. The dashboard defaults are used for the fields which aren't set.
+type ExportPanelQuery struct {
+	DashboardUID string
+	PanelID      int64
+	OrgID        int64
+	User         *user.SignedInUser
+	Format       Format
+	// From and To are relative like now-6h or absolute in epoch milliseconds.
+	From          string
+	To            string
+	Variables     map[string][]string
+	MaxDataPoints int64
+	// FrameIndex is the frame to export to CSV and Arrow, which hold a single frame. XLSX exports have a sheet per
+	// frame.
+	FrameIndex int
+}
+
+// Export is the exported data of a panel.
+type Export struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+	// Truncated is true if the rows over the data proxy row limit were dropped.
+	Truncated bool
+}
@@ -0,0 +1,292 @@
+package panelexport
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/slugify"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// defaultMaxDataPoints is the resolution of the queries when it isn't set.
+const defaultMaxDataPoints = 1000
+
+func ProvideService(cfg *setting.Cfg, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService, dataSourceService datasources.DataSourceService, queryService query.Service,
+	libraryElementService libraryelements.Service,
+) *PanelExportService {
+	s := &PanelExportService{
+		Cfg:                   cfg,
+		RouteRegister:         routeRegister,
+		AccessControl:         ac,
+		DashboardService:      dashboardService,
+		DataSourceService:     dataSourceService,
+		QueryService:          queryService,
+		LibraryElementService: libraryElementService,
+		intervalCalculator:    intervalv2.NewCalculator(),
+		log:                   log.New("panelexport"),
+		now:                   time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// PanelExportService runs the queries of dashboard panels and exports their results to CSV, XLSX or Arrow files.
+type PanelExportService struct {
+	Cfg               *setting.Cfg
+	RouteRegister     routing.RouteRegister
+	AccessControl     accesscontrol.AccessControl
+	DashboardService  dashboards.DashboardService
+	DataSourceService datasources.DataSourceService
+	QueryService      query.Service
+	// LibraryElementService resolves the library panels of dashboards, whose queries aren't stored in the dashboard.
+	LibraryElementService libraryelements.Service
+
+	intervalCalculator intervalv2.Calculator
+	log                log.Logger
+	now                func() time.Time
+}
+
+// ExportPanel runs the queries of a panel with the permissions of the user of the query, and encodes their results.
+// The results are truncated to the row limit of the data proxy.
+func (s *PanelExportService) ExportPanel(ctx context.Context, q ExportPanelQuery) (*Export, error) {
+	if q.Format == "" {
+		q.Format = FormatCSV
+	}
+	if !q.Format.valid() {
+		return nil, ErrInvalidFormat
+	}
+
+	dash, err := s.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: q.DashboardUID, OrgID: q.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	frames, err := s.queryPanel(ctx, q, dash)
+	if err != nil {
+		return nil, err
+	}
+	truncated := truncateFrames(frames, s.Cfg.DataProxyRowLimit)
+	if truncated {
+		s.log.Debug("Panel export truncated to the row limit", "dashboardUID", q.DashboardUID, "panelID", q.PanelID, "limit", s.Cfg.DataProxyRowLimit)
+	}
+
+	slug := dash.Slug
+	if slug == "" {
+		slug = slugify.Slugify(dash.Title)
+	}
+	export := &Export{
+		Filename:    fmt.Sprintf("%s-panel-%d-%s.%s", slug, q.PanelID, s.now().UTC().Format("2006-01-02"), q.Format),
+		ContentType: q.Format.ContentType(),
+		Truncated:   truncated,
+	}
+
+	if q.Format == FormatXLSX {
+		if export.Body, err = encodeXLSX(frames); err != nil {
+			return nil, fmt.Errorf("failed to encode XLSX: %w", err)
+		}
+		return export, nil
+	}
+
+	if q.FrameIndex < 0 || q.FrameIndex >= len(frames) {
+		return nil, fmt.Errorf("%w: the panel returned %d frames", ErrFrameNotFound, len(frames))
+	}
+	frame := frames[q.FrameIndex]
+	if q.Format == FormatArrow {
+		export.Body, err = frame.MarshalArrow()
+	} else {
+		export.Body, err = encodeCSV(frame)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", q.Format, err)
+	}
+	return export, nil
+}
+
+// resolveLibraryPanel returns the model of the library panel if the panel is a library panel, and the panel otherwise.
+// Dashboards only store a reference to library panels, so their queries are in the library panel.
+func (s *PanelExportService) resolveLibraryPanel(ctx context.Context, user identity.Requester, panel *simplejson.Json) (*simplejson.Json, error) {
+	uid := panel.GetPath("libraryPanel", "uid").MustString()
+	if uid == "" {
+		return panel, nil
+	}
+	element, err := s.LibraryElementService.GetElement(ctx, user, model.GetLibraryElementCommand{
+		UID:        uid,
+		FolderName: dashboards.RootFolderName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library panel %s: %w", uid, err)
+	}
+	libraryPanel, err := simplejson.NewJson(element.Model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read library panel %s: %w", uid, err)
+	}
+	return libraryPanel, nil
+}
+
+// queryPanel runs the queries of a panel and returns the resulting frames, in the order of the queries.
+func (s *PanelExportService) queryPanel(ctx context.Context, q ExportPanelQuery, dash *dashboards.Dashboard) (data.Frames, error) {
+	panel, ok := findPanel(dash.Data.Get("panels").MustArray(), q.PanelID)
+	if !ok {
+		return nil, ErrPanelNotFound
+	}
+	panel, err := s.resolveLibraryPanel(ctx, q.User, panel)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := s.timeRange(q, dash.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultDatasourceUID string
+	if getDataSourceUidFromJson(panel) == "" {
+		ds, err := s.DataSourceService.GetDefaultDataSource(ctx, &datasources.GetDefaultDataSourceQuery{OrgID: q.OrgID, User: q.User})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the default data source: %w", err)
+		}
+		if ds != nil {
+			defaultDatasourceUID = ds.UID
+		}
+	}
+
+	queries := panelQueries(panel, dashboardVariables(dash.Data, q.Variables), defaultDatasourceUID)
+	if len(queries) == 0 {
+		return nil, ErrPanelQueriesNotFound
+	}
+
+	maxDataPoints := q.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
+	interval := s.intervalCalculator.Calculate(backend.TimeRange{From: from, To: to}, time.Millisecond, maxDataPoints)
+	for _, query := range queries {
+		query.Set("intervalMs", interval.Milliseconds())
+		query.Set("maxDataPoints", maxDataPoints)
+	}
+
+	res, err := s.QueryService.QueryData(ctx, q.User, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(from.UnixMilli(), 10),
+		To:      strconv.FormatInt(to.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The responses are keyed by ref id, the order of the queries of the panel is the order of the frames.
+	var frames data.Frames
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		refID := query.Get("refId").MustString("A")
+		if seen[refID] {
+			continue
+		}
+		seen[refID] = true
+
+		r, ok := res.Responses[refID]
+		if !ok {
+			continue
+		}
+		if r.Error != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrQueryFailed, refID, r.Error)
+		}
+		for _, frame := range r.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			frames = append(frames, frame)
+		}
+	}
+	return frames, nil
+}
+
+// timeRange returns the time range of the query, or of the dashboard if the query doesn't have one, in the time zone
+// of the dashboard.
+func (s *PanelExportService) timeRange(q ExportPanelQuery, dashboard *simplejson.Json) (time.Time, time.Time, error) {
+	from, to := q.From, q.To
+	if from == "" {
+		from = dashboard.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dashboard.GetPath("time", "to").MustString("now")
+	}
+
+	loc, err := time.LoadLocation(dashboard.Get("timezone").MustString())
+	if err != nil {
+		loc = time.UTC
+	}
+
+	tr := legacydata.NewDataTimeRange(from, to)
+	tr.Now = s.now()
+	fromTime, err := tr.ParseFrom(legacydata.WithLocation(loc))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeRange, from)
+	}
+	toTime, err := tr.ParseTo(legacydata.WithLocation(loc))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q", ErrInvalidTimeRange, to)
+	}
+	if !fromTime.Before(toTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q is not before %q", ErrInvalidTimeRange, from, to)
+	}
+	return fromTime, toTime, nil
+}
+
+// truncateFrames drops the rows over the limit, counting the rows of all frames, and returns true if any row was
+// dropped. A limit of 0 or less disables the limit.
+func truncateFrames(frames data.Frames, limit int64) bool {
+	if limit <= 0 {
+		return false
+	}
+
+	truncated := false
+	remaining := limit
+	for i, frame := range frames {
+		rows := int64(frame.Rows())
+		if rows <= remaining {
+			remaining -= rows
+			continue
+		}
+
+		truncated = true
+		frames[i] = headFrame(frame, int(remaining))
+		remaining = 0
+	}
+	return truncated
+}
+
+// headFrame returns a copy of the first n rows of a frame.
+func headFrame(frame *data.Frame, n int) *data.Frame {
+	head := frame.EmptyCopy()
+	head.Meta = frame.Meta
+	head.Extend(n)
+	for i, field := range frame.Fields {
+		head.Fields[i].Config = field.Config
+		for row := 0; row < n; row++ {
+			head.Fields[i].Set(row, field.CopyAt(row))
+		}
+	}
+	return head
+}
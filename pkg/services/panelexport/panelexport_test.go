@@ -0,0 +1,276 @@
+package panelexport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testDashboard = `{
+	"time": {"from": "now-1h", "to": "now"},
+	"templating": {"list": [{"name": "env", "current": {"value": "prod"}}]},
+	"panels": [
+		{
+			"id": 1,
+			"type": "timeseries",
+			"datasource": {"type": "prometheus", "uid": "prom"},
+			"targets": [
+				{"refId": "A", "expr": "up{env=\"$env\"}"},
+				{"refId": "B", "expr": "down", "hide": true},
+				{"refId": "C", "datasource": {"type": "loki", "uid": "loki"}, "expr": "{env=\"$env\"}"}
+			]
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"collapsed": true,
+			"panels": [
+				{
+					"id": 3,
+					"type": "stat",
+					"targets": [
+						{"refId": "A", "expr": "up", "hide": true},
+						{"refId": "B", "datasource": {"type": "__expr__", "uid": "__expr__"}, "type": "math", "expression": "$A * 2"}
+					]
+				}
+			]
+		},
+		{"id": 4, "type": "text"},
+		{"id": 5, "gridPos": {"h": 8, "w": 12, "x": 0, "y": 8}, "libraryPanel": {"uid": "lib", "name": "Errors"}},
+		{"id": 6, "gridPos": {"h": 8, "w": 12, "x": 12, "y": 8}, "libraryPanel": {"uid": "deleted", "name": "Deleted"}}
+	]
+}`
+
+const testLibraryPanel = `{
+	"type": "timeseries",
+	"datasource": {"type": "loki", "uid": "loki"},
+	"targets": [{"refId": "A", "expr": "{env=\"$env\"} |= \"error\""}]
+}`
+
+// fakeLibraryElementService returns the test library panel for the "lib" uid.
+type fakeLibraryElementService struct {
+	libraryelements.Service
+}
+
+func (s *fakeLibraryElementService) GetElement(_ context.Context, _ identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error) {
+	if cmd.UID != "lib" {
+		return model.LibraryElementDTO{}, model.ErrLibraryElementNotFound
+	}
+	return model.LibraryElementDTO{UID: "lib", Name: "Errors", Kind: int64(model.PanelElement), Model: []byte(testLibraryPanel)}, nil
+}
+
+// fakeQueryService returns the frames of the queries by ref id, and an error for the ref id of the "error" frame.
+type fakeQueryService struct {
+	frames  map[string]data.Frames
+	request dtos.MetricRequest
+}
+
+func (s *fakeQueryService) QueryData(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	s.request = req
+	res := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		refID := q.Get("refId").MustString()
+		if f, ok := s.frames[refID]; ok {
+			res.Responses[refID] = backend.DataResponse{Frames: f}
+		}
+	}
+	if f, ok := s.frames["error"]; ok {
+		res.Responses[f[0].RefID] = backend.DataResponse{Error: errors.New("bad query")}
+	}
+	return res, nil
+}
+
+func (s *fakeQueryService) Run(context.Context) error {
+	return nil
+}
+
+type fakeDataSourceService struct {
+	fakeDatasources.FakeDataSourceService
+}
+
+func (s *fakeDataSourceService) GetDefaultDataSource(context.Context, *datasources.GetDefaultDataSourceQuery) (*datasources.DataSource, error) {
+	return &datasources.DataSource{UID: "default", IsDefault: true}, nil
+}
+
+type testEnv struct {
+	service *PanelExportService
+	queries *fakeQueryService
+}
+
+func setupTestEnv(t *testing.T, frames map[string]data.Frames) *testEnv {
+	t.Helper()
+
+	dashboard, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+		if query.UID != "dash" {
+			return nil, dashboards.ErrDashboardNotFound
+		}
+		return &dashboards.Dashboard{UID: "dash", Slug: "my-dashboard", Title: "My dashboard", Data: dashboard}, nil
+	}).Maybe()
+
+	env := &testEnv{queries: &fakeQueryService{frames: frames}}
+
+	cfg := setting.NewCfg()
+	cfg.DataProxyRowLimit = 1000
+	env.service = ProvideService(cfg, routing.NewRouteRegister(), actest.FakeAccessControl{ExpectedEvaluate: true},
+		dashboardService, &fakeDataSourceService{}, env.queries, &fakeLibraryElementService{})
+	env.service.now = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	return env
+}
+
+func testQuery(panelID int64, format Format) ExportPanelQuery {
+	return ExportPanelQuery{
+		DashboardUID: "dash",
+		PanelID:      panelID,
+		OrgID:        1,
+		User:         &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer},
+		Format:       format,
+	}
+}
+
+func TestExportPanel(t *testing.T) {
+	frameA := testFrame()
+	frameC := data.NewFrame("logs", data.NewField("line", nil, []string{"first", "second"}))
+	env := setupTestEnv(t, map[string]data.Frames{"A": {frameA}, "C": {frameC}})
+	ctx := context.Background()
+
+	t.Run("queries are built from the panel and the dashboard", func(t *testing.T) {
+		export, err := env.service.ExportPanel(ctx, testQuery(1, ""))
+		require.NoError(t, err)
+		require.Equal(t, "my-dashboard-panel-1-2024-01-01.csv", export.Filename)
+		require.Equal(t, "text/csv; charset=utf-8", export.ContentType)
+		require.False(t, export.Truncated)
+		require.True(t, bytes.HasPrefix(export.Body, []byte("time,")))
+
+		require.Equal(t, "1704106800000", env.queries.request.From)
+		require.Equal(t, "1704110400000", env.queries.request.To)
+		// The hidden query isn't sent.
+		require.Len(t, env.queries.request.Queries, 2)
+		a, c := env.queries.request.Queries[0], env.queries.request.Queries[1]
+		require.Equal(t, `up{env="prod"}`, a.Get("expr").MustString())
+		require.Equal(t, "prom", a.GetPath("datasource", "uid").MustString())
+		require.Equal(t, int64(1000), a.Get("maxDataPoints").MustInt64())
+		require.Equal(t, int64(5000), a.Get("intervalMs").MustInt64())
+		require.Equal(t, "loki", c.GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("time range, variables and frame from the query", func(t *testing.T) {
+		q := testQuery(1, FormatCSV)
+		q.From = "1704067200000"
+		q.To = "1704153600000"
+		q.Variables = map[string][]string{"env": {"dev", "test"}}
+		q.FrameIndex = 1
+		export, err := env.service.ExportPanel(ctx, q)
+		require.NoError(t, err)
+		require.Equal(t, "line\nfirst\nsecond\n", string(export.Body))
+
+		require.Equal(t, "1704067200000", env.queries.request.From)
+		require.Equal(t, `up{env="{dev,test}"}`, env.queries.request.Queries[0].Get("expr").MustString())
+
+		q.FrameIndex = 2
+		_, err = env.service.ExportPanel(ctx, q)
+		require.ErrorIs(t, err, ErrFrameNotFound)
+	})
+
+	t.Run("hidden queries are sent with expressions and panels of collapsed rows use the default data source", func(t *testing.T) {
+		_, err := env.service.ExportPanel(ctx, testQuery(3, FormatCSV))
+		require.NoError(t, err)
+		require.Len(t, env.queries.request.Queries, 2)
+		require.True(t, env.queries.request.Queries[0].Get("hide").MustBool())
+		require.Equal(t, "default", env.queries.request.Queries[0].GetPath("datasource", "uid").MustString())
+		require.Equal(t, "__expr__", env.queries.request.Queries[1].GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("queries of library panels are read from the library panel", func(t *testing.T) {
+		_, err := env.service.ExportPanel(ctx, testQuery(5, FormatCSV))
+		require.NoError(t, err)
+		require.Len(t, env.queries.request.Queries, 1)
+		require.Equal(t, `{env="prod"} |= "error"`, env.queries.request.Queries[0].Get("expr").MustString())
+		require.Equal(t, "loki", env.queries.request.Queries[0].GetPath("datasource", "uid").MustString())
+
+		_, err = env.service.ExportPanel(ctx, testQuery(6, FormatCSV))
+		require.ErrorIs(t, err, model.ErrLibraryElementNotFound)
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		export, err := env.service.ExportPanel(ctx, testQuery(1, FormatXLSX))
+		require.NoError(t, err)
+		require.Equal(t, "my-dashboard-panel-1-2024-01-01.xlsx", export.Filename)
+		files := readZip(t, export.Body)
+		require.Contains(t, files, "xl/worksheets/sheet2.xml")
+
+		export, err = env.service.ExportPanel(ctx, testQuery(1, FormatArrow))
+		require.NoError(t, err)
+		frame, err := data.UnmarshalArrowFrame(export.Body)
+		require.NoError(t, err)
+		require.Equal(t, 3, frame.Rows())
+
+		_, err = env.service.ExportPanel(ctx, testQuery(1, "pdf"))
+		require.ErrorIs(t, err, ErrInvalidFormat)
+	})
+
+	t.Run("rows over the row limit are dropped", func(t *testing.T) {
+		env.service.Cfg.DataProxyRowLimit = 4
+		defer func() { env.service.Cfg.DataProxyRowLimit = 1000 }()
+
+		export, err := env.service.ExportPanel(ctx, testQuery(1, FormatXLSX))
+		require.NoError(t, err)
+		require.True(t, export.Truncated)
+		files := readZip(t, export.Body)
+		require.Contains(t, files["xl/worksheets/sheet1.xml"], `<row r="4">`)
+		require.Contains(t, files["xl/worksheets/sheet2.xml"], `<row r="2">`)
+		require.NotContains(t, files["xl/worksheets/sheet2.xml"], `<row r="3">`)
+		// The frames of the query results aren't modified.
+		require.Equal(t, 2, frameC.Rows())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := env.service.ExportPanel(ctx, testQuery(42, FormatCSV))
+		require.ErrorIs(t, err, ErrPanelNotFound)
+
+		_, err = env.service.ExportPanel(ctx, testQuery(4, FormatCSV))
+		require.ErrorIs(t, err, ErrPanelQueriesNotFound)
+
+		q := testQuery(1, FormatCSV)
+		q.DashboardUID = "missing"
+		_, err = env.service.ExportPanel(ctx, q)
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+
+		q = testQuery(1, FormatCSV)
+		q.From = "yesterday"
+		_, err = env.service.ExportPanel(ctx, q)
+		require.ErrorIs(t, err, ErrInvalidTimeRange)
+	})
+
+	t.Run("query errors", func(t *testing.T) {
+		failed := data.NewFrame("")
+		failed.RefID = "C"
+		env := setupTestEnv(t, map[string]data.Frames{"A": {testFrame()}, "error": {failed}})
+		_, err := env.service.ExportPanel(ctx, testQuery(1, FormatCSV))
+		require.ErrorIs(t, err, ErrQueryFailed)
+		require.Contains(t, err.Error(), "C: bad query")
+	})
+}
@@ -0,0 +1,74 @@
+package panelexport
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+// findPanel returns the panel with the given id, including the panels of collapsed rows.
+func findPanel(panels []any, id int64) (*simplejson.Json, bool) {
+	for _, panelObj := range panels {
+		panel := simplejson.NewFromAny(panelObj)
+
+		if panel.Get("type").MustString() == "row" {
+			if p, ok := findPanel(panel.Get("panels").MustArray(), id); ok {
+				return p, true
+			}
+			continue
+		}
+
+		if panel.Get("id").MustInt64() == id {
+			return panel, true
+		}
+	}
+	return nil, false
+}
+
+// panelQueries returns the queries of a panel with the template variables replaced. Queries without a data source
+// use the data source of the panel, or the default data source if the panel doesn't have one either.
+func panelQueries(panel *simplejson.Json, variables map[string][]string, defaultDatasourceUID string) []*simplejson.Json {
+	var queries []*simplejson.Json
+	hasExpression := panelHasAnExpression(panel)
+
+	panelDatasource := panel.Get("datasource").Interface()
+	if getDataSourceUidFromJson(panel) == "" {
+		panelDatasource = map[string]any{"uid": defaultDatasourceUID}
+	}
+
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(interpolate(queryObj, variables))
+
+		// the expression handler removes the hidden queries from the results, but still needs them to calculate the
+		// value of the expressions
+		if !hasExpression && query.Get("hide").MustBool() {
+			continue
+		}
+
+		if getDataSourceUidFromJson(query) == "" {
+			query.Set("datasource", interpolate(panelDatasource, variables))
+		}
+		queries = append(queries, query)
+	}
+	return queries
+}
+
+func panelHasAnExpression(panel *simplejson.Json) bool {
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(queryObj)
+		if expr.NodeTypeFromDatasourceUID(getDataSourceUidFromJson(query)) == expr.TypeCMDNode {
+			return true
+		}
+	}
+	return false
+}
+
+func getDataSourceUidFromJson(query *simplejson.Json) string {
+	uid := query.Get("datasource").Get("uid").MustString()
+
+	// before 8.3 special types could be sent as datasource (expr)
+	if uid == "" {
+		uid = query.Get("datasource").MustString()
+	}
+
+	return uid
+}
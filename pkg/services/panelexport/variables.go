@@ -0,0 +1,154 @@
+package panelexport
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// allValue is the value of a template variable when "All" is selected.
+const allValue = "$__all"
+
+// variablePattern matches the $var, ${var}, ${var:format} and [[var]] syntaxes of template variables.
+var variablePattern = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^\}]+))?\}`)
+
+// dashboardVariables returns the current values of the template variables of a dashboard, overridden by the given
+// values.
+func dashboardVariables(dashboard *simplejson.Json, values map[string][]string) map[string][]string {
+	variables := make(map[string][]string)
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		current := variable.GetPath("current", "value")
+		value := current.MustStringArray()
+		if s, err := current.String(); err == nil {
+			value = []string{s}
+		}
+		if len(value) == 1 && value[0] == allValue {
+			value = allValues(variable)
+		}
+		variables[name] = value
+	}
+
+	for name, value := range values {
+		variables[name] = value
+	}
+	return variables
+}
+
+// allValues returns the values of a variable when "All" is selected, which is either its custom all value or all
+// of its options.
+func allValues(variable *simplejson.Json) []string {
+	if custom := variable.Get("allValue").MustString(); custom != "" {
+		return []string{custom}
+	}
+
+	var values []string
+	for _, o := range variable.Get("options").MustArray() {
+		value := simplejson.NewFromAny(o).Get("value").MustString()
+		if value != "" && value != allValue {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// interpolate replaces the template variables in all the strings of a query. Unknown variables, like the $__interval
+// built-in variables handled by the data sources, are left as is.
+func interpolate(value any, variables map[string][]string) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, variables)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = interpolate(item, variables)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = interpolate(item, variables)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+func interpolateString(s string, variables map[string][]string) string {
+	if !strings.ContainsAny(s, "$[") {
+		return s
+	}
+
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variablePattern.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		if groups[2] != "" {
+			name, format = groups[2], groups[3]
+		} else if groups[4] != "" {
+			name, format = groups[4], groups[5]
+		}
+
+		values, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return formatValues(values, format)
+	})
+}
+
+// formatValues formats the values of a variable with one of the formats of the frontend. Multiple values without a
+// format are formatted as a glob, like {a,b}.
+func formatValues(values []string, format string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	switch format {
+	case "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, v := range values {
+			escaped[i] = regexp.QuoteMeta(v)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quote(values, "'")
+	case "doublequote":
+		return quote(values, `"`)
+	case "json":
+		b, err := json.Marshal(values)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	case "raw":
+		return strings.Join(values, ",")
+	}
+
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+func quote(values []string, quote string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote + strings.ReplaceAll(v, quote, `\`+quote) + quote
+	}
+	return strings.Join(quoted, ",")
+}
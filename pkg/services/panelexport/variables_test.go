@@ -0,0 +1,88 @@
+package panelexport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestDashboardVariables(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{"name": "env", "current": {"text": "prod", "value": "prod"}},
+				{"name": "server", "current": {"text": ["a", "b"], "value": ["a", "b"]}},
+				{"name": "region", "current": {"value": ["$__all"]}, "options": [
+					{"value": "$__all"}, {"value": "eu"}, {"value": "us"}
+				]},
+				{"name": "dc", "current": {"value": "$__all"}, "allValue": ".*"}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]string{
+		"env":    {"prod"},
+		"server": {"a", "b"},
+		"region": {"eu", "us"},
+		"dc":     {".*"},
+	}, dashboardVariables(dashboard, nil))
+
+	variables := dashboardVariables(dashboard, map[string][]string{"env": {"dev"}, "other": {"x"}})
+	require.Equal(t, []string{"dev"}, variables["env"])
+	require.Equal(t, []string{"x"}, variables["other"])
+}
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string][]string{
+		"env":    {"prod"},
+		"server": {"a.1", "b"},
+		"quoted": {"it's"},
+		"none":   {},
+	}
+
+	for _, tc := range []struct {
+		query    string
+		expected string
+	}{
+		{`up{env="$env"}`, `up{env="prod"}`},
+		{`up{env="${env}"}`, `up{env="prod"}`},
+		{`up{env="[[env]]"}`, `up{env="prod"}`},
+		{`$server`, `{a.1,b}`},
+		{`${server:csv}`, `a.1,b`},
+		{`${server:pipe}`, `a.1|b`},
+		{`${server:regex}`, `(a\.1|b)`},
+		{`${env:regex}`, `prod`},
+		{`${server:singlequote}`, `'a.1','b'`},
+		{`${server:doublequote}`, `"a.1","b"`},
+		{`${server:json}`, `["a.1","b"]`},
+		{`[[server:raw]]`, `a.1,b`},
+		{`${quoted:singlequote}`, `'it\'s'`},
+		{`x$none`, `x`},
+		{`rate(x[$__interval])`, `rate(x[$__interval])`},
+		{`$unknown and $envs`, `$unknown and $envs`},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, interpolateString(tc.query, variables))
+		})
+	}
+
+	t.Run("all the strings of a query are interpolated", func(t *testing.T) {
+		query := map[string]any{
+			"refId":      "A",
+			"expr":       "up{env=\"$env\"}",
+			"datasource": map[string]any{"uid": "${ds}"},
+			"hide":       false,
+			"filters":    []any{map[string]any{"value": "$env"}},
+		}
+		require.Equal(t, map[string]any{
+			"refId":      "A",
+			"expr":       "up{env=\"prod\"}",
+			"datasource": map[string]any{"uid": "prom"},
+			"hide":       false,
+			"filters":    []any{map[string]any{"value": "prod"}},
+		}, interpolate(query, map[string][]string{"env": {"prod"}, "ds": {"prom"}}))
+	})
+}
@@ -0,0 +1,228 @@
+package panelexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// xlsxMaxRows is the number of rows of an Excel sheet.
+	xlsxMaxRows = 1048576
+	// xlsxMaxSheetName is the length of the names of Excel sheets.
+	xlsxMaxSheetName = 31
+
+	// The styles of the cells, as indexes of the cellXfs of the stylesheet.
+	xlsxStyleHeader = 1
+	xlsxStyleTime   = 2
+)
+
+// excelEpoch is the time of the serial date 0 of Excel.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+var sheetNameReplacer = strings.NewReplacer(":", " ", `\`, " ", "/", " ", "?", " ", "*", " ", "[", "(", "]", ")")
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`%s</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
+
+// encodeXLSX encodes frames to an Excel workbook with a sheet per frame. The first row of the sheets has the display
+// names of the fields.
+func encodeXLSX(frames data.Frames) ([]byte, error) {
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+
+	names := sheetNames(frames)
+	var overrides, sheets, rels strings.Builder
+	for i := range frames {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(names[i]), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(frames)+1)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, overrides.String())},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + sheets.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, f := range files {
+		w, err := z.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, frame := range frames {
+		if frame.Rows()+1 > xlsxMaxRows {
+			return nil, fmt.Errorf("frame %d has more rows than an Excel sheet", i)
+		}
+		w, err := z.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(sheetXML(frame)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := z.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sheetNames returns unique names of sheets for the frames, which are limited in length and can't contain some
+// characters.
+func sheetNames(frames data.Frames) []string {
+	names := make([]string, len(frames))
+	used := make(map[string]bool, len(frames))
+	for i, frame := range frames {
+		name := strings.TrimSpace(sheetNameReplacer.Replace(frame.Name))
+		if name == "" {
+			name = frame.RefID
+		}
+		if name == "" {
+			name = "Sheet"
+		}
+		name = truncate(name, xlsxMaxSheetName)
+
+		// Excel compares the names of the sheets case insensitively.
+		unique := name
+		for n := 2; used[strings.ToLower(unique)]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			unique = truncate(name, xlsxMaxSheetName-len(suffix)) + suffix
+		}
+		used[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+	return names
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+func sheetXML(frame *data.Frame) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	buf.WriteString(`<row r="1">`)
+	for col, field := range frame.Fields {
+		writeStringCell(&buf, cellRef(col, 1), fieldDisplayName(frame, field), xlsxStyleHeader)
+	}
+	buf.WriteString(`</row>`)
+
+	for row := 0; row < frame.Rows(); row++ {
+		fmt.Fprintf(&buf, `<row r="%d">`, row+2)
+		for col, field := range frame.Fields {
+			writeCell(&buf, cellRef(col, row+2), cellValue(field, row))
+		}
+		buf.WriteString(`</row>`)
+	}
+
+	buf.WriteString(`</sheetData></worksheet>`)
+	return buf.Bytes()
+}
+
+func writeCell(buf *bytes.Buffer, ref string, v any) {
+	switch v := v.(type) {
+	case nil:
+		return
+	case bool:
+		b := 0
+		if v {
+			b = 1
+		}
+		fmt.Fprintf(buf, `<c r="%s" t="b"><v>%d</v></c>`, ref, b)
+	case time.Time:
+		serial := float64(v.UTC().Sub(excelEpoch)) / float64(24*time.Hour)
+		fmt.Fprintf(buf, `<c r="%s" s="%d"><v>%s</v></c>`, ref, xlsxStyleTime, strconv.FormatFloat(serial, 'f', -1, 64))
+	case float64:
+		writeNumberCell(buf, ref, v)
+	case float32:
+		writeNumberCell(buf, ref, float64(v))
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(buf, `<c r="%s"><v>%d</v></c>`, ref, v)
+	default:
+		writeStringCell(buf, ref, formatCell(v), 0)
+	}
+}
+
+func writeNumberCell(buf *bytes.Buffer, ref string, v float64) {
+	// Excel has no representation of these values.
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		writeStringCell(buf, ref, formatCell(v), 0)
+		return
+	}
+	fmt.Fprintf(buf, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func writeStringCell(buf *bytes.Buffer, ref string, s string, style int) {
+	fmt.Fprintf(buf, `<c r="%s" t="inlineStr"`, ref)
+	if style != 0 {
+		fmt.Fprintf(buf, ` s="%d"`, style)
+	}
+	fmt.Fprintf(buf, `><is><t xml:space="preserve">%s</t></is></c>`, escapeXML(s))
+}
+
+// cellRef returns the reference of a cell, like A1, from its 0-based column and 1-based row.
+func cellRef(col, row int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
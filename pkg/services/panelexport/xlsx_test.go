@@ -0,0 +1,91 @@
+package panelexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func readZip(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestEncodeXLSX(t *testing.T) {
+	other := data.NewFrame("", data.NewField("ok", nil, []bool{true, false}))
+	other.RefID = "B"
+
+	b, err := encodeXLSX(data.Frames{testFrame(), other})
+	require.NoError(t, err)
+
+	files := readZip(t, b)
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		require.Contains(t, files, name)
+		// All the parts are well formed XML.
+		d := xml.NewDecoder(strings.NewReader(files[name]))
+		for {
+			_, err := d.Token()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err, name)
+		}
+	}
+
+	require.Contains(t, files["xl/workbook.xml"], `<sheet name="cpu" sheetId="1" r:id="rId1"/><sheet name="B" sheetId="2" r:id="rId2"/>`)
+
+	sheet := files["xl/worksheets/sheet1.xml"]
+	require.Contains(t, sheet, `<c r="B1" t="inlineStr" s="1"><is><t xml:space="preserve">value {env=&#34;prod&#34;, host=&#34;a&#34;}</t></is></c>`)
+	require.Contains(t, sheet, `<c r="A2" s="2"><v>45292</v></c><c r="B2"><v>1.5</v></c>`)
+	// Null values are empty cells.
+	require.Contains(t, sheet, `<row r="3"><c r="A3" s="2"><v>45292.00069444445</v></c><c r="C3" t="inlineStr">`)
+	require.Contains(t, files["xl/worksheets/sheet2.xml"], `<c r="A2" t="b"><v>1</v></c>`)
+}
+
+func TestSheetNames(t *testing.T) {
+	frames := data.Frames{
+		data.NewFrame("cpu"),
+		data.NewFrame("CPU"),
+		data.NewFrame("a/b:c[d]"),
+		data.NewFrame(""),
+		data.NewFrame(strings.Repeat("x", 40)),
+		data.NewFrame(strings.Repeat("x", 40)),
+	}
+	frames[3].RefID = "A"
+
+	require.Equal(t, []string{
+		"cpu",
+		"CPU (2)",
+		"a b c(d)",
+		"A",
+		strings.Repeat("x", 31),
+		strings.Repeat("x", 27) + " (2)",
+	}, sheetNames(frames))
+}
+
+func TestCellRef(t *testing.T) {
+	require.Equal(t, "A1", cellRef(0, 1))
+	require.Equal(t, "Z2", cellRef(25, 2))
+	require.Equal(t, "AA3", cellRef(26, 3))
+	require.Equal(t, "AZ4", cellRef(51, 4))
+	require.Equal(t, "ZZ5", cellRef(701, 5))
+	require.Equal(t, "AAA6", cellRef(702, 6))
+}
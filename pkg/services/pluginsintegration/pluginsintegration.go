@@ -174,11 +174,6 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		clientmiddleware.NewResourceResponseMiddleware(),
 	)
 
-	// Placing the new service implementation behind a feature flag until it is known to be stable
-	if features.IsEnabledGlobally(featuremgmt.FlagUseCachingService) {
-		middlewares = append(middlewares, clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features))
-	}
-
 	if features.IsEnabledGlobally(featuremgmt.FlagIdForwarding) {
 		middlewares = append(middlewares, clientmiddleware.NewForwardIDMiddleware())
 	}
@@ -187,6 +182,13 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 		middlewares = append(middlewares, clientmiddleware.NewUserHeaderMiddleware())
 	}
 
+	// Placing the new service implementation behind a feature flag until it is known to be stable.
+	// The middleware is also needed by the query cache enabled in the [query_caching] section. It comes after the
+	// middlewares that forward the identity of the user, so the query cache keys results by the forwarded identity.
+	if features.IsEnabledGlobally(featuremgmt.FlagUseCachingService) || cfg.QueryCaching.Enabled {
+		middlewares = append(middlewares, clientmiddleware.NewCachingMiddlewareWithFeatureManager(cachingService, features))
+	}
+
 	middlewares = append(middlewares, clientmiddleware.NewHTTPClientMiddleware())
 
 	if features.IsEnabledGlobally(featuremgmt.FlagPluginsInstrumentationStatusSource) {
@@ -0,0 +1,31 @@
+package pluginsintegration
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins/manager/registry"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCreateMiddlewares(t *testing.T) {
+	count := func(cfg *setting.Cfg, features *featuremgmt.FeatureManager) int {
+		return len(CreateMiddlewares(cfg, nil, tracing.InitializeTracerForTest(), nil, features, prometheus.NewRegistry(), registry.NewInMemory()))
+	}
+	cfg := setting.NewCfg()
+	withoutCaching := count(cfg, featuremgmt.WithFeatures())
+
+	t.Run("the caching middleware is added with the feature flag", func(t *testing.T) {
+		require.Equal(t, withoutCaching+1, count(cfg, featuremgmt.WithFeatures(featuremgmt.FlagUseCachingService)))
+	})
+
+	t.Run("the caching middleware is added when query caching is enabled", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.QueryCaching.Enabled = true
+		require.Equal(t, withoutCaching+1, count(cfg, featuremgmt.WithFeatures()))
+	})
+}
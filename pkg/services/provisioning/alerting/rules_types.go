@@ -73,6 +73,8 @@ type AlertRuleV1 struct {
 	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
 	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
 	IsPaused     values.BoolValue      `json:"isPaused" yaml:"isPaused"`
+
+	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -130,9 +132,60 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: no data set", alertRule.Title)
 	}
 	alertRule.IsPaused = rule.IsPaused.Value()
+	if rule.NotificationSettings != nil {
+		ns, err := rule.NotificationSettings.mapToModel()
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.NotificationSettings = append(alertRule.NotificationSettings, ns)
+	}
 	return alertRule, nil
 }
 
+type NotificationSettingsV1 struct {
+	Receiver          values.StringValue   `json:"receiver" yaml:"receiver"`
+	GroupBy           []values.StringValue `json:"group_by" yaml:"group_by"`
+	GroupWait         values.StringValue   `json:"group_wait" yaml:"group_wait"`
+	GroupInterval     values.StringValue   `json:"group_interval" yaml:"group_interval"`
+	RepeatInterval    values.StringValue   `json:"repeat_interval" yaml:"repeat_interval"`
+	MuteTimeIntervals []values.StringValue `json:"mute_time_intervals" yaml:"mute_time_intervals"`
+}
+
+func (nsV1 *NotificationSettingsV1) mapToModel() (models.NotificationSettings, error) {
+	if nsV1.Receiver.Value() == "" {
+		return models.NotificationSettings{}, fmt.Errorf("no receiver is set in notification settings")
+	}
+	parseDuration := func(field string, value values.StringValue) (*model.Duration, error) {
+		if value.Value() == "" {
+			return nil, nil
+		}
+		d, err := model.ParseDuration(value.Value())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in notification settings: %w", field, err)
+		}
+		return &d, nil
+	}
+
+	result := models.NewDefaultNotificationSettings(nsV1.Receiver.Value())
+	var err error
+	if result.GroupWait, err = parseDuration("group_wait", nsV1.GroupWait); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	if result.GroupInterval, err = parseDuration("group_interval", nsV1.GroupInterval); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	if result.RepeatInterval, err = parseDuration("repeat_interval", nsV1.RepeatInterval); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	for _, lbl := range nsV1.GroupBy {
+		result.GroupBy = append(result.GroupBy, lbl.Value())
+	}
+	for _, interval := range nsV1.MuteTimeIntervals {
+		result.MuteTimeIntervals = append(result.MuteTimeIntervals, interval.Value())
+	}
+	return result, nil
+}
+
 type QueryV1 struct {
 	RefID             values.StringValue       `json:"refId" yaml:"refId"`
 	QueryType         values.StringValue       `json:"queryType" yaml:"queryType"`
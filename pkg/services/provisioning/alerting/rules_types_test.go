@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -187,6 +188,45 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, ruleMapped.NoDataState, models.NoData)
 	})
+	t.Run("a rule with notification settings should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ns := &NotificationSettingsV1{}
+		err := yaml.Unmarshal([]byte(`
+receiver: test-receiver
+group_by: [namespace]
+group_wait: 30s
+repeat_interval: 1d
+mute_time_intervals: [weekends]
+`), ns)
+		require.NoError(t, err)
+		rule.NotificationSettings = ns
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		groupWait := model.Duration(30 * time.Second)
+		repeatInterval := model.Duration(24 * time.Hour)
+		require.Equal(t, []models.NotificationSettings{{
+			Receiver:          "test-receiver",
+			GroupBy:           []string{"namespace"},
+			GroupWait:         &groupWait,
+			RepeatInterval:    &repeatInterval,
+			MuteTimeIntervals: []string{"weekends"},
+		}}, ruleMapped.NotificationSettings)
+	})
+	t.Run("a rule with notification settings without a receiver should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.NotificationSettings = &NotificationSettingsV1{}
+		_, err := rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with notification settings with an invalid duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		ns := &NotificationSettingsV1{}
+		err := yaml.Unmarshal([]byte("{receiver: test-receiver, group_interval: 10x}"), ns)
+		require.NoError(t, err)
+		rule.NotificationSettings = ns
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
 }
 
 func validRuleGroupV1(t *testing.T) AlertRuleGroupV1 {
@@ -0,0 +1,286 @@
+package rendering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+)
+
+const renderCacheFolder = "/render-cache"
+
+// renderCache is a cache of rendered images shared by all the callers of the rendering service. The images are stored
+// by the hash of their contents, so that requests rendering the same image share the same blob, and concurrent
+// identical requests are rendered only once.
+type renderCache struct {
+	log     log.Logger
+	store   filestorage.FileStorage
+	ttl     time.Duration
+	maxSize int64
+	now     func() time.Time
+
+	// newFilePath returns the path of the file the image of a request is written to.
+	newFilePath func() (string, error)
+
+	group singleflight.Group
+	// blobSeq makes the paths of the stored images unique, so that removing an image doesn't remove the same image
+	// stored again in the meantime.
+	blobSeq int64
+
+	mu      sync.Mutex
+	entries map[string]*renderCacheEntry
+	blobs   map[string]*renderCacheBlob
+	size    int64
+}
+
+// renderCacheEntry is the image of a request, by the hash of its contents.
+type renderCacheEntry struct {
+	hash     string
+	expires  time.Time
+	lastUsed time.Time
+}
+
+// renderCacheBlob is a stored image and the number of entries using it.
+type renderCacheBlob struct {
+	path string
+	size int64
+	refs int
+}
+
+func newRenderCache(ctx context.Context, logger log.Logger, store filestorage.FileStorage, ttl time.Duration, maxSize int64, newFilePath func() (string, error)) *renderCache {
+	// Only the entries of this process are known, images of a previous one are removed.
+	if err := store.DeleteFolder(ctx, renderCacheFolder, &filestorage.DeleteFolderOptions{Force: true}); err != nil {
+		logger.Warn("Failed to remove the images of the render cache", "err", err)
+	}
+
+	return &renderCache{
+		log:         logger,
+		store:       store,
+		ttl:         ttl,
+		maxSize:     maxSize,
+		now:         time.Now,
+		newFilePath: newFilePath,
+		entries:     make(map[string]*renderCacheEntry),
+		blobs:       make(map[string]*renderCacheBlob),
+	}
+}
+
+// renderCacheKey returns the key of the options of a request, which includes the user the image is rendered for.
+func renderCacheKey(opts Opts) string {
+	headers := make([]string, 0, len(opts.Headers))
+	for name := range opts.Headers {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+
+	key := []any{opts.OrgID, opts.UserID, opts.OrgRole, opts.Path, opts.Width, opts.Height, opts.DeviceScaleFactor,
+		opts.Encoding, opts.Timezone, opts.Theme}
+	for _, name := range headers {
+		key = append(key, name, opts.Headers[name])
+	}
+	b, _ := json.Marshal(key)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// render returns the cached image of the options, or renders it. Concurrent requests with the same options wait for
+// the image of the first one, each caller gets its own copy of the image which it is free to remove.
+func (c *renderCache) render(ctx context.Context, opts Opts, render func(ctx context.Context) (*RenderResult, error)) (*RenderResult, error) {
+	key := renderCacheKey(opts)
+	if image, ok := c.get(ctx, key); ok {
+		metrics.MRenderingCacheTotal.WithLabelValues("hit").Inc()
+		return c.writeImage(image)
+	}
+
+	rendered := false
+	ch := c.group.DoChan(key, func() (any, error) {
+		rendered = true
+		// The render isn't canceled when the caller which started it leaves, the other callers wait for it.
+		renderCtx := context.WithoutCancel(ctx)
+		if timeout := getRequestTimeout(opts.TimeoutOpts); timeout > 0 {
+			var cancel context.CancelFunc
+			renderCtx, cancel = context.WithTimeout(renderCtx, timeout)
+			defer cancel()
+		}
+
+		res, err := render(renderCtx)
+		if err != nil {
+			return nil, err
+		}
+		image, err := os.ReadFile(res.FilePath)
+		// The caller which started the render may have left, so all callers get a copy of the image.
+		if err := os.Remove(res.FilePath); err != nil && !os.IsNotExist(err) {
+			c.log.Warn("Failed to remove the rendered image", "path", res.FilePath, "err", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the rendered image: %w", err)
+		}
+		if err := c.set(renderCtx, key, image); err != nil {
+			c.log.Warn("Failed to cache the rendered image", "path", opts.Path, "err", err)
+		}
+		return image, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		if rendered {
+			metrics.MRenderingCacheTotal.WithLabelValues("miss").Inc()
+		} else {
+			metrics.MRenderingCacheTotal.WithLabelValues("shared").Inc()
+		}
+		return c.writeImage(res.Val.([]byte))
+	}
+}
+
+func (c *renderCache) writeImage(image []byte) (*RenderResult, error) {
+	filePath, err := c.newFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filePath, image, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the cached image: %w", err)
+	}
+	return &RenderResult{FilePath: filePath}, nil
+}
+
+func (c *renderCache) get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	now := c.now()
+	entry, ok := c.entries[key]
+	if ok && !now.Before(entry.expires) {
+		removed := c.remove(key, entry, nil)
+		c.mu.Unlock()
+		c.deleteBlobs(ctx, removed)
+		return nil, false
+	}
+	if !ok {
+		c.mu.Unlock()
+		return nil, false
+	}
+	entry.lastUsed = now
+	path := c.blobs[entry.hash].path
+	c.mu.Unlock()
+
+	file, found, err := c.store.Get(ctx, path, &filestorage.GetFileOptions{WithContents: true})
+	if err != nil {
+		c.log.Warn("Failed to read a cached image", "path", path, "err", err)
+		return nil, false
+	}
+	if !found {
+		// The image has been evicted in the meantime.
+		return nil, false
+	}
+	return file.Contents, true
+}
+
+func (c *renderCache) set(ctx context.Context, key string, image []byte) error {
+	size := int64(len(image))
+	if size > c.maxSize {
+		c.log.Debug("Image too large to be cached", "size", size, "maxSize", c.maxSize)
+		return nil
+	}
+	sum := sha256.Sum256(image)
+	hash := hex.EncodeToString(sum[:])
+
+	// The image is stored without holding the lock, unless an identical image is already stored.
+	var stored string
+	c.mu.Lock()
+	for {
+		if _, ok := c.blobs[hash]; ok || stored != "" {
+			break
+		}
+		c.mu.Unlock()
+		path := filestorage.Join(renderCacheFolder, fmt.Sprintf("%s-%d.png", hash, atomic.AddInt64(&c.blobSeq, 1)))
+		if err := c.store.Upsert(ctx, &filestorage.UpsertFileCommand{
+			Path:     path,
+			MimeType: "image/png",
+			Contents: image,
+		}); err != nil {
+			return err
+		}
+		stored = path
+		c.mu.Lock()
+	}
+
+	var removed []string
+	blob, ok := c.blobs[hash]
+	if ok && stored != "" {
+		// An identical image was stored in the meantime.
+		removed = append(removed, stored)
+	}
+	if !ok {
+		blob = &renderCacheBlob{path: stored, size: size}
+		c.blobs[hash] = blob
+		c.size += size
+	}
+	// The image is referenced before other entries are removed, so that it isn't removed with them.
+	blob.refs++
+	if entry, ok := c.entries[key]; ok {
+		removed = c.remove(key, entry, removed)
+	}
+	now := c.now()
+	c.entries[key] = &renderCacheEntry{hash: hash, expires: now.Add(c.ttl), lastUsed: now}
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			removed = c.remove(k, entry, removed)
+		}
+	}
+
+	// The least recently used entries are evicted until the images fit.
+	for c.size > c.maxSize {
+		var oldestKey string
+		var oldest *renderCacheEntry
+		for k, entry := range c.entries {
+			if oldest == nil || entry.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = k, entry
+			}
+		}
+		removed = c.remove(oldestKey, oldest, removed)
+	}
+	metrics.MRenderingCacheSize.Set(float64(c.size))
+	c.mu.Unlock()
+
+	c.deleteBlobs(ctx, removed)
+	return nil
+}
+
+// remove removes an entry, and its image once no other entry uses it, appending the path of the image to removed. It
+// must be called with the lock held, the images are deleted with deleteBlobs once the lock is released.
+func (c *renderCache) remove(key string, entry *renderCacheEntry, removed []string) []string {
+	delete(c.entries, key)
+	blob := c.blobs[entry.hash]
+	blob.refs--
+	if blob.refs > 0 {
+		return removed
+	}
+
+	delete(c.blobs, entry.hash)
+	c.size -= blob.size
+	metrics.MRenderingCacheSize.Set(float64(c.size))
+	return append(removed, blob.path)
+}
+
+func (c *renderCache) deleteBlobs(ctx context.Context, paths []string) {
+	for _, path := range paths {
+		if err := c.store.Delete(ctx, path); err != nil {
+			c.log.Warn("Failed to remove a cached image", "path", path, "err", err)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package rendering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeRenderKeyProvider struct{}
+
+func (fakeRenderKeyProvider) get(context.Context, AuthOpts) (string, error) {
+	return "render-key", nil
+}
+
+func (fakeRenderKeyProvider) afterRequest(context.Context, AuthOpts, string) {}
+
+// fakeRenderer writes the image of its path, counting the renders.
+type fakeRenderer struct {
+	dir     string
+	renders int32
+	block   chan struct{}
+	err     error
+	// ctxErr is the error of the context of the last render once it is done.
+	ctxErr atomic.Value
+}
+
+func (r *fakeRenderer) render(ctx context.Context, _ string, opts Opts) (*RenderResult, error) {
+	atomic.AddInt32(&r.renders, 1)
+	if r.block != nil {
+		<-r.block
+	}
+	r.ctxErr.Store(fmt.Sprint(ctx.Err()))
+	if r.err != nil {
+		return nil, r.err
+	}
+	f, err := os.CreateTemp(r.dir, "*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.WriteString("image of " + opts.Path); err != nil {
+		return nil, err
+	}
+	return &RenderResult{FilePath: f.Name()}, nil
+}
+
+func (r *fakeRenderer) count() int {
+	return int(atomic.LoadInt32(&r.renders))
+}
+
+func newCacheTestService(t *testing.T, store filestorage.FileStorage, maxSize int64) (*RenderingService, *fakeRenderer) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.ImagesDir = t.TempDir()
+	renderer := &fakeRenderer{dir: t.TempDir()}
+	rs := &RenderingService{
+		Cfg:                         cfg,
+		log:                         log.New("test"),
+		pluginAvailable:             true,
+		perRequestRenderKeyProvider: fakeRenderKeyProvider{},
+		renderAction:                renderer.render,
+	}
+	rs.renderCache = newRenderCache(context.Background(), log.New("test"), store, time.Minute, maxSize, func() (string, error) {
+		return rs.getNewFilePath(RenderPNG)
+	})
+	return rs, renderer
+}
+
+func newMemStore() filestorage.FileStorage {
+	return filestorage.NewCdkBlobStorage(log.New("test"), memblob.OpenBucket(nil), "", nil)
+}
+
+func readImage(t *testing.T, res *RenderResult) string {
+	t.Helper()
+	b, err := os.ReadFile(res.FilePath)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestRenderCache(t *testing.T) {
+	ctx := context.Background()
+	opts := Opts{
+		AuthOpts:        AuthOpts{OrgID: 1, UserID: 1, OrgRole: org.RoleViewer},
+		Path:            "d-solo/abc/dash?panelId=2&from=1&to=2",
+		Width:           800,
+		Height:          400,
+		ConcurrentLimit: 10,
+	}
+
+	t.Run("identical requests are rendered once", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+
+		first, err := rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		second, err := rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, 1, renderer.count())
+		require.Equal(t, "image of "+opts.Path, readImage(t, second))
+		// Each caller gets its own file.
+		require.NotEqual(t, first.FilePath, second.FilePath)
+		require.NoError(t, os.Remove(first.FilePath))
+		third, err := rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, "image of "+opts.Path, readImage(t, third))
+	})
+
+	t.Run("images are cached per user and options", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+
+		other := opts
+		other.UserID = 2
+		larger := opts
+		larger.Width = 1000
+		headers := opts
+		headers.Headers = map[string][]string{"Accept-Language": {"fr"}}
+		for _, o := range []Opts{opts, other, larger, headers, opts, other} {
+			_, err := rs.Render(ctx, o, nil)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 4, renderer.count())
+
+		// The images are the same, so a single one is stored.
+		require.Len(t, rs.renderCache.entries, 4)
+		require.Len(t, rs.renderCache.blobs, 1)
+		require.Equal(t, int64(len("image of "+opts.Path)), rs.renderCache.size)
+	})
+
+	t.Run("concurrent identical requests wait for the same render", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+		renderer.block = make(chan struct{})
+
+		var wg sync.WaitGroup
+		results := make([]*RenderResult, 5)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				res, err := rs.Render(ctx, opts, nil)
+				assert.NoError(t, err)
+				results[i] = res
+			}(i)
+		}
+		require.Eventually(t, func() bool { return renderer.count() == 1 }, time.Second, 10*time.Millisecond)
+		// Let the other requests join the render in progress.
+		time.Sleep(50 * time.Millisecond)
+		close(renderer.block)
+		wg.Wait()
+
+		require.Equal(t, 1, renderer.count())
+		paths := map[string]bool{}
+		for _, res := range results {
+			require.Equal(t, "image of "+opts.Path, readImage(t, res))
+			paths[res.FilePath] = true
+		}
+		require.Len(t, paths, len(results))
+	})
+
+	t.Run("renders go on when the request which started them is canceled", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+		renderer.block = make(chan struct{})
+
+		firstCtx, cancel := context.WithCancel(ctx)
+		firstErr := make(chan error)
+		go func() {
+			_, err := rs.Render(firstCtx, opts, nil)
+			firstErr <- err
+		}()
+		require.Eventually(t, func() bool { return renderer.count() == 1 }, time.Second, 10*time.Millisecond)
+
+		second := make(chan *RenderResult)
+		go func() {
+			res, err := rs.Render(ctx, opts, nil)
+			assert.NoError(t, err)
+			second <- res
+		}()
+		// Let the second request join the render in progress.
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		require.ErrorIs(t, <-firstErr, context.Canceled)
+
+		close(renderer.block)
+		require.Equal(t, "image of "+opts.Path, readImage(t, <-second))
+		require.Equal(t, 1, renderer.count())
+		require.Equal(t, "<nil>", renderer.ctxErr.Load())
+		// The image is cached, and the file of the render is removed.
+		require.Len(t, rs.renderCache.entries, 1)
+		files, err := os.ReadDir(renderer.dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+		now := time.Now()
+		rs.renderCache.now = func() time.Time { return now }
+
+		_, err := rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		now = now.Add(59 * time.Second)
+		_, err = rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, renderer.count())
+
+		now = now.Add(time.Second)
+		_, err = rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, renderer.count())
+		require.Len(t, rs.renderCache.blobs, 1)
+	})
+
+	t.Run("least recently used images are evicted", func(t *testing.T) {
+		size := int64(len("image of " + opts.Path))
+		rs, renderer := newCacheTestService(t, newMemStore(), 2*size+4)
+		now := time.Now()
+		rs.renderCache.now = func() time.Time { return now }
+
+		paths := []string{opts.Path, opts.Path + "&a", opts.Path + "&b"}
+		render := func(path string) {
+			o := opts
+			o.Path = path
+			_, err := rs.Render(ctx, o, nil)
+			require.NoError(t, err)
+			now = now.Add(time.Second)
+		}
+		render(paths[0])
+		render(paths[1])
+		render(paths[0])
+		render(paths[2])
+		require.Equal(t, 3, renderer.count())
+		require.Len(t, rs.renderCache.blobs, 2)
+		require.LessOrEqual(t, rs.renderCache.size, 2*size+4)
+
+		// The second image was evicted, the first one is still cached.
+		render(paths[0])
+		require.Equal(t, 3, renderer.count())
+		render(paths[1])
+		require.Equal(t, 4, renderer.count())
+	})
+
+	t.Run("images larger than the cache aren't cached", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 10)
+		for i := 0; i < 2; i++ {
+			_, err := rs.Render(ctx, opts, nil)
+			require.NoError(t, err)
+		}
+		require.Equal(t, 2, renderer.count())
+		require.Empty(t, rs.renderCache.entries)
+	})
+
+	t.Run("errors aren't cached", func(t *testing.T) {
+		rs, renderer := newCacheTestService(t, newMemStore(), 1024)
+		renderer.err = errors.New("render failed")
+		_, err := rs.Render(ctx, opts, nil)
+		require.ErrorIs(t, err, renderer.err)
+
+		renderer.err = nil
+		_, err = rs.Render(ctx, opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, renderer.count())
+	})
+}
+
+func TestRenderCacheDiskStorage(t *testing.T) {
+	dataPath := t.TempDir()
+	leftover := filepath.Join(dataPath, "render-cache", "leftover.png")
+	require.NoError(t, os.MkdirAll(filepath.Dir(leftover), 0700))
+	require.NoError(t, os.WriteFile(leftover, []byte("old"), 0600))
+
+	bucket, err := fileblob.OpenBucket(dataPath, nil)
+	require.NoError(t, err)
+	rs, renderer := newCacheTestService(t, filestorage.NewCdkBlobStorage(log.New("test"), bucket, "", nil), 1024)
+	// Images of a previous run are removed.
+	require.NoFileExists(t, leftover)
+
+	opts := Opts{Path: "d-solo/abc/dash?panelId=2", ConcurrentLimit: 10}
+	for i := 0; i < 2; i++ {
+		res, err := rs.Render(context.Background(), opts, nil)
+		require.NoError(t, err)
+		require.Equal(t, "image of "+opts.Path, readImage(t, res))
+	}
+	require.Equal(t, 1, renderer.count())
+
+	for _, blob := range rs.renderCache.blobs {
+		require.FileExists(t, filepath.Join(dataPath, blob.path))
+	}
+}
@@ -40,6 +40,12 @@ func (rs *RenderingService) HasCapability(ctx context.Context, capability Capabi
 		return CapabilitySupportRequestResult{}, ErrUnknownCapability
 	}
 
+	// The chromium renderer doesn't have a version of the image renderer, it supports all capabilities but SVG
+	// sanitization.
+	if rs.chromiumAvailable() {
+		return CapabilitySupportRequestResult{IsSupported: capability != SvgSanitization, SemverConstraint: semverConstraint}, nil
+	}
+
 	compiledSemverConstraint, err := semver.NewConstraint(semverConstraint)
 	if err != nil {
 		rs.log.Error("Failed to parse semver constraint", "constraint", semverConstraint, "capability", capability, "error", err.Error())
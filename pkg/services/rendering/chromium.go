@@ -0,0 +1,469 @@
+package rendering
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	// chromiumStartTimeout is the time to wait for the browser to listen on its DevTools endpoint.
+	chromiumStartTimeout = 30 * time.Second
+	// chromiumEventBuffer is the number of events buffered for a subscription, events of subscriptions which are
+	// behind are dropped.
+	chromiumEventBuffer = 64
+)
+
+var errChromiumClosed = errors.New("chromium connection closed")
+
+var devToolsListening = regexp.MustCompile(`DevTools listening on (ws://\S+)`)
+
+// chromiumDefaultArgs are the command line flags of the browser, the flags of the configuration are appended to them.
+var chromiumDefaultArgs = []string{
+	"--headless=new",
+	"--remote-debugging-port=0",
+	"--no-first-run",
+	"--no-default-browser-check",
+	"--disable-gpu",
+	"--disable-dev-shm-usage",
+	"--disable-extensions",
+	"--disable-background-networking",
+	"--disable-sync",
+	"--hide-scrollbars",
+	"--mute-audio",
+	"--font-render-hinting=none",
+	"about:blank",
+}
+
+// cdpMessage is a message of the Chrome DevTools protocol. Commands have an id, which is the id of their response,
+// and events have a method but no id.
+type cdpMessage struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *cdpError       `json:"error,omitempty"`
+}
+
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *cdpError) Error() string {
+	return fmt.Sprintf("chromium: %s (%d)", e.Message, e.Code)
+}
+
+// cdpSubscription receives the events of some methods of a session.
+type cdpSubscription struct {
+	sessionID string
+	methods   map[string]bool
+	// accept returns false for the events the subscription isn't interested in, if set. It is called by the read
+	// loop only, so it may keep state between events.
+	accept func(msg cdpMessage) bool
+	events chan cdpMessage
+}
+
+// chromiumBrowser is a connection to a browser over the DevTools protocol, with a pool of browser contexts. Browser
+// contexts are isolated from each other like incognito windows, each render uses a context of its own.
+type chromiumBrowser struct {
+	log  log.Logger
+	conn *websocket.Conn
+	cmd  *exec.Cmd
+	// userDataDir is the profile directory of the browser, removed when it is closed.
+	userDataDir string
+	exited      chan struct{}
+
+	writeMu sync.Mutex
+	nextID  int64
+
+	mu            sync.Mutex
+	pending       map[int64]chan cdpMessage
+	subscriptions map[*cdpSubscription]bool
+
+	contexts        chan string
+	contextsCreated int32
+	maxContexts     int32
+
+	version string
+	done    chan struct{}
+	err     error
+}
+
+// launchChromium starts a headless browser and connects to it.
+func launchChromium(ctx context.Context, logger log.Logger, path string, args []string, maxContexts int) (*chromiumBrowser, error) {
+	userDataDir, err := os.MkdirTemp("", "grafana-chromium-")
+	if err != nil {
+		return nil, err
+	}
+
+	cmdArgs := append([]string{"--user-data-dir=" + userDataDir}, chromiumDefaultArgs...)
+	cmdArgs = append(cmdArgs, args...)
+	//nolint:gosec
+	cmd := exec.Command(path, cmdArgs...)
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		_ = os.RemoveAll(userDataDir)
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		_ = stderr.Close()
+		_ = os.RemoveAll(userDataDir)
+		return nil, fmt.Errorf("failed to start chromium: %w", err)
+	}
+
+	stop := func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		_ = os.RemoveAll(userDataDir)
+	}
+
+	wsURL := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if m := devToolsListening.FindStringSubmatch(scanner.Text()); m != nil {
+				wsURL <- m[1]
+				break
+			}
+		}
+		// The browser blocks if its output isn't read.
+		_, _ = io.Copy(io.Discard, stderr)
+	}()
+
+	var u string
+	select {
+	case u = <-wsURL:
+	case <-time.After(chromiumStartTimeout):
+		stop()
+		return nil, fmt.Errorf("chromium didn't start its DevTools endpoint within %s", chromiumStartTimeout)
+	case <-ctx.Done():
+		stop()
+		return nil, ctx.Err()
+	}
+
+	b, err := dialChromium(ctx, logger, u, maxContexts)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	b.cmd = cmd
+	b.userDataDir = userDataDir
+	b.exited = make(chan struct{})
+
+	go func() {
+		err := cmd.Wait()
+		logger.Debug("Chromium exited", "err", err)
+		close(b.exited)
+		_ = b.conn.Close()
+	}()
+
+	return b, nil
+}
+
+// dialChromium connects to the DevTools endpoint of a browser.
+func dialChromium(ctx context.Context, logger log.Logger, wsURL string, maxContexts int) (*chromiumBrowser, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chromium: %w", err)
+	}
+
+	if maxContexts <= 0 {
+		maxContexts = 1
+	}
+	b := &chromiumBrowser{
+		log:           logger,
+		conn:          conn,
+		pending:       make(map[int64]chan cdpMessage),
+		subscriptions: make(map[*cdpSubscription]bool),
+		contexts:      make(chan string, maxContexts),
+		maxContexts:   int32(maxContexts),
+		done:          make(chan struct{}),
+	}
+	go b.readLoop()
+
+	var version struct {
+		Product string `json:"product"`
+	}
+	if err := b.call(ctx, "", "Browser.getVersion", nil, &version); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	b.version = version.Product
+
+	return b, nil
+}
+
+func (b *chromiumBrowser) readLoop() {
+	var err error
+	defer func() {
+		b.mu.Lock()
+		b.err = err
+		close(b.done)
+		b.mu.Unlock()
+	}()
+
+	for {
+		var msg cdpMessage
+		if err = b.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		if msg.ID != 0 {
+			b.mu.Lock()
+			ch, ok := b.pending[msg.ID]
+			delete(b.pending, msg.ID)
+			b.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+			continue
+		}
+
+		b.mu.Lock()
+		var subs []*cdpSubscription
+		for sub := range b.subscriptions {
+			if sub.sessionID == msg.SessionID && sub.methods[msg.Method] {
+				subs = append(subs, sub)
+			}
+		}
+		b.mu.Unlock()
+		// The loop doesn't wait for subscriptions, so responses aren't blocked by a subscription which is behind.
+		for _, sub := range subs {
+			if sub.accept != nil && !sub.accept(msg) {
+				continue
+			}
+			select {
+			case sub.events <- msg:
+			default:
+				b.log.Debug("Dropped chromium event", "method", msg.Method, "sessionID", msg.SessionID)
+			}
+		}
+	}
+}
+
+// alive returns false once the connection to the browser is closed.
+func (b *chromiumBrowser) alive() bool {
+	select {
+	case <-b.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// call sends a command and decodes its result into result, if not nil.
+func (b *chromiumBrowser) call(ctx context.Context, sessionID, method string, params any, result any) error {
+	id := atomic.AddInt64(&b.nextID, 1)
+	ch := make(chan cdpMessage, 1)
+
+	b.mu.Lock()
+	if !b.alive() {
+		b.mu.Unlock()
+		return errChromiumClosed
+	}
+	b.pending[id] = ch
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.pending, id)
+		b.mu.Unlock()
+	}()
+
+	msg := cdpMessage{ID: id, SessionID: sessionID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = raw
+	}
+
+	b.writeMu.Lock()
+	err := b.conn.WriteJSON(msg)
+	b.writeMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case res := <-ch:
+		if res.Error != nil {
+			return fmt.Errorf("%s: %w", method, res.Error)
+		}
+		if result != nil && len(res.Result) > 0 {
+			return json.Unmarshal(res.Result, result)
+		}
+		return nil
+	case <-b.done:
+		return errChromiumClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// subscribe returns a subscription to the events of the given methods of a session, the empty session being the
+// browser, filtered by accept if not nil. The subscription must be closed with unsubscribe.
+func (b *chromiumBrowser) subscribe(sessionID string, accept func(msg cdpMessage) bool, methods ...string) *cdpSubscription {
+	sub := &cdpSubscription{
+		sessionID: sessionID,
+		methods:   make(map[string]bool, len(methods)),
+		accept:    accept,
+		events:    make(chan cdpMessage, chromiumEventBuffer),
+	}
+	for _, m := range methods {
+		sub.methods[m] = true
+	}
+
+	b.mu.Lock()
+	b.subscriptions[sub] = true
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *chromiumBrowser) unsubscribe(sub *cdpSubscription) {
+	b.mu.Lock()
+	delete(b.subscriptions, sub)
+	b.mu.Unlock()
+}
+
+// acquireContext returns a browser context of the pool, creating it if the pool isn't full, or waits for a context
+// to be released.
+func (b *chromiumBrowser) acquireContext(ctx context.Context) (string, error) {
+	select {
+	case id := <-b.contexts:
+		return id, nil
+	default:
+	}
+
+	if atomic.AddInt32(&b.contextsCreated, 1) <= b.maxContexts {
+		var res struct {
+			BrowserContextID string `json:"browserContextId"`
+		}
+		if err := b.call(ctx, "", "Target.createBrowserContext", map[string]any{"disposeOnDetach": false}, &res); err != nil {
+			atomic.AddInt32(&b.contextsCreated, -1)
+			return "", err
+		}
+		return res.BrowserContextID, nil
+	}
+	atomic.AddInt32(&b.contextsCreated, -1)
+
+	select {
+	case id := <-b.contexts:
+		return id, nil
+	case <-b.done:
+		return "", errChromiumClosed
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// releaseContext clears the cookies of a browser context and puts it back in the pool. Contexts which can't be
+// cleared are disposed of.
+func (b *chromiumBrowser) releaseContext(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := b.call(ctx, "", "Storage.clearCookies", map[string]any{"browserContextId": id}, nil); err != nil {
+		b.log.Warn("Failed to clear the cookies of a chromium browser context", "err", err)
+		_ = b.call(ctx, "", "Target.disposeBrowserContext", map[string]any{"browserContextId": id}, nil)
+		atomic.AddInt32(&b.contextsCreated, -1)
+		return
+	}
+	b.contexts <- id
+}
+
+// close closes the browser and removes its profile.
+func (b *chromiumBrowser) close() {
+	if b.cmd != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := b.call(ctx, "", "Browser.close", nil, nil)
+		cancel()
+		if err != nil {
+			_ = b.cmd.Process.Kill()
+		}
+		select {
+		case <-b.exited:
+		case <-time.After(5 * time.Second):
+			_ = b.cmd.Process.Kill()
+			<-b.exited
+		}
+	}
+	_ = b.conn.Close()
+	<-b.done
+
+	if b.userDataDir != "" {
+		if err := os.RemoveAll(b.userDataDir); err != nil {
+			b.log.Warn("Failed to remove the chromium profile", "path", b.userDataDir, "err", err)
+		}
+	}
+}
+
+// chromiumLauncher starts the browser on first use, and again if it exits.
+type chromiumLauncher struct {
+	log         log.Logger
+	path        string
+	args        []string
+	maxContexts int
+	launch      func(ctx context.Context, logger log.Logger, path string, args []string, maxContexts int) (*chromiumBrowser, error)
+
+	mu      sync.Mutex
+	browser *chromiumBrowser
+}
+
+func newChromiumLauncher(logger log.Logger, path string, args []string, maxContexts int) *chromiumLauncher {
+	return &chromiumLauncher{
+		log:         logger,
+		path:        path,
+		args:        args,
+		maxContexts: maxContexts,
+		launch:      launchChromium,
+	}
+}
+
+// get returns the running browser, starting it if needed.
+func (l *chromiumLauncher) get(ctx context.Context) (*chromiumBrowser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.browser != nil && l.browser.alive() {
+		return l.browser, nil
+	}
+	if l.browser != nil {
+		l.log.Warn("Chromium connection lost, restarting the browser", "err", l.browser.err)
+		l.browser.close()
+		l.browser = nil
+	}
+
+	b, err := l.launch(ctx, l.log, l.path, l.args, l.maxContexts)
+	if err != nil {
+		return nil, err
+	}
+	l.log.Info("Started chromium", "path", l.path, "version", b.version)
+	l.browser = b
+	return b, nil
+}
+
+func (l *chromiumLauncher) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.browser != nil {
+		l.browser.close()
+		l.browser = nil
+	}
+}
@@ -0,0 +1,417 @@
+package rendering
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// chromiumDefaultTimeout is the timeout of the renders which don't set one.
+	chromiumDefaultTimeout = 30 * time.Second
+	// chromiumReadyPollInterval is how often the page is checked for its panels to be rendered.
+	chromiumReadyPollInterval = 100 * time.Millisecond
+	// chromiumFullHeightViewport is the height of the viewport before the page is resized to its full height.
+	chromiumFullHeightViewport = 1080
+)
+
+// chromiumNoPanelsWait is how long a page without panels is waited for, the panels of a dashboard are added to the
+// page after it is loaded.
+var chromiumNoPanelsWait = 2 * time.Second
+
+// panelsRenderedScript returns the number of panels of the page and the number of rendered panels. The frontend
+// counts the rendered panels in window.panelsRendered.
+const panelsRenderedScript = `(() => ({
+	panels: document.querySelectorAll('.panel-solo').length || document.querySelectorAll('[data-panelid]').length,
+	rendered: window.panelsRendered || 0,
+}))()`
+
+// scrollHeightScript returns the height of the content of the page, which scrolls in a container rather than in the
+// body.
+const scrollHeightScript = `Math.max(document.body.scrollHeight, ...Array.from(document.querySelectorAll('.scrollbar-view, main')).map((e) => e.scrollHeight))`
+
+// chromiumPage is a browser tab opened in a browser context of its own.
+type chromiumPage struct {
+	browser   *chromiumBrowser
+	contextID string
+	targetID  string
+	sessionID string
+}
+
+func (p *chromiumPage) call(ctx context.Context, method string, params any, result any) error {
+	return p.browser.call(ctx, p.sessionID, method, params, result)
+}
+
+// evaluate runs a script in the page and decodes its value into result.
+func (p *chromiumPage) evaluate(ctx context.Context, script string, result any) error {
+	var res struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text string `json:"text"`
+		} `json:"exceptionDetails"`
+	}
+	if err := p.call(ctx, "Runtime.evaluate", map[string]any{"expression": script, "returnByValue": true}, &res); err != nil {
+		return err
+	}
+	if res.ExceptionDetails != nil {
+		return fmt.Errorf("script failed: %s", res.ExceptionDetails.Text)
+	}
+	return json.Unmarshal(res.Result.Value, result)
+}
+
+// navigate opens a URL and waits for the page to be loaded.
+func (p *chromiumPage) navigate(ctx context.Context, url string) error {
+	sub := p.browser.subscribe(p.sessionID, nil, "Page.loadEventFired")
+	defer p.browser.unsubscribe(sub)
+
+	var res struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.call(ctx, "Page.navigate", map[string]any{"url": url}, &res); err != nil {
+		return err
+	}
+	if res.ErrorText != "" {
+		return fmt.Errorf("failed to open %s: %s", url, res.ErrorText)
+	}
+
+	select {
+	case <-sub.events:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitForPanels waits for the panels of the page to be rendered, or returns once the page still has no panels after
+// chromiumNoPanelsWait.
+func (p *chromiumPage) waitForPanels(ctx context.Context) error {
+	ticker := time.NewTicker(chromiumReadyPollInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		var state struct {
+			Panels   int `json:"panels"`
+			Rendered int `json:"rendered"`
+		}
+		if err := p.evaluate(ctx, panelsRenderedScript, &state); err != nil {
+			return err
+		}
+		if state.Panels > 0 && state.Rendered >= state.Panels {
+			return nil
+		}
+		if state.Panels == 0 && time.Since(start) >= chromiumNoPanelsWait {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *chromiumPage) setViewport(ctx context.Context, width, height int, deviceScaleFactor float64) error {
+	return p.call(ctx, "Emulation.setDeviceMetricsOverride", map[string]any{
+		"width":             width,
+		"height":            height,
+		"deviceScaleFactor": deviceScaleFactor,
+		"mobile":            false,
+	}, nil)
+}
+
+// withChromiumPage opens a page in a browser context of the pool, authenticated with the render key, and closes it
+// once fn returns.
+func (rs *RenderingService) withChromiumPage(ctx context.Context, renderKey string, headers map[string][]string, timezone string, fn func(p *chromiumPage) error) error {
+	browser, err := rs.chromium.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	contextID, err := browser.acquireContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer browser.releaseContext(contextID)
+
+	err = browser.call(ctx, "", "Storage.setCookies", map[string]any{
+		"browserContextId": contextID,
+		"cookies": []map[string]any{{
+			"name":     "renderKey",
+			"value":    renderKey,
+			"domain":   rs.domain,
+			"path":     "/",
+			"httpOnly": true,
+		}},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := browser.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank", "browserContextId": contextID}, &target); err != nil {
+		return err
+	}
+	defer func() {
+		// The page is closed even if the render timed out.
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := browser.call(closeCtx, "", "Target.closeTarget", map[string]any{"targetId": target.TargetID}, nil); err != nil {
+			rs.log.Warn("Failed to close chromium page", "err", err)
+		}
+	}()
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := browser.call(ctx, "", "Target.attachToTarget", map[string]any{"targetId": target.TargetID, "flatten": true}, &session); err != nil {
+		return err
+	}
+
+	p := &chromiumPage{browser: browser, contextID: contextID, targetID: target.TargetID, sessionID: session.SessionID}
+	if err := p.call(ctx, "Page.enable", nil, nil); err != nil {
+		return err
+	}
+	if len(headers) > 0 {
+		extraHeaders := make(map[string]string, len(headers))
+		for k, v := range headers {
+			extraHeaders[k] = strings.Join(v, ", ")
+		}
+		if err := p.call(ctx, "Network.enable", nil, nil); err != nil {
+			return err
+		}
+		if err := p.call(ctx, "Network.setExtraHTTPHeaders", map[string]any{"headers": extraHeaders}, nil); err != nil {
+			return err
+		}
+	}
+	if tz := chromiumTimezone(timezone); tz != "" {
+		if err := p.call(ctx, "Emulation.setTimezoneOverride", map[string]any{"timezoneId": tz}, nil); err != nil {
+			rs.log.Debug("Failed to set the timezone of the chromium page", "timezone", timezone, "err", err)
+		}
+	}
+
+	return fn(p)
+}
+
+// chromiumTimezone returns the IANA time zone of the browser for the time zone of a render, which is either an IANA
+// time zone or an offset like UTC+02:00. Offsets which aren't whole hours aren't supported.
+func chromiumTimezone(timezone string) string {
+	if timezone == "" {
+		return ""
+	}
+
+	if offset, ok := strings.CutPrefix(timezone, "UTC"); ok && offset != "" {
+		hours, minutes, _ := strings.Cut(offset[1:], ":")
+		if minutes != "" && minutes != "00" {
+			return ""
+		}
+		hours = strings.TrimLeft(hours, "0")
+		if hours == "" {
+			return "UTC"
+		}
+		// The sign of the Etc zones is inverted, Etc/GMT-2 is UTC+02:00.
+		switch offset[0] {
+		case '+':
+			return "Etc/GMT-" + hours
+		case '-':
+			return "Etc/GMT+" + hours
+		}
+		return ""
+	}
+
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return ""
+	}
+	return timezone
+}
+
+// chromiumTimeout returns the context of a render, with a timeout error of the rendering package.
+func chromiumTimeout(ctx context.Context, opts TimeoutOpts) (context.Context, context.CancelFunc) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = chromiumDefaultTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+func chromiumError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	return err
+}
+
+func (rs *RenderingService) renderViaChromium(ctx context.Context, renderKey string, opts Opts) (*RenderResult, error) {
+	filePath, err := rs.getNewFilePath(RenderPNG)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := chromiumTimeout(ctx, opts.TimeoutOpts)
+	defer cancel()
+
+	url := rs.getURL(opts.Path)
+	err = rs.withChromiumPage(ctx, renderKey, opts.Headers, opts.Timezone, func(p *chromiumPage) error {
+		fullHeight := opts.Height == -1
+		height := opts.Height
+		if fullHeight {
+			height = chromiumFullHeightViewport
+		}
+		if err := p.setViewport(ctx, opts.Width, height, opts.DeviceScaleFactor); err != nil {
+			return err
+		}
+
+		if err := p.navigate(ctx, url); err != nil {
+			return err
+		}
+		if err := p.waitForPanels(ctx); err != nil {
+			return err
+		}
+
+		if fullHeight {
+			var scrollHeight int
+			if err := p.evaluate(ctx, scrollHeightScript, &scrollHeight); err != nil {
+				return err
+			}
+			if scrollHeight > height {
+				if err := p.setViewport(ctx, opts.Width, scrollHeight, opts.DeviceScaleFactor); err != nil {
+					return err
+				}
+				// Panels out of the viewport are rendered once they are visible.
+				if err := p.waitForPanels(ctx); err != nil {
+					return err
+				}
+			}
+		}
+
+		var screenshot struct {
+			Data string `json:"data"`
+		}
+		if err := p.call(ctx, "Page.captureScreenshot", map[string]any{"format": "png", "fromSurface": true}, &screenshot); err != nil {
+			return err
+		}
+		img, err := base64.StdEncoding.DecodeString(screenshot.Data)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filePath, img, 0600)
+	})
+	if err != nil {
+		err = chromiumError(ctx, err)
+		rs.log.Error("Chromium rendering failed", "url", url, "err", err)
+		return nil, err
+	}
+
+	return &RenderResult{FilePath: filePath}, nil
+}
+
+func (rs *RenderingService) renderCSVViaChromium(ctx context.Context, renderKey string, opts CSVOpts) (*RenderCSVResult, error) {
+	filePath, err := rs.getNewFilePath(RenderCSV)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadDir, err := os.MkdirTemp(rs.Cfg.CSVsDir, "download-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(downloadDir); err != nil {
+			rs.log.Warn("Failed to remove chromium download directory", "path", downloadDir, "err", err)
+		}
+	}()
+
+	ctx, cancel := chromiumTimeout(ctx, opts.TimeoutOpts)
+	defer cancel()
+
+	var fileName string
+	url := rs.getURL(opts.Path)
+	err = rs.withChromiumPage(ctx, renderKey, opts.Headers, opts.Timezone, func(p *chromiumPage) error {
+		// Downloads are named after their guid, and renamed once completed.
+		err := p.browser.call(ctx, "", "Browser.setDownloadBehavior", map[string]any{
+			"behavior":         "allowAndName",
+			"browserContextId": p.contextID,
+			"downloadPath":     downloadDir,
+			"eventsEnabled":    true,
+		}, nil)
+		if err != nil {
+			return err
+		}
+
+		// Download events are sent to the browser session for the downloads of all pages, only the download of
+		// this page and the end of its progress are delivered.
+		var guid string
+		accept := func(event cdpMessage) bool {
+			var params struct {
+				FrameID string `json:"frameId"`
+				GUID    string `json:"guid"`
+				State   string `json:"state"`
+			}
+			if err := json.Unmarshal(event.Params, &params); err != nil {
+				return false
+			}
+			if event.Method == "Browser.downloadWillBegin" {
+				if guid != "" || params.FrameID != p.targetID {
+					return false
+				}
+				guid = params.GUID
+				return true
+			}
+			return guid != "" && params.GUID == guid && (params.State == "completed" || params.State == "canceled")
+		}
+		sub := p.browser.subscribe("", accept, "Browser.downloadWillBegin", "Browser.downloadProgress")
+		defer p.browser.unsubscribe(sub)
+
+		// The panel is downloaded as CSV once the page is loaded.
+		if err := p.navigate(ctx, url); err != nil {
+			return err
+		}
+
+		for {
+			select {
+			case event := <-sub.events:
+				var params struct {
+					GUID              string `json:"guid"`
+					SuggestedFilename string `json:"suggestedFilename"`
+					State             string `json:"state"`
+				}
+				if err := json.Unmarshal(event.Params, &params); err != nil {
+					return err
+				}
+
+				if event.Method == "Browser.downloadWillBegin" {
+					fileName = params.SuggestedFilename
+					continue
+				}
+				if params.State == "canceled" {
+					return errors.New("the CSV download was canceled")
+				}
+				return os.Rename(filepath.Join(downloadDir, params.GUID), filePath)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
+	if err != nil {
+		err = chromiumError(ctx, err)
+		rs.log.Error("Chromium CSV rendering failed", "url", url, "err", err)
+		return nil, err
+	}
+
+	return &RenderCSVResult{FilePath: filePath, FileName: fileName}, nil
+}
+
+func (rs *RenderingService) sanitizeSVGViaChromium(ctx context.Context, req *SanitizeSVGRequest) (*SanitizeSVGResponse, error) {
+	return nil, errors.New("svg sanitization is not supported by the chromium renderer")
+}
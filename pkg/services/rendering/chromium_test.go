@@ -0,0 +1,336 @@
+package rendering
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// fakeChromium implements the commands of the DevTools protocol used by the chromium renderer.
+type fakeChromium struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	calls      map[string][]map[string]any
+	ids        int
+	panels     int
+	panelsDone bool
+}
+
+func newFakeChromium(t *testing.T) *fakeChromium {
+	f := &fakeChromium{t: t, calls: make(map[string][]map[string]any), panels: 1, panelsDone: true}
+	upgrader := websocket.Upgrader{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+
+		var writeMu sync.Mutex
+		send := func(msg map[string]any) {
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			_ = conn.WriteJSON(msg)
+		}
+		for {
+			var msg cdpMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			var params map[string]any
+			if len(msg.Params) > 0 {
+				require.NoError(t, json.Unmarshal(msg.Params, &params))
+			}
+			result, events := f.handle(msg.Method, msg.SessionID, params)
+			send(map[string]any{"id": msg.ID, "result": result})
+			for _, event := range events {
+				send(event)
+			}
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeChromium) url() string {
+	return "ws" + strings.TrimPrefix(f.server.URL, "http")
+}
+
+func (f *fakeChromium) handle(method, sessionID string, params map[string]any) (map[string]any, []map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[method] = append(f.calls[method], params)
+
+	switch method {
+	case "Browser.getVersion":
+		return map[string]any{"product": "HeadlessChrome/120.0.0.0"}, nil
+	case "Target.createBrowserContext":
+		f.ids++
+		return map[string]any{"browserContextId": fmt.Sprintf("context-%d", f.ids)}, nil
+	case "Target.createTarget":
+		f.ids++
+		return map[string]any{"targetId": fmt.Sprintf("target-%d", f.ids)}, nil
+	case "Target.attachToTarget":
+		return map[string]any{"sessionId": "session-" + params["targetId"].(string)}, nil
+	case "Page.navigate":
+		events := []map[string]any{{"method": "Page.loadEventFired", "sessionId": sessionID, "params": map[string]any{}}}
+		if downloads := f.calls["Browser.setDownloadBehavior"]; len(downloads) > 0 {
+			// The CSV of the panel is downloaded by the page.
+			dir := downloads[len(downloads)-1]["downloadPath"].(string)
+			require.NoError(f.t, os.WriteFile(filepath.Join(dir, "guid"), []byte("time,value\n"), 0600))
+			targetID := strings.TrimPrefix(sessionID, "session-")
+			events = append(events,
+				map[string]any{"method": "Browser.downloadWillBegin", "params": map[string]any{"frameId": "other", "guid": "other", "suggestedFilename": "other.csv"}},
+				map[string]any{"method": "Browser.downloadWillBegin", "params": map[string]any{"frameId": targetID, "guid": "guid", "suggestedFilename": "panel.csv"}},
+			)
+			// More progress events than the subscription buffers.
+			for i := 0; i < chromiumEventBuffer; i++ {
+				events = append(events,
+					map[string]any{"method": "Browser.downloadProgress", "params": map[string]any{"guid": "other", "state": "inProgress"}},
+					map[string]any{"method": "Browser.downloadProgress", "params": map[string]any{"guid": "guid", "state": "inProgress"}},
+				)
+			}
+			events = append(events, map[string]any{"method": "Browser.downloadProgress", "params": map[string]any{"guid": "guid", "state": "completed"}})
+		}
+		return map[string]any{"frameId": "frame"}, events
+	case "Runtime.evaluate":
+		if params["expression"] == scrollHeightScript {
+			return map[string]any{"result": map[string]any{"value": 3000}}, nil
+		}
+		rendered := 0
+		if f.panelsDone {
+			rendered = f.panels
+		}
+		return map[string]any{"result": map[string]any{"value": map[string]any{"panels": f.panels, "rendered": rendered}}}, nil
+	case "Page.captureScreenshot":
+		return map[string]any{"data": base64.StdEncoding.EncodeToString([]byte("png"))}, nil
+	}
+	return map[string]any{}, nil
+}
+
+func (f *fakeChromium) called(method string) []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+func newChromiumTestService(t *testing.T, f *fakeChromium, maxContexts int) *RenderingService {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.ImagesDir = t.TempDir()
+	cfg.CSVsDir = t.TempDir()
+	cfg.HTTPPort = "3000"
+	cfg.Protocol = setting.HTTPScheme
+	cfg.RendererChromiumPath = "/usr/bin/chromium"
+
+	logger := log.New("test")
+	return &RenderingService{
+		Cfg:    cfg,
+		log:    logger,
+		domain: "localhost",
+		chromium: &chromiumLauncher{
+			log:         logger,
+			maxContexts: maxContexts,
+			launch: func(ctx context.Context, logger log.Logger, _ string, _ []string, maxContexts int) (*chromiumBrowser, error) {
+				return dialChromium(ctx, logger, f.url(), maxContexts)
+			},
+		},
+	}
+}
+
+func TestRenderViaChromium(t *testing.T) {
+	f := newFakeChromium(t)
+	rs := newChromiumTestService(t, f, 2)
+	defer rs.chromium.close()
+	require.True(t, rs.chromiumAvailable())
+
+	opts := Opts{
+		TimeoutOpts:       TimeoutOpts{Timeout: 5 * time.Second},
+		Width:             800,
+		Height:            400,
+		Path:              "d-solo/abc/dash?panelId=2",
+		Timezone:          "UTC+02:00",
+		DeviceScaleFactor: 2,
+		Headers:           map[string][]string{"Accept-Language": {"en", "fr"}},
+	}
+
+	t.Run("renders a screenshot of the page", func(t *testing.T) {
+		res, err := rs.renderViaChromium(context.Background(), "render-key", opts)
+		require.NoError(t, err)
+		png, err := os.ReadFile(res.FilePath)
+		require.NoError(t, err)
+		require.Equal(t, "png", string(png))
+
+		cookies := f.called("Storage.setCookies")[0]["cookies"].([]any)[0].(map[string]any)
+		require.Equal(t, "renderKey", cookies["name"])
+		require.Equal(t, "render-key", cookies["value"])
+		require.Equal(t, "localhost", cookies["domain"])
+
+		require.Equal(t, "http://localhost:3000/d-solo/abc/dash?panelId=2&render=1", f.called("Page.navigate")[0]["url"])
+		viewport := f.called("Emulation.setDeviceMetricsOverride")[0]
+		require.Equal(t, float64(800), viewport["width"])
+		require.Equal(t, float64(400), viewport["height"])
+		require.Equal(t, float64(2), viewport["deviceScaleFactor"])
+		require.Equal(t, "Etc/GMT-2", f.called("Emulation.setTimezoneOverride")[0]["timezoneId"])
+		require.Equal(t, map[string]any{"Accept-Language": "en, fr"}, f.called("Network.setExtraHTTPHeaders")[0]["headers"])
+
+		// The page is closed and the browser context goes back to the pool without its cookies.
+		require.Len(t, f.called("Target.closeTarget"), 1)
+		require.Len(t, f.called("Storage.clearCookies"), 1)
+	})
+
+	t.Run("browser contexts are reused", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := rs.renderViaChromium(context.Background(), "render-key", opts)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+		require.LessOrEqual(t, len(f.called("Target.createBrowserContext")), 2)
+		require.Len(t, f.called("Target.createTarget"), 6)
+	})
+
+	t.Run("full height images are resized to the height of the page", func(t *testing.T) {
+		fullHeight := opts
+		fullHeight.Height = -1
+		calls := len(f.called("Emulation.setDeviceMetricsOverride"))
+		_, err := rs.renderViaChromium(context.Background(), "render-key", fullHeight)
+		require.NoError(t, err)
+
+		viewports := f.called("Emulation.setDeviceMetricsOverride")[calls:]
+		require.Len(t, viewports, 2)
+		require.Equal(t, float64(chromiumFullHeightViewport), viewports[0]["height"])
+		require.Equal(t, float64(3000), viewports[1]["height"])
+	})
+
+	t.Run("renders time out if the panels aren't rendered", func(t *testing.T) {
+		f.mu.Lock()
+		f.panelsDone = false
+		f.mu.Unlock()
+		defer func() {
+			f.mu.Lock()
+			f.panelsDone = true
+			f.mu.Unlock()
+		}()
+
+		timeout := opts
+		timeout.Timeout = 300 * time.Millisecond
+		_, err := rs.renderViaChromium(context.Background(), "render-key", timeout)
+		require.ErrorIs(t, err, ErrTimeout)
+	})
+
+	t.Run("pages without panels are rendered without waiting for the timeout", func(t *testing.T) {
+		f.mu.Lock()
+		f.panels = 0
+		f.mu.Unlock()
+		defer func() {
+			f.mu.Lock()
+			f.panels = 1
+			f.mu.Unlock()
+		}()
+		noPanelsWait := chromiumNoPanelsWait
+		chromiumNoPanelsWait = 50 * time.Millisecond
+		defer func() { chromiumNoPanelsWait = noPanelsWait }()
+
+		start := time.Now()
+		_, err := rs.renderViaChromium(context.Background(), "render-key", opts)
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), opts.Timeout)
+	})
+}
+
+func TestChromiumBrowserSlowSubscription(t *testing.T) {
+	f := newFakeChromium(t)
+	b, err := dialChromium(context.Background(), log.New("test"), f.url(), 1)
+	require.NoError(t, err)
+	defer b.close()
+
+	// The events of a subscription which isn't read are dropped once its buffer is full, without blocking the
+	// responses of the commands.
+	sub := b.subscribe("session", nil, "Page.loadEventFired")
+	defer b.unsubscribe(sub)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for i := 0; i < chromiumEventBuffer+2; i++ {
+		require.NoError(t, b.call(ctx, "session", "Page.navigate", map[string]any{"url": "about:blank"}, nil))
+	}
+	require.Len(t, sub.events, chromiumEventBuffer)
+}
+
+func TestRenderCSVViaChromium(t *testing.T) {
+	f := newFakeChromium(t)
+	rs := newChromiumTestService(t, f, 1)
+	defer rs.chromium.close()
+
+	res, err := rs.renderCSVViaChromium(context.Background(), "render-key", CSVOpts{
+		TimeoutOpts: TimeoutOpts{Timeout: 5 * time.Second},
+		Path:        "d-csv/abc/dash?panelId=2",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "panel.csv", res.FileName)
+	csv, err := os.ReadFile(res.FilePath)
+	require.NoError(t, err)
+	require.Equal(t, "time,value\n", string(csv))
+
+	download := f.called("Browser.setDownloadBehavior")[0]
+	require.Equal(t, "allowAndName", download["behavior"])
+	require.Equal(t, "context-1", download["browserContextId"])
+	// The download directory is removed.
+	require.NoDirExists(t, download["downloadPath"].(string))
+}
+
+func TestChromiumCapabilities(t *testing.T) {
+	rs := newChromiumTestService(t, newFakeChromium(t), 1)
+	rs.capabilities = []Capability{
+		{name: FullHeightImages, semverConstraint: ">= 3.4.0"},
+		{name: SvgSanitization, semverConstraint: ">= 3.5.0"},
+	}
+
+	res, err := rs.HasCapability(context.Background(), FullHeightImages)
+	require.NoError(t, err)
+	require.True(t, res.IsSupported)
+
+	res, err = rs.HasCapability(context.Background(), SvgSanitization)
+	require.NoError(t, err)
+	require.False(t, res.IsSupported)
+
+	// The remote rendering service takes precedence.
+	rs.Cfg.RendererUrl = "http://localhost:8081/render"
+	require.False(t, rs.chromiumAvailable())
+}
+
+func TestChromiumTimezone(t *testing.T) {
+	for tz, expected := range map[string]string{
+		"":                 "",
+		"UTC":              "UTC",
+		"Europe/Stockholm": "Europe/Stockholm",
+		"UTC+02:00":        "Etc/GMT-2",
+		"UTC-10:00":        "Etc/GMT+10",
+		"UTC+00:00":        "UTC",
+		"UTC+05:30":        "",
+		"browser":          "",
+	} {
+		require.Equal(t, expected, chromiumTimezone(tz), tz)
+	}
+}
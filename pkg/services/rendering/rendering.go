@@ -14,6 +14,11 @@ import (
 	"sync/atomic"
 	"time"
 
+	"gocloud.dev/blob"
+	"gocloud.dev/blob/fileblob"
+	"gocloud.dev/blob/memblob"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/remotecache"
@@ -39,6 +44,8 @@ type RenderingService struct {
 	versionMutex      sync.RWMutex
 	capabilities      []Capability
 	pluginAvailable   bool
+	chromium          *chromiumLauncher
+	renderCache       *renderCache
 
 	perRequestRenderKeyProvider renderKeyProvider
 	Cfg                         *setting.Cfg
@@ -137,11 +144,35 @@ func ProvideService(cfg *setting.Cfg, features *featuremgmt.FeatureManager, remo
 		pluginAvailable:       exists,
 	}
 
+	if cfg.RendererChromiumPath != "" {
+		s.chromium = newChromiumLauncher(logger.New("renderer", "chromium"), cfg.RendererChromiumPath, cfg.RendererChromiumArgs, cfg.RendererChromiumMaxContexts)
+	}
+
+	if cfg.RendererCacheEnabled {
+		bucket, err := openRenderCacheBucket(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the render cache storage: %w", err)
+		}
+		cacheLogger := logger.New("cache", cfg.RendererCacheStorage)
+		store := filestorage.NewCdkBlobStorage(cacheLogger, bucket, "", nil)
+		s.renderCache = newRenderCache(context.Background(), cacheLogger, store, cfg.RendererCacheTTL, cfg.RendererCacheMaxSize, func() (string, error) {
+			return s.getNewFilePath(RenderPNG)
+		})
+	}
+
 	gob.Register(&RenderUser{})
 
 	return s, nil
 }
 
+// openRenderCacheBucket returns the bucket the render cache stores the images in, disk storage uses the data path.
+func openRenderCacheBucket(cfg *setting.Cfg) (*blob.Bucket, error) {
+	if cfg.RendererCacheStorage == "disk" {
+		return fileblob.OpenBucket(cfg.DataPath, nil)
+	}
+	return memblob.OpenBucket(nil), nil
+}
+
 func getSanitizerURL(rendererURL string) string {
 	rendererBaseURL := strings.TrimSuffix(rendererURL, "/render")
 	return rendererBaseURL + "/sanitize"
@@ -181,6 +212,25 @@ func (rs *RenderingService) Run(ctx context.Context) error {
 		}
 	}
 
+	if rs.chromiumAvailable() {
+		rs.log = rs.log.New("renderer", "chromium")
+		rs.renderAction = rs.renderViaChromium
+		rs.renderCSVAction = rs.renderCSVViaChromium
+		rs.sanitizeSVGAction = rs.sanitizeSVGViaChromium
+
+		// The browser is started on the first render, a failure to start it isn't fatal.
+		if browser, err := rs.chromium.get(ctx); err != nil {
+			rs.log.Error("Failed to start chromium", "path", rs.Cfg.RendererChromiumPath, "err", err)
+		} else {
+			rs.setVersion(browser.version)
+		}
+		rs.log.Info("Backend rendering via local chromium", "path", rs.Cfg.RendererChromiumPath)
+
+		<-ctx.Done()
+		rs.chromium.close()
+		return nil
+	}
+
 	if rp, exists := rs.RendererPluginManager.Renderer(ctx); exists {
 		rs.log = rs.log.New("renderer", "plugin")
 		rs.plugin = rp
@@ -208,8 +258,14 @@ func (rs *RenderingService) remoteAvailable() bool {
 	return rs.Cfg.RendererUrl != ""
 }
 
+// chromiumAvailable returns true if a local browser renders the images, which the remote rendering service takes
+// precedence over.
+func (rs *RenderingService) chromiumAvailable() bool {
+	return !rs.remoteAvailable() && rs.chromium != nil
+}
+
 func (rs *RenderingService) IsAvailable(ctx context.Context) bool {
-	return rs.remoteAvailable() || rs.pluginAvailable
+	return rs.remoteAvailable() || rs.chromiumAvailable() || rs.pluginAvailable
 }
 
 func (rs *RenderingService) Version() string {
@@ -219,6 +275,13 @@ func (rs *RenderingService) Version() string {
 	return rs.version
 }
 
+func (rs *RenderingService) setVersion(version string) {
+	rs.versionMutex.Lock()
+	defer rs.versionMutex.Unlock()
+
+	rs.version = version
+}
+
 func (rs *RenderingService) RenderErrorImage(theme models.Theme, err error) (*RenderResult, error) {
 	if theme == "" {
 		theme = models.ThemeDark
@@ -290,10 +353,20 @@ func (rs *RenderingService) render(ctx context.Context, opts Opts, renderKeyProv
 		return rs.renderUnavailableImage(), nil
 	}
 
-	rs.log.Info("Rendering", "path", opts.Path)
 	if math.IsInf(opts.DeviceScaleFactor, 0) || math.IsNaN(opts.DeviceScaleFactor) || opts.DeviceScaleFactor == 0 {
 		opts.DeviceScaleFactor = 1
 	}
+
+	if rs.renderCache != nil {
+		return rs.renderCache.render(ctx, opts, func(ctx context.Context) (*RenderResult, error) {
+			return rs.renderWithKey(ctx, opts, renderKeyProvider)
+		})
+	}
+	return rs.renderWithKey(ctx, opts, renderKeyProvider)
+}
+
+func (rs *RenderingService) renderWithKey(ctx context.Context, opts Opts, renderKeyProvider renderKeyProvider) (*RenderResult, error) {
+	rs.log.Info("Rendering", "path", opts.Path)
 	renderKey, err := renderKeyProvider.get(ctx, opts.AuthOpts)
 	if err != nil {
 		return nil, err
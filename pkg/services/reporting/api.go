@@ -0,0 +1,340 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// runsDefaultLimit is the number of runs returned by the run history endpoint by default.
+const runsDefaultLimit = 100
+
+func (s *ReportingService) registerAPIEndpoints() {
+	s.RouteRegister.Group("/api/reports", func(reports routing.RouteRegister) {
+		reports.Get("/", routing.Wrap(s.getReportsHandler))
+		reports.Post("/", routing.Wrap(s.createHandler))
+
+		reports.Group("/:uid", func(report routing.RouteRegister) {
+			report.Get("/", routing.Wrap(s.getReportHandler))
+			report.Put("/", routing.Wrap(s.updateHandler))
+			report.Delete("/", routing.Wrap(s.deleteHandler))
+			report.Post("/send", routing.Wrap(s.sendHandler))
+			report.Get("/runs", routing.Wrap(s.getReportRunsHandler))
+		})
+	}, middleware.ReqSignedIn, middleware.ReqEditorRole)
+}
+
+// canReadDashboard checks that the user can read the dashboard of a report, reports are rendered with the
+// permissions of the user who created or last updated them.
+func (s *ReportingService) canReadDashboard(ctx context.Context, user identity.Requester, dashboardUID string) (bool, error) {
+	return s.AccessControl.Evaluate(ctx, user, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)))
+}
+
+// getReadableReport returns the report of the request if the user can read its dashboard, and the error response
+// otherwise.
+func (s *ReportingService) getReadableReport(c *contextmodel.ReqContext) (Report, response.Response) {
+	report, err := s.GetReport(c.Req.Context(), GetReportQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return Report{}, errorResponse(err, "Failed to get report")
+	}
+	if ok, err := s.canReadDashboard(c.Req.Context(), c.SignedInUser, report.DashboardUID); err != nil || !ok {
+		return Report{}, response.Error(http.StatusForbidden, "Access denied to the dashboard of the report", err)
+	}
+	return report, nil
+}
+
+func errorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, "Report not found", err)
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, "Dashboard not found", err)
+	case errors.Is(err, ErrInvalidReport):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:route GET /reports reports getReports
+//
+// Get the reports of the organization whose dashboards the user can read.
+//
+// Responses:
+// 200: getReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ReportingService) getReportsHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.GetReports(c.Req.Context(), GetReportsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: c.Query("dashboardUid"),
+	})
+	if err != nil {
+		return errorResponse(err, "Failed to get reports")
+	}
+
+	readable := make([]Report, 0, len(reports))
+	canRead := map[string]bool{}
+	for _, report := range reports {
+		ok, checked := canRead[report.DashboardUID]
+		if !checked {
+			if ok, err = s.canReadDashboard(c.Req.Context(), c.SignedInUser, report.DashboardUID); err != nil {
+				return errorResponse(err, "Failed to get reports")
+			}
+			canRead[report.DashboardUID] = ok
+		}
+		if ok {
+			readable = append(readable, report)
+		}
+	}
+
+	return response.JSON(http.StatusOK, readable)
+}
+
+// swagger:parameters getReports
+type GetReportsParams struct {
+	// Only return the reports of this dashboard.
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:response getReportsResponse
+type GetReportsResponse struct {
+	// in: body
+	Body []Report `json:"body"`
+}
+
+// swagger:route POST /reports reports createReport
+//
+// Create a report. The dashboard is rendered with the permissions of the user who creates the report.
+//
+// Responses:
+// 200: reportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+
+	if ok, err := s.canReadDashboard(c.Req.Context(), c.SignedInUser, cmd.DashboardUID); err != nil || !ok {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard of the report", err)
+	}
+
+	report, err := s.CreateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to create report")
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters createReport
+type CreateReportParams struct {
+	// in:body
+	// required:true
+	Body CreateReportCommand `json:"body"`
+}
+
+// swagger:response reportResponse
+type ReportResponse struct {
+	// in: body
+	Body Report `json:"body"`
+}
+
+// swagger:route GET /reports/{uid} reports getReport
+//
+// Get a report.
+//
+// Responses:
+// 200: reportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) getReportHandler(c *contextmodel.ReqContext) response.Response {
+	report, errResp := s.getReadableReport(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters getReport deleteReport sendReport getReportRuns
+type ReportUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:route PUT /reports/{uid} reports updateReport
+//
+// Update a report. The user must be able to read the current and the new dashboard of the report, and becomes its
+// owner.
+//
+// Responses:
+// 200: reportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+
+	if _, errResp := s.getReadableReport(c); errResp != nil {
+		return errResp
+	}
+	if ok, err := s.canReadDashboard(c.Req.Context(), c.SignedInUser, cmd.DashboardUID); err != nil || !ok {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard of the report", err)
+	}
+
+	report, err := s.UpdateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse(err, "Failed to update report")
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters updateReport
+type UpdateReportParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body UpdateReportCommand `json:"body"`
+}
+
+// swagger:route DELETE /reports/{uid} reports deleteReport
+//
+// Delete a report and its run history.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	report, errResp := s.getReadableReport(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	err := s.DeleteReport(c.Req.Context(), DeleteReportCommand{
+		UID:   report.UID,
+		OrgID: report.OrgID,
+	})
+	if err != nil {
+		return errorResponse(err, "Failed to delete report")
+	}
+
+	return response.Success("Report deleted")
+}
+
+// swagger:route POST /reports/{uid}/send reports sendReport
+//
+// Send a report now, outside of its schedule. The run is returned, with the error if sending failed.
+//
+// Responses:
+// 200: reportRunResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) sendHandler(c *contextmodel.ReqContext) response.Response {
+	report, errResp := s.getReadableReport(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	run, err := s.SendReport(c.Req.Context(), GetReportQuery{
+		UID:   report.UID,
+		OrgID: report.OrgID,
+	})
+	if err != nil {
+		return errorResponse(err, "Failed to send report")
+	}
+
+	return response.JSON(http.StatusOK, run)
+}
+
+// swagger:response reportRunResponse
+type ReportRunResponse struct {
+	// in: body
+	Body ReportRun `json:"body"`
+}
+
+// swagger:route GET /reports/{uid}/runs reports getReportRuns
+//
+// Get the run history of a report, most recent first.
+//
+// Responses:
+// 200: getReportRunsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ReportingService) getReportRunsHandler(c *contextmodel.ReqContext) response.Response {
+	report, errResp := s.getReadableReport(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	limit := c.QueryInt("limit")
+	if limit <= 0 {
+		limit = runsDefaultLimit
+	}
+	runs, err := s.GetReportRuns(c.Req.Context(), GetReportRunsQuery{
+		ReportID: report.ID,
+		OrgID:    report.OrgID,
+		Limit:    limit,
+	})
+	if err != nil {
+		return errorResponse(err, "Failed to get report runs")
+	}
+
+	return response.JSON(http.StatusOK, runs)
+}
+
+// swagger:parameters getReportRuns
+type GetReportRunsParams struct {
+	// Maximum number of runs to return, 100 by default.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:response getReportRunsResponse
+type GetReportRunsResponse struct {
+	// in: body
+	Body []ReportRun `json:"body"`
+}
@@ -0,0 +1,190 @@
+package reporting
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// createReport adds a report
+func (s *ReportingService) createReport(ctx context.Context, cmd CreateReportCommand, now time.Time) (Report, error) {
+	report := Report{
+		UID:    util.GenerateShortUID(),
+		OrgID:  cmd.OrgID,
+		UserID: cmd.UserID,
+	}
+	report.apply(cmd.ReportSpec, now)
+	report.Created = now
+
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Insert(&report)
+		return err
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+func (s *ReportingService) updateReport(ctx context.Context, cmd UpdateReportCommand, now time.Time) (Report, error) {
+	var report Report
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		found, err := session.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(&report)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrReportNotFound
+		}
+
+		report.UserID = cmd.UserID
+		report.apply(cmd.ReportSpec, now)
+		_, err = session.ID(report.ID).AllCols().Update(&report)
+		return err
+	})
+	if err != nil {
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+func (s *ReportingService) deleteReport(ctx context.Context, cmd DeleteReportCommand) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var report Report
+		found, err := session.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Get(&report)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrReportNotFound
+		}
+
+		if _, err := session.Exec("DELETE FROM report_run WHERE report_id = ?", report.ID); err != nil {
+			return err
+		}
+		_, err = session.Exec("DELETE FROM report WHERE id = ?", report.ID)
+		return err
+	})
+}
+
+func (s *ReportingService) getReport(ctx context.Context, query GetReportQuery) (Report, error) {
+	var report Report
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		found, err := session.Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(&report)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *ReportingService) getReports(ctx context.Context, query GetReportsQuery) ([]Report, error) {
+	reports := make([]Report, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		session.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			session.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		return session.OrderBy("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// getDueReports returns the enabled reports of all orgs which should have been run at the given time.
+func (s *ReportingService) getDueReports(ctx context.Context, now time.Time) ([]Report, error) {
+	reports := make([]Report, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		return session.Where("enabled = ? AND next_run_at <= ?", true, now).
+			OrderBy("next_run_at").
+			Find(&reports)
+	})
+	return reports, err
+}
+
+// setNextRun sets the time of the next run of a report, nil when it isn't scheduled anymore.
+func (s *ReportingService) setNextRun(ctx context.Context, reportID int64, next *time.Time) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.ID(reportID).Cols("next_run_at").Update(&Report{NextRunAt: next})
+		return err
+	})
+}
+
+// disableReport disables a report, which isn't sent anymore until it's enabled again.
+func (s *ReportingService) disableReport(ctx context.Context, reportID int64) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.ID(reportID).Cols("enabled").Update(&Report{Enabled: false})
+		return err
+	})
+}
+
+func (s *ReportingService) createRun(ctx context.Context, run *ReportRun) error {
+	return s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Insert(run)
+		return err
+	})
+}
+
+// finishRun records the result of a run and removes the oldest runs of the report above the history size.
+func (s *ReportingService) finishRun(ctx context.Context, run *ReportRun) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		if _, err := session.ID(run.ID).Cols("state", "error", "finished").Update(run); err != nil {
+			return err
+		}
+
+		if s.Cfg.DashboardReportsHistoryMaxCount <= 0 {
+			return nil
+		}
+		var ids []int64
+		if err := session.Table("report_run").Cols("id").Where("report_id = ?", run.ReportID).
+			OrderBy("started DESC, id DESC").Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) <= s.Cfg.DashboardReportsHistoryMaxCount {
+			return nil
+		}
+		_, err := session.In("id", ids[s.Cfg.DashboardReportsHistoryMaxCount:]).Delete(&ReportRun{})
+		return err
+	})
+}
+
+func (s *ReportingService) getReportRuns(ctx context.Context, query GetReportRunsQuery) ([]ReportRun, error) {
+	runs := make([]ReportRun, 0)
+	err := s.SQLStore.WithDbSession(ctx, func(session *db.Session) error {
+		session.Where("org_id = ? AND report_id = ?", query.OrgID, query.ReportID).OrderBy("started DESC, id DESC")
+		if query.Limit > 0 {
+			session.Limit(query.Limit)
+		}
+		return session.Find(&runs)
+	})
+	return runs, err
+}
+
+// apply sets the fields of the report from the spec and schedules the next run.
+func (r *Report) apply(spec ReportSpec, now time.Time) {
+	r.Name = spec.Name
+	r.DashboardUID = spec.DashboardUID
+	r.TimeFrom = spec.TimeFrom
+	r.TimeTo = spec.TimeTo
+	r.Variables = spec.Variables
+	r.Recipients = spec.Recipients
+	r.ReplyTo = spec.ReplyTo
+	r.Message = spec.Message
+	r.Schedule = spec.Schedule
+	r.Layout = spec.Layout
+	r.Orientation = spec.Orientation
+	r.Enabled = spec.Enabled
+	r.Updated = now
+
+	r.NextRunAt = nil
+	if next, ok := spec.Schedule.Next(now); ok {
+		r.NextRunAt = &next
+	}
+}
@@ -0,0 +1,237 @@
+package reporting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrReportNotFound        = errors.New("report not found")
+	ErrInvalidReport         = errors.New("invalid report")
+	ErrReportNameEmpty       = errors.New("report name is required")
+	ErrDashboardUIDEmpty     = errors.New("dashboard UID is required")
+	ErrRecipientsEmpty       = errors.New("at least one recipient is required")
+	ErrInvalidRecipient      = errors.New("invalid recipient email")
+	ErrInvalidTimeRange      = errors.New("invalid time range")
+	ErrInvalidFrequency      = errors.New("invalid schedule frequency")
+	ErrInvalidTimeZone       = errors.New("invalid schedule time zone")
+	ErrInvalidEndDate        = errors.New("schedule end date is before the start date")
+	ErrInvalidLayout         = errors.New("invalid report layout")
+	ErrInvalidOrientation    = errors.New("invalid report orientation")
+	ErrReportingDisabled     = errors.New("reporting is disabled")
+	ErrDashboardHasNoPanels  = errors.New("dashboard has no panels to render")
+	ErrDashboardAccessDenied = errors.New("owner of the report can't read the dashboard")
+)
+
+// Frequency is how often a report is sent.
+type Frequency string
+
+const (
+	FrequencyOnce    Frequency = "once"
+	FrequencyHourly  Frequency = "hourly"
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+// Layout is how the panels are placed on the pages of the report.
+type Layout string
+
+const (
+	// LayoutSimple puts one panel on each page.
+	LayoutSimple Layout = "simple"
+	// LayoutGrid puts two panels side by side, with as many rows as fit on a page.
+	LayoutGrid Layout = "grid"
+)
+
+// Orientation is the orientation of the pages of the report.
+type Orientation string
+
+const (
+	OrientationPortrait  Orientation = "portrait"
+	OrientationLandscape Orientation = "landscape"
+)
+
+// RunState is the state of a run of a report.
+type RunState string
+
+const (
+	RunStateRunning RunState = "running"
+	RunStateSuccess RunState = "success"
+	RunStateError   RunState = "error"
+)
+
+// Schedule defines when a report is sent.
+type Schedule struct {
+	Frequency Frequency `json:"frequency"`
+	// StartDate is the time of the first run. Later runs keep its time of day, and its weekday or day of month in
+	// the time zone of the schedule.
+	StartDate time.Time `json:"startDate"`
+	// EndDate is the time after which the report isn't sent anymore, optional.
+	EndDate *time.Time `json:"endDate,omitempty"`
+	// TimeZone is the IANA name of the time zone of the schedule, defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// Report is a dashboard which is rendered to a PDF and sent to recipients by email on a schedule.
+type Report struct {
+	ID    int64  `json:"id" xorm:"pk autoincr 'id'"`
+	UID   string `json:"uid" xorm:"uid"`
+	OrgID int64  `json:"orgId" xorm:"org_id"`
+	// UserID is the owner of the report, the dashboard is rendered with the permissions of the owner.
+	UserID       int64  `json:"userId" xorm:"user_id"`
+	Name         string `json:"name" xorm:"name"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	// TimeFrom and TimeTo are the time range of the dashboard, relative like now-7d or absolute.
+	TimeFrom string `json:"timeFrom" xorm:"time_from"`
+	TimeTo   string `json:"timeTo" xorm:"time_to"`
+	// Variables are the values of the template variables of the dashboard, by variable name.
+	Variables   map[string][]string `json:"variables" xorm:"jsonb variables"`
+	Recipients  []string            `json:"recipients" xorm:"jsonb recipients"`
+	ReplyTo     string              `json:"replyTo" xorm:"reply_to"`
+	Message     string              `json:"message" xorm:"message"`
+	Schedule    Schedule            `json:"schedule" xorm:"jsonb schedule"`
+	Layout      Layout              `json:"layout" xorm:"layout"`
+	Orientation Orientation         `json:"orientation" xorm:"orientation"`
+	Enabled     bool                `json:"enabled" xorm:"enabled"`
+	// NextRunAt is the time of the next scheduled run, nil if the report isn't scheduled anymore.
+	NextRunAt *time.Time `json:"nextRunAt" xorm:"next_run_at"`
+	Created   time.Time  `json:"created" xorm:"created"`
+	Updated   time.Time  `json:"updated" xorm:"updated"`
+}
+
+// ReportRun is a record of the run history of a report.
+type ReportRun struct {
+	ID       int64      `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID    int64      `json:"orgId" xorm:"org_id"`
+	ReportID int64      `json:"reportId" xorm:"report_id"`
+	State    RunState   `json:"state" xorm:"state"`
+	Error    string     `json:"error,omitempty" xorm:"error"`
+	Started  time.Time  `json:"started" xorm:"started"`
+	Finished *time.Time `json:"finished,omitempty" xorm:"finished"`
+}
+
+// ReportSpec is the part of a report which is set by users.
+type ReportSpec struct {
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Variables    map[string][]string `json:"variables"`
+	Recipients   []string            `json:"recipients"`
+	ReplyTo      string              `json:"replyTo"`
+	Message      string              `json:"message"`
+	Schedule     Schedule            `json:"schedule"`
+	Layout       Layout              `json:"layout"`
+	Orientation  Orientation         `json:"orientation"`
+	Enabled      bool                `json:"enabled"`
+}
+
+// CreateReportCommand is the command for creating a report.
+// swagger:model
+type CreateReportCommand struct {
+	ReportSpec
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+// UpdateReportCommand is the command for updating a report. The user who updates a report becomes its owner, so
+// the dashboard is never rendered with the permissions of a user who didn't choose it.
+// swagger:model
+type UpdateReportCommand struct {
+	ReportSpec
+	UID    string `json:"-"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+}
+
+type DeleteReportCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type GetReportQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type GetReportsQuery struct {
+	OrgID int64
+	// DashboardUID filters the reports of a dashboard, optional.
+	DashboardUID string
+}
+
+type GetReportRunsQuery struct {
+	ReportID int64
+	OrgID    int64
+	Limit    int
+}
+
+// Validate checks the spec and sets the defaults of optional fields.
+func (s *ReportSpec) Validate() error {
+	if s.Name == "" {
+		return ErrReportNameEmpty
+	}
+	if s.DashboardUID == "" {
+		return ErrDashboardUIDEmpty
+	}
+	if s.TimeFrom == "" {
+		s.TimeFrom = "now-6h"
+	}
+	if s.TimeTo == "" {
+		s.TimeTo = "now"
+	}
+	tr := legacydata.NewDataTimeRange(s.TimeFrom, s.TimeTo)
+	if _, err := tr.ParseFrom(); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeRange, s.TimeFrom)
+	}
+	if _, err := tr.ParseTo(); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeRange, s.TimeTo)
+	}
+	if len(s.Recipients) == 0 {
+		return ErrRecipientsEmpty
+	}
+	for _, recipient := range s.Recipients {
+		if !util.IsEmail(recipient) {
+			return fmt.Errorf("%w: %q", ErrInvalidRecipient, recipient)
+		}
+	}
+	if s.ReplyTo != "" && !util.IsEmail(s.ReplyTo) {
+		return fmt.Errorf("%w: %q", ErrInvalidRecipient, s.ReplyTo)
+	}
+	switch s.Layout {
+	case "":
+		s.Layout = LayoutSimple
+	case LayoutSimple, LayoutGrid:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidLayout, s.Layout)
+	}
+	switch s.Orientation {
+	case "":
+		s.Orientation = OrientationLandscape
+	case OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidOrientation, s.Orientation)
+	}
+	return s.Schedule.Validate()
+}
+
+// Validate checks the schedule.
+func (s Schedule) Validate() error {
+	switch s.Frequency {
+	case FrequencyOnce, FrequencyHourly, FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidFrequency, s.Frequency)
+	}
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeZone, s.TimeZone)
+	}
+	if s.EndDate != nil && s.EndDate.Before(s.StartDate) {
+		return ErrInvalidEndDate
+	}
+	return nil
+}
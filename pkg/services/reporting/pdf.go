@@ -0,0 +1,103 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/grafana/gofpdf"
+)
+
+const (
+	pdfMargin       = 10.0
+	pdfHeaderHeight = 16.0
+	pdfFooterHeight = 10.0
+	pdfGridColumns  = 2
+	pdfGridGap      = 5.0
+)
+
+// pdfOptions are the texts and the layout of a report PDF.
+type pdfOptions struct {
+	Title       string
+	Subtitle    string
+	Layout      Layout
+	Orientation Orientation
+}
+
+// createPDF assembles the PNG renders of the panels of a dashboard into an A4 PDF document. Every page has a header
+// with the title and the subtitle, and a footer with the page number.
+func createPDF(opts pdfOptions, images [][]byte) ([]byte, error) {
+	orientation := "L"
+	if opts.Orientation == OrientationPortrait {
+		orientation = "P"
+	}
+
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(opts.Title, true)
+	pdf.SetCreator("Grafana", false)
+	pdf.SetMargins(pdfMargin, pdfMargin+pdfHeaderHeight, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetXY(pdfMargin, pdfMargin)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 7, tr(opts.Title), "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 5, tr(opts.Subtitle), "", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfFooterHeight)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(100, 100, 100)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentTop := pdfMargin + pdfHeaderHeight
+	contentWidth := pageWidth - 2*pdfMargin
+	contentHeight := pageHeight - contentTop - pdfFooterHeight - pdfMargin
+
+	cellWidth, cellHeight := contentWidth, contentHeight
+	columns, rows := 1, 1
+	if opts.Layout == LayoutGrid {
+		columns = pdfGridColumns
+		cellWidth = (contentWidth - pdfGridGap*float64(columns-1)) / float64(columns)
+		// Cells keep the aspect ratio of the rendered panels.
+		cellHeight = cellWidth * float64(gridPanelHeight) / float64(gridPanelWidth)
+		rows = int((contentHeight + pdfGridGap) / (cellHeight + pdfGridGap))
+		if rows < 1 {
+			rows = 1
+		}
+	}
+
+	perPage := columns * rows
+	for i, image := range images {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		name := fmt.Sprintf("panel-%d", i)
+		info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(image))
+		if pdf.Err() {
+			return nil, pdf.Error()
+		}
+
+		// Scale the image to fit in its cell, centered horizontally.
+		width, height := cellWidth, cellWidth*info.Height()/info.Width()
+		if height > cellHeight {
+			width, height = cellHeight*info.Width()/info.Height(), cellHeight
+		}
+		cell := i % perPage
+		x := pdfMargin + float64(cell%columns)*(cellWidth+pdfGridGap) + (cellWidth-width)/2
+		y := contentTop + float64(cell/columns)*(cellHeight+pdfGridGap)
+		pdf.ImageOptions(name, x, y, width, height, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
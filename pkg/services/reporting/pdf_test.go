@@ -0,0 +1,92 @@
+package reporting
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+var pdfPage = regexp.MustCompile(`/Type /Page\b[^s]`)
+
+func TestCreatePDF(t *testing.T) {
+	images := [][]byte{testPNG(t, 160, 90), testPNG(t, 160, 90), testPNG(t, 90, 160)}
+
+	for _, tc := range []struct {
+		name        string
+		layout      Layout
+		orientation Orientation
+		pages       int
+	}{
+		{"simple layout has a page per panel", LayoutSimple, OrientationLandscape, 3},
+		{"simple layout in portrait", LayoutSimple, OrientationPortrait, 3},
+		{"grid layout has two rows of two panels in landscape", LayoutGrid, OrientationLandscape, 1},
+		{"grid layout in portrait", LayoutGrid, OrientationPortrait, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pdf, err := createPDF(pdfOptions{
+				Title:       "Überblick",
+				Subtitle:    "2024-01-01 00:00 to 2024-01-08 00:00 UTC",
+				Layout:      tc.layout,
+				Orientation: tc.orientation,
+			}, images)
+			require.NoError(t, err)
+			require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-")))
+			require.Len(t, pdfPage.FindAll(pdf, -1), tc.pages)
+		})
+	}
+
+	t.Run("invalid image", func(t *testing.T) {
+		_, err := createPDF(pdfOptions{Title: "Report"}, [][]byte{[]byte("not a png")})
+		require.Error(t, err)
+	})
+}
+
+func TestDashboardPanelIDs(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries"},
+			{"id": 2, "type": "row", "collapsed": false, "panels": []},
+			{"id": 3, "type": "stat"},
+			{"id": 4, "type": "row", "collapsed": true, "panels": [{"id": 5, "type": "table"}, {"id": 6, "type": "text"}]}
+		]
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 3, 5, 6}, dashboardPanelIDs(data))
+
+	require.Empty(t, dashboardPanelIDs(simplejson.New()))
+}
+
+func TestPanelPath(t *testing.T) {
+	report := Report{
+		OrgID:     2,
+		Variables: map[string][]string{"server": {"a", "b"}, "env": {"prod"}},
+	}
+	dash := &dashboards.Dashboard{UID: "abc", Slug: "my-dashboard"}
+	from := time.UnixMilli(1704067200000)
+	to := time.UnixMilli(1704672000000)
+
+	require.Equal(t,
+		"d-solo/abc/my-dashboard?from=1704067200000&orgId=2&panelId=4&to=1704672000000&var-env=prod&var-server=a&var-server=b",
+		panelPath(report, dash, 4, from, to),
+	)
+}
@@ -0,0 +1,134 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// Sizes of the panel renders in pixels, chosen to fill an A4 page or half of its width.
+const (
+	landscapePanelWidth  = 1600
+	landscapePanelHeight = 900
+	portraitPanelWidth   = 1200
+	portraitPanelHeight  = 900
+	gridPanelWidth       = 800
+	gridPanelHeight      = 450
+)
+
+// dashboardPanelIDs returns the IDs of the panels of a dashboard in the order of the dashboard. Panels in collapsed
+// rows are included, rows themselves are not rendered.
+func dashboardPanelIDs(data *simplejson.Json) []int64 {
+	ids := make([]int64, 0)
+	for _, p := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		if panel.Get("type").MustString() == "row" {
+			for _, nested := range panel.Get("panels").MustArray() {
+				if id := simplejson.NewFromAny(nested).Get("id").MustInt64(); id != 0 {
+					ids = append(ids, id)
+				}
+			}
+			continue
+		}
+		if id := panel.Get("id").MustInt64(); id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// dashboardQuery returns the query parameters of the dashboard of a report for the given time range.
+func dashboardQuery(report Report, from, to time.Time) url.Values {
+	values := url.Values{}
+	values.Set("orgId", strconv.FormatInt(report.OrgID, 10))
+	values.Set("from", strconv.FormatInt(from.UnixMilli(), 10))
+	values.Set("to", strconv.FormatInt(to.UnixMilli(), 10))
+
+	names := make([]string, 0, len(report.Variables))
+	for name := range report.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range report.Variables[name] {
+			values.Add("var-"+name, value)
+		}
+	}
+	return values
+}
+
+// panelPath returns the path of the solo view of a panel for the renderer.
+func panelPath(report Report, dash *dashboards.Dashboard, panelID int64, from, to time.Time) string {
+	values := dashboardQuery(report, from, to)
+	values.Set("panelId", strconv.FormatInt(panelID, 10))
+	return fmt.Sprintf("d-solo/%s/%s?%s", dash.UID, dash.Slug, values.Encode())
+}
+
+func panelSize(report Report) (int, int) {
+	switch {
+	case report.Layout == LayoutGrid:
+		return gridPanelWidth, gridPanelHeight
+	case report.Orientation == OrientationPortrait:
+		return portraitPanelWidth, portraitPanelHeight
+	default:
+		return landscapePanelWidth, landscapePanelHeight
+	}
+}
+
+// renderPanels renders the panels of the dashboard of a report to PNG images, as the owner of the report.
+func (s *ReportingService) renderPanels(ctx context.Context, report Report, owner *user.SignedInUser, dash *dashboards.Dashboard, from, to time.Time) ([][]byte, error) {
+	panelIDs := dashboardPanelIDs(dash.Data)
+	if len(panelIDs) == 0 {
+		return nil, ErrDashboardHasNoPanels
+	}
+
+	width, height := panelSize(report)
+	images := make([][]byte, 0, len(panelIDs))
+	for _, panelID := range panelIDs {
+		result, err := s.RenderingService.Render(ctx, rendering.Opts{
+			TimeoutOpts: rendering.TimeoutOpts{
+				Timeout: s.Cfg.DashboardReportsRenderTimeout,
+			},
+			AuthOpts: rendering.AuthOpts{
+				OrgID:   report.OrgID,
+				UserID:  owner.UserID,
+				OrgRole: owner.OrgRole,
+			},
+			ErrorOpts: rendering.ErrorOpts{
+				ErrorConcurrentLimitReached: true,
+				ErrorRenderUnavailable:      true,
+			},
+			Width:           width,
+			Height:          height,
+			Path:            panelPath(report, dash, panelID, from, to),
+			Timezone:        report.Schedule.TimeZone,
+			ConcurrentLimit: s.Cfg.RendererConcurrentRequestLimit,
+			Theme:           models.ThemeLight,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render panel %d: %w", panelID, err)
+		}
+
+		// nolint:gosec
+		// We can ignore the gosec G304 warning on this one because the path comes from the rendering service
+		image, err := os.ReadFile(result.FilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read render of panel %d: %w", panelID, err)
+		}
+		if err := os.Remove(result.FilePath); err != nil {
+			s.log.Warn("Failed to remove panel render", "path", result.FilePath, "error", err)
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
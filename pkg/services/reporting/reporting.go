@@ -0,0 +1,285 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	// schedulerInterval is how often the scheduler looks for reports to send.
+	schedulerInterval = time.Minute
+	schedulerLockName = "send scheduled reports"
+
+	emailTemplate = "report"
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	dashboardService dashboards.DashboardService, userService user.Service, renderingService rendering.Service,
+	notificationService notifications.Service, serverLock *serverlock.ServerLockService,
+) *ReportingService {
+	s := &ReportingService{
+		Cfg:                 cfg,
+		SQLStore:            sqlStore,
+		RouteRegister:       routeRegister,
+		AccessControl:       ac,
+		DashboardService:    dashboardService,
+		UserService:         userService,
+		RenderingService:    renderingService,
+		NotificationService: notificationService,
+		serverLock:          serverLock,
+		log:                 log.New("reporting"),
+		now:                 time.Now,
+	}
+
+	if cfg.DashboardReportsEnabled {
+		s.registerAPIEndpoints()
+	}
+
+	return s
+}
+
+// ReportingService renders dashboards to PDF documents and sends them by email on a schedule. In HA setups, the
+// scheduler runs on a single instance at a time.
+type ReportingService struct {
+	Cfg                 *setting.Cfg
+	SQLStore            db.DB
+	RouteRegister       routing.RouteRegister
+	AccessControl       accesscontrol.AccessControl
+	DashboardService    dashboards.DashboardService
+	UserService         user.Service
+	RenderingService    rendering.Service
+	NotificationService notifications.Service
+
+	serverLock *serverlock.ServerLockService
+	log        log.Logger
+	now        func() time.Time
+}
+
+// IsDisabled returns true if reporting is disabled in the configuration.
+func (s *ReportingService) IsDisabled() bool {
+	return !s.Cfg.DashboardReportsEnabled
+}
+
+// Run sends the reports which are due every minute.
+func (s *ReportingService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// The lock is taken at most once per interval, so only one instance sends the reports of each tick.
+			err := s.serverLock.LockAndExecute(ctx, schedulerLockName, schedulerInterval/2, func(ctx context.Context) {
+				if err := s.sendDueReports(ctx); err != nil {
+					s.log.Error("Failed to send scheduled reports", "error", err)
+				}
+			})
+			if err != nil {
+				s.log.Error("Failed to lock and execute sending scheduled reports", "error", err)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sendDueReports sends the reports whose next run is in the past. Runs which were missed while Grafana was down are
+// sent once.
+func (s *ReportingService) sendDueReports(ctx context.Context) error {
+	now := s.now()
+	reports, err := s.getDueReports(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		// Schedule the next run first, so a report isn't sent twice if sending takes longer than the interval.
+		var nextRunAt *time.Time
+		if next, ok := report.Schedule.Next(now); ok {
+			nextRunAt = &next
+		}
+		if err := s.setNextRun(ctx, report.ID, nextRunAt); err != nil {
+			return err
+		}
+
+		if _, err := s.runReport(ctx, report); err != nil {
+			s.log.Error("Failed to record report run", "reportUID", report.UID, "orgID", report.OrgID, "error", err)
+		}
+	}
+	return nil
+}
+
+// runReport sends a report and records the run in its history. The returned error is about recording the run, the
+// error of sending the report is in the run.
+func (s *ReportingService) runReport(ctx context.Context, report Report) (ReportRun, error) {
+	run := ReportRun{
+		OrgID:    report.OrgID,
+		ReportID: report.ID,
+		State:    RunStateRunning,
+		Started:  s.now(),
+	}
+	if err := s.createRun(ctx, &run); err != nil {
+		return run, err
+	}
+
+	run.State = RunStateSuccess
+	if err := s.sendReport(ctx, report); err != nil {
+		s.log.Warn("Failed to send report", "reportUID", report.UID, "orgID", report.OrgID, "error", err)
+		run.State = RunStateError
+		run.Error = err.Error()
+		// The report isn't sent anymore until a user who can read the dashboard enables it again.
+		if errors.Is(err, ErrDashboardAccessDenied) {
+			if err := s.disableReport(ctx, report.ID); err != nil {
+				s.log.Error("Failed to disable report", "reportUID", report.UID, "orgID", report.OrgID, "error", err)
+			}
+		}
+	}
+	finished := s.now()
+	run.Finished = &finished
+
+	return run, s.finishRun(ctx, &run)
+}
+
+// sendReport renders the dashboard of a report as its owner and sends the PDF to the recipients. The owner must
+// still be able to read the dashboard.
+func (s *ReportingService) sendReport(ctx context.Context, report Report) error {
+	owner, err := s.UserService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.UserID, OrgID: report.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get the owner of the report: %w", err)
+	}
+	ok, err := s.canReadDashboard(ctx, owner, report.DashboardUID)
+	if err != nil {
+		return fmt.Errorf("failed to check the dashboard permissions of the owner of the report: %w", err)
+	}
+	if !ok {
+		return ErrDashboardAccessDenied
+	}
+
+	dash, err := s.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: report.DashboardUID, OrgID: report.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	loc, err := report.Schedule.location()
+	if err != nil {
+		return err
+	}
+	tr := legacydata.NewDataTimeRange(report.TimeFrom, report.TimeTo)
+	tr.Now = s.now()
+	from, err := tr.ParseFrom(legacydata.WithLocation(loc))
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeRange, report.TimeFrom)
+	}
+	to, err := tr.ParseTo(legacydata.WithLocation(loc))
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimeRange, report.TimeTo)
+	}
+	timeRange := fmt.Sprintf("%s to %s %s", from.In(loc).Format("2006-01-02 15:04"), to.In(loc).Format("2006-01-02 15:04"), loc)
+
+	images, err := s.renderPanels(ctx, report, owner, dash, from, to)
+	if err != nil {
+		return err
+	}
+
+	pdf, err := createPDF(pdfOptions{
+		Title:       dash.Title,
+		Subtitle:    timeRange,
+		Layout:      report.Layout,
+		Orientation: report.Orientation,
+	}, images)
+	if err != nil {
+		return fmt.Errorf("failed to create PDF: %w", err)
+	}
+
+	var replyTo []string
+	if report.ReplyTo != "" {
+		replyTo = []string{report.ReplyTo}
+	}
+	err = s.NotificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       report.Recipients,
+			Template: emailTemplate,
+			ReplyTo:  replyTo,
+			Data: map[string]any{
+				"ReportName":     report.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardURL":   dashboards.GetFullDashboardURL(dash.UID, dash.Slug) + "?" + dashboardQuery(report, from, to).Encode(),
+				"TimeRange":      timeRange,
+				"Message":        report.Message,
+			},
+			AttachedFiles: []*notifications.SendEmailAttachFile{
+				{
+					Name:    fmt.Sprintf("%s-%s.pdf", dash.Slug, to.In(loc).Format("2006-01-02")),
+					Content: pdf,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// CreateReport adds a report owned by the user of the command.
+func (s *ReportingService) CreateReport(ctx context.Context, cmd CreateReportCommand) (Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return Report{}, fmt.Errorf("%w: %w", ErrInvalidReport, err)
+	}
+	if _, err := s.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: cmd.OrgID}); err != nil {
+		return Report{}, err
+	}
+	return s.createReport(ctx, cmd, s.now())
+}
+
+// UpdateReport replaces the spec of a report and schedules its next run again.
+func (s *ReportingService) UpdateReport(ctx context.Context, cmd UpdateReportCommand) (Report, error) {
+	if err := cmd.Validate(); err != nil {
+		return Report{}, fmt.Errorf("%w: %w", ErrInvalidReport, err)
+	}
+	if _, err := s.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: cmd.OrgID}); err != nil {
+		return Report{}, err
+	}
+	return s.updateReport(ctx, cmd, s.now())
+}
+
+// DeleteReport deletes a report and its run history.
+func (s *ReportingService) DeleteReport(ctx context.Context, cmd DeleteReportCommand) error {
+	return s.deleteReport(ctx, cmd)
+}
+
+func (s *ReportingService) GetReport(ctx context.Context, query GetReportQuery) (Report, error) {
+	return s.getReport(ctx, query)
+}
+
+func (s *ReportingService) GetReports(ctx context.Context, query GetReportsQuery) ([]Report, error) {
+	return s.getReports(ctx, query)
+}
+
+// GetReportRuns returns the run history of a report, most recent first.
+func (s *ReportingService) GetReportRuns(ctx context.Context, query GetReportRunsQuery) ([]ReportRun, error) {
+	return s.getReportRuns(ctx, query)
+}
+
+// SendReport sends a report now, outside of its schedule, and returns the run.
+func (s *ReportingService) SendReport(ctx context.Context, query GetReportQuery) (ReportRun, error) {
+	report, err := s.getReport(ctx, query)
+	if err != nil {
+		return ReportRun{}, err
+	}
+	return s.runReport(ctx, report)
+}
@@ -0,0 +1,325 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type testEnv struct {
+	service   *ReportingService
+	renderer  *rendering.MockService
+	mailer    *notifications.NotificationServiceMock
+	now       time.Time
+	renderErr error
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.DashboardReportsEnabled = true
+	cfg.DashboardReportsRenderTimeout = time.Minute
+	cfg.DashboardReportsHistoryMaxCount = 2
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(func(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+		if query.UID != "dash" && query.UID != "other" {
+			return nil, dashboards.ErrDashboardNotFound
+		}
+		return &dashboards.Dashboard{
+			UID:   query.UID,
+			Slug:  "my-dashboard",
+			Title: "My dashboard",
+			Data:  simplejson.NewFromAny(map[string]any{"panels": []any{map[string]any{"id": 1}, map[string]any{"id": 2}}}),
+		}, nil
+	}).Maybe()
+
+	env := &testEnv{
+		renderer: rendering.NewMockService(gomock.NewController(t)),
+		mailer:   &notifications.NotificationServiceMock{},
+		now:      time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+	}
+	dir := t.TempDir()
+	env.renderer.EXPECT().Render(gomock.Any(), gomock.Any(), nil).DoAndReturn(func(_ context.Context, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+		if env.renderErr != nil {
+			return nil, env.renderErr
+		}
+		path := filepath.Join(dir, "panel.png")
+		require.NoError(t, os.WriteFile(path, testPNG(t, opts.Width, opts.Height), 0600))
+		return &rendering.RenderResult{FilePath: path}, nil
+	}).AnyTimes()
+
+	env.service = ProvideService(cfg, sqlStore, routing.NewRouteRegister(), actest.FakeAccessControl{ExpectedEvaluate: true},
+		dashboardService, &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleEditor}},
+		env.renderer, env.mailer, serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()))
+	env.service.now = func() time.Time { return env.now }
+	return env
+}
+
+func testSpec() ReportSpec {
+	return ReportSpec{
+		Name:         "Weekly report",
+		DashboardUID: "dash",
+		TimeFrom:     "now-7d",
+		TimeTo:       "now",
+		Variables:    map[string][]string{"env": {"prod"}},
+		Recipients:   []string{"team@example.com", "boss@example.com"},
+		Message:      "Have a good week",
+		Schedule: Schedule{
+			Frequency: FrequencyWeekly,
+			StartDate: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		},
+		Enabled: true,
+	}
+}
+
+func TestIntegrationReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	env := setupTestEnv(t)
+	s := env.service
+	ctx := context.Background()
+
+	report, err := s.CreateReport(ctx, CreateReportCommand{ReportSpec: testSpec(), OrgID: 1, UserID: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, report.UID)
+	require.Equal(t, LayoutSimple, report.Layout)
+	require.NotNil(t, report.NextRunAt)
+	require.True(t, time.Date(2024, 1, 22, 8, 0, 0, 0, time.UTC).Equal(*report.NextRunAt))
+
+	t.Run("invalid reports are rejected", func(t *testing.T) {
+		spec := testSpec()
+		spec.Recipients = nil
+		_, err := s.CreateReport(ctx, CreateReportCommand{ReportSpec: spec, OrgID: 1, UserID: 1})
+		require.ErrorIs(t, err, ErrInvalidReport)
+		require.ErrorIs(t, err, ErrRecipientsEmpty)
+
+		spec = testSpec()
+		spec.DashboardUID = "missing"
+		_, err = s.CreateReport(ctx, CreateReportCommand{ReportSpec: spec, OrgID: 1, UserID: 1})
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+	})
+
+	t.Run("get reports", func(t *testing.T) {
+		stored, err := s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, report.Recipients, stored.Recipients)
+		require.Equal(t, report.Variables, stored.Variables)
+		require.Equal(t, report.Schedule.Frequency, stored.Schedule.Frequency)
+
+		_, err = s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 2})
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		reports, err := s.GetReports(ctx, GetReportsQuery{OrgID: 1, DashboardUID: "dash"})
+		require.NoError(t, err)
+		require.Len(t, reports, 1)
+		reports, err = s.GetReports(ctx, GetReportsQuery{OrgID: 1, DashboardUID: "other"})
+		require.NoError(t, err)
+		require.Empty(t, reports)
+	})
+
+	t.Run("due reports are sent once", func(t *testing.T) {
+		env.now = time.Date(2024, 1, 22, 8, 0, 30, 0, time.UTC)
+		require.NoError(t, s.sendDueReports(ctx))
+
+		email := env.mailer.EmailSync
+		require.Equal(t, emailTemplate, email.Template)
+		require.Equal(t, []string{"team@example.com", "boss@example.com"}, email.To)
+		require.Equal(t, "Weekly report", email.Data["ReportName"])
+		require.Equal(t, "2024-01-15 08:00 to 2024-01-22 08:00 UTC", email.Data["TimeRange"])
+		require.Contains(t, email.Data["DashboardURL"], "d/dash/my-dashboard?from=1705305630000&orgId=1&to=1705910430000&var-env=prod")
+		require.Len(t, email.AttachedFiles, 1)
+		require.Equal(t, "my-dashboard-2024-01-22.pdf", email.AttachedFiles[0].Name)
+		require.True(t, bytes.HasPrefix(email.AttachedFiles[0].Content, []byte("%PDF-")))
+
+		stored, err := s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.True(t, time.Date(2024, 1, 29, 8, 0, 0, 0, time.UTC).Equal(*stored.NextRunAt))
+
+		env.mailer.EmailSync = notifications.SendEmailCommandSync{}
+		require.NoError(t, s.sendDueReports(ctx))
+		require.Empty(t, env.mailer.EmailSync.To)
+
+		runs, err := s.GetReportRuns(ctx, GetReportRunsQuery{ReportID: report.ID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, RunStateSuccess, runs[0].State)
+		require.NotNil(t, runs[0].Finished)
+	})
+
+	t.Run("errors are recorded in the run history", func(t *testing.T) {
+		env.renderErr = rendering.ErrRenderUnavailable
+		defer func() { env.renderErr = nil }()
+
+		run, err := s.SendReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, RunStateError, run.State)
+		require.Contains(t, run.Error, "failed to render panel 1")
+
+		env.renderErr = nil
+		env.mailer.ShouldError = errors.New("smtp not configured")
+		defer func() { env.mailer.ShouldError = nil }()
+		run, err = s.SendReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, RunStateError, run.State)
+		require.Equal(t, "failed to send email: smtp not configured", run.Error)
+
+		// The history keeps the two most recent runs.
+		runs, err := s.GetReportRuns(ctx, GetReportRunsQuery{ReportID: report.ID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.Equal(t, run.ID, runs[0].ID)
+	})
+
+	t.Run("reports whose owner can't read the dashboard are disabled", func(t *testing.T) {
+		s.AccessControl = actest.FakeAccessControl{ExpectedEvaluate: false}
+		defer func() { s.AccessControl = actest.FakeAccessControl{ExpectedEvaluate: true} }()
+
+		env.mailer.EmailSync = notifications.SendEmailCommandSync{}
+		run, err := s.SendReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, RunStateError, run.State)
+		require.Equal(t, ErrDashboardAccessDenied.Error(), run.Error)
+		require.Empty(t, env.mailer.EmailSync.To)
+
+		stored, err := s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.False(t, stored.Enabled)
+	})
+
+	t.Run("update report", func(t *testing.T) {
+		spec := testSpec()
+		spec.Name = "Daily report"
+		spec.Layout = LayoutGrid
+		spec.Enabled = false
+		spec.Schedule.Frequency = FrequencyDaily
+		updated, err := s.UpdateReport(ctx, UpdateReportCommand{ReportSpec: spec, UID: report.UID, OrgID: 1, UserID: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), updated.UserID)
+		require.Equal(t, report.Created.Unix(), updated.Created.Unix())
+
+		stored, err := s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, "Daily report", stored.Name)
+		require.Equal(t, LayoutGrid, stored.Layout)
+		require.False(t, stored.Enabled)
+
+		// Disabled reports aren't sent.
+		env.now = env.now.Add(48 * time.Hour)
+		env.mailer.EmailSync = notifications.SendEmailCommandSync{}
+		require.NoError(t, s.sendDueReports(ctx))
+		require.Empty(t, env.mailer.EmailSync.To)
+
+		_, err = s.UpdateReport(ctx, UpdateReportCommand{ReportSpec: spec, UID: "missing", OrgID: 1, UserID: 2})
+		require.ErrorIs(t, err, ErrReportNotFound)
+	})
+
+	t.Run("delete report", func(t *testing.T) {
+		require.NoError(t, s.DeleteReport(ctx, DeleteReportCommand{UID: report.UID, OrgID: 1}))
+		_, err := s.GetReport(ctx, GetReportQuery{UID: report.UID, OrgID: 1})
+		require.ErrorIs(t, err, ErrReportNotFound)
+		runs, err := s.GetReportRuns(ctx, GetReportRunsQuery{ReportID: report.ID, OrgID: 1})
+		require.NoError(t, err)
+		require.Empty(t, runs)
+
+		require.ErrorIs(t, s.DeleteReport(ctx, DeleteReportCommand{UID: report.UID, OrgID: 1}), ErrReportNotFound)
+	})
+}
+
+func TestIntegrationReportsAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	env := setupTestEnv(t)
+	s := env.service
+	ctx := context.Background()
+
+	hidden, err := s.CreateReport(ctx, CreateReportCommand{ReportSpec: testSpec(), OrgID: 1, UserID: 1})
+	require.NoError(t, err)
+	spec := testSpec()
+	spec.DashboardUID = "other"
+	visible, err := s.CreateReport(ctx, CreateReportCommand{ReportSpec: spec, OrgID: 1, UserID: 1})
+	require.NoError(t, err)
+
+	// The editor can only read the dashboard of the visible report.
+	s.AccessControl = acimpl.ProvideAccessControl(setting.NewCfg())
+	editor := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor, Permissions: map[int64]map[string][]string{
+		1: {dashboards.ActionDashboardsRead: {dashboards.ScopeDashboardsProvider.GetResourceScopeUID("other")}},
+	}}
+	server := webtest.NewServer(t, s.RouteRegister)
+	send := func(method, target string, body any) *http.Response {
+		t.Helper()
+		var reqBody bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+		}
+		req := webtest.RequestWithSignedInUser(server.NewRequest(method, target, &reqBody), editor)
+		resp, err := server.SendJSON(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { require.NoError(t, resp.Body.Close()) })
+		return resp
+	}
+
+	t.Run("only reports of readable dashboards are listed", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/reports", nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var reports []Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&reports))
+		require.Len(t, reports, 1)
+		require.Equal(t, visible.UID, reports[0].UID)
+	})
+
+	t.Run("reports of dashboards that the user can't read are denied", func(t *testing.T) {
+		env.mailer.EmailSync = notifications.SendEmailCommandSync{}
+		require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/reports/"+hidden.UID, nil).StatusCode)
+		require.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/reports/"+hidden.UID+"/runs", nil).StatusCode)
+		require.Equal(t, http.StatusForbidden, send(http.MethodPost, "/api/reports/"+hidden.UID+"/send", nil).StatusCode)
+		require.Equal(t, http.StatusForbidden, send(http.MethodPut, "/api/reports/"+hidden.UID, spec).StatusCode)
+		require.Equal(t, http.StatusForbidden, send(http.MethodDelete, "/api/reports/"+hidden.UID, nil).StatusCode)
+
+		stored, err := s.GetReport(ctx, GetReportQuery{UID: hidden.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), stored.UserID)
+		require.Equal(t, "dash", stored.DashboardUID)
+		require.Empty(t, env.mailer.EmailSync.To)
+	})
+
+	t.Run("reports of readable dashboards are returned", func(t *testing.T) {
+		resp := send(http.MethodGet, "/api/reports/"+visible.UID, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var report Report
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		require.Equal(t, visible.UID, report.UID)
+
+		require.Equal(t, http.StatusOK, send(http.MethodGet, "/api/reports/"+visible.UID+"/runs", nil).StatusCode)
+	})
+}
@@ -0,0 +1,73 @@
+package reporting
+
+import (
+	"time"
+)
+
+func (s Schedule) location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
+
+// maxInterval is an upper bound of the time between two runs, used to skip the runs in the past without iterating
+// over all of them.
+func (s Schedule) maxInterval() time.Duration {
+	switch s.Frequency {
+	case FrequencyHourly:
+		return time.Hour
+	case FrequencyDaily:
+		// Days are 25 hours long when daylight saving time ends.
+		return 25 * time.Hour
+	case FrequencyWeekly:
+		return 7*24*time.Hour + time.Hour
+	default:
+		return 31*24*time.Hour + time.Hour
+	}
+}
+
+// occurrence returns the time of the n-th run of the schedule, the first run being 0.
+func (s Schedule) occurrence(start time.Time, n int) time.Time {
+	switch s.Frequency {
+	case FrequencyHourly:
+		return start.Add(time.Duration(n) * time.Hour)
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	default:
+		// Runs on the 31st are moved to the last day of shorter months, rather than overflowing to the next month.
+		first := time.Date(start.Year(), start.Month()+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		day := start.Day()
+		if last := first.AddDate(0, 1, -1).Day(); day > last {
+			day = last
+		}
+		return first.AddDate(0, 0, day-1)
+	}
+}
+
+// Next returns the time of the first run of the schedule after the given time, and false if there is no run left.
+func (s Schedule) Next(after time.Time) (time.Time, bool) {
+	loc, err := s.location()
+	if err != nil {
+		return time.Time{}, false
+	}
+	start := s.StartDate.In(loc)
+
+	next := start
+	if !start.After(after) {
+		if s.Frequency == FrequencyOnce {
+			return time.Time{}, false
+		}
+		n := int(after.Sub(start) / s.maxInterval())
+		for next = s.occurrence(start, n); !next.After(after); next = s.occurrence(start, n) {
+			n++
+		}
+	}
+
+	if s.EndDate != nil && next.After(*s.EndDate) {
+		return time.Time{}, false
+	}
+	return next, true
+}
@@ -0,0 +1,141 @@
+package reporting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		name     string
+		schedule Schedule
+		after    time.Time
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "once before the start date",
+			schedule: Schedule{Frequency: FrequencyOnce, StartDate: start},
+			after:    start.Add(-time.Hour),
+			expected: start,
+			ok:       true,
+		},
+		{
+			name:     "once after the start date",
+			schedule: Schedule{Frequency: FrequencyOnce, StartDate: start},
+			after:    start,
+		},
+		{
+			name:     "hourly",
+			schedule: Schedule{Frequency: FrequencyHourly, StartDate: start},
+			after:    start.Add(90 * time.Minute),
+			expected: start.Add(2 * time.Hour),
+			ok:       true,
+		},
+		{
+			name:     "daily keeps the time of day",
+			schedule: Schedule{Frequency: FrequencyDaily, StartDate: start},
+			after:    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "weekly keeps the weekday",
+			schedule: Schedule{Frequency: FrequencyWeekly, StartDate: start},
+			after:    start,
+			expected: time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "monthly",
+			schedule: Schedule{Frequency: FrequencyMonthly, StartDate: start},
+			after:    time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 7, 15, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "monthly on the 31st runs on the last day of shorter months",
+			schedule: Schedule{Frequency: FrequencyMonthly, StartDate: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)},
+			after:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name: "daily keeps the time of day in the time zone across daylight saving time",
+			schedule: Schedule{
+				Frequency: FrequencyDaily,
+				StartDate: time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC), // 09:00 in Paris
+				TimeZone:  "Europe/Paris",
+			},
+			after:    time.Date(2024, 3, 30, 9, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 3, 31, 7, 0, 0, 0, time.UTC), // 09:00 in Paris, summer time
+			ok:       true,
+		},
+		{
+			name: "after the end date",
+			schedule: Schedule{
+				Frequency: FrequencyDaily,
+				StartDate: start,
+				EndDate:   func() *time.Time { t := start.Add(36 * time.Hour); return &t }(),
+			},
+			after: start.Add(25 * time.Hour),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := tc.schedule.Next(tc.after)
+			require.Equal(t, tc.ok, ok)
+			if tc.ok {
+				require.True(t, tc.expected.Equal(next), "expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+func TestReportSpec_Validate(t *testing.T) {
+	valid := func() ReportSpec {
+		return ReportSpec{
+			Name:         "Weekly report",
+			DashboardUID: "dash",
+			Recipients:   []string{"team@example.com"},
+			Schedule:     Schedule{Frequency: FrequencyWeekly, StartDate: time.Now()},
+		}
+	}
+
+	spec := valid()
+	require.NoError(t, spec.Validate())
+	require.Equal(t, "now-6h", spec.TimeFrom)
+	require.Equal(t, "now", spec.TimeTo)
+	require.Equal(t, LayoutSimple, spec.Layout)
+	require.Equal(t, OrientationLandscape, spec.Orientation)
+
+	for _, tc := range []struct {
+		name   string
+		modify func(*ReportSpec)
+		err    error
+	}{
+		{"no name", func(s *ReportSpec) { s.Name = "" }, ErrReportNameEmpty},
+		{"no dashboard", func(s *ReportSpec) { s.DashboardUID = "" }, ErrDashboardUIDEmpty},
+		{"invalid time range", func(s *ReportSpec) { s.TimeFrom = "yesterday" }, ErrInvalidTimeRange},
+		{"no recipients", func(s *ReportSpec) { s.Recipients = nil }, ErrRecipientsEmpty},
+		{"invalid recipient", func(s *ReportSpec) { s.Recipients = []string{"team"} }, ErrInvalidRecipient},
+		{"invalid reply to", func(s *ReportSpec) { s.ReplyTo = "team" }, ErrInvalidRecipient},
+		{"invalid layout", func(s *ReportSpec) { s.Layout = "table" }, ErrInvalidLayout},
+		{"invalid orientation", func(s *ReportSpec) { s.Orientation = "square" }, ErrInvalidOrientation},
+		{"invalid frequency", func(s *ReportSpec) { s.Schedule.Frequency = "yearly" }, ErrInvalidFrequency},
+		{"invalid time zone", func(s *ReportSpec) { s.Schedule.TimeZone = "Mars/Olympus" }, ErrInvalidTimeZone},
+		{"end before start", func(s *ReportSpec) {
+			end := s.Schedule.StartDate.Add(-time.Hour)
+			s.Schedule.EndDate = &end
+		}, ErrInvalidEndDate},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := valid()
+			tc.modify(&spec)
+			require.ErrorIs(t, spec.Validate(), tc.err)
+		})
+	}
+}
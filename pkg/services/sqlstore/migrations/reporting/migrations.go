@@ -0,0 +1,59 @@
+package reporting
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func AddMigration(mg *migrator.Migrator) {
+	reportV1 := migrator.Table{
+		Name: "report",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "time_from", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "time_to", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "variables", Type: migrator.DB_Text, Nullable: true},
+			{Name: "recipients", Type: migrator.DB_Text, Nullable: false},
+			{Name: "reply_to", Type: migrator.DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "schedule", Type: migrator.DB_Text, Nullable: false},
+			{Name: "layout", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "orientation", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "enabled", Type: migrator.DB_Bool, Nullable: false},
+			{Name: "next_run_at", Type: migrator.DB_DateTime, Nullable: true},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"enabled", "next_run_at"}},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create report table", migrator.NewAddTableMigration(reportV1))
+	mg.AddMigration("add unique index report.org_id_uid", migrator.NewAddIndexMigration(reportV1, reportV1.Indices[0]))
+	mg.AddMigration("add index report.enabled_next_run_at", migrator.NewAddIndexMigration(reportV1, reportV1.Indices[1]))
+	mg.AddMigration("add index report.org_id_dashboard_uid", migrator.NewAddIndexMigration(reportV1, reportV1.Indices[2]))
+
+	reportRunV1 := migrator.Table{
+		Name: "report_run",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "report_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "state", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "started", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "finished", Type: migrator.DB_DateTime, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"report_id", "started"}},
+		},
+	}
+
+	mg.AddMigration("create report_run table", migrator.NewAddTableMigration(reportRunV1))
+	mg.AddMigration("add index report_run.report_id_started", migrator.NewAddIndexMigration(reportRunV1, reportRunV1.Indices[0]))
+}
@@ -207,6 +207,13 @@ type Cfg struct {
 	RendererAuthToken              string
 	RendererConcurrentRequestLimit int
 	RendererRenderKeyLifeTime      time.Duration
+	RendererChromiumPath           string
+	RendererChromiumArgs           []string
+	RendererChromiumMaxContexts    int
+	RendererCacheEnabled           bool
+	RendererCacheTTL               time.Duration
+	RendererCacheMaxSize           int64
+	RendererCacheStorage           string
 
 	// Security
 	DisableInitAdminCreation          bool
@@ -402,6 +409,8 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	SqlDatasourceLocalFilesPath         string
+	SqlDatasourceResultBytesLimit       int64
 
 	// Snapshots
 	SnapshotEnabled       bool
@@ -480,6 +489,33 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveTransports are the transports clients can use to connect to Live, in the
+	// order clients try them: "websocket", "http_stream" and "sse".
+	LiveTransports []string
+	// LiveHistoryMaxFrames is a maximum number of recent frames kept per managed
+	// stream channel and sent to new subscribers. 1 keeps only the latest frame.
+	LiveHistoryMaxFrames int
+	// LiveHistoryMaxAge is a maximum age of the recent frames kept per managed
+	// stream channel. The latest frame is always kept. Zero means no limit.
+	LiveHistoryMaxAge time.Duration
+	// LivePublishRatePerOrg, LivePublishRatePerChannel and LivePublishRatePerToken are
+	// maximum rates of messages per second published to Live per organization, per channel
+	// and per user, API key or service account. Zero means no limit.
+	LivePublishRatePerOrg     float64
+	LivePublishRatePerChannel float64
+	LivePublishRatePerToken   float64
+	// LiveSubscribeRatePerToken is a maximum rate of subscriptions per second per user,
+	// API key or service account. Zero means no limit.
+	LiveSubscribeRatePerToken float64
+	// LiveRateLimitBurst is a number of messages or subscriptions accepted at once above
+	// the rate limits. Zero uses the rate rounded up.
+	LiveRateLimitBurst int
+	// LiveMaxMessageSize is a maximum size in bytes of messages published to Live. Zero
+	// means no limit.
+	LiveMaxMessageSize int
+	// LiveOTLPMaxBodySize is a maximum size in bytes of the decompressed bodies of requests
+	// to the OTLP push endpoints.
+	LiveOTLPMaxBodySize int64
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -502,6 +538,9 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	// Query caching
+	QueryCaching QueryCachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -544,6 +583,11 @@ type Cfg struct {
 	// Public dashboards
 	PublicDashboardsEnabled bool
 
+	// Dashboard reports
+	DashboardReportsEnabled         bool
+	DashboardReportsRenderTimeout   time.Duration
+	DashboardReportsHistoryMaxCount int
+
 	// Feature Management Settings
 	FeatureManagement FeatureMgmtSettings
 }
@@ -1194,6 +1238,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.QueryCaching = readQueryCachingSettings(iniFile)
 
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
@@ -1265,6 +1310,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.readFeatureManagementConfig()
 	cfg.readPublicDashboardsSettings()
+	cfg.readReportingSettings()
 
 	return nil
 }
@@ -1720,6 +1766,16 @@ func (cfg *Cfg) readRenderingSettings(iniFile *ini.File) error {
 
 	cfg.RendererConcurrentRequestLimit = renderSec.Key("concurrent_render_request_limit").MustInt(30)
 	cfg.RendererRenderKeyLifeTime = renderSec.Key("render_key_lifetime").MustDuration(5 * time.Minute)
+	cfg.RendererChromiumPath = valueAsString(renderSec, "chromium_path", "")
+	cfg.RendererChromiumArgs = strings.Fields(valueAsString(renderSec, "chromium_args", ""))
+	cfg.RendererChromiumMaxContexts = renderSec.Key("chromium_max_browser_contexts").MustInt(5)
+	cfg.RendererCacheEnabled = renderSec.Key("render_cache_enabled").MustBool(false)
+	cfg.RendererCacheTTL = renderSec.Key("render_cache_ttl").MustDuration(time.Minute)
+	cfg.RendererCacheMaxSize = renderSec.Key("render_cache_max_size_mb").MustInt64(100) * 1024 * 1024
+	cfg.RendererCacheStorage = valueAsString(renderSec, "render_cache_storage", "memory")
+	if cfg.RendererCacheStorage != "memory" && cfg.RendererCacheStorage != "disk" {
+		return fmt.Errorf("invalid render_cache_storage %q, must be memory or disk", cfg.RendererCacheStorage)
+	}
 	cfg.ImagesDir = filepath.Join(cfg.DataPath, "png")
 	cfg.CSVsDir = filepath.Join(cfg.DataPath, "csv")
 
@@ -1930,6 +1986,8 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SqlDatasourceLocalFilesPath = sqlDatasources.Key("local_files_path").String()
+	cfg.SqlDatasourceResultBytesLimit = sqlDatasources.Key("result_bytes_limit").MustInt64(0)
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1974,6 +2032,55 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 		return err
 	}
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LiveTransports = nil
+	for _, transport := range util.SplitString(section.Key("transports").MustString("websocket,http_stream,sse")) {
+		switch transport {
+		case "websocket", "http_stream", "sse":
+		default:
+			return fmt.Errorf("unsupported live transport: %s", transport)
+		}
+		cfg.LiveTransports = append(cfg.LiveTransports, transport)
+	}
+	if len(cfg.LiveTransports) == 0 {
+		return fmt.Errorf("[live] transports must not be empty")
+	}
+
+	cfg.LiveHistoryMaxFrames = section.Key("history_max_frames").MustInt(1)
+	if cfg.LiveHistoryMaxFrames < 1 {
+		return fmt.Errorf("unexpected value %d for [live] history_max_frames", cfg.LiveHistoryMaxFrames)
+	}
+	cfg.LiveHistoryMaxAge, err = gtime.ParseDuration(section.Key("history_max_age").MustString("0"))
+	if err != nil {
+		return fmt.Errorf("unexpected value for [live] history_max_age: %w", err)
+	}
+
+	for _, rateSetting := range []struct {
+		key   string
+		value *float64
+	}{
+		{"publish_rate_per_org", &cfg.LivePublishRatePerOrg},
+		{"publish_rate_per_channel", &cfg.LivePublishRatePerChannel},
+		{"publish_rate_per_token", &cfg.LivePublishRatePerToken},
+		{"subscribe_rate_per_token", &cfg.LiveSubscribeRatePerToken},
+	} {
+		*rateSetting.value = section.Key(rateSetting.key).MustFloat64(0)
+		if *rateSetting.value < 0 {
+			return fmt.Errorf("unexpected value %v for [live] %s", *rateSetting.value, rateSetting.key)
+		}
+	}
+	cfg.LiveRateLimitBurst = section.Key("rate_limit_burst").MustInt(0)
+	if cfg.LiveRateLimitBurst < 0 {
+		return fmt.Errorf("unexpected value %d for [live] rate_limit_burst", cfg.LiveRateLimitBurst)
+	}
+	cfg.LiveMaxMessageSize = section.Key("max_message_size").MustInt(0)
+	if cfg.LiveMaxMessageSize < 0 {
+		return fmt.Errorf("unexpected value %d for [live] max_message_size", cfg.LiveMaxMessageSize)
+	}
+	cfg.LiveOTLPMaxBodySize = section.Key("otlp_max_body_size").MustInt64(10 * 1024 * 1024)
+	if cfg.LiveOTLPMaxBodySize <= 0 {
+		return fmt.Errorf("unexpected value %d for [live] otlp_max_body_size", cfg.LiveOTLPMaxBodySize)
+	}
 	return nil
 }
 
@@ -1981,3 +2088,10 @@ func (cfg *Cfg) readPublicDashboardsSettings() {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
 }
+
+func (cfg *Cfg) readReportingSettings() {
+	reporting := cfg.Raw.Section("reporting")
+	cfg.DashboardReportsEnabled = reporting.Key("enabled").MustBool(true)
+	cfg.DashboardReportsRenderTimeout = reporting.Key("render_timeout").MustDuration(time.Minute)
+	cfg.DashboardReportsHistoryMaxCount = reporting.Key("history_max_count").MustInt(100)
+}
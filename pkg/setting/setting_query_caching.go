@@ -0,0 +1,27 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	// Enabled enables the caching of query results of data sources that do not disable it.
+	Enabled bool
+	// TTL is the default time that query results are cached. The time range of a query is aligned to the TTL,
+	// so queries of relative time ranges, e.g. the last hour, share results during the TTL.
+	TTL time.Duration
+	// MaxValueSize is the maximum size in bytes of a cached query result. Larger results are not cached.
+	MaxValueSize int64
+}
+
+func readQueryCachingSettings(iniFile *ini.File) QueryCachingSettings {
+	s := QueryCachingSettings{}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(time.Minute)
+	s.MaxValueSize = section.Key("max_value_size").MustInt64(10 * 1024 * 1024)
+	return s
+}
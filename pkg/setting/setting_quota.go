@@ -1,11 +1,12 @@
 package setting
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User        int64 `target:"org_user"`
+	DataSource  int64 `target:"data_source"`
+	Dashboard   int64 `target:"dashboard"`
+	ApiKey      int64 `target:"api_key"`
+	AlertRule   int64 `target:"alert_rule"`
+	LiveChannel int64 `target:"live_channel"`
 }
 
 type UserQuota struct {
@@ -22,6 +23,7 @@ type GlobalQuota struct {
 	AlertRule    int64 `target:"alert_rule"`
 	File         int64 `target:"file"`
 	Correlations int64 `target:"correlations"`
+	LiveChannel  int64 `target:"live_channel"`
 }
 
 type QuotaSettings struct {
@@ -38,11 +40,12 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	// per ORG Limits
 	cfg.Quota.Org = OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  quota.Key("org_alert_rule").MustInt64(100),
+		User:        quota.Key("org_user").MustInt64(10),
+		DataSource:  quota.Key("org_data_source").MustInt64(10),
+		Dashboard:   quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:      quota.Key("org_api_key").MustInt64(10),
+		AlertRule:   quota.Key("org_alert_rule").MustInt64(100),
+		LiveChannel: quota.Key("org_live_channel").MustInt64(-1),
 	}
 
 	// per User limits
@@ -61,5 +64,6 @@ func (cfg *Cfg) readQuotaSettings() {
 		File:         quota.Key("global_file").MustInt64(-1),
 		AlertRule:    quota.Key("global_alert_rule").MustInt64(-1),
 		Correlations: quota.Key("global_correlations").MustInt64(-1),
+		LiveChannel:  quota.Key("global_live_channel").MustInt64(-1),
 	}
 }
@@ -50,6 +50,7 @@ const (
 	schedulerDefaultMaxAttempts             = 1
 	schedulerDefaultLegacyMinInterval       = 1
 	screenshotsDefaultCapture               = false
+	screenshotsDefaultCaptureGraphs         = false
 	screenshotsDefaultCaptureTimeout        = 10 * time.Second
 	screenshotsMaxCaptureTimeout            = 30 * time.Second
 	screenshotsDefaultMaxConcurrent         = 5
@@ -61,6 +62,13 @@ const (
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
 	DefaultRuleEvaluationInterval = SchedulerBaseInterval * 6 // == 60 seconds
 	stateHistoryDefaultEnabled    = true
+
+	// EvaluationJitterDisabled evaluates all rules with the same interval at the same time.
+	EvaluationJitterDisabled = "disabled"
+	// EvaluationJitterByGroup spreads the evaluations of rule groups across their evaluation interval.
+	EvaluationJitterByGroup = "group"
+	// EvaluationJitterByRule spreads the evaluations of individual rules across their evaluation interval.
+	EvaluationJitterByRule = "rule"
 )
 
 type UnifiedAlertingSettings struct {
@@ -83,10 +91,13 @@ type UnifiedAlertingSettings struct {
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
-	ExecuteAlerts                  bool
-	DefaultConfiguration           string
-	Enabled                        *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
-	DisabledOrgs                   map[int64]struct{}
+	// EvaluationJitter determines how the scheduler spreads rule evaluations across their interval.
+	// It is one of EvaluationJitterDisabled, EvaluationJitterByGroup and EvaluationJitterByRule.
+	EvaluationJitter     string
+	ExecuteAlerts        bool
+	DefaultConfiguration string
+	Enabled              *bool // determines whether unified alerting is enabled. If it is nil then user did not define it and therefore its value will be determined during migration. Services should not use it directly.
+	DisabledOrgs         map[int64]struct{}
 	// BaseInterval interval of time the scheduler updates the rules and evaluates rules.
 	// Only for internal use and not user configuration.
 	BaseInterval time.Duration
@@ -113,6 +124,7 @@ type RemoteAlertmanagerSettings struct {
 
 type UnifiedAlertingScreenshotSettings struct {
 	Capture                    bool
+	CaptureGraphs              bool
 	CaptureTimeout             time.Duration
 	MaxConcurrentScreenshots   int64
 	UploadExternalImageStorage bool
@@ -346,6 +358,13 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfg.DefaultRuleEvaluationInterval = uaMinInterval
 	}
 
+	uaCfg.EvaluationJitter = strings.ToLower(strings.TrimSpace(valueAsString(ua, "evaluation_jitter", EvaluationJitterDisabled)))
+	switch uaCfg.EvaluationJitter {
+	case EvaluationJitterDisabled, EvaluationJitterByGroup, EvaluationJitterByRule:
+	default:
+		return fmt.Errorf("value of setting 'evaluation_jitter' should be one of '%s', '%s' or '%s', got '%s'", EvaluationJitterDisabled, EvaluationJitterByGroup, EvaluationJitterByRule, uaCfg.EvaluationJitter)
+	}
+
 	remoteAlertmanager := iniFile.Section("remote.alertmanager")
 	uaCfgRemoteAM := RemoteAlertmanagerSettings{
 		Enable:   remoteAlertmanager.Key("enabled").MustBool(false),
@@ -364,6 +383,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfgScreenshots := uaCfg.Screenshots
 
 	uaCfgScreenshots.Capture = screenshots.Key("capture").MustBool(screenshotsDefaultCapture)
+	uaCfgScreenshots.CaptureGraphs = screenshots.Key("capture_graphs").MustBool(screenshotsDefaultCaptureGraphs)
 
 	captureTimeout := screenshots.Key("capture_timeout").MustDuration(screenshotsDefaultCaptureTimeout)
 	if captureTimeout > screenshotsMaxCaptureTimeout {
@@ -298,3 +298,38 @@ func TestMinInterval(t *testing.T) {
 		})
 	}
 }
+
+func TestEvaluationJitter(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected string
+		expErr   bool
+	}{
+		{value: "", expected: EvaluationJitterDisabled},
+		{value: "disabled", expected: EvaluationJitterDisabled},
+		{value: "group", expected: EvaluationJitterByGroup},
+		{value: " Rule ", expected: EvaluationJitterByRule},
+		{value: "random", expErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.value, func(t *testing.T) {
+			f := ini.Empty()
+			if testCase.value != "" {
+				section, err := f.NewSection("unified_alerting")
+				require.NoError(t, err)
+				_, err = section.NewKey("evaluation_jitter", testCase.value)
+				require.NoError(t, err)
+			}
+			cfg := NewCfg()
+			cfg.IsFeatureToggleEnabled = func(key string) bool { return false }
+			err := cfg.ReadUnifiedAlertingSettings(f)
+			if testCase.expErr {
+				require.ErrorContains(t, err, "evaluation_jitter")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, cfg.UnifiedAlerting.EvaluationJitter)
+		})
+	}
+}
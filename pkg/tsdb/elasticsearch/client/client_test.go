@@ -3,6 +3,7 @@ package es
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -231,3 +232,38 @@ func createMultisearchForTest(t *testing.T, c Client) (*MultiSearchRequest, erro
 	})
 	return msb.Build()
 }
+
+func TestClient_ExecuteESQL(t *testing.T) {
+	var request *http.Request
+	var requestBody []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		request = r
+		var err error
+		requestBody, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		rw.Header().Set("Content-Type", "application/json")
+		_, err = rw.Write([]byte(`{"columns": [{"name": "count", "type": "long"}], "values": [[9007199254740993]]}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+
+	ds := DatasourceInfo{
+		URL:        ts.URL,
+		HTTPClient: ts.Client(),
+		Database:   "metrics",
+	}
+	c, err := NewClient(context.Background(), &ds, backend.TimeRange{}, log.New("test", "test"), tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+
+	res, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM metrics | STATS count = COUNT(*)", Columnar: true})
+	require.NoError(t, err)
+	require.NotNil(t, request)
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "/_query", request.URL.Path)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"query": "FROM metrics | STATS count = COUNT(*)", "columnar": true}`, string(requestBody))
+
+	assert.Equal(t, 200, res.Status)
+	require.Len(t, res.Columns, 1)
+	assert.Equal(t, "9007199254740993", res.Values[0][0].(json.Number).String())
+}
@@ -61,6 +61,29 @@ type MultiSearchResponse struct {
 	Responses []*SearchResponse `json:"responses"`
 }
 
+// ESQLRequest represents an ES|QL query request
+type ESQLRequest struct {
+	Query string `json:"query"`
+	// Filter is a Query DSL filter that is applied to the documents before the query runs
+	Filter   map[string]any `json:"filter,omitempty"`
+	Columnar bool           `json:"columnar"`
+}
+
+// ESQLColumn represents a column of an ES|QL query response
+type ESQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ESQLResponse represents the response of an ES|QL query. The values contain one array per column if the query
+// was columnar, otherwise one array per row.
+type ESQLResponse struct {
+	Status  int            `json:"-"`
+	Columns []ESQLColumn   `json:"columns"`
+	Values  [][]any        `json:"values"`
+	Error   map[string]any `json:"error"`
+}
+
 // Query represents a query
 type Query struct {
 	Bool *BoolQuery `json:"bool"`
@@ -242,6 +265,22 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation. After is the after key of the previous page of buckets.
+type CompositeAggregation struct {
+	Size    int              `json:"size"`
+	Sources []map[string]any `json:"sources"`
+	After   map[string]any   `json:"after,omitempty"`
+}
+
+// AddTermsSource adds a terms source for the field to the composite aggregation
+func (a *CompositeAggregation) AddTermsSource(name, field string, missingBucket bool) {
+	terms := map[string]any{"field": field}
+	if missingBucket {
+		terms["missing_bucket"] = true
+	}
+	a.Sources = append(a.Sources, map[string]any{name: map[string]any{"terms": terms}})
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
@@ -0,0 +1,242 @@
+package elasticsearch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	defaultCompositeSize = 1000
+	// compositeMaxPages is the maximum number of pages of buckets that are requested for a composite aggregation
+	compositeMaxPages = 100
+)
+
+// compositeSources returns the fields of the sources of the composite aggregation
+func compositeSources(bucketAgg *BucketAgg) []string {
+	var fields []string
+	for _, f := range bucketAgg.Settings.Get("fields").MustArray() {
+		if field, ok := f.(string); ok && field != "" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 && bucketAgg.Field != "" {
+		fields = append(fields, bucketAgg.Field)
+	}
+	return fields
+}
+
+func compositeSize(bucketAgg *BucketAgg) int {
+	if size, err := bucketAgg.Settings.Get("size").Int(); err == nil && size > 0 {
+		return size
+	}
+	return stringToIntWithDefaultValue(bucketAgg.Settings.Get("size").MustString(), defaultCompositeSize)
+}
+
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, after map[string]any) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = compositeSize(bucketAgg)
+		missingBucket := bucketAgg.Settings.Get("missing_bucket").MustBool(false)
+		for _, field := range compositeSources(bucketAgg) {
+			a.AddTermsSource(field, field, missingBucket)
+		}
+		a.After = after
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// isCompositeQuery returns whether the query aggregates with a composite aggregation, which must be the first
+// bucket aggregation, as Elasticsearch does not support composite aggregations as sub-aggregations.
+func isCompositeQuery(q *Query) bool {
+	return len(q.BucketAggs) > 0 && q.BucketAggs[0].Type == compositeType
+}
+
+func validateCompositeQuery(q *Query) error {
+	for i, bucketAgg := range q.BucketAggs {
+		if bucketAgg.Type != compositeType {
+			continue
+		}
+		if i > 0 {
+			return errors.New("composite aggregation must be the first bucket aggregation")
+		}
+		if len(compositeSources(bucketAgg)) == 0 {
+			return errors.New("composite aggregation requires at least one field")
+		}
+	}
+	return nil
+}
+
+// nextCompositePage returns the after key of the next page of buckets of the composite aggregation of the query,
+// or nil if all buckets were returned.
+func nextCompositePage(q *Query, res *es.SearchResponse) map[string]any {
+	if !isCompositeQuery(q) || res == nil || res.Error != nil {
+		return nil
+	}
+	agg := simplejson.NewFromAny(res.Aggregations).Get(q.BucketAggs[0].ID)
+	afterKey, err := agg.Get("after_key").Map()
+	if err != nil || len(afterKey) == 0 {
+		return nil
+	}
+	// Only the first page is checked here, as the after key of the last page is removed by appendCompositePage
+	if q.compositeAfter == nil && len(agg.Get("buckets").MustArray()) < compositeSize(q.BucketAggs[0]) {
+		return nil
+	}
+	return afterKey
+}
+
+// appendCompositePage appends the buckets of the next page of the composite aggregation to the response.
+func appendCompositePage(q *Query, res *es.SearchResponse, page *es.SearchResponse) {
+	if page.Error != nil {
+		res.Error = page.Error
+		return
+	}
+	id := q.BucketAggs[0].ID
+	agg := simplejson.NewFromAny(res.Aggregations).Get(id)
+	pageAgg := simplejson.NewFromAny(page.Aggregations).Get(id)
+	buckets := pageAgg.Get("buckets").MustArray()
+	agg.Set("buckets", append(agg.Get("buckets").MustArray(), buckets...))
+	// The page is the last one if it is not full
+	if afterKey, err := pageAgg.Get("after_key").Map(); err == nil && len(buckets) >= compositeSize(q.BucketAggs[0]) {
+		agg.Set("after_key", afterKey)
+	} else {
+		agg.Del("after_key")
+	}
+}
+
+// fetchCompositePages requests the remaining pages of buckets of composite aggregations using their after keys,
+// so that the responses contain all buckets instead of only the first page.
+func (e *elasticsearchDataQuery) fetchCompositePages(queries []*Query, responses []*es.SearchResponse, from, to int64) error {
+	for page := 1; page < compositeMaxPages; page++ {
+		var pending []int
+		for i, q := range queries {
+			if i >= len(responses) {
+				break
+			}
+			if after := nextCompositePage(q, responses[i]); after != nil {
+				q.compositeAfter = after
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ms := e.client.MultiSearch()
+		for _, i := range pending {
+			if err := e.processQuery(queries[i], ms, from, to); err != nil {
+				return err
+			}
+		}
+		req, err := ms.Build()
+		if err != nil {
+			return err
+		}
+		res, err := e.client.ExecuteMultisearch(req)
+		if err != nil {
+			return err
+		}
+		if len(res.Responses) != len(pending) {
+			return fmt.Errorf("expected %d responses for the next page of composite aggregations, got %d", len(pending), len(res.Responses))
+		}
+		for j, i := range pending {
+			appendCompositePage(queries[i], responses[i], res.Responses[j])
+		}
+	}
+	e.logger.Warn("Stopped requesting buckets of composite aggregation after reaching the maximum number of pages", "maxPages", compositeMaxPages)
+	return nil
+}
+
+// processCompositeAggregationDocs creates a table with a column for each source of the composite aggregation and
+// for each metric.
+func processCompositeAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Query, queryResult *backend.DataResponse) error {
+	sources := compositeSources(aggDef)
+	buckets := esAgg.Get("buckets").MustArray()
+
+	// A source is numeric if all of its non-null values are numbers
+	numeric := make([]bool, len(sources))
+	for i, source := range sources {
+		numeric[i] = true
+		for _, b := range buckets {
+			v := simplejson.NewFromAny(b).GetPath("key", source).Interface()
+			if _, ok := v.(string); ok {
+				numeric[i] = false
+				break
+			}
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(sources))
+	for i, source := range sources {
+		var field *data.Field
+		if numeric[i] {
+			field = extractDataField(source, new(float64))
+		} else {
+			field = extractDataField(source, new(string))
+		}
+		fields = append(fields, field)
+	}
+
+	for _, b := range buckets {
+		bucket := simplejson.NewFromAny(b)
+		var values []interface{}
+		for i, source := range sources {
+			key := bucket.GetPath("key", source)
+			if key.Interface() == nil {
+				if numeric[i] {
+					fields[i].Append((*float64)(nil))
+				} else {
+					fields[i].Append((*string)(nil))
+				}
+				continue
+			}
+			if numeric[i] {
+				f, err := key.Float64()
+				if err != nil {
+					return fmt.Errorf("error appending bucket key to field with name %s: %w", source, err)
+				}
+				fields[i].Append(&f)
+			} else {
+				s := compositeKeyString(key.Interface())
+				fields[i].Append(&s)
+			}
+		}
+
+		for _, metric := range target.Metrics {
+			switch metric.Type {
+			case countType:
+				addMetricValueToFields(&fields, values, getMetricName(metric.Type), castToFloat(bucket.Get("doc_count")))
+			case extendedStatsType:
+				addExtendedStatsToFields(&fields, bucket, metric, values)
+			case percentilesType:
+				addPercentilesToFields(&fields, bucket, metric, values)
+			case topMetricsType:
+				addTopMetricsToFields(&fields, bucket, metric, values)
+			default:
+				addOtherMetricsToFields(&fields, bucket, metric, values, target)
+			}
+		}
+	}
+
+	queryResult.Frames = data.Frames{data.NewFrame("", fields...)}
+	return nil
+}
+
+// compositeKeyString formats a value of a composite aggregation key
+func compositeKeyString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
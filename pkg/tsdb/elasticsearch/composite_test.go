@@ -0,0 +1,123 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func multiSearchResponse(t *testing.T, body string) *es.MultiSearchResponse {
+	t.Helper()
+	var res es.MultiSearchResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &res))
+	return &res
+}
+
+func TestCompositeAggregation(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+	query := `{
+		"bucketAggs": [{ "type": "composite", "id": "2", "settings": { "fields": ["host", "port"], "size": 2, "missing_bucket": true } }],
+		"metrics": [{ "type": "count", "id": "1" }, { "type": "avg", "field": "value", "id": "3" }]
+	}`
+
+	t.Run("builds a composite aggregation with a terms source per field", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, query, from, to)
+		require.NoError(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+		require.Equal(t, "2", sr.Aggs[0].Key)
+		require.Equal(t, "composite", sr.Aggs[0].Aggregation.Type)
+		agg := sr.Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation)
+		assert.Equal(t, 2, agg.Size)
+		assert.Nil(t, agg.After)
+		assert.Equal(t, []map[string]any{
+			{"host": map[string]any{"terms": map[string]any{"field": "host", "missing_bucket": true}}},
+			{"port": map[string]any{"terms": map[string]any{"field": "port", "missing_bucket": true}}},
+		}, agg.Sources)
+		require.Len(t, sr.Aggs[0].Aggregation.Aggs, 1)
+		assert.Equal(t, "3", sr.Aggs[0].Aggregation.Aggs[0].Key)
+	})
+
+	t.Run("requests all pages of buckets and frames them as table", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponse = multiSearchResponse(t, `{"responses": [{"aggregations": {"2": {
+			"after_key": {"host": "b", "port": 80},
+			"buckets": [
+				{"key": {"host": "a", "port": 80}, "doc_count": 1, "3": {"value": 10}},
+				{"key": {"host": "b", "port": 80}, "doc_count": 2, "3": {"value": 20}}
+			]
+		}}}]}`)
+		c.nextMultiSearchResponses = []*es.MultiSearchResponse{multiSearchResponse(t, `{"responses": [{"aggregations": {"2": {
+			"after_key": {"host": null, "port": 443},
+			"buckets": [
+				{"key": {"host": null, "port": 443}, "doc_count": 3, "3": {"value": 30}}
+			]
+		}}}]}`)}
+
+		res, err := executeElasticsearchDataQuery(c, query, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 2)
+		after := c.multisearchRequests[1].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation).After
+		assert.Equal(t, map[string]any{"host": "b", "port": float64(80)}, after)
+
+		dr := res.Responses["A"]
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		frame := dr.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "b", *frame.Fields[0].At(1).(*string))
+		assert.Nil(t, frame.Fields[0].At(2))
+		assert.Equal(t, "port", frame.Fields[1].Name)
+		assert.Equal(t, 443.0, *frame.Fields[1].At(2).(*float64))
+		assert.Equal(t, "Count", frame.Fields[2].Name)
+		assert.Equal(t, 3.0, *frame.Fields[2].At(2).(*float64))
+		assert.Equal(t, "Average", frame.Fields[3].Name)
+		assert.Equal(t, 30.0, *frame.Fields[3].At(2).(*float64))
+	})
+
+	t.Run("uses the keys as labels of time series of a date histogram", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponse = multiSearchResponse(t, `{"responses": [{"aggregations": {"2": {
+			"after_key": {"host": "a"},
+			"buckets": [
+				{"key": {"host": "a"}, "doc_count": 1, "4": {"buckets": [{"key": 1000, "doc_count": 1}]}}
+			]
+		}}}]}`)
+		res, err := executeElasticsearchDataQuery(c, `{
+			"bucketAggs": [
+				{ "type": "composite", "id": "2", "field": "host" },
+				{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+			],
+			"metrics": [{ "type": "count", "id": "1" }]
+		}`, from, to)
+		require.NoError(t, err)
+		// The page is not full, so there are no more buckets.
+		require.Len(t, c.multisearchRequests, 1)
+		dr := res.Responses["A"]
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		assert.Equal(t, "a", dr.Frames[0].Fields[1].Labels["host"])
+	})
+
+	t.Run("must be the first bucket aggregation", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{
+			"bucketAggs": [
+				{ "type": "terms", "field": "dc", "id": "1" },
+				{ "type": "composite", "field": "host", "id": "2" }
+			],
+			"metrics": [{ "type": "count", "id": "3" }]
+		}`, from, to)
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+		assert.Empty(t, c.multisearchRequests)
+	})
+}
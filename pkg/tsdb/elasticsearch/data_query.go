@@ -51,10 +51,28 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddPluginErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	ms := e.client.MultiSearch()
-
 	from := e.dataQueries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
 	to := e.dataQueries[0].TimeRange.To.UnixNano() / int64(time.Millisecond)
+
+	// ES|QL queries are not part of the multisearch request
+	searchQueries := make([]*Query, 0, len(queries))
+	for _, q := range queries {
+		if isESQLQuery(q) {
+			if err := isQueryWithError(q); err != nil {
+				return errorsource.AddPluginErrorToResponse(q.RefID, response, fmt.Errorf("received invalid query. %w", err)), nil
+			}
+			response.Responses[q.RefID] = e.executeESQLQuery(q, from, to)
+			continue
+		}
+		searchQueries = append(searchQueries, q)
+	}
+	if len(searchQueries) == 0 {
+		return response, nil
+	}
+	queries = searchQueries
+
+	ms := e.client.MultiSearch()
+
 	for _, q := range queries {
 		if err := e.processQuery(q, ms, from, to); err != nil {
 			mq, _ := json.Marshal(q)
@@ -77,7 +95,18 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	if err := e.fetchCompositePages(queries, res.Responses, from, to); err != nil {
+		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+	}
+
+	searchResponse, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	if err != nil {
+		return searchResponse, err
+	}
+	for refID, r := range response.Responses {
+		searchResponse.Responses[refID] = r
+	}
+	return searchResponse, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -319,6 +348,15 @@ func getPipelineAggField(m *MetricAgg) string {
 }
 
 func isQueryWithError(query *Query) error {
+	if isESQLQuery(query) {
+		if query.RawQuery == "" {
+			return fmt.Errorf("invalid query, missing ES|QL query")
+		}
+		return nil
+	}
+	if err := validateCompositeQuery(query); err != nil {
+		return err
+	}
 	if len(query.BucketAggs) == 0 {
 		// If no aggregations, only document and logs queries are valid
 		if len(query.Metrics) == 0 || !(isLogsQuery(query) || isDocumentQuery(query)) {
@@ -418,6 +456,8 @@ func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64
 			aggBuilder = addGeoHashGridAgg(aggBuilder, bucketAgg)
 		case nestedType:
 			aggBuilder = addNestedAgg(aggBuilder, bucketAgg)
+		case compositeType:
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg, q.compositeAfter)
 		}
 	}
 
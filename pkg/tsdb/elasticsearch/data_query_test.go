@@ -1806,6 +1806,10 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	// nextMultiSearchResponses are returned by the following multisearch requests
+	nextMultiSearchResponses []*es.MultiSearchResponse
+	esqlRequests             []*es.ESQLRequest
+	esqlResponse             *es.ESQLResponse
 }
 
 func newFakeClient() *fakeClient {
@@ -1828,9 +1832,19 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multisearchRequests) > 1 && len(c.nextMultiSearchResponses) > 0 {
+		res := c.nextMultiSearchResponses[0]
+		c.nextMultiSearchResponses = c.nextMultiSearchResponses[1:]
+		return res, nil
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ESQLResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -0,0 +1,164 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const esqlQueryType = "esql"
+
+func isESQLQuery(query *Query) bool {
+	return query.QueryType == esqlQueryType
+}
+
+// executeESQLQuery runs the ES|QL query on the documents in the time range and frames the columnar response
+func (e *elasticsearchDataQuery) executeESQLQuery(q *Query, from, to int64) backend.DataResponse {
+	timeField := e.client.GetConfiguredFields().TimeField
+	req := &es.ESQLRequest{
+		Query:    q.RawQuery,
+		Columnar: true,
+		Filter: map[string]any{
+			"range": map[string]any{
+				timeField: map[string]any{
+					"gte":    from,
+					"lte":    to,
+					"format": es.DateFormatEpochMS,
+				},
+			},
+		},
+	}
+
+	res, err := e.client.ExecuteESQL(req)
+	if err != nil {
+		return errorsource.Response(err)
+	}
+	if res.Error != nil {
+		reason := getErrorFromElasticResponse(&es.SearchResponse{Error: res.Error})
+		return errorsource.Response(errorsource.DownstreamError(errors.New(reason), false))
+	}
+	if res.Status >= 400 {
+		return errorsource.Response(errorsource.DownstreamError(fmt.Errorf("unexpected status code %d", res.Status), false))
+	}
+
+	return backend.DataResponse{Frames: data.Frames{esqlFrame(q, res)}}
+}
+
+// esqlFrame creates a frame with a field for each column of the columnar ES|QL response
+func esqlFrame(q *Query, res *es.ESQLResponse) *data.Frame {
+	frame := data.NewFrame(q.RefID)
+	frame.RefID = q.RefID
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: q.RawQuery}
+	for i, column := range res.Columns {
+		var values []any
+		if i < len(res.Values) {
+			values = res.Values[i]
+		}
+		field, err := esqlField(column, values)
+		if err != nil {
+			// Multi-valued columns contain arrays instead of values of the type of the column
+			field = esqlStringField(column.Name, values)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame
+}
+
+func esqlField(column es.ESQLColumn, values []any) (*data.Field, error) {
+	switch column.Type {
+	case "date", "date_nanos":
+		field := data.NewField(column.Name, nil, make([]*time.Time, len(values)))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected date value %v", v)
+			}
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			field.Set(i, &t)
+		}
+		return field, nil
+	case "long", "integer", "short", "byte", "counter_long", "counter_integer":
+		field := data.NewField(column.Name, nil, make([]*int64, len(values)))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("unexpected %s value %v", column.Type, v)
+			}
+			value, err := n.Int64()
+			if err != nil {
+				return nil, err
+			}
+			field.Set(i, &value)
+		}
+		return field, nil
+	case "double", "float", "half_float", "scaled_float", "unsigned_long", "counter_double":
+		field := data.NewField(column.Name, nil, make([]*float64, len(values)))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("unexpected %s value %v", column.Type, v)
+			}
+			value, err := n.Float64()
+			if err != nil {
+				return nil, err
+			}
+			field.Set(i, &value)
+		}
+		return field, nil
+	case "boolean":
+		field := data.NewField(column.Name, nil, make([]*bool, len(values)))
+		for i, v := range values {
+			if v == nil {
+				continue
+			}
+			b, ok := v.(bool)
+			if !ok {
+				return nil, fmt.Errorf("unexpected boolean value %v", v)
+			}
+			field.Set(i, &b)
+		}
+		return field, nil
+	default:
+		// Keywords, text, IPs, versions and geo points are returned as strings
+		return esqlStringField(column.Name, values), nil
+	}
+}
+
+// esqlStringField creates a string field with the values, which are JSON encoded if they are not strings
+func esqlStringField(name string, values []any) *data.Field {
+	field := data.NewField(name, nil, make([]*string, len(values)))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			b, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			s = string(b)
+		}
+		field.Set(i, &s)
+	}
+	return field
+}
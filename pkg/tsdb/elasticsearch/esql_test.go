@@ -0,0 +1,84 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestESQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("sends the query with a time range filter and frames the columnar response", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{Status: 200}
+		dec := json.NewDecoder(strings.NewReader(`{
+			"columns": [
+				{"name": "@timestamp", "type": "date"},
+				{"name": "host", "type": "keyword"},
+				{"name": "count", "type": "long"},
+				{"name": "avg", "type": "double"},
+				{"name": "ok", "type": "boolean"},
+				{"name": "tags", "type": "keyword"}
+			],
+			"values": [
+				["2018-05-15T17:50:00.000Z", null],
+				["a", "b"],
+				[9007199254740993, 2],
+				[1.5, null],
+				[true, false],
+				[["x", "y"], "z"]
+			]
+		}`))
+		dec.UseNumber()
+		require.NoError(t, dec.Decode(c.esqlResponse))
+
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs | STATS count = COUNT(*) BY host"}`, from, to)
+		require.NoError(t, err)
+		assert.Empty(t, c.multisearchRequests)
+		require.Len(t, c.esqlRequests, 1)
+		req := c.esqlRequests[0]
+		assert.Equal(t, "FROM logs | STATS count = COUNT(*) BY host", req.Query)
+		assert.True(t, req.Columnar)
+		assert.Equal(t, map[string]any{"range": map[string]any{"@timestamp": map[string]any{
+			"gte": from.UnixMilli(), "lte": to.UnixMilli(), "format": es.DateFormatEpochMS,
+		}}}, req.Filter)
+
+		dr := res.Responses["A"]
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		frame := dr.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, from, *frame.Fields[0].At(0).(*time.Time))
+		assert.Nil(t, frame.Fields[0].At(1))
+		assert.Equal(t, "a", *frame.Fields[1].At(0).(*string))
+		assert.Equal(t, int64(9007199254740993), *frame.Fields[2].At(0).(*int64))
+		assert.Equal(t, 1.5, *frame.Fields[3].At(0).(*float64))
+		assert.Equal(t, false, *frame.Fields[4].At(1).(*bool))
+		assert.Equal(t, `["x","y"]`, *frame.Fields[5].At(0).(*string))
+		assert.Equal(t, "FROM logs | STATS count = COUNT(*) BY host", frame.Meta.ExecutedQueryString)
+	})
+
+	t.Run("returns errors of Elasticsearch", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{Status: 400, Error: map[string]any{"reason": "Unknown index [logs]"}}
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql", "query": "FROM logs"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "Unknown index [logs]")
+	})
+
+	t.Run("requires a query", func(t *testing.T) {
+		c := newFakeClient()
+		res, err := executeElasticsearchDataQuery(c, `{"queryType": "esql"}`, from, to)
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+		assert.Empty(t, c.esqlRequests)
+	})
+}
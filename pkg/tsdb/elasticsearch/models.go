@@ -16,6 +16,10 @@ type Query struct {
 	IntervalMs    int64
 	RefID         string
 	MaxDataPoints int64
+	// QueryType is esqlQueryType for ES|QL queries, which are sent as RawQuery
+	QueryType string
+	// compositeAfter is the after key of the next page of buckets of the composite aggregation
+	compositeAfter map[string]any
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -30,6 +30,7 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 			return nil, err
 		}
 		alias := model.Get("alias").MustString("")
+		queryType := model.Get("queryType").MustString("")
 		intervalMs := model.Get("intervalMs").MustInt64(0)
 		interval := q.Interval
 
@@ -42,6 +43,7 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 			IntervalMs:    intervalMs,
 			RefID:         q.RefID,
 			MaxDataPoints: q.MaxDataPoints,
+			QueryType:     queryType,
 		})
 	}
 
@@ -38,6 +38,7 @@ const (
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+	compositeType   = "composite"
 	//  Document types
 	rawDocumentType = "raw_document"
 	rawDataType     = "raw_data"
@@ -417,6 +418,8 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 		if depth == maxDepth {
 			if aggDef.Type == dateHistType {
 				err = processMetrics(esAgg, target, queryResult, props)
+			} else if aggDef.Type == compositeType {
+				err = processCompositeAggregationDocs(esAgg, aggDef, target, queryResult)
 			} else {
 				err = processAggregationDocs(esAgg, aggDef, target, queryResult, props)
 			}
@@ -436,6 +439,13 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 					newProps[aggDef.Field] = key
 				} else if key, err := bucket.Get("key").Int64(); err == nil {
 					newProps[aggDef.Field] = strconv.FormatInt(key, 10)
+				} else if key, err := bucket.Get("key").Map(); err == nil {
+					// The keys of composite aggregations contain the value of each source
+					for name, v := range key {
+						if v != nil {
+							newProps[name] = compositeKeyString(v)
+						}
+					}
 				}
 
 				if key, err := bucket.Get("key_as_string").String(); err == nil {
@@ -0,0 +1,108 @@
+package graphite
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/tsdb/resourceutil"
+)
+
+// infinityDefault matches function parameters with an infinite default value, which Graphite returns as invalid
+// JSON.
+var infinityDefault = regexp.MustCompile(`("default":\s*)(-?Infinity)`)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleLookup("metrics/find", "query", "from", "until"))
+	mux.HandleFunc("/metrics/expand", s.handleLookup("metrics/expand", "query", "from", "until", "leavesOnly", "groupByExpr"))
+	mux.HandleFunc("/tags", s.handleLookup("tags", "filter", "from", "until", "limit"))
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleLookup("tags/autoComplete/tags", "expr", "tagPrefix", "from", "until", "limit"))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleLookup("tags/autoComplete/values", "expr", "tag", "valuePrefix", "from", "until", "limit"))
+	mux.HandleFunc("/functions", s.handleFunctions)
+	mux.HandleFunc("/version", s.handleVersion)
+	return mux
+}
+
+// handleLookup handles metric and tag lookups by passing the allowed parameters of the request to the Graphite
+// endpoint. The results are cached, unless the data source forwards the identity of the user.
+func (s *Service) handleLookup(resourcePath string, allowedParams ...string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		logger := logger.FromContext(req.Context())
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			resourceutil.WriteError(rw, logger, &resourceutil.Error{Status: http.StatusMethodNotAllowed, Message: "method not allowed"})
+			return
+		}
+		if err := req.ParseForm(); err != nil {
+			resourceutil.WriteError(rw, logger, &resourceutil.Error{Status: http.StatusBadRequest, Message: err.Error()})
+			return
+		}
+		params := url.Values{}
+		for _, name := range allowedParams {
+			if values, ok := req.Form[name]; ok {
+				params[name] = values
+			}
+		}
+
+		dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			resourceutil.WriteError(rw, logger, err)
+			return
+		}
+		resources := dsInfo.resources
+		body, err := resources.Get(req.Context(), logger, resourcePath, params, resources.LookupTTL(), resources.ValidJSON)
+		if err != nil {
+			resourceutil.WriteError(rw, logger, err)
+			return
+		}
+		resourceutil.WriteResponse(rw, logger, http.StatusOK, body)
+	}
+}
+
+// handleFunctions returns the functions that Graphite supports, with infinite default values of parameters
+// replaced by strings, so that the response is valid JSON.
+func (s *Service) handleFunctions(rw http.ResponseWriter, req *http.Request) {
+	logger := logger.FromContext(req.Context())
+	dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		resourceutil.WriteError(rw, logger, err)
+		return
+	}
+	body, err := dsInfo.resources.Get(req.Context(), logger, "functions", url.Values{}, resourceutil.MetadataCacheTTL, func(body []byte) ([]byte, error) {
+		return dsInfo.resources.ValidJSON(infinityDefault.ReplaceAll(body, []byte(`$1"$2"`)))
+	})
+	if err != nil {
+		resourceutil.WriteError(rw, logger, err)
+		return
+	}
+	resourceutil.WriteResponse(rw, logger, http.StatusOK, body)
+}
+
+// handleVersion returns the version of Graphite, which is empty for versions that do not have a version endpoint.
+func (s *Service) handleVersion(rw http.ResponseWriter, req *http.Request) {
+	logger := logger.FromContext(req.Context())
+	dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		resourceutil.WriteError(rw, logger, err)
+		return
+	}
+	body, err := dsInfo.resources.Get(req.Context(), logger, "version", url.Values{}, resourceutil.MetadataCacheTTL, func(body []byte) ([]byte, error) {
+		// Graphite returns the version as text, some implementations as a JSON string
+		version := strings.Trim(strings.TrimSpace(string(body)), `"`)
+		return json.Marshal(map[string]string{"version": version})
+	})
+	var resErr *resourceutil.Error
+	if errors.As(err, &resErr) && resErr.Status == http.StatusNotFound {
+		body, err = json.Marshal(map[string]string{"version": ""})
+	}
+	if err != nil {
+		resourceutil.WriteError(rw, logger, err)
+		return
+	}
+	resourceutil.WriteResponse(rw, logger, http.StatusOK, body)
+}
@@ -0,0 +1,167 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type fakeGraphite struct {
+	mtx      sync.Mutex
+	requests []*url.URL
+	handler  http.HandlerFunc
+}
+
+func (g *fakeGraphite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mtx.Lock()
+	g.requests = append(g.requests, r.URL)
+	g.mtx.Unlock()
+	g.handler(w, r)
+}
+
+func newResourceTestService(t *testing.T, jsonData string, handler http.HandlerFunc) (*Service, *fakeGraphite, backend.PluginContext) {
+	t.Helper()
+	g := &fakeGraphite{handler: handler}
+	srv := httptest.NewServer(g)
+	t.Cleanup(srv.Close)
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+		UID:      "graphite",
+		URL:      srv.URL,
+		JSONData: json.RawMessage(jsonData),
+	}}
+	return ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest()), g, pluginCtx
+}
+
+func callResource(t *testing.T, s *Service, pluginCtx backend.PluginContext, method, path string, body []byte) *backend.CallResourceResponse {
+	t.Helper()
+	sender := &fakeSender{}
+	req := &backend.CallResourceRequest{PluginContext: pluginCtx, Method: method, Path: strings.Split(path, "?")[0], URL: path, Body: body}
+	if method == http.MethodPost {
+		req.Headers = map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}}
+	}
+	err := s.CallResource(context.Background(), req, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.res)
+	return sender.res
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("finds metrics with the allowed parameters and caches the result", func(t *testing.T) {
+		s, g, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"text":"cpu","id":"servers.cpu","expandable":1,"leaf":0}]`))
+		})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=servers.*&from=-1h&secret=1", nil)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[{"text":"cpu","id":"servers.cpu","expandable":1,"leaf":0}]`, string(res.Body))
+
+		res = callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=servers.*&from=-1h", nil)
+		assert.Equal(t, http.StatusOK, res.Status)
+		require.Len(t, g.requests, 1)
+		assert.Equal(t, "/metrics/find", g.requests[0].Path)
+		assert.Equal(t, url.Values{"query": {"servers.*"}, "from": {"-1h"}}, g.requests[0].Query())
+	})
+
+	t.Run("reads parameters from form data", func(t *testing.T) {
+		s, g, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`["prod","staging"]`))
+		})
+		res := callResource(t, s, pluginCtx, http.MethodPost, "tags/autoComplete/values", []byte(`expr=name%3Dcpu&tag=env`))
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["prod","staging"]`, string(res.Body))
+		require.Len(t, g.requests, 1)
+		assert.Equal(t, "/tags/autoComplete/values", g.requests[0].Path)
+		assert.Equal(t, url.Values{"expr": {"name=cpu"}, "tag": {"env"}}, g.requests[0].Query())
+	})
+
+	t.Run("does not cache lookups if the identity of the user is forwarded", func(t *testing.T) {
+		for _, jsonData := range []string{`{"oauthPassThru":true}`, `{"keepCookies":["session"]}`} {
+			s, g, pluginCtx := newResourceTestService(t, jsonData, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`["env"]`))
+			})
+			callResource(t, s, pluginCtx, http.MethodGet, "tags/autoComplete/tags?expr=name%3Dcpu", nil)
+			callResource(t, s, pluginCtx, http.MethodGet, "tags/autoComplete/tags?expr=name%3Dcpu", nil)
+			assert.Len(t, g.requests, 2, jsonData)
+		}
+	})
+
+	t.Run("returns functions as valid JSON", func(t *testing.T) {
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"maxSeries":{"params":[{"name":"n","type":"integer","default": Infinity}]}}`))
+		})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "functions", nil)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `{"maxSeries":{"params":[{"name":"n","type":"integer","default":"Infinity"}]}}`, string(res.Body))
+	})
+
+	t.Run("detects the version", func(t *testing.T) {
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("1.1.10\n"))
+		})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "version", nil)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `{"version":"1.1.10"}`, string(res.Body))
+	})
+
+	t.Run("returns an empty version if Graphite has no version endpoint", func(t *testing.T) {
+		s, _, pluginCtx := newResourceTestService(t, `{}`, http.NotFound)
+		res := callResource(t, s, pluginCtx, http.MethodGet, "version", nil)
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `{"version":""}`, string(res.Body))
+	})
+
+	t.Run("maps errors of Graphite", func(t *testing.T) {
+		s, g, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("query") == "bad" {
+				http.Error(w, "invalid query", http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=bad", nil)
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.JSONEq(t, `{"message":"request failed, status: 400 Bad Request: invalid query"}`, string(res.Body))
+
+		res = callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=servers.*", nil)
+		assert.Equal(t, http.StatusBadGateway, res.Status)
+
+		// Errors are not cached
+		callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=servers.*", nil)
+		assert.Len(t, g.requests, 3)
+	})
+
+	t.Run("rejects invalid responses", func(t *testing.T) {
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html>login</html>`))
+		})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "metrics/find?query=*", nil)
+		assert.Equal(t, http.StatusBadGateway, res.Status)
+	})
+
+	t.Run("rejects unknown resources", func(t *testing.T) {
+		s, g, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {})
+		res := callResource(t, s, pluginCtx, http.MethodGet, "render?target=*", nil)
+		assert.Equal(t, http.StatusNotFound, res.Status)
+		assert.Empty(t, g.requests)
+	})
+}
@@ -65,6 +65,7 @@ var (
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	split      splitOptions
 
 	// open streams
 	streams   map[string]data.FrameJSONCache
@@ -100,9 +101,15 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		split, err := parseSplitOptions(settings)
+		if err != nil {
+			return nil, err
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			split:      split,
 			streams:    make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
@@ -209,7 +216,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.split, responseOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -218,7 +225,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, dsInfo.split, responseOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -226,7 +233,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, split splitOptions, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -239,7 +246,13 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	frames, err := runQuery(ctx, api, query, responseOpts, plog)
+	var frames data.Frames
+	var err error
+	if query.QueryType == QueryTypeRange && split.enabled() {
+		frames, err = runSplitQuery(ctx, api, query, split, responseOpts, plog)
+	} else {
+		frames, err = runQuery(ctx, api, query, responseOpts, plog)
+	}
 	queryRes := backend.DataResponse{}
 	if err != nil {
 		span.RecordError(err)
@@ -0,0 +1,518 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+)
+
+const (
+	defaultSplitConcurrency = 4
+	// streamShardLabel is the label that Loki adds to streams that it shards on ingestion
+	streamShardLabel = "__stream_shard__"
+)
+
+var (
+	shardValue = regexp.MustCompile(`^[0-9]+$`)
+	// innerAggregation matches vector aggregations, which aggregate streams of different shards, so their results
+	// cannot be summed across shards.
+	innerAggregation = regexp.MustCompile(`\b(sum|avg|min|max|count|stddev|stdvar|topk|bottomk|sort|sort_desc|absent_over_time)\s*(\(|by\b|without\b)`)
+)
+
+// splitSettings are the settings in the JSON data of the data source for splitting range queries.
+type splitSettings struct {
+	// SplitQueryInterval splits range queries into sub-queries of this interval, e.g. 1d. Disabled if empty.
+	SplitQueryInterval string `json:"splitQueryInterval"`
+	// SplitQueryConcurrency is the maximum number of sub-queries of a query that are run in parallel.
+	SplitQueryConcurrency int `json:"splitQueryConcurrency"`
+	// ShardQueries splits range queries by the stream shards of their stream selector.
+	ShardQueries bool `json:"shardQueries"`
+}
+
+type splitOptions struct {
+	interval    time.Duration
+	concurrency int
+	shard       bool
+}
+
+func parseSplitOptions(settings backend.DataSourceInstanceSettings) (splitOptions, error) {
+	var ss splitSettings
+	if len(settings.JSONData) > 0 {
+		if err := json.Unmarshal(settings.JSONData, &ss); err != nil {
+			return splitOptions{}, err
+		}
+	}
+
+	opts := splitOptions{
+		concurrency: ss.SplitQueryConcurrency,
+		shard:       ss.ShardQueries,
+	}
+	if opts.concurrency <= 0 {
+		opts.concurrency = defaultSplitConcurrency
+	}
+	if ss.SplitQueryInterval != "" {
+		interval, err := intervalv2.ParseIntervalStringToTimeDuration(ss.SplitQueryInterval)
+		if err != nil {
+			return splitOptions{}, fmt.Errorf("invalid split query interval: %w", err)
+		}
+		opts.interval = interval
+	}
+	return opts, nil
+}
+
+func (o splitOptions) enabled() bool {
+	return o.interval > 0 || o.shard
+}
+
+// subQuery is a part of a split query. Sub-queries with the same time index query the same time range.
+type subQuery struct {
+	query     *lokiQuery
+	timeIndex int
+}
+
+// runSplitQuery splits the range query by time and by stream shard, runs the sub-queries in parallel and merges
+// their frames before adjusting them, so that the result is the same as the one of the query.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, opts splitOptions, responseOpts ResponseOpts, plog log.Logger) (data.Frames, error) {
+	subQueries := splitQuery(ctx, api, query, opts, plog)
+	if len(subQueries) == 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	results := make([]data.Frames, len(subQueries))
+	err := concurrency.ForEachJob(ctx, len(subQueries), opts.concurrency, func(ctx context.Context, idx int) error {
+		frames, err := api.DataQuery(ctx, *subQueries[idx].query, responseOpts)
+		results[idx] = frames
+		return err
+	})
+	if err != nil {
+		plog.Error("Error querying loki", "error", err, "subQueries", len(subQueries))
+		return data.Frames{}, err
+	}
+
+	plog.Debug("Merging split query", "query", query.Expr, "subQueries", len(subQueries))
+	frames, err := mergeSplitFrames(query, subQueries, results)
+	if err != nil {
+		plog.Error("Error merging frames of split query", "error", err)
+		return data.Frames{}, err
+	}
+
+	for _, frame := range frames {
+		if err := adjustFrame(frame, query, !responseOpts.metricDataplane, responseOpts.logsDataplane); err != nil {
+			plog.Error("Error adjusting frame", "error", err)
+			return data.Frames{}, err
+		}
+	}
+	if len(frames) > 0 && frames[0].Meta != nil {
+		frames[0].Meta.ExecutedQueryString += fmt.Sprintf("\nSplit: %d sub-queries", len(subQueries))
+	}
+	return frames, nil
+}
+
+// splitQuery returns the sub-queries of the query for each sub-range of its time range and each group of shards.
+func splitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, opts splitOptions, plog log.Logger) []subQuery {
+	var exprs []string
+	if opts.shard {
+		var err error
+		exprs, err = shardExprs(ctx, api, query, opts.concurrency)
+		if err != nil {
+			// The query is still correct without sharding
+			plog.Warn("Failed to shard query", "error", err)
+		}
+	}
+	if len(exprs) == 0 {
+		exprs = []string{query.Expr}
+	}
+
+	var subQueries []subQuery
+	for i, r := range splitTimeRange(query, opts.interval) {
+		for _, expr := range exprs {
+			sub := *query
+			sub.Start, sub.End, sub.Expr = r[0], r[1], expr
+			subQueries = append(subQueries, subQuery{query: &sub, timeIndex: i})
+		}
+	}
+	return subQueries
+}
+
+// splitTimeRange splits the time range of the query into sub-ranges of the interval. The sub-ranges of metric queries
+// are a multiple of the step and end one step before the next one starts, as their start and end are inclusive.
+// The sub-ranges of log queries share their boundaries, as duplicate lines are removed when merging.
+func splitTimeRange(query *lokiQuery, interval time.Duration) [][2]time.Time {
+	if interval <= 0 || query.End.Sub(query.Start) <= interval {
+		return [][2]time.Time{{query.Start, query.End}}
+	}
+
+	var step time.Duration
+	if !isLogsQuery(query.Expr) && query.Step > 0 {
+		step = query.Step
+		if rem := interval % step; rem != 0 {
+			interval += step - rem
+		}
+	}
+
+	var ranges [][2]time.Time
+	for start := query.Start; ; start = start.Add(interval) {
+		end := start.Add(interval - step)
+		// The last sub-range also includes the end, instead of querying it separately
+		if !end.Add(step).Before(query.End) {
+			return append(ranges, [2]time.Time{start, query.End})
+		}
+		ranges = append(ranges, [2]time.Time{start, end})
+	}
+}
+
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+// shardExprs returns the expressions of the query for groups of the stream shards of its stream selector, and one
+// for the streams that are not sharded. Only log queries and sums of range aggregations can be sharded, as their
+// results for all streams are the merge of the results for each shard.
+func shardExprs(ctx context.Context, api *LokiAPI, query *lokiQuery, groups int) ([]string, error) {
+	if !isLogsQuery(query.Expr) && !isShardableSum(query.Expr) {
+		return nil, nil
+	}
+	selectors := findStreamSelectors(query.Expr)
+	if len(selectors) != 1 {
+		return nil, nil
+	}
+	selector := query.Expr[selectors[0][0]:selectors[0][1]]
+
+	params := url.Values{}
+	params.Set("query", selector)
+	params.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+	res, err := api.RawQuery(ctx, "/loki/api/v1/label/"+streamShardLabel+"/values?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if res.Status/100 != 2 {
+		return nil, fmt.Errorf("unexpected status code %d when requesting stream shards", res.Status)
+	}
+	var values struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body, &values); err != nil {
+		return nil, err
+	}
+	var shards []string
+	for _, v := range values.Data {
+		if !shardValue.MatchString(v) {
+			return nil, fmt.Errorf("unexpected stream shard %q", v)
+		}
+		shards = append(shards, v)
+	}
+	if len(shards) == 0 {
+		return nil, nil
+	}
+
+	if groups > len(shards) {
+		groups = len(shards)
+	}
+	exprs := []string{withMatcher(query.Expr, selectors[0], streamShardLabel+`=""`)}
+	for i := 0; i < groups; i++ {
+		group := shards[i*len(shards)/groups : (i+1)*len(shards)/groups]
+		exprs = append(exprs, withMatcher(query.Expr, selectors[0], streamShardLabel+`=~"`+strings.Join(group, "|")+`"`))
+	}
+	return exprs, nil
+}
+
+// withMatcher adds the label matcher to the stream selector at the position in the expression.
+func withMatcher(expr string, selector [2]int, matcher string) string {
+	inner := strings.TrimSpace(expr[selector[0]+1 : selector[1]-1])
+	if inner != "" {
+		matcher = inner + ", " + matcher
+	}
+	return expr[:selector[0]] + "{" + matcher + "}" + expr[selector[1]:]
+}
+
+// findStreamSelectors returns the start and end positions of the stream selectors in the expression. Braces in
+// strings, like templates in line formats, are skipped.
+func findStreamSelectors(expr string) [][2]int {
+	var selectors [][2]int
+	start := -1
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '"', '`':
+			i = skipString(expr, i)
+		case '{':
+			if start < 0 {
+				start = i
+			}
+		case '}':
+			if start >= 0 {
+				selectors = append(selectors, [2]int{start, i + 1})
+				start = -1
+			}
+		}
+	}
+	return selectors
+}
+
+// skipString returns the position of the end of the string that starts at the position in the expression.
+func skipString(expr string, i int) int {
+	quote := expr[i]
+	for i++; i < len(expr); i++ {
+		if quote == '"' && expr[i] == '\\' {
+			i++
+			continue
+		}
+		if expr[i] == quote {
+			return i
+		}
+	}
+	return i
+}
+
+// skipParens returns the position after the parenthesis that closes the one at the start of the expression, or -1.
+func skipParens(expr string) int {
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '"', '`':
+			i = skipString(expr, i)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// skipGrouping returns the expression after a leading by or without clause.
+func skipGrouping(expr string) (string, bool) {
+	for _, keyword := range []string{"by", "without"} {
+		if rest, ok := strings.CutPrefix(expr, keyword); ok {
+			rest = strings.TrimSpace(rest)
+			if !strings.HasPrefix(rest, "(") {
+				return "", false
+			}
+			end := skipParens(rest)
+			if end < 0 {
+				return "", false
+			}
+			return strings.TrimSpace(rest[end:]), true
+		}
+	}
+	return expr, true
+}
+
+// isShardableSum returns whether the expression is a sum of range aggregations, like the log volume query, whose
+// series are the sums of the series of the shards.
+func isShardableSum(expr string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), "sum")
+	if !ok {
+		return false
+	}
+	if rest, ok = skipGrouping(strings.TrimSpace(rest)); !ok || !strings.HasPrefix(rest, "(") {
+		return false
+	}
+	end := skipParens(rest)
+	if end < 0 || innerAggregation.MatchString(rest[1:end-1]) {
+		return false
+	}
+	rest, ok = skipGrouping(strings.TrimSpace(rest[end:]))
+	return ok && rest == ""
+}
+
+// mergeSplitFrames merges the frames of the sub-queries. Log lines are deduplicated, sorted in the direction of the
+// query and limited to its maximum number of lines. The values of series of different shards are summed.
+func mergeSplitFrames(query *lokiQuery, subQueries []subQuery, results []data.Frames) (data.Frames, error) {
+	var logFrames []*data.Frame
+	var metricFrames []*data.Frame
+	var metricTimeIndexes []int
+	for i, frames := range results {
+		for _, frame := range frames {
+			if len(frame.Fields) < 2 {
+				return nil, fmt.Errorf("missing fields in frame")
+			}
+			if frame.Fields[1].Type() == data.FieldTypeFloat64 {
+				metricFrames = append(metricFrames, frame)
+				metricTimeIndexes = append(metricTimeIndexes, subQueries[i].timeIndex)
+			} else {
+				logFrames = append(logFrames, frame)
+			}
+		}
+	}
+
+	var merged data.Frames
+	if len(logFrames) > 0 {
+		frame, err := mergeLogFrames(query, logFrames)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, frame)
+	}
+	if len(metricFrames) > 0 {
+		frames, err := mergeMetricFrames(metricFrames, metricTimeIndexes)
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, frames...)
+	}
+	return merged, nil
+}
+
+// mergeLogFrames merges the raw log frames into one frame without duplicate lines. Lines are identified by their
+// timestamp in nanoseconds, labels and content.
+func mergeLogFrames(query *lokiQuery, frames []*data.Frame) (*data.Frame, error) {
+	template := frames[0]
+	for _, frame := range frames {
+		if frame.Rows() > 0 {
+			template = frame
+			break
+		}
+	}
+	if len(template.Fields) < 4 {
+		return nil, fmt.Errorf("invalid field length in logs frame. expected at least 4, got %d", len(template.Fields))
+	}
+
+	type row struct {
+		frame *data.Frame
+		idx   int
+		time  time.Time
+	}
+	var rows []row
+	seen := map[string]struct{}{}
+	for _, frame := range frames {
+		if frame.Rows() == 0 {
+			continue
+		}
+		if len(frame.Fields) != len(template.Fields) {
+			return nil, fmt.Errorf("indifferent field lengths in logs frames. expected %d, got %d", len(template.Fields), len(frame.Fields))
+		}
+		for i := range frame.Fields {
+			if frame.Fields[i].Type() != template.Fields[i].Type() {
+				return nil, fmt.Errorf("indifferent field types in logs frames. expected %s, got %s", template.Fields[i].Type(), frame.Fields[i].Type())
+			}
+		}
+		labels, ts, line, tsNs := frame.Fields[0], frame.Fields[1], frame.Fields[2], frame.Fields[3]
+		if ts.Type() != data.FieldTypeTime || line.Type() != data.FieldTypeString || labels.Type() != data.FieldTypeJSON || tsNs.Type() != data.FieldTypeString {
+			return nil, fmt.Errorf("invalid field types in logs frame. expected json, time, string and string, got %s, %s, %s and %s", labels.Type(), ts.Type(), line.Type(), tsNs.Type())
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			key := tsNs.At(i).(string) + "\x00" + string(labels.At(i).(json.RawMessage)) + "\x00" + line.At(i).(string)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			rows = append(rows, row{frame: frame, idx: i, time: ts.At(i).(time.Time)})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if query.Direction == DirectionForward {
+			return rows[i].time.Before(rows[j].time)
+		}
+		return rows[i].time.After(rows[j].time)
+	})
+	if query.MaxLines > 0 && len(rows) > query.MaxLines {
+		rows = rows[:query.MaxLines]
+	}
+
+	merged := emptyFrameCopy(template)
+	for _, r := range rows {
+		for i, f := range r.frame.Fields {
+			merged.Fields[i].Append(f.At(r.idx))
+		}
+	}
+	return merged, nil
+}
+
+// mergeMetricFrames merges the metric frames of the same series. Values of the same time range are the values of
+// different shards and are summed, values of different time ranges are appended.
+func mergeMetricFrames(frames []*data.Frame, timeIndexes []int) (data.Frames, error) {
+	type series struct {
+		frame  *data.Frame
+		values map[int64]float64
+		// timeIndexes are the time indexes of the values, to add values of the same time range only
+		timeIndexes map[int64]int
+	}
+	var keys []string
+	index := map[string]*series{}
+	for i, frame := range frames {
+		if len(frame.Fields) != 2 {
+			return nil, fmt.Errorf("invalid field length in metric frame. expected 2, got %d", len(frame.Fields))
+		}
+		timeField, valueField := frame.Fields[0], frame.Fields[1]
+		if timeField.Type() != data.FieldTypeTime {
+			return nil, fmt.Errorf("invalid field types in metric frame. expected time and float64, got %s and %s", timeField.Type(), valueField.Type())
+		}
+
+		key := frame.Name + "\x00" + valueField.Name + "\x00" + valueField.Labels.String()
+		s, ok := index[key]
+		if !ok {
+			s = &series{frame: frame, values: map[int64]float64{}, timeIndexes: map[int64]int{}}
+			index[key] = s
+			keys = append(keys, key)
+		}
+		for row := 0; row < frame.Rows(); row++ {
+			ts := timeField.At(row).(time.Time).UnixNano()
+			v := valueField.At(row).(float64)
+			timeIndex, ok := s.timeIndexes[ts]
+			switch {
+			case !ok:
+				s.values[ts] = v
+				s.timeIndexes[ts] = timeIndexes[i]
+			case timeIndex == timeIndexes[i]:
+				s.values[ts] += v
+			}
+		}
+	}
+
+	merged := make(data.Frames, 0, len(keys))
+	for _, key := range keys {
+		s := index[key]
+		timestamps := make([]int64, 0, len(s.values))
+		for ts := range s.values {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		frame := emptyFrameCopy(s.frame)
+		for _, ts := range timestamps {
+			frame.Fields[0].Append(time.Unix(0, ts).UTC())
+			frame.Fields[1].Append(s.values[ts])
+		}
+		merged = append(merged, frame)
+	}
+	return merged, nil
+}
+
+// emptyFrameCopy returns a frame with the same name, meta and fields as the frame, but without rows.
+func emptyFrameCopy(frame *data.Frame) *data.Frame {
+	c := data.NewFrame(frame.Name)
+	c.RefID = frame.RefID
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		c.Meta = &meta
+	}
+	for _, f := range frame.Fields {
+		field := data.NewFieldFromFieldType(f.Type(), 0)
+		field.Name = f.Name
+		field.Labels = f.Labels.Copy()
+		if f.Config != nil {
+			config := *f.Config
+			field.Config = &config
+		}
+		c.Fields = append(c.Fields, field)
+	}
+	return c
+}
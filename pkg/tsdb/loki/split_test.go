@@ -0,0 +1,249 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// fakeLoki has the streams {app="a"}, {app="a",__stream_shard__="0"} and {app="a",__stream_shard__="1"} with one
+// line per minute. Metric queries return the number of matching streams per step.
+type fakeLoki struct {
+	mtx     sync.Mutex
+	queries []string
+	ranges  [][2]time.Time
+}
+
+func (l *fakeLoki) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if r.URL.Path == "/loki/api/v1/label/__stream_shard__/values" {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "data": []string{"0", "1"}})
+		return
+	}
+
+	expr := q.Get("query")
+	startNs, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+	endNs, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+	start, end := time.Unix(0, startNs).UTC(), time.Unix(0, endNs).UTC()
+	l.mtx.Lock()
+	l.queries = append(l.queries, expr)
+	l.ranges = append(l.ranges, [2]time.Time{start, end})
+	l.mtx.Unlock()
+
+	var shards []string
+	switch {
+	case strings.Contains(expr, `__stream_shard__=""`):
+		shards = []string{""}
+	case strings.Contains(expr, `__stream_shard__=~"`):
+		matcher := expr[strings.Index(expr, `__stream_shard__=~"`)+len(`__stream_shard__=~"`):]
+		shards = strings.Split(matcher[:strings.Index(matcher, `"`)], "|")
+	default:
+		shards = []string{"", "0", "1"}
+	}
+
+	if !isLogsQuery(expr) {
+		step, _ := time.ParseDuration(q.Get("step"))
+		values := [][]any{}
+		for ts := start; !ts.After(end); ts = ts.Add(step) {
+			values = append(values, []any{float64(ts.Unix()), strconv.Itoa(len(shards))})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "success",
+			"data": map[string]any{
+				"resultType": "matrix",
+				"result":     []any{map[string]any{"metric": map[string]string{}, "values": values}},
+			},
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	var result []any
+	for _, shard := range shards {
+		stream := map[string]string{"app": "a"}
+		if shard != "" {
+			stream[streamShardLabel] = shard
+		}
+		values := [][]string{}
+		for ts := end.Truncate(time.Minute); !ts.Before(start) && (limit == 0 || len(values) < limit); ts = ts.Add(-time.Minute) {
+			values = append(values, []string{strconv.FormatInt(ts.UnixNano(), 10), "line " + shard})
+		}
+		result = append(result, map[string]any{"stream": stream, "values": values})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": "success",
+		"data":   map[string]any{"resultType": "streams", "result": result},
+	})
+}
+
+func newSplitTestAPI(t *testing.T) (*LokiAPI, *fakeLoki) {
+	t.Helper()
+	l := &fakeLoki{}
+	srv := httptest.NewServer(l)
+	t.Cleanup(srv.Close)
+	return newLokiAPI(srv.Client(), srv.URL, log.New("test"), tracing.InitializeTracerForTest(), false), l
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	start := time.Date(2023, 1, 2, 3, 30, 0, 0, time.UTC)
+	end := start.Add(3 * time.Hour)
+	splitByTime := splitOptions{interval: time.Hour, concurrency: 2}
+
+	t.Run("splits metric queries by time", func(t *testing.T) {
+		api, l := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `sum(count_over_time({app="a"}[1m]))`, QueryType: QueryTypeRange, Step: time.Minute, Start: start, End: end, RefID: "A"}
+		frames, err := runSplitQuery(context.Background(), api, query, splitByTime, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+
+		assert.Equal(t, [][2]time.Time{
+			{start, start.Add(59 * time.Minute)},
+			{start.Add(time.Hour), start.Add(119 * time.Minute)},
+			{start.Add(2 * time.Hour), end},
+		}, sortedRanges(l.ranges))
+		require.Len(t, frames, 1)
+		require.Equal(t, 181, frames[0].Rows())
+		for i := 0; i < frames[0].Rows(); i++ {
+			require.Equal(t, start.Add(time.Duration(i)*time.Minute), frames[0].Fields[0].At(i))
+			require.Equal(t, float64(3), frames[0].Fields[1].At(i))
+		}
+		assert.Contains(t, frames[0].Meta.ExecutedQueryString, "Split: 3 sub-queries")
+	})
+
+	t.Run("splits log queries by time and removes duplicate lines", func(t *testing.T) {
+		api, l := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, MaxLines: 1000, Start: start, End: end, RefID: "A"}
+		frames, err := runSplitQuery(context.Background(), api, query, splitByTime, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+
+		assert.Len(t, l.ranges, 3)
+		require.Len(t, frames, 1)
+		require.Equal(t, 3*181, frames[0].Rows())
+		timeField, _ := frames[0].FieldByName("Time")
+		require.NotNil(t, timeField)
+		assert.Equal(t, end, timeField.At(0))
+		assert.Equal(t, start, timeField.At(timeField.Len()-1))
+	})
+
+	t.Run("limits log queries to the newest lines", func(t *testing.T) {
+		api, _ := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Direction: DirectionBackward, Step: time.Minute, MaxLines: 10, Start: start, End: end, RefID: "A"}
+		frames, err := runSplitQuery(context.Background(), api, query, splitByTime, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 10, frames[0].Rows())
+		timeField, _ := frames[0].FieldByName("Time")
+		assert.Equal(t, end, timeField.At(0))
+		assert.Equal(t, end.Add(-3*time.Minute), timeField.At(9))
+	})
+
+	t.Run("shards sums of range aggregations by stream shard", func(t *testing.T) {
+		api, l := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `sum by (level) (count_over_time({app="a"} | json [1m]))`, QueryType: QueryTypeRange, Step: time.Minute, Start: start, End: end, RefID: "A"}
+		frames, err := runSplitQuery(context.Background(), api, query, splitOptions{concurrency: 2, shard: true}, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{
+			`sum by (level) (count_over_time({app="a", __stream_shard__=""} | json [1m]))`,
+			`sum by (level) (count_over_time({app="a", __stream_shard__=~"0"} | json [1m]))`,
+			`sum by (level) (count_over_time({app="a", __stream_shard__=~"1"} | json [1m]))`,
+		}, l.queries)
+		require.Len(t, frames, 1)
+		require.Equal(t, 181, frames[0].Rows())
+		for i := 0; i < frames[0].Rows(); i++ {
+			require.Equal(t, float64(3), frames[0].Fields[1].At(i))
+		}
+	})
+
+	t.Run("shards log queries by stream shard and time", func(t *testing.T) {
+		api, l := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `{app="a"} |= "line"`, QueryType: QueryTypeRange, Direction: DirectionForward, Step: time.Minute, MaxLines: 1000, Start: start, End: end, RefID: "A"}
+		frames, err := runSplitQuery(context.Background(), api, query, splitOptions{interval: time.Hour, concurrency: 4, shard: true}, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+
+		assert.Len(t, l.queries, 9)
+		require.Len(t, frames, 1)
+		require.Equal(t, 3*181, frames[0].Rows())
+		timeField, _ := frames[0].FieldByName("Time")
+		assert.Equal(t, start, timeField.At(0))
+		assert.Equal(t, end, timeField.At(timeField.Len()-1))
+	})
+
+	t.Run("does not shard other aggregations", func(t *testing.T) {
+		api, l := newSplitTestAPI(t)
+		query := &lokiQuery{Expr: `max(count_over_time({app="a"}[1m]))`, QueryType: QueryTypeRange, Step: time.Minute, Start: start, End: end, RefID: "A"}
+		_, err := runSplitQuery(context.Background(), api, query, splitOptions{concurrency: 2, shard: true}, ResponseOpts{}, log.New("test"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{query.Expr}, l.queries)
+	})
+
+	t.Run("fails if a sub-query fails", func(t *testing.T) {
+		api := makeMockedAPI(400, "application/json", []byte(`{"message":"query too large"}`), nil, false)
+		query := &lokiQuery{Expr: `{app="a"}`, QueryType: QueryTypeRange, Step: time.Minute, Start: start, End: end, RefID: "A"}
+		_, err := runSplitQuery(context.Background(), api, query, splitByTime, ResponseOpts{}, log.New("test"))
+		require.Error(t, err)
+	})
+}
+
+func sortedRanges(ranges [][2]time.Time) [][2]time.Time {
+	sorted := append([][2]time.Time{}, ranges...)
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if sorted[j][0].Before(sorted[i][0]) {
+				sorted[i], sorted[j] = sorted[j], sorted[i]
+			}
+		}
+	}
+	return sorted
+}
+
+func TestIsShardableSum(t *testing.T) {
+	tests := map[string]bool{
+		`sum(count_over_time({app="a"}[1m]))`:                                   true,
+		`sum by (level) (count_over_time({app="a"}[1m]))`:                       true,
+		`sum(rate({app="a"} | unwrap bytes [1m])) without (pod)`:                true,
+		`sum(count_over_time({app="a"}[1m])) > 5`:                               false,
+		`sum(max by (pod) (count_over_time({app="a"}[1m])))`:                    false,
+		`sum_over_time({app="a"} | unwrap bytes [1m])`:                          false,
+		`sum(absent_over_time({app="a"}[1m]))`:                                  false,
+		`avg(count_over_time({app="a"}[1m]))`:                                   false,
+		`sum(count_over_time({app="a"} | line_format "{{.level}} (sum)" [1m]))`: true,
+	}
+	for expr, expected := range tests {
+		assert.Equal(t, expected, isShardableSum(expr), expr)
+	}
+}
+
+func TestFindStreamSelectors(t *testing.T) {
+	expr := `sum(count_over_time({app="a}"} | line_format "{{.level}}" [1m])) / sum(count_over_time({app="b"}[1m]))`
+	selectors := findStreamSelectors(expr)
+	require.Len(t, selectors, 2)
+	assert.Equal(t, `{app="a}"}`, expr[selectors[0][0]:selectors[0][1]])
+	assert.Equal(t, `{app="b"}`, expr[selectors[1][0]:selectors[1][1]])
+	assert.Equal(t, `{app="a}", x="y"} | line_format "{{.level}}"`, withMatcher(`{app="a}"} | line_format "{{.level}}"`, [2]int{0, 10}, `x="y"`))
+}
+
+func TestParseSplitOptions(t *testing.T) {
+	opts, err := parseSplitOptions(backend.DataSourceInstanceSettings{JSONData: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.False(t, opts.enabled())
+
+	opts, err = parseSplitOptions(backend.DataSourceInstanceSettings{JSONData: []byte(`{"splitQueryInterval":"1d","shardQueries":true}`)})
+	require.NoError(t, err)
+	assert.Equal(t, splitOptions{interval: 24 * time.Hour, concurrency: defaultSplitConcurrency, shard: true}, opts)
+
+	_, err = parseSplitOptions(backend.DataSourceInstanceSettings{JSONData: []byte(`{"splitQueryInterval":"often"}`)})
+	require.Error(t, err)
+}
@@ -143,6 +143,7 @@ func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.Instanc
 			TimeColumnNames:   []string{"time", "time_sec"},
 			MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			SchemaQueries:     schemaQueries,
 		}
 
 		rowTransformer := mysqlQueryResultTransformer{
@@ -198,6 +199,27 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsHandler.QueryData(ctx, req)
 }
 
+// CallResource serves the schemas, tables, columns and indexes of the database to the query editor.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.CallResource(ctx, req, sender)
+}
+
+// schemaQueries introspect the schema of the database. A schema in MySQL is a database.
+var schemaQueries = &sqleng.SchemaQueries{
+	CurrentSchema: `SELECT DATABASE()`,
+	Schemas: `SELECT schema_name FROM information_schema.schemata
+		WHERE schema_name NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys') ORDER BY schema_name`,
+	Tables: `SELECT table_name FROM information_schema.tables WHERE table_schema = ? ORDER BY table_name`,
+	Columns: `SELECT column_name, column_type FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ? ORDER BY ordinal_position`,
+	Indexes: `SELECT index_name, column_name, non_unique = 0 FROM information_schema.statistics
+		WHERE table_schema = ? AND table_name = ? ORDER BY index_name, seq_in_index`,
+}
+
 type mysqlQueryResultTransformer struct {
 	userError string
 }
@@ -215,6 +237,22 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	return err
 }
 
+// ConnectionID returns the ID of the connection, so the query of the connection can be killed. The MySQL driver
+// only closes the connection if a query is canceled, which does not stop the query on the server.
+func (t *mysqlQueryResultTransformer) ConnectionID(ctx context.Context, conn *sql.Conn) (string, error) {
+	var id string
+	err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&id)
+	return id, err
+}
+
+func (t *mysqlQueryResultTransformer) CancelQuery(ctx context.Context, db *sql.DB, connectionID string) error {
+	if _, err := strconv.ParseUint(connectionID, 10, 64); err != nil {
+		return fmt.Errorf("invalid connection id %q", connectionID)
+	}
+	_, err := db.ExecContext(ctx, "KILL QUERY "+connectionID)
+	return err
+}
+
 func (t *mysqlQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
 	// For the MySQL driver , we have these possible data types:
 	// https://www.w3schools.com/sql/sql_datatypes.asp#:~:text=In%20MySQL%20there%20are%20three,numeric%2C%20and%20date%20and%20time.
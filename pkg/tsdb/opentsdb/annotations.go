@@ -0,0 +1,106 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const annotationsQueryType = "annotations"
+
+type annotationsQueryModel struct {
+	// Target is the metric of the annotations
+	Target string `json:"target"`
+	// IsGlobal returns the global annotations instead of the annotations of the time series of the metric
+	IsGlobal bool `json:"isGlobal"`
+}
+
+// queryAnnotations returns the annotations of the metric of the query, or the global annotations, in the time range
+// of the query as a frame with the time, end time and text of each annotation.
+func (s *Service) queryAnnotations(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery) backend.DataResponse {
+	var model annotationsQueryModel
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return backend.DataResponse{Error: fmt.Errorf("failed to parse annotations query: %w", err)}
+	}
+	if model.Target == "" {
+		return backend.DataResponse{Error: fmt.Errorf("annotations query has no metric")}
+	}
+
+	tsdbQuery := OpenTsdbQuery{
+		Start:             query.TimeRange.From.UnixMilli(),
+		End:               query.TimeRange.To.UnixMilli(),
+		Queries:           []map[string]any{{"aggregator": "sum", "metric": model.Target}},
+		GlobalAnnotations: model.IsGlobal,
+	}
+	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return backend.DataResponse{Error: fmt.Errorf("request failed, status: %s", res.Status)}
+	}
+	var responseData []OpenTsdbResponse
+	if err := json.Unmarshal(body, &responseData); err != nil {
+		logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
+		return backend.DataResponse{Error: err}
+	}
+
+	var annotations []OpenTsdbAnnotation
+	if len(responseData) > 0 {
+		annotations = responseData[0].Annotations
+		if model.IsGlobal {
+			annotations = responseData[0].GlobalAnnotations
+		}
+	}
+	return backend.DataResponse{Frames: data.Frames{annotationsFrame(query.RefID, annotations)}}
+}
+
+func annotationsFrame(refID string, annotations []OpenTsdbAnnotation) *data.Frame {
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].StartTime < annotations[j].StartTime
+	})
+
+	times := make([]time.Time, 0, len(annotations))
+	timeEnds := make([]*time.Time, 0, len(annotations))
+	texts := make([]string, 0, len(annotations))
+	for _, a := range annotations {
+		times = append(times, time.Unix(int64(a.StartTime), 0).UTC())
+		var timeEnd *time.Time
+		if a.EndTime > 0 {
+			t := time.Unix(int64(a.EndTime), 0).UTC()
+			timeEnd = &t
+		}
+		timeEnds = append(timeEnds, timeEnd)
+		texts = append(texts, a.Description)
+	}
+
+	frame := data.NewFrame(annotationsQueryType,
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("text", nil, texts))
+	frame.RefID = refID
+	return frame
+}
@@ -0,0 +1,100 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryAnnotations(t *testing.T) {
+	from := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	response := `[{
+		"metric": "deploys",
+		"dps": {},
+		"annotations": [
+			{"tsuid": "000001", "description": "second", "startTime": 1672630200},
+			{"tsuid": "000001", "description": "first", "startTime": 1672628400, "endTime": 1672629000}
+		],
+		"globalAnnotations": [{"description": "global", "startTime": 1672629000}]
+	}]`
+
+	newRequest := func(queries ...backend.DataQuery) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{Queries: queries}
+	}
+
+	t.Run("returns the annotations of the metric", func(t *testing.T) {
+		var body OpenTsdbQuery
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(response))
+		})
+		req := newRequest(backend.DataQuery{RefID: "A", QueryType: annotationsQueryType, JSON: []byte(`{"target":"deploys"}`), TimeRange: backend.TimeRange{From: from, To: to}})
+		req.PluginContext = pluginCtx
+		res, err := s.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["A"].Error)
+
+		assert.Equal(t, from.UnixMilli(), body.Start)
+		assert.Equal(t, []map[string]any{{"aggregator": "sum", "metric": "deploys"}}, body.Queries)
+		assert.False(t, body.GlobalAnnotations)
+
+		require.Len(t, res.Responses["A"].Frames, 1)
+		frame := res.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1672628400, 0).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1672629000, 0).UTC(), *frame.Fields[1].At(0).(*time.Time))
+		assert.Equal(t, "first", frame.Fields[2].At(0))
+		assert.Nil(t, frame.Fields[1].At(1))
+		assert.Equal(t, "second", frame.Fields[2].At(1))
+	})
+
+	t.Run("returns global annotations", func(t *testing.T) {
+		var body OpenTsdbQuery
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(response))
+		})
+		req := newRequest(backend.DataQuery{RefID: "A", QueryType: annotationsQueryType, JSON: []byte(`{"target":"deploys","isGlobal":true}`), TimeRange: backend.TimeRange{From: from, To: to}})
+		req.PluginContext = pluginCtx
+		res, err := s.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, body.GlobalAnnotations)
+		frame := res.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, "global", frame.Fields[2].At(0))
+	})
+
+	t.Run("queries annotations separately from metrics", func(t *testing.T) {
+		requests := 0
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			_, _ = w.Write([]byte(response))
+		})
+		req := newRequest(
+			backend.DataQuery{RefID: "A", JSON: []byte(`{"metric":"deploys","aggregator":"sum"}`), TimeRange: backend.TimeRange{From: from, To: to}},
+			backend.DataQuery{RefID: "B", QueryType: annotationsQueryType, JSON: []byte(`{"target":"deploys"}`), TimeRange: backend.TimeRange{From: from, To: to}},
+		)
+		req.PluginContext = pluginCtx
+		res, err := s.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, 2, requests)
+		assert.Contains(t, res.Responses, "A")
+		assert.Equal(t, 2, res.Responses["B"].Frames[0].Rows())
+	})
+
+	t.Run("fails without a metric", func(t *testing.T) {
+		s, _, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {})
+		req := newRequest(backend.DataQuery{RefID: "A", QueryType: annotationsQueryType, JSON: []byte(`{}`)})
+		req.PluginContext = pluginCtx
+		res, err := s.QueryData(context.Background(), req)
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+	})
+}
@@ -0,0 +1,70 @@
+package opentsdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+
+	"github.com/grafana/grafana/pkg/tsdb/resourceutil"
+)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/suggest", s.handleResource("api/suggest", true, "type", "q", "max"))
+	mux.HandleFunc("/api/search/lookup", s.handleResource("api/search/lookup", true, "m", "limit", "useMeta"))
+	mux.HandleFunc("/api/aggregators", s.handleResource("api/aggregators", false))
+	mux.HandleFunc("/api/config/filters", s.handleResource("api/config/filters", false))
+	return mux
+}
+
+// handleResource handles requests of an OpenTSDB endpoint by passing on the allowed parameters of the request. The
+// results of lookups are cached unless the data source forwards the identity of the user, other results are metadata
+// that is always cached.
+func (s *Service) handleResource(resourcePath string, lookup bool, allowedParams ...string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		logger := logger.FromContext(req.Context())
+		if req.Method != http.MethodGet {
+			resourceutil.WriteError(rw, logger, &resourceutil.Error{Status: http.StatusMethodNotAllowed, Message: "method not allowed"})
+			return
+		}
+		query := req.URL.Query()
+		params := url.Values{}
+		for _, name := range allowedParams {
+			if values, ok := query[name]; ok {
+				params[name] = values
+			}
+		}
+
+		dsInfo, err := s.getDSInfo(req.Context(), httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			resourceutil.WriteError(rw, logger, err)
+			return
+		}
+		ttl := resourceutil.MetadataCacheTTL
+		if lookup {
+			ttl = dsInfo.resources.LookupTTL()
+		}
+		body, err := dsInfo.resources.Get(req.Context(), logger, resourcePath, params, ttl, dsInfo.resources.ValidJSON)
+		if err != nil {
+			resourceutil.WriteError(rw, logger, err)
+			return
+		}
+		resourceutil.WriteResponse(rw, logger, http.StatusOK, body)
+	}
+}
+
+// errorMessage returns the message of the error in the body of a failed OpenTSDB response, or an empty message if
+// it is not an OpenTSDB error.
+func errorMessage(body []byte) string {
+	var res struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &res); err == nil && res.Error.Message != "" {
+		return res.Error.Message
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+)
+
+type fakeOpenTSDB struct {
+	mtx      sync.Mutex
+	requests []*url.URL
+	handler  http.HandlerFunc
+}
+
+func (o *fakeOpenTSDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	o.mtx.Lock()
+	o.requests = append(o.requests, r.URL)
+	o.mtx.Unlock()
+	o.handler(w, r)
+}
+
+func newResourceTestService(t *testing.T, jsonData string, handler http.HandlerFunc) (*Service, *fakeOpenTSDB, backend.PluginContext) {
+	t.Helper()
+	o := &fakeOpenTSDB{handler: handler}
+	srv := httptest.NewServer(o)
+	t.Cleanup(srv.Close)
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+		UID:      "opentsdb",
+		URL:      srv.URL,
+		JSONData: json.RawMessage(jsonData),
+	}}
+	return ProvideService(httpclient.NewProvider()), o, pluginCtx
+}
+
+type fakeSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+func callResource(t *testing.T, s *Service, pluginCtx backend.PluginContext, path string) *backend.CallResourceResponse {
+	t.Helper()
+	sender := &fakeSender{}
+	req := &backend.CallResourceRequest{PluginContext: pluginCtx, Method: http.MethodGet, Path: strings.Split(path, "?")[0], URL: path}
+	require.NoError(t, s.CallResource(context.Background(), req, sender))
+	require.NotNil(t, sender.res)
+	return sender.res
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("suggests metrics with the allowed parameters and caches the result", func(t *testing.T) {
+		s, o, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`["cpu.idle","cpu.user"]`))
+		})
+		res := callResource(t, s, pluginCtx, "api/suggest?type=metrics&q=cpu&max=10&secret=1")
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `["cpu.idle","cpu.user"]`, string(res.Body))
+
+		callResource(t, s, pluginCtx, "api/suggest?type=metrics&q=cpu&max=10")
+		require.Len(t, o.requests, 1)
+		assert.Equal(t, "/api/suggest", o.requests[0].Path)
+		assert.Equal(t, url.Values{"type": {"metrics"}, "q": {"cpu"}, "max": {"10"}}, o.requests[0].Query())
+	})
+
+	t.Run("caches only metadata if the identity of the user is forwarded", func(t *testing.T) {
+		for _, jsonData := range []string{`{"oauthPassThru":true}`, `{"keepCookies":["session"]}`} {
+			s, o, pluginCtx := newResourceTestService(t, jsonData, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{}`))
+			})
+			callResource(t, s, pluginCtx, "api/search/lookup?m=cpu")
+			callResource(t, s, pluginCtx, "api/search/lookup?m=cpu")
+			callResource(t, s, pluginCtx, "api/config/filters")
+			callResource(t, s, pluginCtx, "api/config/filters")
+			assert.Len(t, o.requests, 3, jsonData)
+		}
+	})
+
+	t.Run("maps errors of OpenTSDB", func(t *testing.T) {
+		s, o, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("m") == "bad" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"code":400,"message":"No such name for 'metrics': 'bad'"}}`))
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		res := callResource(t, s, pluginCtx, "api/search/lookup?m=bad")
+		assert.Equal(t, http.StatusBadRequest, res.Status)
+		assert.JSONEq(t, `{"message":"request failed, status: 400 Bad Request: No such name for 'metrics': 'bad'"}`, string(res.Body))
+
+		res = callResource(t, s, pluginCtx, "api/search/lookup?m=cpu")
+		assert.Equal(t, http.StatusBadGateway, res.Status)
+		callResource(t, s, pluginCtx, "api/search/lookup?m=cpu")
+		assert.Len(t, o.requests, 3)
+	})
+
+	t.Run("rejects unknown resources", func(t *testing.T) {
+		s, o, pluginCtx := newResourceTestService(t, `{}`, func(w http.ResponseWriter, r *http.Request) {})
+		res := callResource(t, s, pluginCtx, "api/put")
+		assert.Equal(t, http.StatusNotFound, res.Status)
+		assert.Empty(t, o.requests)
+	})
+}
@@ -46,6 +46,8 @@ type QueryData struct {
 	TimeInterval       string
 	enableDataplane    bool
 	exemplarSampler    func() exemplar.Sampler
+	// splitter splits range queries into sub-ranges if configured for the data source.
+	splitter *splitter
 }
 
 func New(
@@ -71,6 +73,11 @@ func New(
 
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
+	splitter, err := newSplitter(settings)
+	if err != nil {
+		return nil, err
+	}
+
 	// standard deviation sampler is the default for backwards compatibility
 	exemplarSampler := exemplar.NewStandardDeviationSampler
 
@@ -88,6 +95,7 @@ func New(
 		URL:                settings.URL,
 		enableDataplane:    features.IsEnabledGlobally(featuremgmt.FlagPrometheusDataplane),
 		exemplarSampler:    exemplarSampler,
+		splitter:           splitter,
 	}, nil
 }
 
@@ -133,7 +141,12 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, headers)
+		var res backend.DataResponse
+		if s.splitter != nil {
+			res = s.splitRangeQuery(traceCtx, client, q, headers)
+		} else {
+			res = s.rangeQuery(traceCtx, client, q, headers)
+		}
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
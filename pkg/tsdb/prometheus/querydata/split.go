@@ -0,0 +1,272 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+const (
+	defaultSplitConcurrency   = 4
+	defaultSplitOverlapWindow = 10 * time.Minute
+	// subRangeCacheTTL is the time that the results of sub-ranges are kept for incremental querying after they
+	// were last used.
+	subRangeCacheTTL = 10 * time.Minute
+	// maxSubRangeCacheItems is the maximum number of results of sub-ranges kept for incremental querying.
+	maxSubRangeCacheItems = 1000
+)
+
+// splitSettings are the settings in the JSON data of the data source for splitting range queries.
+type splitSettings struct {
+	// RangeSplitInterval splits range queries into sub-ranges of this interval, e.g. 1d. Disabled if empty.
+	RangeSplitInterval string `json:"rangeSplitInterval"`
+	// RangeSplitConcurrency is the maximum number of sub-ranges of a query that are queried in parallel.
+	RangeSplitConcurrency int `json:"rangeSplitConcurrency"`
+	// RangeSplitIncremental reuses the results of sub-ranges that were queried before.
+	RangeSplitIncremental bool `json:"rangeSplitIncremental"`
+	// RangeSplitOverlapWindow is the time before now in which results may still change, so sub-ranges that
+	// overlap it are always queried again.
+	RangeSplitOverlapWindow string `json:"rangeSplitOverlapWindow"`
+	// OAuthPassThru and KeepCookies are set if queries are made with the identity of the user, whose results must
+	// not be shared with other users, so incremental querying is disabled.
+	OAuthPassThru bool     `json:"oauthPassThru"`
+	KeepCookies   []string `json:"keepCookies"`
+}
+
+// identityHeaders are the headers that forward the identity of the user. The results of sub-ranges are cached
+// per value of these headers, so they are not shared between users, e.g. if the ID or the login of the user is
+// forwarded to all data sources.
+var identityHeaders = []string{
+	backend.OAuthIdentityTokenHeaderName,
+	backend.OAuthIdentityIDTokenHeaderName,
+	backend.CookiesHeaderName,
+	"X-Grafana-Id",
+	"X-Grafana-User",
+}
+
+// splitter splits range queries into aligned sub-ranges that are queried in parallel and merged into one result.
+type splitter struct {
+	interval      time.Duration
+	concurrency   int
+	overlapWindow time.Duration
+	// cache contains the results of sub-ranges outside of the overlap window if incremental querying is enabled.
+	cache *cache.Cache
+	now   func() time.Time
+}
+
+func newSplitter(settings backend.DataSourceInstanceSettings) (*splitter, error) {
+	var ss splitSettings
+	if len(settings.JSONData) > 0 {
+		if err := json.Unmarshal(settings.JSONData, &ss); err != nil {
+			return nil, err
+		}
+	}
+	if ss.RangeSplitInterval == "" {
+		return nil, nil
+	}
+
+	interval, err := intervalv2.ParseIntervalStringToTimeDuration(ss.RangeSplitInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid range split interval: %w", err)
+	}
+	if interval <= 0 {
+		return nil, nil
+	}
+	s := &splitter{
+		interval:      interval,
+		concurrency:   ss.RangeSplitConcurrency,
+		overlapWindow: defaultSplitOverlapWindow,
+		now:           time.Now,
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = defaultSplitConcurrency
+	}
+	if ss.RangeSplitOverlapWindow != "" {
+		if s.overlapWindow, err = intervalv2.ParseIntervalStringToTimeDuration(ss.RangeSplitOverlapWindow); err != nil {
+			return nil, fmt.Errorf("invalid range split overlap window: %w", err)
+		}
+	}
+	if ss.RangeSplitIncremental && !ss.OAuthPassThru && len(ss.KeepCookies) == 0 {
+		s.cache = cache.New(subRangeCacheTTL, subRangeCacheTTL)
+	}
+	return s, nil
+}
+
+// subRanges returns the sub-ranges of the query. Their boundaries are aligned to the split interval, so the same
+// sub-ranges are queried when the time range of the query moves. As the start and end of range queries are
+// inclusive, each sub-range ends one step before the next one starts.
+func (s *splitter) subRanges(q *models.Query) []*models.Query {
+	tr := q.TimeRange()
+	if tr.Step <= 0 || tr.End.Sub(tr.Start) <= s.interval {
+		return []*models.Query{q}
+	}
+	// The interval must be a multiple of the step, so that the boundaries are aligned to the step.
+	interval := s.interval
+	if rem := interval % tr.Step; rem != 0 {
+		interval += tr.Step - rem
+	}
+
+	var subRanges []*models.Query
+	for start := tr.Start; !start.After(tr.End); {
+		next := models.AlignTimeRange(start, interval, q.UtcOffsetSec).Add(interval)
+		end := next.Add(-tr.Step)
+		if end.After(tr.End) {
+			end = tr.End
+		}
+		sub := *q
+		sub.Start, sub.End = start, end
+		subRanges = append(subRanges, &sub)
+		start = next
+	}
+	return subRanges
+}
+
+// cacheKey returns the key of the result of the sub-range in the cache for the forwarded identity.
+func (s *splitter) cacheKey(q *models.Query, identity string) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%d\x00%d\x00%d", identity, q.RefId, q.Expr, q.LegendFormat, q.Step, q.Start.UnixMilli(), q.End.UnixMilli())
+}
+
+// forwardedIdentity returns a hash of the identity headers in the headers of the request.
+func forwardedIdentity(headers map[string]string) string {
+	req := backend.QueryDataRequest{Headers: headers}
+	h := sha256.New()
+	for _, name := range identityHeaders {
+		_, _ = fmt.Fprintf(h, "%s\x00", req.GetHTTPHeader(name))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheable returns whether the result of the sub-range can be reused, which is the case if it ends before the
+// overlap window.
+func (s *splitter) cacheable(q *models.Query) bool {
+	return s.cache != nil && q.End.Before(s.now().Add(-s.overlapWindow))
+}
+
+// store caches the result of the sub-range, unless the cache is full.
+func (s *splitter) store(q *models.Query, identity string, res backend.DataResponse) {
+	if s.cache.ItemCount() >= maxSubRangeCacheItems {
+		s.cache.DeleteExpired()
+		if s.cache.ItemCount() >= maxSubRangeCacheItems {
+			return
+		}
+	}
+	s.cache.SetDefault(s.cacheKey(q, identity), res)
+}
+
+// splitRangeQuery queries the sub-ranges of the range query with bounded concurrency and merges their results.
+func (s *QueryData) splitRangeQuery(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) backend.DataResponse {
+	subRanges := s.splitter.subRanges(q)
+	if len(subRanges) == 1 {
+		return s.rangeQuery(ctx, c, q, headers)
+	}
+
+	identity := forwardedIdentity(headers)
+	results := make([]backend.DataResponse, len(subRanges))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.splitter.concurrency)
+	for i, sub := range subRanges {
+		i, sub := i, sub
+		if s.splitter.cacheable(sub) {
+			if cached, ok := s.splitter.cache.Get(s.splitter.cacheKey(sub, identity)); ok {
+				results[i] = cached.(backend.DataResponse)
+				continue
+			}
+		}
+		g.Go(func() error {
+			res := s.rangeQuery(gctx, c, sub, headers)
+			if res.Error != nil {
+				return res.Error
+			}
+			if s.splitter.cacheable(sub) {
+				s.splitter.store(sub, identity, res)
+			}
+			results[i] = res
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	s.log.FromContext(ctx).Debug("Merging split range query", "query", q.Expr, "subRanges", len(subRanges))
+	frames := mergeFrames(results)
+	if len(frames) > 0 && frames[0].Meta != nil {
+		frames[0].Meta.ExecutedQueryString = executedQueryString(q) + fmt.Sprintf("\nSplit: %d sub-queries", len(subRanges))
+	}
+	return backend.DataResponse{Frames: frames}
+}
+
+// mergeFrames merges the frames of the results of consecutive sub-ranges. The rows of frames of the same series
+// are appended to a new frame in the order of the sub-ranges, so the frames of the results are not modified.
+func mergeFrames(results []backend.DataResponse) data.Frames {
+	var merged data.Frames
+	index := map[string]*data.Frame{}
+	for _, res := range results {
+		for _, frame := range res.Frames {
+			if len(frame.Fields) == 0 {
+				continue
+			}
+			key := seriesKey(frame)
+			m, ok := index[key]
+			if !ok {
+				m = emptyCopy(frame)
+				index[key] = m
+				merged = append(merged, m)
+			}
+			for i, f := range frame.Fields {
+				for row := 0; row < f.Len(); row++ {
+					m.Fields[i].Append(f.At(row))
+				}
+			}
+		}
+	}
+	if len(merged) == 0 {
+		// Add frame to attach metadata
+		frame := data.NewFrame("")
+		frame.Meta = &data.FrameMeta{}
+		merged = append(merged, frame)
+	}
+	return merged
+}
+
+// seriesKey identifies the series of a frame across the results of sub-ranges.
+func seriesKey(frame *data.Frame) string {
+	key := frame.Name
+	for _, f := range frame.Fields {
+		key += "\x00" + f.Name + "\x00" + f.Type().ItemTypeString() + "\x00" + f.Labels.String()
+	}
+	return key
+}
+
+// emptyCopy returns a frame with the same name, meta and fields as the frame, but without rows.
+func emptyCopy(frame *data.Frame) *data.Frame {
+	c := data.NewFrame(frame.Name)
+	c.RefID = frame.RefID
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		c.Meta = &meta
+	}
+	for _, f := range frame.Fields {
+		field := data.NewFieldFromFieldType(f.Type(), 0)
+		field.Name = f.Name
+		field.Labels = f.Labels.Copy()
+		if f.Config != nil {
+			config := *f.Config
+			field.Config = &config
+		}
+		c.Fields = append(c.Fields, field)
+	}
+	return c
+}
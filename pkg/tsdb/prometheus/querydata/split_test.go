@@ -0,0 +1,219 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+// fakePrometheus returns one sample of the series up{job="a"} per step in the requested range, with the Unix time
+// in seconds as value.
+type fakePrometheus struct {
+	mtx    sync.Mutex
+	ranges [][2]int64
+}
+
+func (p *fakePrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	start, _ := strconv.ParseInt(r.Form.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(r.Form.Get("end"), 10, 64)
+	step, _ := strconv.ParseInt(r.Form.Get("step"), 10, 64)
+	p.mtx.Lock()
+	p.ranges = append(p.ranges, [2]int64{start, end})
+	p.mtx.Unlock()
+
+	values := [][]any{}
+	for ts := start; ts <= end; ts += step {
+		values = append(values, []any{ts, strconv.FormatInt(ts, 10)})
+	}
+	res := map[string]any{
+		"status": "success",
+		"data": map[string]any{
+			"resultType": "matrix",
+			"result":     []any{map[string]any{"metric": map[string]string{"__name__": "up", "job": "a"}, "values": values}},
+		},
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (p *fakePrometheus) queriedRanges() [][2]int64 {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	r := p.ranges
+	p.ranges = nil
+	return r
+}
+
+func newSplitTestQueryData(t *testing.T, jsonData string) (*QueryData, *fakePrometheus) {
+	t.Helper()
+	prom := &fakePrometheus{}
+	srv := httptest.NewServer(prom)
+	t.Cleanup(srv.Close)
+	qd, err := New(srv.Client(), featuremgmt.WithFeatures(), backend.DataSourceInstanceSettings{
+		URL:      srv.URL,
+		JSONData: json.RawMessage(jsonData),
+	}, log.New())
+	require.NoError(t, err)
+	return qd, prom
+}
+
+func rangeQueryRequest(from, to time.Time) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{Queries: []backend.DataQuery{{
+		RefID:     "A",
+		JSON:      []byte(`{"expr":"up","range":true,"interval":"1m"}`),
+		TimeRange: backend.TimeRange{From: from, To: to},
+		Interval:  time.Minute,
+		// Low resolution, so the step is the interval of one minute.
+		MaxDataPoints: 100000,
+	}}}
+}
+
+func TestSplitRangeQuery(t *testing.T) {
+	from := time.Date(2023, 1, 2, 3, 30, 0, 0, time.UTC)
+	to := from.Add(3 * time.Hour)
+
+	t.Run("splits range queries into aligned sub-ranges and merges the results", func(t *testing.T) {
+		qd, prom := newSplitTestQueryData(t, `{"rangeSplitInterval":"1h","rangeSplitConcurrency":2}`)
+		resp, err := qd.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+		res := resp.Responses["A"]
+		require.NoError(t, res.Error)
+
+		ranges := prom.queriedRanges()
+		assert.ElementsMatch(t, [][2]int64{
+			{from.Unix(), from.Add(29 * time.Minute).Unix()},
+			{from.Add(30 * time.Minute).Unix(), from.Add(89 * time.Minute).Unix()},
+			{from.Add(90 * time.Minute).Unix(), from.Add(149 * time.Minute).Unix()},
+			{from.Add(150 * time.Minute).Unix(), to.Unix()},
+		}, ranges)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 181, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			ts := frame.Fields[0].At(i).(time.Time)
+			require.Equal(t, from.Add(time.Duration(i)*time.Minute), ts)
+			require.Equal(t, float64(ts.Unix()), frame.Fields[1].At(i))
+		}
+		assert.Contains(t, frame.Meta.ExecutedQueryString, "Split: 4 sub-queries")
+	})
+
+	t.Run("does not split queries shorter than the interval", func(t *testing.T) {
+		qd, prom := newSplitTestQueryData(t, `{"rangeSplitInterval":"1d"}`)
+		resp, err := qd.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		assert.Len(t, prom.queriedRanges(), 1)
+		assert.Equal(t, 181, resp.Responses["A"].Frames[0].Rows())
+	})
+
+	t.Run("reuses sub-ranges before the overlap window if incremental querying is enabled", func(t *testing.T) {
+		qd, prom := newSplitTestQueryData(t, `{"rangeSplitInterval":"1h","rangeSplitIncremental":true,"rangeSplitOverlapWindow":"10m"}`)
+		now := to
+		qd.splitter.now = func() time.Time { return now }
+
+		resp, err := qd.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		assert.Len(t, prom.queriedRanges(), 4)
+
+		// The first sub-range changes with the time range and the last one overlaps the window.
+		now = to.Add(5 * time.Minute)
+		resp, err = qd.Execute(context.Background(), rangeQueryRequest(from.Add(5*time.Minute), now))
+		require.NoError(t, err)
+		require.NoError(t, resp.Responses["A"].Error)
+		assert.ElementsMatch(t, [][2]int64{
+			{from.Add(5 * time.Minute).Unix(), from.Add(29 * time.Minute).Unix()},
+			{from.Add(150 * time.Minute).Unix(), now.Unix()},
+		}, prom.queriedRanges())
+		frame := resp.Responses["A"].Frames[0]
+		require.Equal(t, 181, frame.Rows())
+		assert.Equal(t, from.Add(5*time.Minute), frame.Fields[0].At(0))
+		assert.Equal(t, now, frame.Fields[0].At(180))
+	})
+
+	t.Run("does not reuse sub-ranges of other forwarded identities", func(t *testing.T) {
+		qd, prom := newSplitTestQueryData(t, `{"rangeSplitInterval":"1h","rangeSplitIncremental":true,"rangeSplitOverlapWindow":"10m"}`)
+		qd.splitter.now = func() time.Time { return to.Add(time.Hour) }
+
+		for i, user := range []string{"alice", "bob", "alice"} {
+			req := rangeQueryRequest(from, to)
+			req.SetHTTPHeader("X-Grafana-User", user)
+			resp, err := qd.Execute(context.Background(), req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Responses["A"].Error)
+			if i < 2 {
+				assert.Len(t, prom.queriedRanges(), 4, user)
+			}
+		}
+		assert.Empty(t, prom.queriedRanges(), "cached sub-ranges of alice")
+	})
+
+	t.Run("fails if a sub-range fails", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"query too large"}`)
+		}))
+		t.Cleanup(srv.Close)
+		qd, err := New(srv.Client(), featuremgmt.WithFeatures(), backend.DataSourceInstanceSettings{
+			URL:      srv.URL,
+			JSONData: json.RawMessage(`{"rangeSplitInterval":"1h"}`),
+		}, log.New())
+		require.NoError(t, err)
+		resp, err := qd.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["A"].Error)
+	})
+}
+
+func TestNewSplitter(t *testing.T) {
+	s, err := newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{}`)})
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"1d"}`)})
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, s.interval)
+	assert.Equal(t, defaultSplitConcurrency, s.concurrency)
+	assert.Nil(t, s.cache)
+
+	s, err = newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"1d","rangeSplitIncremental":true}`)})
+	require.NoError(t, err)
+	assert.NotNil(t, s.cache)
+
+	// The results of queries made with the identity of the user are not shared with other users.
+	s, err = newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"1d","rangeSplitIncremental":true,"oauthPassThru":true}`)})
+	require.NoError(t, err)
+	assert.Nil(t, s.cache)
+	s, err = newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"1d","rangeSplitIncremental":true,"keepCookies":["session"]}`)})
+	require.NoError(t, err)
+	assert.Nil(t, s.cache)
+
+	_, err = newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"often"}`)})
+	require.Error(t, err)
+}
+
+func TestSplitterCacheSize(t *testing.T) {
+	s, err := newSplitter(backend.DataSourceInstanceSettings{JSONData: []byte(`{"rangeSplitInterval":"1h","rangeSplitIncremental":true}`)})
+	require.NoError(t, err)
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < maxSubRangeCacheItems+10; i++ {
+		q := &models.Query{Expr: "up", Step: time.Minute, Start: start.Add(time.Duration(i) * time.Hour), End: start.Add(time.Duration(i+1) * time.Hour)}
+		s.store(q, "", backend.DataResponse{})
+	}
+	assert.Equal(t, maxSubRangeCacheItems, s.cache.ItemCount())
+}
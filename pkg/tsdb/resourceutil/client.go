@@ -0,0 +1,192 @@
+package resourceutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/patrickmn/go-cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+const (
+	// LookupCacheTTL is the time that the results of lookups, e.g. of metrics and tags, are cached
+	LookupCacheTTL = time.Minute
+	// MetadataCacheTTL is the time that the metadata of a data source, e.g. its functions or version, is cached
+	MetadataCacheTTL = time.Hour
+	// maxErrorMessageLength is the maximum length of the part of the body of a failed response that is included in
+	// the error message
+	maxErrorMessageLength = 256
+)
+
+// settings are the settings in the JSON data of a data source that forward the identity of the user.
+type settings struct {
+	OAuthPassThru bool     `json:"oauthPassThru"`
+	KeepCookies   []string `json:"keepCookies"`
+}
+
+// ClientOptions are the optional settings of a Client.
+type ClientOptions struct {
+	// Tracer traces the requests of the client if set.
+	Tracer tracing.Tracer
+	// ErrorMessage returns the message of the error in the body of a failed response. The beginning of the body is
+	// used if it is nil or returns an empty message.
+	ErrorMessage func(body []byte) string
+}
+
+// Client requests resources from the API of a data source and caches their responses.
+type Client struct {
+	name       string
+	url        string
+	id         int64
+	httpClient *http.Client
+	opts       ClientOptions
+	cache      *cache.Cache
+	// forwardIdentity is set if requests are made with the identity of the user, so lookups are not cached
+	forwardIdentity bool
+}
+
+// NewClient creates a client for the resources of the data source. The name of the data source is used in error
+// messages and traces.
+func NewClient(name string, httpClient *http.Client, dsSettings backend.DataSourceInstanceSettings, opts ClientOptions) (*Client, error) {
+	var s settings
+	if len(dsSettings.JSONData) > 0 {
+		if err := json.Unmarshal(dsSettings.JSONData, &s); err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+	}
+	return &Client{
+		name:            name,
+		url:             dsSettings.URL,
+		id:              dsSettings.ID,
+		httpClient:      httpClient,
+		opts:            opts,
+		cache:           cache.New(LookupCacheTTL, 10*time.Minute),
+		forwardIdentity: s.OAuthPassThru || len(s.KeepCookies) > 0,
+	}, nil
+}
+
+// LookupTTL returns the time that the results of lookups are cached, which is zero if the data source forwards the
+// OAuth identity or the cookies of the user, as the results depend on the permissions of the user.
+func (c *Client) LookupTTL() time.Duration {
+	if c.forwardIdentity {
+		return 0
+	}
+	return LookupCacheTTL
+}
+
+// ValidJSON returns the body if it is valid JSON, and an error otherwise.
+func (c *Client) ValidJSON(body []byte) ([]byte, error) {
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("invalid response from %s", c.name)
+	}
+	return body, nil
+}
+
+// Get returns the processed body of the resource from the cache, or fetches the resource and caches the processed
+// body for the TTL if it is not zero. Failed responses are not cached.
+func (c *Client) Get(ctx context.Context, logger log.Logger, resourcePath string, params url.Values, ttl time.Duration, process func([]byte) ([]byte, error)) ([]byte, error) {
+	key := resourcePath + "?" + params.Encode()
+	if ttl > 0 {
+		if body, ok := c.cache.Get(key); ok {
+			return body.([]byte), nil
+		}
+	}
+
+	body, err := c.Fetch(ctx, logger, resourcePath, params)
+	if err != nil {
+		return nil, err
+	}
+	if body, err = process(body); err != nil {
+		return nil, err
+	}
+	if ttl > 0 {
+		c.cache.Set(key, body, ttl)
+	}
+	return body, nil
+}
+
+// Fetch requests the resource at the path relative to the URL of the data source. Failed responses are returned
+// as *Error.
+func (c *Client) Fetch(ctx context.Context, logger log.Logger, resourcePath string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		logger.Info("Failed to create request", "error", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var span trace.Span
+	if c.opts.Tracer != nil {
+		ctx, span = c.opts.Tracer.Start(ctx, strings.ToLower(c.name)+" resource")
+		defer span.End()
+		span.SetAttributes(
+			attribute.String("path", resourcePath),
+			attribute.Int64("datasource_id", c.id),
+		)
+		c.opts.Tracer.Inject(ctx, req.Header, span)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		if span != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	if span != nil {
+		span.SetAttributes(attribute.Int("http.status_code", res.StatusCode))
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		message := c.errorMessage(body)
+		logger.Info("Resource request failed", "path", resourcePath, "status", res.Status, "body", message)
+		err := &Error{Status: res.StatusCode, Message: fmt.Sprintf("request failed, status: %s", res.Status)}
+		if message != "" {
+			err.Message += ": " + message
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+func (c *Client) errorMessage(body []byte) string {
+	if c.opts.ErrorMessage != nil {
+		if message := c.opts.ErrorMessage(body); message != "" {
+			return message
+		}
+	}
+	message := strings.TrimSpace(string(body))
+	if len(message) > maxErrorMessageLength {
+		message = message[:maxErrorMessageLength]
+	}
+	return message
+}
@@ -0,0 +1,111 @@
+package resourceutil
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func newTestClient(t *testing.T, jsonData string, opts ClientOptions, handler http.HandlerFunc) (*Client, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := NewClient("Test", srv.Client(), backend.DataSourceInstanceSettings{URL: srv.URL + "/api", JSONData: json.RawMessage(jsonData)}, opts)
+	require.NoError(t, err)
+	return c, &requests
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	logger := log.NewNopLogger()
+
+	t.Run("fetches resources relative to the URL and caches the processed body", func(t *testing.T) {
+		c, requests := newTestClient(t, `{}`, ClientOptions{}, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/metrics", r.URL.Path)
+			assert.Equal(t, "a", r.URL.Query().Get("q"))
+			_, _ = w.Write([]byte(`["cpu"]`))
+		})
+		for i := 0; i < 2; i++ {
+			body, err := c.Get(ctx, logger, "metrics", url.Values{"q": {"a"}}, c.LookupTTL(), c.ValidJSON)
+			require.NoError(t, err)
+			assert.JSONEq(t, `["cpu"]`, string(body))
+		}
+		assert.Equal(t, int64(1), requests.Load())
+	})
+
+	t.Run("does not cache lookups if the identity of the user is forwarded", func(t *testing.T) {
+		for _, jsonData := range []string{`{"oauthPassThru":true}`, `{"keepCookies":["session"]}`} {
+			c, requests := newTestClient(t, jsonData, ClientOptions{}, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`[]`))
+			})
+			assert.Equal(t, time.Duration(0), c.LookupTTL(), jsonData)
+			for i := 0; i < 2; i++ {
+				_, err := c.Get(ctx, logger, "metrics", url.Values{}, c.LookupTTL(), c.ValidJSON)
+				require.NoError(t, err)
+				_, err = c.Get(ctx, logger, "functions", url.Values{}, MetadataCacheTTL, c.ValidJSON)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, int64(3), requests.Load(), jsonData)
+		}
+	})
+
+	t.Run("rejects invalid JSON", func(t *testing.T) {
+		c, _ := newTestClient(t, `{}`, ClientOptions{}, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html>login</html>`))
+		})
+		_, err := c.Get(ctx, logger, "metrics", url.Values{}, c.LookupTTL(), c.ValidJSON)
+		require.EqualError(t, err, "invalid response from Test")
+	})
+
+	t.Run("returns failed responses as errors with the beginning of the body", func(t *testing.T) {
+		c, requests := newTestClient(t, `{}`, ClientOptions{}, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, strings.Repeat("x", 2*maxErrorMessageLength), http.StatusBadRequest)
+		})
+		for i := 0; i < 2; i++ {
+			_, err := c.Get(ctx, logger, "metrics", url.Values{}, c.LookupTTL(), c.ValidJSON)
+			var resErr *Error
+			require.ErrorAs(t, err, &resErr)
+			assert.Equal(t, http.StatusBadRequest, resErr.Status)
+			assert.Equal(t, "request failed, status: 400 Bad Request: "+strings.Repeat("x", maxErrorMessageLength), resErr.Message)
+		}
+		assert.Equal(t, int64(2), requests.Load(), "errors are not cached")
+	})
+
+	t.Run("uses the error message of the data source", func(t *testing.T) {
+		opts := ClientOptions{ErrorMessage: func(body []byte) string {
+			if strings.HasPrefix(string(body), "{") {
+				return "message"
+			}
+			return ""
+		}}
+		c, _ := newTestClient(t, `{}`, opts, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(r.URL.Query().Get("body")))
+		})
+		_, err := c.Fetch(ctx, logger, "metrics", url.Values{"body": {`{"error":{}}`}})
+		assert.EqualError(t, err, "request failed, status: 400 Bad Request: message")
+		_, err = c.Fetch(ctx, logger, "metrics", url.Values{"body": {"plain"}})
+		assert.EqualError(t, err, "request failed, status: 400 Bad Request: plain")
+	})
+
+	t.Run("fails for invalid settings", func(t *testing.T) {
+		_, err := NewClient("Test", http.DefaultClient, backend.DataSourceInstanceSettings{JSONData: json.RawMessage(`{"keepCookies":"session"}`)}, ClientOptions{})
+		require.Error(t, err)
+	})
+}
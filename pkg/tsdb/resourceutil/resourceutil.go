@@ -0,0 +1,46 @@
+// Package resourceutil contains helpers for the resource handlers of data sources that pass on requests to the
+// API of the data source.
+package resourceutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// Error is a failed response from the API of a data source
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// WriteError writes the error as JSON. Client errors of the data source are passed on, other errors of the data
+// source and failed requests are reported as a bad gateway.
+func WriteError(rw http.ResponseWriter, logger log.Logger, err error) {
+	status := http.StatusBadGateway
+	var resErr *Error
+	switch {
+	case errors.As(err, &resErr) && resErr.Status/100 == 4:
+		status = resErr.Status
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+	body, _ := json.Marshal(map[string]string{"message": err.Error()})
+	WriteResponse(rw, logger, status, body)
+}
+
+// WriteResponse writes the JSON body with the status.
+func WriteResponse(rw http.ResponseWriter, logger log.Logger, status int, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package resourceutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestWriteError(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+	}{
+		{err: &Error{Status: http.StatusNotFound, Message: "not found"}, status: http.StatusNotFound},
+		{err: fmt.Errorf("lookup: %w", &Error{Status: http.StatusBadRequest, Message: "bad query"}), status: http.StatusBadRequest},
+		{err: &Error{Status: http.StatusInternalServerError, Message: "failed"}, status: http.StatusBadGateway},
+		{err: fmt.Errorf("request: %w", context.DeadlineExceeded), status: http.StatusGatewayTimeout},
+		{err: errors.New("connection refused"), status: http.StatusBadGateway},
+	} {
+		rw := httptest.NewRecorder()
+		WriteError(rw, log.NewNopLogger(), tc.err)
+		assert.Equal(t, tc.status, rw.Code)
+		assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+		assert.JSONEq(t, fmt.Sprintf(`{"message":%q}`, tc.err.Error()), rw.Body.String())
+	}
+}
@@ -0,0 +1,197 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+// cancelQueryTimeout is the maximum time to wait for a query to be canceled on the server.
+const cancelQueryTimeout = 5 * time.Second
+
+// QueryCanceler can be implemented by a SqlQueryResultTransformer of a driver that does not cancel a query on the
+// server when its context is canceled. The query then runs on a dedicated connection that is canceled explicitly.
+type QueryCanceler interface {
+	// ConnectionID returns the ID of the connection on the server.
+	ConnectionID(ctx context.Context, conn *sql.Conn) (string, error)
+	// CancelQuery cancels the query that runs on the connection with the given ID.
+	CancelQuery(ctx context.Context, db *sql.DB, connectionID string) error
+}
+
+// queryRows runs the query. The returned function must be called after the rows are closed.
+func (e *DataSourceHandler) queryRows(ctx context.Context, query string) (*sql.Rows, func(), error) {
+	canceler, ok := e.queryResultTransformer.(QueryCanceler)
+	if !ok {
+		rows, err := e.db.QueryContext(ctx, query)
+		return rows, func() {}, err
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := canceler.ConnectionID(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+
+	// The query must not be canceled after the connection was released, as it may run another query by then.
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-stop:
+			// The context may have been canceled before the rows were read completely.
+			if ctx.Err() == nil {
+				return
+			}
+		case <-ctx.Done():
+		}
+		cancelCtx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+		defer cancel()
+		if err := canceler.CancelQuery(cancelCtx, e.db, id); err != nil {
+			e.log.Warn("Failed to cancel query", "connectionId", id, "error", err)
+		}
+	}()
+	release := func() {
+		close(stop)
+		<-done
+		if err := conn.Close(); err != nil {
+			e.log.Warn("Failed to close connection", "err", err)
+		}
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return rows, release, nil
+}
+
+// frameFromRows converts the rows to a frame, one row at a time. It stops reading rows at the row limit or once
+// the values of the rows exceed the bytes limit, and adds a notice that the result was truncated to the frame.
+// It returns whether the result was truncated, so the caller can cancel the remaining query.
+// The bytes limit does not apply to dynamic converters, as the SDK detects their types from the whole result.
+func frameFromRows(rows *sql.Rows, rowLimit int64, bytesLimit int64, converters ...sqlutil.Converter) (*data.Frame, bool, error) {
+	for _, c := range converters {
+		if c.Dynamic {
+			return dynamicFrameFromRows(rows, rowLimit, converters...)
+		}
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, false, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, false, err
+	}
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, false, err
+	}
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var count, size int64
+	for {
+		for rows.Next() {
+			if count == rowLimit {
+				appendTruncatedNotice(frame, count, rowLimitReason(rowLimit))
+				return frame, true, nil
+			}
+
+			r := scanRow.NewScannableRow()
+			if err := rows.Scan(r...); err != nil {
+				return nil, false, err
+			}
+			if bytesLimit > 0 {
+				size += rowSize(r)
+				if size > bytesLimit {
+					appendTruncatedNotice(frame, count, fmt.Sprintf("the result exceeded the limit of %d bytes", bytesLimit))
+					return frame, true, nil
+				}
+			}
+			if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+				return nil, false, err
+			}
+			count++
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	return frame, false, rows.Err()
+}
+
+// dynamicFrameFromRows converts the rows to a frame with the SDK, which detects the types of the columns from their
+// values. One row more than the limit is read to know whether the result is truncated, it is removed from the frame.
+func dynamicFrameFromRows(rows *sql.Rows, rowLimit int64, converters ...sqlutil.Converter) (*data.Frame, bool, error) {
+	limit := rowLimit
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	frame, err := sqlutil.FrameFromRows(rows, limit, converters...)
+	if err != nil || int64(frame.Rows()) <= rowLimit {
+		return frame, false, err
+	}
+	frame.DeleteRow(frame.Rows() - 1)
+	appendTruncatedNotice(frame, rowLimit, rowLimitReason(rowLimit))
+	return frame, true, nil
+}
+
+func rowLimitReason(rowLimit int64) string {
+	return fmt.Sprintf("the row limit of %d rows was reached", rowLimit)
+}
+
+func appendTruncatedNotice(frame *data.Frame, rows int64, reason string) {
+	frame.AppendNotices(data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("Result truncated to %d rows because %s", rows, reason),
+	})
+}
+
+// rowSize estimates the memory used by the scanned values of a row.
+func rowSize(row []any) int64 {
+	var size int64
+	for _, v := range row {
+		size += valueSize(reflect.ValueOf(v))
+	}
+	return size
+}
+
+func valueSize(v reflect.Value) int64 {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 8
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		return int64(v.Len()) * int64(v.Type().Elem().Size())
+	case reflect.Struct:
+		// Null types of the sql package, e.g. sql.NullString.
+		size := int64(v.Type().Size())
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Field(i); f.Kind() == reflect.String {
+				size += int64(f.Len())
+			}
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
@@ -0,0 +1,186 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type cancelingQueryResultTransformer struct {
+	mtx      sync.Mutex
+	canceled []string
+}
+
+func (t *cancelingQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *cancelingQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+func (t *cancelingQueryResultTransformer) ConnectionID(ctx context.Context, conn *sql.Conn) (string, error) {
+	var id string
+	err := conn.QueryRowContext(ctx, "SELECT '42'").Scan(&id)
+	return id, err
+}
+
+func (t *cancelingQueryResultTransformer) CancelQuery(_ context.Context, _ *sql.DB, connectionID string) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.canceled = append(t.canceled, connectionID)
+	return nil
+}
+
+func (t *cancelingQueryResultTransformer) canceledIDs() []string {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.canceled
+}
+
+type testMacroEngine struct{}
+
+func (testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func newRowsTestHandler(t *testing.T, jsonData JsonData, transformer SqlQueryResultTransformer) *DataSourceHandler {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file::memory:?cache=shared")
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS logs (id INTEGER, line TEXT);
+		DELETE FROM logs;
+		INSERT INTO logs VALUES (1, 'first'), (2, 'second'), (3, 'third'), (4, 'fourth');`)
+	require.NoError(t, err)
+
+	config := DataPluginConfiguration{DSInfo: DataSourceInfo{JsonData: jsonData}, RowLimit: 1000}
+	handler, err := NewQueryDataHandler(setting.NewCfg(), db, config, transformer, testMacroEngine{}, backend.NewLoggerWith("logger", "test"))
+	require.NoError(t, err)
+	t.Cleanup(handler.Dispose)
+	return handler
+}
+
+func queryTable(t *testing.T, handler *DataSourceHandler, rawSQL string) backend.DataResponse {
+	t.Helper()
+	model, err := json.Marshal(map[string]any{"rawSql": rawSQL, "format": "table"})
+	require.NoError(t, err)
+	resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{RefID: "A", JSON: model, Interval: time.Minute}},
+	})
+	require.NoError(t, err)
+	return resp.Responses["A"]
+}
+
+func TestResultLimits(t *testing.T) {
+	t.Run("returns all rows below the limits", func(t *testing.T) {
+		handler := newRowsTestHandler(t, JsonData{}, &cancelingQueryResultTransformer{})
+		res := queryTable(t, handler, `SELECT id, line FROM logs ORDER BY id`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 4, res.Frames[0].Rows())
+		assert.Empty(t, res.Frames[0].Meta.Notices)
+	})
+
+	t.Run("the row limit of the data source overrides the default", func(t *testing.T) {
+		transformer := &cancelingQueryResultTransformer{}
+		handler := newRowsTestHandler(t, JsonData{RowLimit: 2}, transformer)
+		res := queryTable(t, handler, `SELECT id, line FROM logs ORDER BY id`)
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, "Result truncated to 2 rows because the row limit of 2 rows was reached", frame.Meta.Notices[0].Text)
+		// The remaining query is canceled.
+		assert.Equal(t, []string{"42"}, transformer.canceledIDs())
+	})
+
+	t.Run("truncates results that exceed the bytes limit", func(t *testing.T) {
+		handler := newRowsTestHandler(t, JsonData{ResultBytesLimit: 100}, &cancelingQueryResultTransformer{})
+		res := queryTable(t, handler, `SELECT id, line FROM logs ORDER BY id`)
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Less(t, frame.Rows(), 4)
+		require.Greater(t, frame.Rows(), 0)
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.True(t, strings.HasSuffix(frame.Meta.Notices[0].Text, "because the result exceeded the limit of 100 bytes"))
+	})
+}
+
+func TestFrameFromRows(t *testing.T) {
+	handler := newRowsTestHandler(t, JsonData{}, &cancelingQueryResultTransformer{})
+	dynamic := sqlutil.Converter{Dynamic: true}
+
+	for _, tc := range []struct {
+		name      string
+		limit     int64
+		rows      int
+		truncated bool
+	}{
+		{name: "below the row limit", limit: 5, rows: 4},
+		{name: "at the row limit", limit: 4, rows: 4},
+		{name: "above the row limit", limit: 3, rows: 3, truncated: true},
+	} {
+		t.Run("dynamic converters "+tc.name, func(t *testing.T) {
+			rows, err := handler.db.Query(`SELECT id, line FROM logs ORDER BY id`)
+			require.NoError(t, err)
+			defer func() { _ = rows.Close() }()
+
+			frame, truncated, err := frameFromRows(rows, tc.limit, 0, dynamic)
+			require.NoError(t, err)
+			require.Equal(t, tc.truncated, truncated)
+			require.Equal(t, tc.rows, frame.Rows())
+			if tc.truncated {
+				require.Len(t, frame.Meta.Notices, 1)
+				assert.Equal(t, "Result truncated to 3 rows because the row limit of 3 rows was reached", frame.Meta.Notices[0].Text)
+			}
+		})
+	}
+}
+
+func TestQueryRows(t *testing.T) {
+	t.Run("cancels the query on the server if the context is canceled", func(t *testing.T) {
+		transformer := &cancelingQueryResultTransformer{}
+		handler := newRowsTestHandler(t, JsonData{}, transformer)
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, release, err := handler.queryRows(ctx, `SELECT id FROM logs`)
+		require.NoError(t, err)
+		cancel()
+		require.NoError(t, rows.Close())
+		release()
+		assert.Equal(t, []string{"42"}, transformer.canceledIDs())
+	})
+
+	t.Run("does not cancel completed queries", func(t *testing.T) {
+		transformer := &cancelingQueryResultTransformer{}
+		handler := newRowsTestHandler(t, JsonData{}, transformer)
+		ctx, cancel := context.WithCancel(context.Background())
+		rows, release, err := handler.queryRows(ctx, `SELECT id FROM logs`)
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+		release()
+		cancel()
+		assert.Empty(t, transformer.canceledIDs())
+	})
+}
+
+func TestValueSize(t *testing.T) {
+	s := "hello"
+	var nilString *string
+	assert.Equal(t, int64(5), rowSize([]any{&s}))
+	assert.Equal(t, int64(8), rowSize([]any{&nilString}))
+	assert.Equal(t, int64(3), rowSize([]any{&[]byte{1, 2, 3}}))
+	assert.Equal(t, int64(8), rowSize([]any{new(float64)}))
+	ns := sql.NullString{String: "hello", Valid: true}
+	assert.Greater(t, rowSize([]any{&ns}), int64(5))
+}
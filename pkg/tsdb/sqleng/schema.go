@@ -0,0 +1,319 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// defaultSchemaCacheTTL is used if the data source does not configure how long schema information is cached.
+const defaultSchemaCacheTTL = 5 * time.Minute
+
+// maxSchemaCacheEntries is the number of results that are cached per data source. Every table has its own
+// results, so the cache of a database with many tables is bounded by evicting the results that expire first.
+const maxSchemaCacheEntries = 1000
+
+// SchemaQueries are the dialect specific queries used to introspect the schema of a database. The queries use
+// the placeholders of the driver: the schema is the first argument and the table is the second argument.
+type SchemaQueries struct {
+	// CurrentSchema returns the name of the schema that is used if the request does not name one.
+	CurrentSchema string
+	// Schemas returns the names of the schemas that can be queried.
+	Schemas string
+	// Tables returns the names of the tables and views of a schema.
+	Tables string
+	// Columns returns the name and type of the columns of a table, in their order in the table.
+	Columns string
+	// Indexes returns one row per indexed column with the name of the index, the name of the column and whether
+	// the index is unique. The columns of an index must be in their order in the index.
+	Indexes string
+}
+
+type Column struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+type schemaCacheEntry struct {
+	value   any
+	expires time.Time
+}
+
+// schemaCache caches the results of the schema queries, so the query editor does not query the database
+// every time it renders the builder or completes a query.
+type schemaCache struct {
+	ttl     time.Duration
+	mtx     sync.Mutex
+	entries map[string]schemaCacheEntry
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	return &schemaCache{ttl: ttl, entries: map[string]schemaCacheEntry{}}
+}
+
+func (c *schemaCache) get(key string) (any, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *schemaCache) set(key string, value any) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxSchemaCacheEntries {
+		c.evict(now)
+	}
+	c.entries[key] = schemaCacheEntry{value: value, expires: now.Add(c.ttl)}
+}
+
+// evict removes the expired entries, or the entry that expires first if none has expired.
+// It must be called with the lock held.
+func (c *schemaCache) evict(now time.Time) {
+	var first string
+	var firstExpires time.Time
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		if first == "" || entry.expires.Before(firstExpires) {
+			first, firstExpires = key, entry.expires
+		}
+	}
+	if len(c.entries) >= maxSchemaCacheEntries {
+		delete(c.entries, first)
+	}
+}
+
+// CallResource serves the schema of the database to the query editor:
+//
+//   - GET /schemas lists the schemas
+//   - GET /tables?schema=<schema> lists the tables of a schema
+//   - GET /columns?schema=<schema>&table=<table> lists the columns of a table with their types
+//   - GET /indexes?schema=<schema>&table=<table> lists the indexes of a table
+//
+// The schema defaults to the current schema of the connection. If the data source restricts the allowed schemas,
+// other schemas are neither listed nor introspected. The results are cached, refresh=true bypasses the cache.
+func (e *DataSourceHandler) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return e.resourceHandler.CallResource(ctx, req, sender)
+}
+
+func (e *DataSourceHandler) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schemas", e.handleSchemaRequest(false, e.getSchemas))
+	mux.HandleFunc("/tables", e.handleSchemaRequest(true, e.getTables))
+	mux.HandleFunc("/columns", e.handleSchemaRequest(true, e.getColumns))
+	mux.HandleFunc("/indexes", e.handleSchemaRequest(true, e.getIndexes))
+	return mux
+}
+
+type schemaRequest struct {
+	schema  string
+	table   string
+	refresh bool
+}
+
+var (
+	// errSchemaNotAllowed is returned for schemas that are not allowed by the data source.
+	errSchemaNotAllowed = errors.New("schema is not allowed")
+	errMissingTable     = errors.New("missing table parameter")
+	// errNoCurrentSchema is returned if the request does not name a schema and the connection has no current
+	// schema, e.g. if no schema of the search path of a PostgreSQL connection exists.
+	errNoCurrentSchema = errors.New("the connection has no current schema, set the schema parameter")
+)
+
+// handleSchemaRequest handles a request for schema information. If the request is for a schema, the schema
+// defaults to the current schema and must be allowed by the data source.
+func (e *DataSourceHandler) handleSchemaRequest(inSchema bool, fn func(ctx context.Context, req schemaRequest) (any, error)) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeSchemaResponse(rw, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		if e.schemaQueries == nil {
+			writeSchemaResponse(rw, http.StatusNotImplemented, map[string]string{"error": "schema introspection is not supported"})
+			return
+		}
+
+		params := req.URL.Query()
+		sreq := schemaRequest{
+			schema:  params.Get("schema"),
+			table:   params.Get("table"),
+			refresh: params.Get("refresh") == "true",
+		}
+		if inSchema && sreq.schema == "" {
+			schema, err := e.currentSchema(req.Context())
+			if errors.Is(err, errNoCurrentSchema) {
+				writeSchemaResponse(rw, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				e.log.Error("Failed to get current schema", "error", err)
+				writeSchemaResponse(rw, http.StatusInternalServerError, map[string]string{"error": e.TransformQueryError(e.log, err).Error()})
+				return
+			}
+			sreq.schema = schema
+		}
+		if inSchema && !e.isSchemaAllowed(sreq.schema) {
+			writeSchemaResponse(rw, http.StatusForbidden, map[string]string{"error": errSchemaNotAllowed.Error()})
+			return
+		}
+
+		key := fmt.Sprintf("%s\x00%s\x00%s", req.URL.Path, sreq.schema, sreq.table)
+		if !sreq.refresh {
+			if value, ok := e.schemaCache.get(key); ok {
+				writeSchemaResponse(rw, http.StatusOK, value)
+				return
+			}
+		}
+
+		value, err := fn(req.Context(), sreq)
+		if err != nil {
+			e.log.Error("Failed to introspect schema", "path", req.URL.Path, "schema", sreq.schema, "table", sreq.table, "error", err)
+			status := http.StatusInternalServerError
+			if errors.Is(err, errMissingTable) {
+				status = http.StatusBadRequest
+			} else {
+				err = e.TransformQueryError(e.log, err)
+			}
+			writeSchemaResponse(rw, status, map[string]string{"error": err.Error()})
+			return
+		}
+		e.schemaCache.set(key, value)
+		writeSchemaResponse(rw, http.StatusOK, value)
+	}
+}
+
+func writeSchemaResponse(rw http.ResponseWriter, status int, value any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = []byte(`{"error":"failed to encode response"}`)
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
+
+func (e *DataSourceHandler) isSchemaAllowed(schema string) bool {
+	return len(e.dsInfo.JsonData.AllowedSchemas) == 0 || slices.Contains(e.dsInfo.JsonData.AllowedSchemas, schema)
+}
+
+func (e *DataSourceHandler) currentSchema(ctx context.Context) (string, error) {
+	if value, ok := e.schemaCache.get("current"); ok {
+		return value.(string), nil
+	}
+	var schema sql.NullString
+	if err := e.db.QueryRowContext(ctx, e.schemaQueries.CurrentSchema).Scan(&schema); err != nil {
+		return "", err
+	}
+	if !schema.Valid {
+		return "", errNoCurrentSchema
+	}
+	e.schemaCache.set("current", schema.String)
+	return schema.String, nil
+}
+
+func (e *DataSourceHandler) getSchemas(ctx context.Context, _ schemaRequest) (any, error) {
+	names, err := e.queryNames(ctx, e.schemaQueries.Schemas)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return !e.isSchemaAllowed(name) }), nil
+}
+
+func (e *DataSourceHandler) getTables(ctx context.Context, req schemaRequest) (any, error) {
+	return e.queryNames(ctx, e.schemaQueries.Tables, req.schema)
+}
+
+func (e *DataSourceHandler) getColumns(ctx context.Context, req schemaRequest) (any, error) {
+	if req.table == "" {
+		return nil, errMissingTable
+	}
+	rows, err := e.db.QueryContext(ctx, e.schemaQueries.Columns, req.schema, req.table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns := []Column{}
+	for rows.Next() {
+		var column Column
+		if err := rows.Scan(&column.Name, &column.Type); err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func (e *DataSourceHandler) getIndexes(ctx context.Context, req schemaRequest) (any, error) {
+	if req.table == "" {
+		return nil, errMissingTable
+	}
+	rows, err := e.db.QueryContext(ctx, e.schemaQueries.Indexes, req.schema, req.table)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	indexes := []Index{}
+	for rows.Next() {
+		var name string
+		// The column is NULL for expressions in the index.
+		var column *string
+		var unique bool
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name, Columns: []string{}, Unique: unique})
+		}
+		if column != nil {
+			last := &indexes[len(indexes)-1]
+			last.Columns = append(last.Columns, *column)
+		}
+	}
+	return indexes, rows.Err()
+}
+
+func (e *DataSourceHandler) queryNames(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := e.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
@@ -0,0 +1,179 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var testSchemaQueries = &SchemaQueries{
+	CurrentSchema: `SELECT 'main'`,
+	Schemas:       `SELECT name FROM pragma_database_list ORDER BY name`,
+	Tables:        `SELECT name FROM pragma_table_list WHERE schema = ?1 AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+	Columns:       `SELECT name, type FROM pragma_table_info(?2, ?1) ORDER BY cid`,
+	Indexes: `SELECT il.name, ii.name, il."unique" FROM pragma_index_list(?2, ?1) il, pragma_index_info(il.name, ?1) ii
+		ORDER BY il.name, ii.seqno`,
+}
+
+func newSchemaTestHandler(t *testing.T, jsonData JsonData, queries *SchemaQueries) (*DataSourceHandler, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file::memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	_, err = db.Exec(`CREATE TABLE metrics (time DATETIME, host TEXT, value REAL);
+		CREATE UNIQUE INDEX metrics_time_host ON metrics (time, host);
+		CREATE INDEX metrics_value ON metrics (value);
+		CREATE VIEW hosts AS SELECT DISTINCT host FROM metrics;`)
+	require.NoError(t, err)
+
+	config := DataPluginConfiguration{DSInfo: DataSourceInfo{JsonData: jsonData}, SchemaQueries: queries}
+	handler, err := NewQueryDataHandler(setting.NewCfg(), db, config, nil, nil, backend.NewLoggerWith("logger", "test"))
+	require.NoError(t, err)
+	t.Cleanup(handler.Dispose)
+	return handler, db
+}
+
+type fakeResourceSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
+
+func callSchemaResource(t *testing.T, handler *DataSourceHandler, path string, v any) int {
+	t.Helper()
+	sender := &fakeResourceSender{}
+	u, err := url.Parse(path)
+	require.NoError(t, err)
+	err = handler.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: u.Path, URL: path}, sender)
+	require.NoError(t, err)
+	res := sender.res
+	require.NotNil(t, res)
+	if res.Status == http.StatusOK {
+		require.NoError(t, json.Unmarshal(res.Body, v))
+	}
+	return res.Status
+}
+
+func TestSchemaResources(t *testing.T) {
+	handler, db := newSchemaTestHandler(t, JsonData{}, testSchemaQueries)
+
+	t.Run("lists schemas", func(t *testing.T) {
+		var schemas []string
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "schemas", &schemas))
+		assert.Equal(t, []string{"main"}, schemas)
+	})
+
+	t.Run("lists tables of the current schema", func(t *testing.T) {
+		var tables []string
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "tables", &tables))
+		assert.Equal(t, []string{"hosts", "metrics"}, tables)
+	})
+
+	t.Run("lists columns with types", func(t *testing.T) {
+		var columns []Column
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "columns?schema=main&table=metrics", &columns))
+		assert.Equal(t, []Column{{Name: "time", Type: "DATETIME"}, {Name: "host", Type: "TEXT"}, {Name: "value", Type: "REAL"}}, columns)
+	})
+
+	t.Run("lists indexes with their columns", func(t *testing.T) {
+		var indexes []Index
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "indexes?table=metrics", &indexes))
+		assert.Equal(t, []Index{
+			{Name: "metrics_time_host", Columns: []string{"time", "host"}, Unique: true},
+			{Name: "metrics_value", Columns: []string{"value"}},
+		}, indexes)
+	})
+
+	t.Run("fails without a table", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, callSchemaResource(t, handler, "columns", nil))
+	})
+
+	t.Run("caches results until refreshed", func(t *testing.T) {
+		_, err := db.Exec(`CREATE TABLE events (time DATETIME)`)
+		require.NoError(t, err)
+
+		var tables []string
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "tables", &tables))
+		assert.Equal(t, []string{"hosts", "metrics"}, tables)
+
+		require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "tables?refresh=true", &tables))
+		assert.Equal(t, []string{"events", "hosts", "metrics"}, tables)
+	})
+}
+
+func TestSchemaResourcesAllowedSchemas(t *testing.T) {
+	handler, _ := newSchemaTestHandler(t, JsonData{AllowedSchemas: []string{"temp"}, SchemaCacheTTL: -1}, testSchemaQueries)
+
+	var schemas []string
+	require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "schemas", &schemas))
+	assert.Empty(t, schemas)
+
+	for _, path := range []string{"tables", "tables?schema=main", "columns?schema=main&table=metrics", "indexes?table=metrics"} {
+		assert.Equal(t, http.StatusForbidden, callSchemaResource(t, handler, path, nil), path)
+	}
+}
+
+func TestSchemaResourcesNotSupported(t *testing.T) {
+	handler, _ := newSchemaTestHandler(t, JsonData{}, nil)
+	assert.Equal(t, http.StatusNotImplemented, callSchemaResource(t, handler, "tables", nil))
+}
+
+func TestSchemaResourcesNoCurrentSchema(t *testing.T) {
+	queries := *testSchemaQueries
+	queries.CurrentSchema = `SELECT NULL`
+	handler, _ := newSchemaTestHandler(t, JsonData{}, &queries)
+
+	assert.Equal(t, http.StatusBadRequest, callSchemaResource(t, handler, "tables", nil))
+	var tables []string
+	require.Equal(t, http.StatusOK, callSchemaResource(t, handler, "tables?schema=main", &tables))
+	assert.Equal(t, []string{"hosts", "metrics"}, tables)
+}
+
+func TestSchemaCache(t *testing.T) {
+	t.Run("evicts the entries that expire first when full", func(t *testing.T) {
+		cache := newSchemaCache(time.Hour)
+		for i := 0; i < maxSchemaCacheEntries; i++ {
+			cache.set(fmt.Sprint(i), i)
+		}
+		cache.entries["0"] = schemaCacheEntry{value: 0, expires: time.Now().Add(time.Minute)}
+		cache.set("new", "value")
+		assert.Len(t, cache.entries, maxSchemaCacheEntries)
+		_, ok := cache.get("0")
+		assert.False(t, ok)
+		value, ok := cache.get("new")
+		require.True(t, ok)
+		assert.Equal(t, "value", value)
+	})
+
+	t.Run("removes expired entries when full", func(t *testing.T) {
+		cache := newSchemaCache(time.Hour)
+		for i := 0; i < maxSchemaCacheEntries; i++ {
+			cache.set(fmt.Sprint(i), i)
+		}
+		for key, entry := range cache.entries {
+			if key != "0" {
+				entry.expires = time.Now().Add(-time.Second)
+				cache.entries[key] = entry
+			}
+		}
+		cache.set("new", "value")
+		assert.Len(t, cache.entries, 2)
+		_, ok := cache.get("0")
+		assert.True(t, ok)
+	})
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/go-stack/stack"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 
@@ -42,6 +43,12 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryResultConverters can be implemented by a SqlQueryResultTransformer that needs converters other than
+// string converters, e.g. dynamic converters for drivers that do not report the types of computed columns.
+type SqlQueryResultConverters interface {
+	GetConverters() []sqlutil.Converter
+}
+
 var sqlIntervalCalculator = intervalv2.NewCalculator()
 
 type JsonData struct {
@@ -65,6 +72,15 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// AllowedSchemas restricts the schemas that the query editor can list and introspect. All schemas are allowed if empty.
+	AllowedSchemas []string `json:"allowedSchemas"`
+	// SchemaCacheTTL is the number of seconds that schema information is cached. Negative values disable the cache.
+	SchemaCacheTTL int `json:"schemaCacheTTL"`
+	// RowLimit overrides the default maximum number of rows of a query result if greater than zero.
+	RowLimit int64 `json:"rowLimit"`
+	// ResultBytesLimit overrides the default maximum size of the values of a query result if not zero.
+	// Negative values disable the limit.
+	ResultBytesLimit int64 `json:"resultBytesLimit"`
 }
 
 type DataSourceInfo struct {
@@ -83,6 +99,8 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// SchemaQueries enables the schema resources of the data source. The resources are not available if nil.
+	SchemaQueries *SchemaQueries
 }
 
 type DataSourceHandler struct {
@@ -94,7 +112,11 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	resultBytesLimit       int64
 	userError              string
+	schemaQueries          *SchemaQueries
+	schemaCache            *schemaCache
+	resourceHandler        backend.CallResourceHandler
 }
 
 type QueryJson struct {
@@ -129,9 +151,25 @@ func NewQueryDataHandler(cfg *setting.Cfg, db *sql.DB, config DataPluginConfigur
 		log:                    log,
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
+		resultBytesLimit:       cfg.SqlDatasourceResultBytesLimit,
 		userError:              cfg.UserFacingDefaultError,
+		schemaQueries:          config.SchemaQueries,
+	}
+
+	if config.DSInfo.JsonData.RowLimit > 0 {
+		queryDataHandler.rowLimit = config.DSInfo.JsonData.RowLimit
+	}
+	if config.DSInfo.JsonData.ResultBytesLimit != 0 {
+		queryDataHandler.resultBytesLimit = config.DSInfo.JsonData.ResultBytesLimit
 	}
 
+	schemaCacheTTL := defaultSchemaCacheTTL
+	if config.DSInfo.JsonData.SchemaCacheTTL != 0 {
+		schemaCacheTTL = time.Duration(config.DSInfo.JsonData.SchemaCacheTTL) * time.Second
+	}
+	queryDataHandler.schemaCache = newSchemaCache(schemaCacheTTL)
+	queryDataHandler.resourceHandler = httpadapter.New(queryDataHandler.newResourceMux())
+
 	if len(config.TimeColumnNames) > 0 {
 		queryDataHandler.timeColumnNames = config.TimeColumnNames
 	}
@@ -257,11 +295,16 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
-	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
+	// The query is canceled if the request is canceled, or once the result is truncated, so the server does
+	// not keep sending rows that are never read.
+	queryContext, cancelQuery := context.WithCancel(queryContext)
+	defer cancelQuery()
+	rows, release, err := e.queryRows(queryContext, interpolatedQuery)
 	if err != nil {
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
 		return
 	}
+	defer release()
 	defer func() {
 		if err := rows.Close(); err != nil {
 			logger.Warn("Failed to close rows", "err", err)
@@ -276,11 +319,18 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	converters := sqlutil.ToConverters(stringConverters...)
+	if t, ok := e.queryResultTransformer.(SqlQueryResultConverters); ok {
+		converters = append(converters, t.GetConverters()...)
+	}
+	frame, truncated, err := frameFromRows(rows, e.rowLimit, e.resultBytesLimit, converters...)
 	if err != nil {
-		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
+		errAppendDebug("convert frame from rows error", e.TransformQueryError(logger, err), interpolatedQuery)
 		return
 	}
+	if truncated {
+		cancelQuery()
+	}
 
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
@@ -0,0 +1,560 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/apache/arrow/go/v13/parquet"
+	"github.com/apache/arrow/go/v13/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	errLocalFilesDisabled = errors.New("local files are not enabled, set local_files_path in the [sql_datasources] section of the configuration")
+	errPathNotAllowed     = errors.New("path is outside of the allowed directory")
+)
+
+const (
+	extCSV     = ".csv"
+	extParquet = ".parquet"
+)
+
+// The types of the columns of tables that are loaded from files. Columns of type DATETIME are stored as text in
+// storedTimeFormat, which the SQLite driver converts to time.Time when the column is scanned.
+const (
+	typeInteger  = "INTEGER"
+	typeReal     = "REAL"
+	typeText     = "TEXT"
+	typeBoolean  = "BOOLEAN"
+	typeDatetime = "DATETIME"
+)
+
+const storedTimeFormat = "2006-01-02 15:04:05.000"
+
+// csvTimeFormats are the formats of the values of CSV columns that are detected as DATETIME.
+var csvTimeFormats = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// resolvePath resolves the path of a data source in the allowed directory. Relative paths are relative to the
+// allowed directory. Symbolic links are followed, so the resolved path is never outside of the allowed directory.
+func resolvePath(allowedDir string, path string) (string, error) {
+	if allowedDir == "" {
+		return "", errLocalFilesDisabled
+	}
+	if path == "" {
+		return "", errors.New("path is required")
+	}
+	root, err := filepath.Abs(allowedDir)
+	if err != nil {
+		return "", err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the allowed directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errPathNotAllowed
+	}
+	return resolved, nil
+}
+
+// isTableFile returns true if the file at the path can be loaded as a table.
+func isTableFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case extCSV, extParquet:
+		return true
+	default:
+		return false
+	}
+}
+
+// tableName returns the name of the table for a file, which is the name of the file without its extension.
+func tableName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+type fileState struct {
+	table   string
+	modTime time.Time
+	size    int64
+}
+
+// fileTables loads CSV and Parquet files as tables into an in-memory SQLite database. The files are either a
+// single file, or all files in a directory. Tables are reloaded when their files change, and dropped when
+// their files are removed.
+type fileTables struct {
+	path   string
+	dir    bool
+	logger log.Logger
+
+	mtx    sync.Mutex
+	loaded map[string]fileState
+	// conn is the driver connection the tables are loaded into.
+	conn any
+}
+
+func newFileTables(path string, dir bool, logger log.Logger) *fileTables {
+	return &fileTables{
+		path:   path,
+		dir:    dir,
+		logger: logger,
+		loaded: make(map[string]fileState),
+	}
+}
+
+// files returns the paths of the files that are loaded as tables.
+func (t *fileTables) files() ([]string, error) {
+	if !t.dir {
+		return []string{t.path}, nil
+	}
+	entries, err := os.ReadDir(t.path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && isTableFile(entry.Name()) {
+			files = append(files, filepath.Join(t.path, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// refresh loads the files that changed since the last refresh. The database must be an in-memory database with
+// a single connection, which is made read-only once the tables are loaded. Files that fail to load are skipped,
+// their errors are returned once all other files are loaded.
+func (t *fileTables) refresh(ctx context.Context, db *sql.DB) error {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			t.logger.Warn("Failed to release connection", "error", err)
+		}
+	}()
+	// The database is lost with its connection, all the files are loaded again into a new one.
+	reopened := false
+	if err := conn.Raw(func(driverConn any) error {
+		if driverConn != t.conn {
+			t.conn, reopened = driverConn, true
+			t.loaded = make(map[string]fileState)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	files, err := t.files()
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	changed := make(map[string]fileState)
+	present := make(map[string]struct{}, len(files))
+	tables := make(map[string]string, len(files))
+	var errs []error
+	for _, file := range files {
+		present[file] = struct{}{}
+		state := fileState{table: tableName(file)}
+		if other, ok := tables[state.table]; ok {
+			errs = append(errs, fmt.Errorf("%s: table %q is already loaded from %s", filepath.Base(file), state.table, filepath.Base(other)))
+			continue
+		}
+		tables[state.table] = file
+		info, err := os.Stat(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		state.modTime, state.size = info.ModTime(), info.Size()
+		if loaded, ok := t.loaded[file]; !ok || loaded != state {
+			changed[file] = state
+		}
+	}
+	var removed []string
+	for file := range t.loaded {
+		if _, ok := present[file]; !ok {
+			removed = append(removed, file)
+		}
+	}
+	if len(changed) == 0 && len(removed) == 0 && !reopened {
+		return errors.Join(errs...)
+	}
+
+	// The connection denies changes to query_only, which is allowed only while the tables are loaded. Queries
+	// cannot run in the meantime, as the database has a single connection.
+	if err := setAuthorizer(conn, nil); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "PRAGMA query_only = ON"); err != nil {
+			t.logger.Error("Failed to make database read-only", "error", err)
+		}
+		if err := setAuthorizer(conn, queryOnlyAuthorizer); err != nil {
+			t.logger.Error("Failed to restore the authorizer of the database", "error", err)
+		}
+	}()
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = OFF"); err != nil {
+		return err
+	}
+
+	for _, file := range removed {
+		if _, err := conn.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdentifier(t.loaded[file].table)); err != nil {
+			return err
+		}
+		delete(t.loaded, file)
+		t.logger.Debug("Dropped table of removed file", "file", file)
+	}
+	for file, state := range changed {
+		start := time.Now()
+		rows, err := loadTable(ctx, conn, file, state.table)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(file), err))
+			continue
+		}
+		t.loaded[file] = state
+		t.logger.Debug("Loaded table from file", "file", file, "table", state.table, "rows", rows, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
+
+// setAuthorizer sets the authorizer of the SQLite connection, or removes it if authorizer is nil.
+func setAuthorizer(conn *sql.Conn, authorizer func(action int, arg1, arg2, arg3 string) int) error {
+	return conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected connection of type %T", driverConn)
+		}
+		c.RegisterAuthorizer(authorizer)
+		return nil
+	})
+}
+
+// table is the content of a file with the SQLite types of its columns.
+type table struct {
+	columns []string
+	types   []string
+	rows    [][]any
+}
+
+func loadTable(ctx context.Context, conn *sql.Conn, file string, name string) (int, error) {
+	var tbl *table
+	var err error
+	switch strings.ToLower(filepath.Ext(file)) {
+	case extCSV:
+		tbl, err = readCSV(file)
+	case extParquet:
+		tbl, err = readParquet(ctx, file)
+	default:
+		err = fmt.Errorf("unsupported file type %q", filepath.Ext(file))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+quoteIdentifier(name)); err != nil {
+		return 0, err
+	}
+	columns := make([]string, len(tbl.columns))
+	placeholders := make([]string, len(tbl.columns))
+	for i, column := range tbl.columns {
+		columns[i] = quoteIdentifier(column) + " " + tbl.types[i]
+		placeholders[i] = "?"
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)", quoteIdentifier(name), strings.Join(columns, ", "))); err != nil {
+		return 0, err
+	}
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)", quoteIdentifier(name), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = stmt.Close() }()
+	for _, row := range tbl.rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, err
+		}
+	}
+	return len(tbl.rows), tx.Commit()
+}
+
+// columnNames makes the names of the columns unique and replaces empty names.
+func columnNames(names []string) []string {
+	result := make([]string, len(names))
+	seen := make(map[string]struct{}, len(names))
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		unique := name
+		for n := 2; ; n++ {
+			if _, ok := seen[strings.ToLower(unique)]; !ok {
+				break
+			}
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		seen[strings.ToLower(unique)] = struct{}{}
+		result[i] = unique
+	}
+	return result
+}
+
+// readCSV reads a CSV file with a header. The type of each column is detected from its values: a column is an
+// INTEGER, REAL or DATETIME column if all of its non-empty values can be parsed as such, otherwise it is a TEXT
+// column. Empty values are NULL.
+func readCSV(file string) (*table, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tbl := &table{
+		columns: columnNames(header),
+		types:   make([]string, len(header)),
+		rows:    make([][]any, len(records)),
+	}
+	for i := range tbl.rows {
+		tbl.rows[i] = make([]any, len(header))
+	}
+	for col := range header {
+		values := make([]string, len(records))
+		for i, record := range records {
+			if col < len(record) {
+				values[i] = record[col]
+			}
+		}
+		tbl.types[col] = csvColumnType(values)
+		for i, value := range values {
+			tbl.rows[i][col] = csvValue(tbl.types[col], value)
+		}
+	}
+	return tbl, nil
+}
+
+func csvColumnType(values []string) string {
+	isInteger, isReal, isTime := true, true, true
+	empty := true
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		empty = false
+		if isInteger {
+			_, err := strconv.ParseInt(value, 10, 64)
+			isInteger = err == nil
+		}
+		if isReal {
+			_, err := strconv.ParseFloat(value, 64)
+			isReal = err == nil
+		}
+		if isTime {
+			_, ok := parseCSVTime(value)
+			isTime = ok
+		}
+		if !isInteger && !isReal && !isTime {
+			return typeText
+		}
+	}
+	switch {
+	case empty:
+		return typeText
+	case isInteger:
+		return typeInteger
+	case isReal:
+		return typeReal
+	case isTime:
+		return typeDatetime
+	default:
+		return typeText
+	}
+}
+
+func csvValue(typ string, value string) any {
+	if value == "" {
+		return nil
+	}
+	switch typ {
+	case typeInteger:
+		v, _ := strconv.ParseInt(value, 10, 64)
+		return v
+	case typeReal:
+		v, _ := strconv.ParseFloat(value, 64)
+		return v
+	case typeDatetime:
+		v, _ := parseCSVTime(value)
+		return v.UTC().Format(storedTimeFormat)
+	default:
+		return value
+	}
+}
+
+func parseCSVTime(value string) (time.Time, bool) {
+	for _, format := range csvTimeFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// readParquet reads a Parquet file. The types of the columns are derived from the Arrow types of the file.
+func readParquet(ctx context.Context, file string) (*table, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	mem := memory.DefaultAllocator
+	arrowTable, err := pqarrow.ReadTable(ctx, f, parquet.NewReaderProperties(mem), pqarrow.ArrowReadProperties{}, mem)
+	if err != nil {
+		return nil, err
+	}
+	defer arrowTable.Release()
+
+	schema := arrowTable.Schema()
+	names := make([]string, len(schema.Fields()))
+	for i, field := range schema.Fields() {
+		names[i] = field.Name
+	}
+	tbl := &table{
+		columns: columnNames(names),
+		types:   make([]string, len(names)),
+		rows:    make([][]any, arrowTable.NumRows()),
+	}
+	for i := range tbl.rows {
+		tbl.rows[i] = make([]any, len(names))
+	}
+	for col, field := range schema.Fields() {
+		tbl.types[col] = arrowColumnType(field.Type)
+		row := 0
+		for _, chunk := range arrowTable.Column(col).Data().Chunks() {
+			for i := 0; i < chunk.Len(); i++ {
+				tbl.rows[row][col] = arrowValue(chunk, i)
+				row++
+			}
+		}
+	}
+	return tbl, nil
+}
+
+func arrowColumnType(t arrow.DataType) string {
+	switch t.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return typeInteger
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64, arrow.DECIMAL128, arrow.DECIMAL256:
+		return typeReal
+	case arrow.BOOL:
+		return typeBoolean
+	case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		return typeDatetime
+	default:
+		return typeText
+	}
+}
+
+func arrowValue(arr arrow.Array, i int) any {
+	if arr.IsNull(i) {
+		return nil
+	}
+	switch a := arr.(type) {
+	case *array.Int8:
+		return int64(a.Value(i))
+	case *array.Int16:
+		return int64(a.Value(i))
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return int64(a.Value(i))
+	case *array.Uint16:
+		return int64(a.Value(i))
+	case *array.Uint32:
+		return int64(a.Value(i))
+	case *array.Uint64:
+		return int64(a.Value(i))
+	case *array.Float16:
+		return float64(a.Value(i).Float32())
+	case *array.Float32:
+		return float64(a.Value(i))
+	case *array.Float64:
+		return a.Value(i)
+	case *array.Decimal128:
+		return a.Value(i).ToFloat64(a.DataType().(*arrow.Decimal128Type).Scale)
+	case *array.Decimal256:
+		return a.Value(i).ToFloat64(a.DataType().(*arrow.Decimal256Type).Scale)
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return a.Value(i).ToTime(unit).UTC().Format(storedTimeFormat)
+	case *array.Date32:
+		return a.Value(i).ToTime().UTC().Format(storedTimeFormat)
+	case *array.Date64:
+		return a.Value(i).ToTime().UTC().Format(storedTimeFormat)
+	default:
+		return arr.ValueStr(i)
+	}
+}
@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow"
+	"github.com/apache/arrow/go/v13/arrow/array"
+	"github.com/apache/arrow/go/v13/arrow/memory"
+	"github.com/apache/arrow/go/v13/parquet"
+	"github.com/apache/arrow/go/v13/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "data.db"), nil, 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.db"), nil, 0600))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.db"), filepath.Join(root, "link.db")))
+
+	t.Run("fails if local files are not enabled", func(t *testing.T) {
+		_, err := resolvePath("", "data.db")
+		require.ErrorIs(t, err, errLocalFilesDisabled)
+	})
+
+	t.Run("resolves paths relative to the allowed directory", func(t *testing.T) {
+		path, err := resolvePath(root, "data.db")
+		require.NoError(t, err)
+		require.Equal(t, "data.db", filepath.Base(path))
+
+		path, err = resolvePath(root, ".")
+		require.NoError(t, err)
+		expected, err := filepath.EvalSymlinks(root)
+		require.NoError(t, err)
+		require.Equal(t, expected, path)
+	})
+
+	t.Run("accepts absolute paths in the allowed directory", func(t *testing.T) {
+		_, err := resolvePath(root, filepath.Join(root, "data.db"))
+		require.NoError(t, err)
+	})
+
+	t.Run("rejects paths outside of the allowed directory", func(t *testing.T) {
+		for _, path := range []string{
+			filepath.Join(outside, "secret.db"),
+			filepath.Join("..", filepath.Base(outside), "secret.db"),
+			"link.db",
+		} {
+			_, err := resolvePath(root, path)
+			require.ErrorIs(t, err, errPathNotAllowed, path)
+		}
+	})
+
+	t.Run("fails if the file does not exist", func(t *testing.T) {
+		_, err := resolvePath(root, "missing.db")
+		require.ErrorContains(t, err, "failed to resolve path")
+	})
+}
+
+func TestCSVColumnType(t *testing.T) {
+	assert.Equal(t, typeInteger, csvColumnType([]string{"1", "", "-3"}))
+	assert.Equal(t, typeReal, csvColumnType([]string{"1", "2.5", "1e3"}))
+	assert.Equal(t, typeDatetime, csvColumnType([]string{"2023-01-02T03:04:05Z", "2023-01-02 03:04:05", "2023-01-02"}))
+	assert.Equal(t, typeText, csvColumnType([]string{"1", "two"}))
+	assert.Equal(t, typeText, csvColumnType([]string{"", ""}))
+}
+
+func TestColumnNames(t *testing.T) {
+	assert.Equal(t, []string{"time", "value", "column_3", "Value_2"}, columnNames([]string{"time", " value ", "", "Value"}))
+}
+
+func openMemoryDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open(driverName, "file::memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestFileTables(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "metrics.csv")
+	require.NoError(t, os.WriteFile(csvFile, []byte("time,host,value\n2023-01-02T03:04:05Z,a,1.5\n2023-01-02T03:05:05Z,b,\n"), 0600))
+	writeParquet(t, filepath.Join(dir, "events.parquet"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+
+	db := openMemoryDB(t)
+	tables := newFileTables(dir, true, backend.NewLoggerWith("logger", "test"))
+	require.NoError(t, tables.refresh(context.Background(), db))
+
+	t.Run("loads CSV files with detected column types", func(t *testing.T) {
+		var ts time.Time
+		var host string
+		var value sql.NullFloat64
+		require.NoError(t, db.QueryRow(`SELECT time, host, value FROM metrics ORDER BY time DESC LIMIT 1`).Scan(&ts, &host, &value))
+		assert.Equal(t, time.Date(2023, 1, 2, 3, 5, 5, 0, time.UTC), ts)
+		assert.Equal(t, "b", host)
+		assert.False(t, value.Valid)
+
+		rows, err := db.Query(`SELECT * FROM metrics`)
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+		types, err := rows.ColumnTypes()
+		require.NoError(t, err)
+		require.Len(t, types, 3)
+		assert.Equal(t, typeDatetime, types[0].DatabaseTypeName())
+		assert.Equal(t, typeText, types[1].DatabaseTypeName())
+		assert.Equal(t, typeReal, types[2].DatabaseTypeName())
+	})
+
+	t.Run("loads Parquet files", func(t *testing.T) {
+		var count int
+		var sum float64
+		require.NoError(t, db.QueryRow(`SELECT count(*), sum(value) FROM events`).Scan(&count, &sum))
+		assert.Equal(t, 3, count)
+		assert.Equal(t, 6.0, sum)
+		var ts time.Time
+		require.NoError(t, db.QueryRow(`SELECT time FROM events ORDER BY time DESC LIMIT 1`).Scan(&ts))
+		assert.Equal(t, time.Unix(1700000120, 0).UTC(), ts)
+	})
+
+	t.Run("the database is read-only", func(t *testing.T) {
+		_, err := db.Exec(`DELETE FROM metrics`)
+		require.Error(t, err)
+		_, err = db.Exec(`ATTACH DATABASE 'other.db' AS other`)
+		require.Error(t, err)
+		_, err = db.Exec(`PRAGMA query_only = OFF`)
+		require.Error(t, err)
+		_, err = db.Exec(`pragma main.QUERY_ONLY(0)`)
+		require.Error(t, err)
+		_, err = db.Exec(`DELETE FROM metrics`)
+		require.Error(t, err)
+		var queryOnly bool
+		require.NoError(t, db.QueryRow(`PRAGMA query_only`).Scan(&queryOnly))
+		assert.True(t, queryOnly)
+	})
+
+	t.Run("reloads changed files and drops removed files", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(csvFile, []byte("time,host,value\n2023-01-02T03:04:05Z,a,1.5\n"), 0600))
+		// Make sure the modification time changes, regardless of the resolution of the file system.
+		require.NoError(t, os.Chtimes(csvFile, time.Now(), time.Now().Add(time.Minute)))
+		require.NoError(t, os.Remove(filepath.Join(dir, "events.parquet")))
+		require.NoError(t, tables.refresh(context.Background(), db))
+
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM metrics`).Scan(&count))
+		assert.Equal(t, 1, count)
+		_, err := db.Exec(`SELECT * FROM events`)
+		require.ErrorContains(t, err, "no such table")
+	})
+
+	t.Run("keeps loading other files if a file is invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.parquet"), []byte("not parquet"), 0600))
+		err := tables.refresh(context.Background(), db)
+		require.ErrorContains(t, err, "broken.parquet")
+
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM metrics`).Scan(&count))
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("reloads all files when the connection is reopened", func(t *testing.T) {
+		// Closing the idle connection loses the in-memory database.
+		db.SetMaxIdleConns(0)
+		db.SetMaxIdleConns(1)
+		_, err := db.Exec(`SELECT * FROM metrics`)
+		require.ErrorContains(t, err, "no such table")
+
+		require.ErrorContains(t, tables.refresh(context.Background(), db), "broken.parquet")
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM metrics`).Scan(&count))
+		assert.Equal(t, 1, count)
+		_, err = db.Exec(`DELETE FROM metrics`)
+		require.Error(t, err)
+	})
+}
+
+func writeParquet(t *testing.T, path string) {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{1700000000000, 1700000060000, 1700000120000}, nil)
+	b.Field(1).(*array.Float64Builder).AppendValues([]float64{1, 2, 3}, nil)
+	b.Field(2).(*array.StringBuilder).AppendValues([]string{"a", "", "c"}, []bool{true, false, true})
+	record := b.NewRecord()
+	defer record.Release()
+
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w, err := pqarrow.NewFileWriter(schema, f, parquet.NewWriterProperties(), pqarrow.DefaultWriterProps())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(record))
+	require.NoError(t, w.Close())
+}
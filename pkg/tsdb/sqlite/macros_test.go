@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSqliteMacroEngine()
+	query := &backend.DataQuery{}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("interpolate __time function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select CASE WHEN typeof(time_column) IN ('integer', 'real') THEN CAST(time_column AS INTEGER) ELSE CAST(strftime('%s', time_column) AS INTEGER) END AS time_sec", sql)
+	})
+
+	t.Run("interpolate __timeGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY CASE WHEN typeof(time_column) IN ('integer', 'real') THEN CAST(time_column AS INTEGER) ELSE CAST(strftime('%s', time_column) AS INTEGER) END / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("interpolate __timeGroup function with fill", func(t *testing.T) {
+		fillQuery := &backend.DataQuery{JSON: []byte("{}")}
+		_, err := engine.Interpolate(fillQuery, timeRange, "GROUP BY $__timeGroup(time_column, '5m', NULL)")
+		require.NoError(t, err)
+		require.Contains(t, string(fillQuery.JSON), `"fill":true`)
+		require.Contains(t, string(fillQuery.JSON), `"fillMode":"null"`)
+	})
+
+	t.Run("interpolate __timeFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "WHERE CASE WHEN typeof(time_column) IN ('integer', 'real') THEN julianday(time_column, 'unixepoch') ELSE julianday(time_column) END BETWEEN julianday('2018-04-12 18:00:00') AND julianday('2018-04-12 18:05:00')", sql)
+	})
+
+	t.Run("interpolate __timeFrom and __timeTo functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__timeFrom(), $__timeTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, "select '2018-04-12 18:00:00', '2018-04-12 18:05:00'", sql)
+	})
+
+	t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochFilter(time)")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __unixEpochNanoFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochNanoFilter(time)")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("select time >= %d AND time <= %d", from.UnixNano(), to.UnixNano()), sql)
+	})
+
+	t.Run("interpolate __unixEpochNanoFrom and __unixEpochNanoTo functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__unixEpochNanoFrom(), $__unixEpochNanoTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("select %d, %d", from.UnixNano(), to.UnixNano()), sql)
+	})
+
+	t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroup(time_column,'5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "SELECT CAST(time_column AS INTEGER) / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("fails for unknown macros and missing arguments", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "select $__unknown(time)")
+		require.ErrorContains(t, err, "unknown macro __unknown")
+
+		_, err = engine.Interpolate(query, timeRange, "select $__timeGroup(time)")
+		require.ErrorContains(t, err, "macro __timeGroup needs time column and interval")
+	})
+}
+
+func TestMacroEngineTimeColumnTypes(t *testing.T) {
+	db := openMemoryDB(t)
+	_, err := db.Exec(`CREATE TABLE events (time)`)
+	require.NoError(t, err)
+	from := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+	// Times are stored as text, and as seconds since the Unix epoch.
+	for _, v := range []any{"2023-01-02 03:01:00", "2023-01-02T03:02:00Z", from.Add(3 * time.Minute).Unix(), float64(from.Add(4 * time.Minute).Unix()), from.Add(time.Hour).Unix()} {
+		_, err := db.Exec(`INSERT INTO events VALUES (?)`, v)
+		require.NoError(t, err)
+	}
+
+	engine := newSqliteMacroEngine()
+	timeRange := backend.TimeRange{From: from, To: from.Add(5 * time.Minute)}
+	sql, err := engine.Interpolate(&backend.DataQuery{}, timeRange, "SELECT $__time(time) FROM events WHERE $__timeFilter(time) ORDER BY 1")
+	require.NoError(t, err)
+	rows, err := db.Query(sql)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var times []int64
+	for rows.Next() {
+		var ts int64
+		require.NoError(t, rows.Scan(&ts))
+		times = append(times, ts)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []int64{
+		from.Add(time.Minute).Unix(),
+		from.Add(2 * time.Minute).Unix(),
+		from.Add(3 * time.Minute).Unix(),
+		from.Add(4 * time.Minute).Unix(),
+	}, times)
+}
@@ -0,0 +1,285 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+// driverName is the name of the SQLite driver that is used by the data source. It does not allow to attach
+// other databases, so queries cannot read files outside of the allowed directory, and it does not allow to
+// change the query_only pragma, so queries cannot make the in-memory databases of files writable.
+const driverName = "sqlite3_datasource"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+			conn.RegisterAuthorizer(queryOnlyAuthorizer)
+			return nil
+		},
+	})
+}
+
+// queryOnlyAuthorizer denies the statements that change the query_only pragma, and allows all other statements.
+func queryOnlyAuthorizer(action int, arg1, arg2, _ string) int {
+	if action == sqlite3.SQLITE_PRAGMA && strings.EqualFold(arg1, "query_only") && arg2 != "" {
+		return sqlite3.SQLITE_DENY
+	}
+	return sqlite3.SQLITE_OK
+}
+
+type Service struct {
+	im     instancemgmt.InstanceManager
+	logger log.Logger
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	logger := backend.NewLoggerWith("logger", "tsdb.sqlite")
+	return &Service{
+		im:     datasource.NewInstanceManager(newInstanceSettings(cfg, logger)),
+		logger: logger,
+	}
+}
+
+// instance is a SQLite database file, or an in-memory database with the tables of CSV and Parquet files.
+type instance struct {
+	handler *sqleng.DataSourceHandler
+	// files is nil if the data source is a SQLite database file.
+	files *fileTables
+	db    *sql.DB
+}
+
+func (i *instance) Dispose() {
+	i.handler.Dispose()
+}
+
+func newInstanceSettings(cfg *setting.Cfg, logger log.Logger) datasource.InstanceFactoryFunc {
+	return func(ctx context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    cfg.SqlDatasourceMaxOpenConnsDefault,
+			MaxIdleConns:    cfg.SqlDatasourceMaxIdleConnsDefault,
+			ConnMaxLifetime: cfg.SqlDatasourceMaxConnLifetimeDefault,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		path, err := resolvePath(cfg.SqlDatasourceLocalFilesPath, settings.URL)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData: jsonData,
+			URL:      path,
+			ID:       settings.ID,
+			Updated:  settings.Updated,
+			UID:      settings.UID,
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR"},
+			RowLimit:          cfg.DataProxyRowLimit,
+			SchemaQueries:     schemaQueries,
+		}
+
+		var files *fileTables
+		var db *sql.DB
+		if info.IsDir() || isTableFile(path) {
+			files = newFileTables(path, info.IsDir(), logger)
+			db, err = sql.Open(driverName, "file::memory:")
+			if err != nil {
+				return nil, err
+			}
+			// Every connection has its own in-memory database, so there must be a single connection that is never closed.
+			db.SetMaxOpenConns(1)
+			db.SetMaxIdleConns(1)
+			db.SetConnMaxLifetime(0)
+			db.SetConnMaxIdleTime(0)
+			if err := files.refresh(ctx, db); err != nil {
+				logger.Warn("Failed to load files", "path", path, "error", err)
+			}
+		} else {
+			u := url.URL{Scheme: "file", Opaque: (&url.URL{Path: path}).EscapedPath(), RawQuery: "mode=ro&_query_only=true"}
+			db, err = sql.Open(driverName, u.String())
+			if err != nil {
+				return nil, err
+			}
+			db.SetMaxOpenConns(config.DSInfo.JsonData.MaxOpenConns)
+			db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
+			db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
+		}
+
+		handler, err := sqleng.NewQueryDataHandler(cfg, db, config, &sqliteQueryResultTransformer{}, newSqliteMacroEngine(), logger)
+		if err != nil {
+			return nil, err
+		}
+		return &instance{handler: handler, files: files, db: db}, nil
+	}
+}
+
+func (s *Service) getInstance(ctx context.Context, pluginCtx backend.PluginContext) (*instance, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	return i.(*instance), nil
+}
+
+// CheckHealth pings the database and reports files that could not be loaded as tables.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+
+	if inst.files != nil {
+		if err := inst.files.refresh(ctx, inst.db); err != nil {
+			return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: fmt.Sprintf("Failed to load files: %s", err)}, nil
+		}
+	}
+
+	if err := inst.handler.Ping(); err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: inst.handler.TransformQueryError(s.logger, err).Error()}, nil
+	}
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	if inst.files != nil {
+		// Files that fail to load keep their previous table, so queries of other tables still work.
+		if err := inst.files.refresh(ctx, inst.db); err != nil {
+			s.logger.Warn("Failed to load files", "error", err)
+		}
+	}
+	return inst.handler.QueryData(ctx, req)
+}
+
+// CallResource serves the schemas, tables, columns and indexes of the database to the query editor.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	inst, err := s.getInstance(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	if inst.files != nil {
+		if err := inst.files.refresh(ctx, inst.db); err != nil {
+			s.logger.Warn("Failed to load files", "error", err)
+		}
+	}
+	return inst.handler.CallResource(ctx, req, sender)
+}
+
+// schemaQueries introspect the schema of the database with the table-valued pragma functions.
+var schemaQueries = &sqleng.SchemaQueries{
+	CurrentSchema: `SELECT 'main'`,
+	Schemas:       `SELECT name FROM pragma_database_list ORDER BY name`,
+	Tables:        `SELECT name FROM pragma_table_list WHERE schema = ?1 AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+	Columns:       `SELECT name, type FROM pragma_table_info(?2, ?1) ORDER BY cid`,
+	Indexes: `SELECT il.name, ii.name, il."unique" FROM pragma_index_list(?2, ?1) il, pragma_index_info(il.name, ?1) ii
+		ORDER BY il.name, ii.seqno`,
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// GetConverters returns a dynamic converter, as SQLite does not report the types of computed columns, such as
+// the results of macros and aggregations. The types of the columns are detected from their values instead.
+// Time columns are always converted to time, as computed time values, e.g. max(time), are returned as text.
+func (t *sqliteQueryResultTransformer) GetConverters() []sqlutil.Converter {
+	converters := []sqlutil.Converter{{Name: "dynamic", Dynamic: true}}
+	for _, column := range []string{"time", "timeend"} {
+		converters = append(converters, sqlutil.Converter{
+			Name:            "handle " + column,
+			InputColumnName: column,
+			FrameConverter: sqlutil.FrameConverter{
+				FieldType:     data.FieldTypeNullableTime,
+				ConverterFunc: convertTime,
+			},
+		})
+	}
+	return converters
+}
+
+// convertTime converts a time value of SQLite to time. Numbers are seconds, milliseconds or nanoseconds since the
+// Unix epoch, depending on their magnitude, and text is parsed in the formats of the SQLite driver.
+func convertTime(in any) (any, error) {
+	var t time.Time
+	switch v := in.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		t = v
+	case int64:
+		t = epochToTime(float64(v))
+	case float64:
+		t = epochToTime(v)
+	case []byte:
+		return convertTime(string(v))
+	case string:
+		var err error
+		if t, err = parseTime(v); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported time value of type %T", in)
+	}
+	return &t, nil
+}
+
+func epochToTime(v float64) time.Time {
+	switch abs := math.Abs(v); {
+	case abs < 1e11:
+		return time.Unix(0, int64(v*1e9)).UTC()
+	case abs < 1e14:
+		return time.UnixMilli(int64(v)).UTC()
+	default:
+		return time.Unix(0, int64(v)).UTC()
+	}
+}
+
+func parseTime(v string) (time.Time, error) {
+	v = strings.TrimSuffix(v, "Z")
+	for _, format := range sqlite3.SQLiteTimestampFormats {
+		if t, err := time.ParseInLocation(format, v, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse time %q", v)
+}
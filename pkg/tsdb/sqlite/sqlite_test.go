@@ -0,0 +1,212 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	cfg := setting.NewCfg()
+	cfg.SqlDatasourceLocalFilesPath = dir
+	cfg.DataProxyRowLimit = 1000000
+
+	// The metrics are stored as a SQLite database file and as CSV file, so all queries must return the same results.
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "metrics.db"))
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE metrics (time DATETIME, host TEXT, value REAL)`)
+	require.NoError(t, err)
+	csv := "time,host,value\n"
+	start := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		// There are no values in the third minute.
+		if i == 2 || i == 3 {
+			continue
+		}
+		ts := start.Add(time.Duration(i) * 30 * time.Second)
+		_, err = db.Exec(`INSERT INTO metrics VALUES (?, ?, ?)`, ts.Format(storedTimeFormat), "a", float64(i))
+		require.NoError(t, err)
+		csv += ts.Format(time.RFC3339) + ",a," + string(rune('0'+i)) + "\n"
+	}
+	require.NoError(t, db.Close())
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "extracts"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extracts", "metrics.csv"), []byte(csv), 0600))
+
+	s := ProvideService(cfg)
+	pluginContext := func(id int64, path string) backend.PluginContext {
+		return backend.PluginContext{
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+				ID:       id,
+				URL:      path,
+				JSONData: []byte("{}"),
+			},
+		}
+	}
+	timeRange := backend.TimeRange{From: start, To: start.Add(3 * time.Minute)}
+	query := func(rawSQL string, format string) backend.DataQuery {
+		model, err := json.Marshal(map[string]any{"rawSql": rawSQL, "format": format})
+		require.NoError(t, err)
+		return backend.DataQuery{
+			RefID:     "A",
+			JSON:      model,
+			TimeRange: timeRange,
+			Interval:  time.Minute,
+		}
+	}
+
+	for i, path := range []string{"metrics.db", "extracts", filepath.Join("extracts", "metrics.csv")} {
+		t.Run(path, func(t *testing.T) {
+			pCtx := pluginContext(int64(i+1), path)
+
+			t.Run("health check succeeds", func(t *testing.T) {
+				res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pCtx})
+				require.NoError(t, err)
+				assert.Equal(t, backend.HealthStatusOk, res.Status, res.Message)
+			})
+
+			t.Run("queries rows in the time range", func(t *testing.T) {
+				resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+					PluginContext: pCtx,
+					Queries:       []backend.DataQuery{query(`SELECT time, value FROM metrics WHERE $__timeFilter(time) AND value > 0 ORDER BY time`, "time_series")},
+				})
+				require.NoError(t, err)
+				res := resp.Responses["A"]
+				require.NoError(t, res.Error)
+				require.Len(t, res.Frames, 1)
+				frame := res.Frames[0]
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, 3, frame.Rows())
+				assert.Equal(t, start.Add(30*time.Second), *frame.Fields[0].At(0).(*time.Time))
+				assert.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+			})
+
+			t.Run("groups by time and fills missing values", func(t *testing.T) {
+				resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+					PluginContext: pCtx,
+					Queries: []backend.DataQuery{query(`SELECT $__timeGroupAlias(time, '1m', 0), host AS metric, sum(value) AS value
+						FROM metrics WHERE $__timeFilter(time) GROUP BY 1, 2 ORDER BY 1`, "time_series")},
+				})
+				require.NoError(t, err)
+				res := resp.Responses["A"]
+				require.NoError(t, res.Error)
+				require.Len(t, res.Frames, 1)
+				frame := res.Frames[0]
+				require.Equal(t, 4, frame.Rows())
+				require.True(t, frame.Fields[0].Type().Time())
+				assert.Equal(t, "a", frame.Fields[1].Name)
+				var values []float64
+				for i := 0; i < frame.Rows(); i++ {
+					v, _ := frame.Fields[1].FloatAt(i)
+					values = append(values, v)
+				}
+				assert.Equal(t, []float64{1, 0, 9, 0}, values)
+			})
+
+			t.Run("serves the schema to the query editor", func(t *testing.T) {
+				sender := &fakeResourceSender{}
+				err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+					PluginContext: pCtx,
+					Method:        "GET",
+					Path:          "columns",
+					URL:           "columns?table=metrics",
+				}, sender)
+				require.NoError(t, err)
+				require.NotNil(t, sender.res)
+				require.Equal(t, 200, sender.res.Status, string(sender.res.Body))
+				var columns []map[string]string
+				require.NoError(t, json.Unmarshal(sender.res.Body, &columns))
+				require.Len(t, columns, 3)
+				assert.Equal(t, map[string]string{"name": "time", "type": "DATETIME"}, columns[0])
+				assert.Equal(t, map[string]string{"name": "host", "type": "TEXT"}, columns[1])
+				// The values in the CSV file are integers.
+				assert.Equal(t, "value", columns[2]["name"])
+			})
+
+			t.Run("converts computed time columns", func(t *testing.T) {
+				resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+					PluginContext: pCtx,
+					Queries:       []backend.DataQuery{query(`SELECT max(time) AS time, count(*) AS value FROM metrics`, "time_series")},
+				})
+				require.NoError(t, err)
+				res := resp.Responses["A"]
+				require.NoError(t, res.Error)
+				frame := res.Frames[0]
+				assert.Equal(t, start.Add(150*time.Second), *frame.Fields[0].At(0).(*time.Time))
+				assert.Equal(t, 4.0, *frame.Fields[1].At(0).(*float64))
+			})
+		})
+	}
+
+	t.Run("health check fails for paths outside of the allowed directory", func(t *testing.T) {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(4, os.TempDir())})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+		assert.Contains(t, res.Message, errPathNotAllowed.Error())
+	})
+
+	t.Run("databases are read-only", func(t *testing.T) {
+		for i := range []string{"metrics.db", "extracts"} {
+			for _, sql := range []string{`PRAGMA query_only = OFF`, `DELETE FROM metrics`} {
+				_, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+					PluginContext: pluginContext(int64(i+1), ""),
+					Queries:       []backend.DataQuery{query(sql, "table")},
+				})
+				require.NoError(t, err)
+			}
+			resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+				PluginContext: pluginContext(int64(i+1), ""),
+				Queries:       []backend.DataQuery{query(`SELECT count(*) AS value FROM metrics`, "table")},
+			})
+			require.NoError(t, err)
+			require.NoError(t, resp.Responses["A"].Error)
+			v, err := resp.Responses["A"].Frames[0].Fields[0].FloatAt(0)
+			require.NoError(t, err)
+			assert.Equal(t, 4.0, v)
+		}
+	})
+}
+
+func TestConvertTime(t *testing.T) {
+	expected := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, in := range []any{
+		expected,
+		expected.Unix(),
+		float64(expected.Unix()),
+		expected.UnixMilli(),
+		expected.UnixNano(),
+		"2023-01-02 03:04:05",
+		"2023-01-02T03:04:05Z",
+		[]byte("2023-01-02 05:04:05+02:00"),
+	} {
+		out, err := convertTime(in)
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(*out.(*time.Time)), "%v", in)
+	}
+
+	out, err := convertTime(nil)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+
+	_, err = convertTime("yesterday")
+	require.Error(t, err)
+}
+
+type fakeResourceSender struct {
+	res *backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(res *backend.CallResourceResponse) error {
+	s.res = res
+	return nil
+}
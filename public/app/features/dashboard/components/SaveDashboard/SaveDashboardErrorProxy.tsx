@@ -5,6 +5,7 @@ import { GrafanaTheme2 } from '@grafana/data';
 import { FetchError } from '@grafana/runtime';
 import { Dashboard } from '@grafana/schema';
 import { Button, ConfirmModal, Modal, useStyles2 } from '@grafana/ui';
+import { dashboardWatcher } from 'app/features/live/dashboard/dashboardWatcher';
 
 import { DashboardModel } from '../../state/DashboardModel';
 
@@ -68,6 +69,24 @@ export const SaveDashboardErrorProxy = ({
           onDismiss={onDismiss}
         />
       )}
+      {error.data && error.data.status === 'edit-locked' && (
+        <ConfirmModal
+          isOpen={true}
+          title="Dashboard is being edited"
+          body={
+            <div>
+              {error.data.message} <br /> <small>Would you like to take over editing and save this dashboard?</small>
+            </div>
+          }
+          confirmText="Take over and save"
+          onConfirm={async () => {
+            await dashboardWatcher.takeOverEditing();
+            await onDashboardSave(dashboardSaveModel, {}, dashboard);
+            onDismiss();
+          }}
+          onDismiss={onDismiss}
+        />
+      )}
       {error.data && error.data.status === 'plugin-dashboard' && (
         <ConfirmPluginDashboardSaveModal
           dashboard={dashboard}
@@ -117,6 +136,7 @@ export const proxyHandlesError = (errorStatus: string) => {
   switch (errorStatus) {
     case 'version-mismatch':
     case 'name-exists':
+    case 'edit-locked':
     case 'plugin-dashboard':
       return true;
 
@@ -168,7 +168,7 @@ export class UnthemedDashboardPage extends PureComponent<Props, State> {
 
     // entering edit mode
     if (this.state.editPanel && !prevState.editPanel) {
-      dashboardWatcher.setEditingState(true);
+      dashboardWatcher.setEditingState(true, this.state.editPanel.id);
 
       // Some panels need to be notified when entering edit mode
       this.props.dashboard?.events.publish(new PanelEditEnteredEvent(this.state.editPanel.id));
@@ -5,6 +5,7 @@ import {
   DisconnectedContext,
   ServerPublicationContext,
   State,
+  TransportName,
 } from 'centrifuge';
 import { BehaviorSubject, Observable, share, startWith } from 'rxjs';
 
@@ -37,6 +38,7 @@ export type CentrifugeSrvDeps = {
   orgId: number;
   orgRole: string;
   liveEnabled: boolean;
+  liveTransports: string[];
   dataStreamSubscriberReadiness: Observable<boolean>;
 };
 
@@ -74,15 +76,23 @@ export class CentrifugeService implements CentrifugeSrv {
   constructor(private deps: CentrifugeSrvDeps) {
     this.dataStreamSubscriberReadiness = deps.dataStreamSubscriberReadiness.pipe(share(), startWith(true));
 
-    let liveUrl = `${deps.appUrl.replace(/^http/, 'ws')}/api/live/ws`;
-
     const token = deps.grafanaAuthToken;
-    if (token !== null && token !== '') {
-      liveUrl += '?auth_token=' + token;
-    }
-
-    this.centrifuge = new Centrifuge(liveUrl, {
+    const withToken = (url: string) => (token !== null && token !== '' ? `${url}?auth_token=${token}` : url);
+
+    // Transports are tried in the configured order, so clients fall back to HTTP streaming
+    // or Server-Sent Events when WebSocket connections are blocked.
+    const transports = (deps.liveTransports.length ? deps.liveTransports : ['websocket']).map((transport) => ({
+      transport: transport as TransportName,
+      endpoint: withToken(
+        transport === 'websocket'
+          ? `${deps.appUrl.replace(/^http/, 'ws')}/api/live/ws`
+          : `${deps.appUrl}/api/live/${transport}`
+      ),
+    }));
+
+    this.centrifuge = new Centrifuge(transports, {
       timeout: 30000,
+      emulationEndpoint: withToken(`${deps.appUrl}/api/live/emulation`),
     });
     // orgRole is set when logged in *or* anonymous users can use grafana
     if (deps.liveEnabled && deps.orgRole !== '') {
@@ -17,29 +17,46 @@ import { ShowModalReactEvent } from '../../../types/events';
 import { getDashboardSrv } from '../../dashboard/services/DashboardSrv';
 
 import { DashboardChangedModal } from './DashboardChangedModal';
-import { DashboardEvent, DashboardEventAction } from './types';
+import { DashboardEditLock, DashboardEvent, DashboardEventAction, PanelEditor } from './types';
 
 // sessionId is not a security-sensitive value.
 // It is used for filtering out dashboard edit events from the same browsing session
 const sessionId = uuidv4();
 
+// The server expires edit locks after a minute, so they are renewed while editing
+const editingHeartbeatInterval = 30 * 1000;
+
 class DashboardWatcher {
   channel?: LiveChannelAddress; // path to the channel
   uid?: string;
   ignoreSave?: boolean;
   editing = false;
+  editPanelId?: number;
   lastEditing?: DashboardEvent;
   subscription?: Unsubscribable;
   hasSeenNotice?: boolean;
+  heartbeat?: ReturnType<typeof setInterval>;
+
+  /** The edit lock of the dashboard, saves by other users are rejected while it is held */
+  editLock?: DashboardEditLock;
+  /** The panels which are being edited, by session */
+  panelEditors: PanelEditor[] = [];
 
-  setEditingState(state: boolean) {
+  setEditingState(state: boolean, panelId?: number) {
     const changed = (this.editing = state);
     this.editing = state;
+    this.editPanelId = state ? panelId : undefined;
     this.hasSeenNotice = false;
 
     if (changed && contextSrv.isEditor) {
       this.sendEditingState();
     }
+
+    clearInterval(this.heartbeat);
+    this.heartbeat = undefined;
+    if (state && contextSrv.isEditor) {
+      this.heartbeat = setInterval(() => this.sendEditingState(), editingHeartbeatInterval);
+    }
   }
 
   private sendEditingState() {
@@ -49,11 +66,48 @@ class DashboardWatcher {
         sessionId,
         uid,
         action: this.editing ? DashboardEventAction.EditingStarted : DashboardEventAction.EditingCanceled,
+        panelId: this.editPanelId,
         timestamp: Date.now(),
       });
     }
   }
 
+  /** Takes over the edit lock from the session which is editing the dashboard */
+  async takeOverEditing() {
+    const { channel, uid } = this;
+    if (channel && uid) {
+      await getGrafanaLiveSrv().publish(channel, {
+        sessionId,
+        uid,
+        action: DashboardEventAction.EditingTakeover,
+        panelId: this.editPanelId,
+        timestamp: Date.now(),
+      });
+    }
+  }
+
+  /** Returns the editors of a panel in other sessions */
+  getPanelEditors(panelId: number) {
+    return this.panelEditors.filter((editor) => editor.panelId === panelId && editor.sessionId !== sessionId);
+  }
+
+  private updateEditState(event: DashboardEvent) {
+    const previous = this.editLock;
+    if (event.action !== DashboardEventAction.EditingState && !event.lock && !event.panels) {
+      return;
+    }
+    this.editLock = event.lock;
+    this.panelEditors = event.panels ?? [];
+
+    if (previous?.sessionId === sessionId && event.lock && event.lock.sessionId !== sessionId) {
+      const user = event.lock.user;
+      appEvents.emit(AppEvents.alertWarning, [
+        'Editing was taken over',
+        `${user?.name || user?.login || 'Another user'} is editing this dashboard now`,
+      ]);
+    }
+  }
+
   watch(uid: string) {
     const live = getGrafanaLiveSrv();
     if (!live) {
@@ -81,6 +135,8 @@ class DashboardWatcher {
     }
     this.subscription = undefined;
     this.uid = undefined;
+    this.editLock = undefined;
+    this.panelEditors = [];
   }
 
   ignoreNextSave() {
@@ -105,6 +161,8 @@ class DashboardWatcher {
       }
 
       if (isLiveChannelMessageEvent(event)) {
+        this.updateEditState(event.message);
+
         if (event.message.sessionId === sessionId) {
           return; // skip internal messages
         }
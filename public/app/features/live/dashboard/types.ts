@@ -2,14 +2,43 @@ export enum DashboardEventAction {
   Saved = 'saved',
   EditingStarted = 'editing-started', // Sent when someone (who can save!) opens the editor
   EditingCanceled = 'editing-cancelled', // Sent when someone discards changes, or unsubscribes while editing
+  EditingTakeover = 'editing-takeover', // Sent when someone takes over the edit lock from another session
+  PanelEditingStarted = 'panel-editing-started',
+  PanelEditingFinished = 'panel-editing-finished',
+  EditingState = 'editing-state', // Sent by the server on subscribe
   Deleted = 'deleted',
 }
 
+export interface DashboardEditor {
+  id?: number;
+  login?: string;
+  name?: string;
+  avatarUrl?: string;
+}
+
+export interface DashboardEditLock {
+  user?: DashboardEditor;
+  sessionId: string;
+  since: number;
+  expires: number;
+}
+
+export interface PanelEditor {
+  panelId: number;
+  user?: DashboardEditor;
+  sessionId: string;
+  expires: number;
+}
+
 export interface DashboardEvent {
   uid: string;
   action: DashboardEventAction;
   userId?: number;
+  user?: DashboardEditor;
   message?: string;
   sessionId?: string;
   timestamp?: number;
+  panelId?: number;
+  lock?: DashboardEditLock;
+  panels?: PanelEditor[];
 }
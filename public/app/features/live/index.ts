@@ -14,6 +14,7 @@ export function initGrafanaLive() {
     orgId: contextSrv.user.orgId,
     orgRole: contextSrv.user.orgRole,
     liveEnabled: config.liveEnabled,
+    liveTransports: config.liveTransports,
     dataStreamSubscriberReadiness: liveTimer.ok.asObservable(),
     grafanaAuthToken: loadUrlToken(),
   };
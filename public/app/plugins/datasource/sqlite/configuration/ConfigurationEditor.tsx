@@ -0,0 +1,70 @@
+import React, { SyntheticEvent } from 'react';
+
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
+import { Field, Input } from '@grafana/ui';
+import { Divider } from 'app/features/plugins/sql/components/configuration/Divider';
+
+import { SQLiteOptions } from '../types';
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+
+  const onPathChanged = (event: SyntheticEvent<HTMLInputElement>) => {
+    onOptionsChange({ ...options, url: event.currentTarget.value });
+  };
+
+  const WIDTH_LONG = 40;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlite/"
+        hasRequiredFields={true}
+      />
+
+      <Divider />
+
+      <ConfigSection title="Files">
+        <Field
+          label="Path"
+          required
+          description={
+            <span>
+              Path of a SQLite database file, a CSV or Parquet file, or a directory of CSV and Parquet files. Relative
+              paths are relative to the <code>local_files_path</code> directory of the Grafana server, which all paths
+              must be in. Every CSV and Parquet file is a table named after the file.
+            </span>
+          }
+        >
+          <Input
+            width={WIDTH_LONG}
+            name="path"
+            type="text"
+            value={options.url || ''}
+            placeholder="metrics.db"
+            onChange={onPathChanged}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings" isCollapsible>
+        <Field
+          label="Min time interval"
+          description="A lower limit for the auto group by time interval. Recommended to be set to write frequency, for example 1m if your data is written every minute."
+        >
+          <Input
+            width={WIDTH_LONG}
+            placeholder="1m"
+            value={jsonData.timeInterval || ''}
+            onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
+          />
+        </Field>
+      </ConfigSection>
+    </>
+  );
+};
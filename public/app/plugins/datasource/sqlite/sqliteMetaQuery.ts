@@ -0,0 +1,9 @@
+import { quoteLiteral } from './sqlUtil';
+
+export function showTables() {
+  return `SELECT name AS "table" FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`;
+}
+
+export function getSchema(table: string) {
+  return `SELECT name AS "column", type AS "type" FROM pragma_table_info(${quoteLiteral(table)}) ORDER BY cid`;
+}